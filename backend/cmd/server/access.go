@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/auth"
+	"github.com/rookiecj/scrum-agents/backend/internal/config"
+)
+
+// Routes whose access level is set by the policy. History routes always
+// require a user and the auth endpoints are always public, so neither is
+// listed.
+const (
+	routeDetect    = "POST /api/detect"
+	routeExtract   = "POST /api/extract"
+	routeProviders = "GET /api/providers"
+	routeClassify  = "POST /api/classify"
+	routeSummarize = "POST /api/summarize"
+	routeProcess   = "POST /api/process"
+	routeBatch     = "POST /api/batch"
+	routeJobs      = "POST /api/jobs"
+)
+
+// llmRoutes spend LLM credits.
+var llmRoutes = []string{routeClassify, routeSummarize, routeProcess, routeBatch, routeJobs}
+
+// userRoutes are LLM routes anonymous access leaves to users: a batch spends
+// many summaries in one request, and jobs are looked up by their owner.
+var userRoutes = []string{routeBatch, routeJobs}
+
+// policyRoutes are all routes whose access level is configurable.
+var policyRoutes = append([]string{routeDetect, routeExtract, routeProviders}, llmRoutes...)
+
+// routeScopes are the scopes personal access tokens need per route.
+var routeScopes = map[string]string{
+	routeDetect:    auth.ScopeExtract,
+	routeExtract:   auth.ScopeExtract,
+	routeClassify:  auth.ScopeClassify,
+	routeSummarize: auth.ScopeSummarize,
+	routeProcess:   auth.ScopeSummarize,
+	routeBatch:     auth.ScopeSummarize,
+	routeJobs:      auth.ScopeSummarize,
+}
+
+// accessPolicy builds the access policy from cfg. By default, detection,
+// extraction and the provider list are public and LLM routes require a
+// token; cfg.Anonymous opens the LLM routes other than batch and jobs, and
+// cfg.Routes overrides single routes.
+func accessPolicy(cfg config.Access) (auth.Policy, error) {
+	policy := auth.Policy{
+		Routes: map[string]auth.Access{
+			routeDetect:    auth.AccessPublic,
+			routeExtract:   auth.AccessPublic,
+			routeProviders: auth.AccessPublic,
+		},
+		Default: auth.AccessUser,
+		Scopes:  routeScopes,
+	}
+	if cfg.Anonymous {
+		for _, r := range llmRoutes {
+			if !slices.Contains(userRoutes, r) {
+				policy.Routes[r] = auth.AccessAnonymous
+			}
+		}
+	}
+
+	for pattern, a := range cfg.Routes {
+		if !slices.Contains(policyRoutes, pattern) {
+			return auth.Policy{}, fmt.Errorf("access.routes: unknown route %q", pattern)
+		}
+		policy.Routes[pattern] = a
+	}
+	return policy, nil
+}
+
+// logPolicy logs the access level of every policy route.
+func logPolicy(p auth.Policy) {
+	attrs := []any{}
+	for _, r := range policyRoutes {
+		attrs = append(attrs, slog.String(r, string(p.Access(r))))
+	}
+	slog.Info("access policy", slog.Group("routes", attrs...))
+}
+
+// purgeTokens deletes expired refresh tokens and denylist entries now and
+// then every interval, until ctx is done.
+func purgeTokens(ctx context.Context, store *auth.Store, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := store.PurgeExpiredTokens(time.Now()); err != nil {
+			slog.Warn("failed to purge expired tokens", slog.String("error", err.Error()))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/auth"
+	"github.com/rookiecj/scrum-agents/backend/internal/config"
+)
+
+func TestAccessPolicy_Defaults(t *testing.T) {
+	p, err := accessPolicy(config.Default().Access)
+	if err != nil {
+		t.Fatalf("accessPolicy() error = %v", err)
+	}
+	for _, r := range []string{routeDetect, routeExtract, routeProviders} {
+		if got := p.Access(r); got != auth.AccessPublic {
+			t.Errorf("Access(%q) = %q, want public", r, got)
+		}
+	}
+	for _, r := range llmRoutes {
+		if got := p.Access(r); got != auth.AccessUser {
+			t.Errorf("Access(%q) = %q, want user", r, got)
+		}
+	}
+	if got := p.Scopes[routeProcess]; got != auth.ScopeSummarize {
+		t.Errorf("Scopes[%q] = %q, want summarize", routeProcess, got)
+	}
+}
+
+func TestAccessPolicy_Config(t *testing.T) {
+	p, err := accessPolicy(config.Access{
+		Anonymous: true,
+		Routes: map[string]auth.Access{
+			routeExtract: auth.AccessUser,
+			routeProcess: auth.AccessUser,
+		},
+	})
+	if err != nil {
+		t.Fatalf("accessPolicy() error = %v", err)
+	}
+	want := map[string]auth.Access{
+		routeDetect:    auth.AccessPublic,
+		routeExtract:   auth.AccessUser,
+		routeClassify:  auth.AccessAnonymous,
+		routeSummarize: auth.AccessAnonymous,
+		routeProcess:   auth.AccessUser,
+		routeBatch:     auth.AccessUser,
+		routeJobs:      auth.AccessUser,
+	}
+	for r, a := range want {
+		if got := p.Access(r); got != a {
+			t.Errorf("Access(%q) = %q, want %q", r, got, a)
+		}
+	}
+}
+
+func TestAccessPolicy_UnknownRoute(t *testing.T) {
+	cfg := config.Access{Routes: map[string]auth.Access{"POST /api/unknown": auth.AccessPublic}}
+	if _, err := accessPolicy(cfg); err == nil {
+		t.Error("accessPolicy() error = nil, want error")
+	}
+}
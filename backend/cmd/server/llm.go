@@ -0,0 +1,33 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/llm"
+)
+
+// providerOrder converts a provider list such as ["claude", "openai"] to
+// provider types. Names are case-insensitive and blank entries are ignored.
+func providerOrder(names []string) []llm.ProviderType {
+	var order []llm.ProviderType
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" {
+			order = append(order, llm.ProviderType(name))
+		}
+	}
+	return order
+}
+
+// pickDefaultProvider returns the first provider in order that is registered,
+// or the first registered provider if none is.
+func pickDefaultProvider(order []llm.ProviderType, registered []llm.Provider) llm.ProviderType {
+	for _, t := range order {
+		for _, p := range registered {
+			if p.Name() == t {
+				return t
+			}
+		}
+	}
+	return registered[0].Name()
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/llm"
+)
+
+func TestProviderOrder(t *testing.T) {
+	tests := []struct {
+		in   []string
+		want []llm.ProviderType
+	}{
+		{in: nil, want: nil},
+		{in: []string{"claude", "openai", "gemini"}, want: []llm.ProviderType{llm.ProviderClaude, llm.ProviderOpenAI, llm.ProviderGemini}},
+		{in: []string{" OpenAI ", " ", "gemini "}, want: []llm.ProviderType{llm.ProviderOpenAI, llm.ProviderGemini}},
+	}
+
+	for _, tt := range tests {
+		if got := providerOrder(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("providerOrder(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPickDefaultProvider(t *testing.T) {
+	openai := llm.NewOpenAIProvider(llm.DefaultOpenAIConfig("k"))
+	gemini := llm.NewGeminiProvider(llm.DefaultGeminiConfig("k"))
+	registered := []llm.Provider{openai, gemini}
+
+	// Claude is preferred but unconfigured, so the next configured one wins.
+	order := []llm.ProviderType{llm.ProviderClaude, llm.ProviderGemini, llm.ProviderOpenAI}
+	if got := pickDefaultProvider(order, registered); got != llm.ProviderGemini {
+		t.Errorf("default = %q, want %q", got, llm.ProviderGemini)
+	}
+	if got := pickDefaultProvider(nil, registered); got != llm.ProviderOpenAI {
+		t.Errorf("default without order = %q, want %q", got, llm.ProviderOpenAI)
+	}
+}
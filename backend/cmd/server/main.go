@@ -0,0 +1,428 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/auth"
+	"github.com/rookiecj/scrum-agents/backend/internal/cache"
+	"github.com/rookiecj/scrum-agents/backend/internal/config"
+	"github.com/rookiecj/scrum-agents/backend/internal/extractor"
+	"github.com/rookiecj/scrum-agents/backend/internal/handler"
+	"github.com/rookiecj/scrum-agents/backend/internal/history"
+	"github.com/rookiecj/scrum-agents/backend/internal/jobs"
+	"github.com/rookiecj/scrum-agents/backend/internal/llm"
+	"github.com/rookiecj/scrum-agents/backend/internal/logging"
+	"github.com/rookiecj/scrum-agents/backend/internal/metrics"
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+	"github.com/rookiecj/scrum-agents/backend/internal/pipeline"
+	"github.com/rookiecj/scrum-agents/backend/internal/ratelimit"
+	"github.com/rookiecj/scrum-agents/backend/internal/summarizer"
+	"github.com/rookiecj/scrum-agents/backend/internal/tracing"
+	"github.com/rookiecj/scrum-agents/backend/internal/usage"
+)
+
+func main() {
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML configuration `file`")
+	printConfig := flag.Bool("print-config", false, "print the effective configuration, with secrets redacted, and exit")
+	flag.Parse()
+
+	logging.Init()
+	if *printConfig {
+		// Keep stdout for the configuration.
+		slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stderr, nil)))
+	}
+
+	// Load .env file if it exists; fall back to system environment variables otherwise.
+	if err := loadEnv(""); err != nil {
+		slog.Warn("could not load .env file, using system environment variables",
+			slog.String("error", err.Error()),
+		)
+	}
+
+	// Defaults, overridden by the config file and then the environment
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		slog.Error("invalid configuration", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	if *printConfig {
+		if err := cfg.Redacted().Write(os.Stdout); err != nil {
+			slog.Error("failed to print configuration", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		return
+	}
+	if *configPath != "" {
+		slog.Info("configuration file loaded", slog.String("path", *configPath))
+	}
+
+	// Cancelled on SIGINT or SIGTERM, which starts the shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	var tasks background
+
+	// Spans for requests, extractions and LLM calls
+	spanExporter, err := tracingExporter(cfg.Tracing)
+	if err != nil {
+		slog.Error("invalid tracing configuration", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	if spanExporter != nil {
+		tracing.SetExporter(spanExporter)
+		slog.Info("tracing enabled", slog.String("exporter", cfg.Tracing.Exporter))
+	}
+
+	// Database & Auth setup
+	dbPath := cfg.Database.Path
+	store, err := auth.NewStore(dbPath)
+	if err != nil {
+		slog.Error("failed to initialise database", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	slog.Info("database initialised", slog.String("path", dbPath))
+
+	jwtSecret := cfg.Auth.JWTSecret
+	if jwtSecret == "" {
+		jwtSecret = "dev-secret-change-in-production"
+		slog.Warn("JWT_SECRET not set, using insecure default — set JWT_SECRET for production")
+	}
+	// Short-lived access tokens, renewed with rotating refresh tokens
+	jwtSvc := auth.NewJWTService(jwtSecret, cfg.Auth.AccessTTL)
+	sessions := auth.NewSessions(store, jwtSvc, cfg.Auth.RefreshTTL)
+	tasks.Go(func() { purgeTokens(ctx, store, time.Hour) })
+
+	hist, err := history.NewStore(store.DB())
+	if err != nil {
+		slog.Error("failed to initialise history store", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	// Token usage and cost of LLM calls
+	usageStore, err := usage.NewStore(store.DB())
+	if err != nil {
+		slog.Error("failed to initialise usage store", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	meter := usage.NewRecorder(usageStore, cfg.Usage.Prices)
+	admins := cfg.Usage.Admins
+	slog.Info("usage accounting enabled",
+		slog.Int("priced_models", len(cfg.Usage.Prices)),
+		slog.Int("admins", len(admins)),
+	)
+
+	// Result cache for extraction, classification and summaries
+	var resultCache *cache.Cache
+	if !cfg.Cache.Disabled {
+		opts := cfg.Cache.Options(store.DB())
+		resultCache, err = cache.New(opts)
+		if err != nil {
+			slog.Error("failed to initialise cache", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		slog.Info("result cache enabled",
+			slog.Duration("ttl", opts.TTL),
+			slog.Int("max_entries", opts.MaxEntries),
+			slog.Bool("persistent", opts.DB != nil),
+		)
+	} else {
+		slog.Info("result cache disabled")
+	}
+
+	policy, err := accessPolicy(cfg.Access)
+	if err != nil {
+		slog.Error("invalid access policy", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	logPolicy(policy)
+	// Session JWTs and personal access tokens are accepted alike
+	authn := auth.NewAuthenticator(jwtSvc, store)
+	guard := auth.NewGuard(authn, policy)
+	requireAuth := auth.Middleware(authn)
+
+	mux := http.NewServeMux()
+	var limiter *ratelimit.Limiter
+	if opts, ok, err := rateLimitOptions(cfg.RateLimit, cfg.Access.TrustProxy, store.DB()); err != nil {
+		slog.Error("invalid rate limits", slog.String("error", err.Error()))
+		os.Exit(1)
+	} else if ok {
+		if limiter, err = ratelimit.New(opts); err != nil {
+			slog.Error("failed to initialise rate limiter", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		logRateLimits(opts)
+	} else {
+		slog.Info("rate limits disabled")
+	}
+	// handle registers a policy route, rate limited and metered once the
+	// caller is known
+	handle := func(pattern string, h http.Handler) {
+		guard.Handle(mux, pattern, limiter.Wrap(pattern, meter.Wrap(pattern, h)))
+	}
+	historyRead := func(h http.Handler) http.Handler {
+		return requireAuth(auth.RequireScope(auth.ScopeHistoryRead)(h))
+	}
+	historyWrite := func(h http.Handler) http.Handler {
+		return requireAuth(auth.RequireScope(auth.ScopeHistoryWrite)(h))
+	}
+
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"status":"ok","version":"%s"}`, Version)
+	})
+	mux.Handle("GET /metrics", metricsHandler(cfg.Metrics.Token))
+
+	// Auth endpoints (public)
+	mux.HandleFunc("POST /api/signup", handler.HandleSignup(store))
+	mux.HandleFunc("POST /api/login", handler.HandleLogin(store, sessions))
+	mux.HandleFunc("POST /api/token/refresh", handler.HandleRefresh(sessions))
+	mux.Handle("POST /api/logout", requireAuth(handler.HandleLogout(sessions)))
+
+	// Personal access tokens, managed with a session token
+	mux.Handle("POST /api/tokens", requireAuth(handler.HandleCreateAPIToken(store)))
+	mux.Handle("GET /api/tokens", requireAuth(handler.HandleListAPITokens(store)))
+	mux.Handle("DELETE /api/tokens/{id}", requireAuth(handler.HandleRevokeAPIToken(store)))
+
+	// API endpoints, guarded by the access policy; results are kept in
+	// history when a token is sent
+	extractors := cache.Extractors(extractor.NewRegistryWithLimits(cfg.Extractor.Limits()).Instrument(), resultCache)
+	handle(routeDetect, handler.HandleDetect(hist))
+	handle(routeExtract, handler.HandleExtract(extractors, hist))
+
+	// History endpoints (authenticated)
+	mux.Handle("GET /api/history", historyRead(handler.HandleListHistory(hist)))
+	mux.Handle("GET /api/history/{id}", historyRead(handler.HandleGetHistory(hist)))
+	mux.Handle("DELETE /api/history/{id}", historyWrite(handler.HandleDeleteHistory(hist)))
+
+	// Usage endpoints (authenticated)
+	usageRead := auth.RequireScope(auth.ScopeUsageRead)
+	mux.Handle("GET /api/usage", requireAuth(usageRead(handler.HandleUsage(usageStore))))
+	mux.Handle("GET /api/admin/usage", requireAuth(usageRead(auth.RequireAdmin(admins)(handler.HandleAdminUsage(usageStore)))))
+
+	// LLM provider registration
+	var registered []llm.Provider
+
+	if cfg.LLM.Claude.APIKey != "" {
+		registered = append(registered, llm.NewClaudeProvider(cfg.LLM.Claude.Apply(llm.DefaultClaudeConfig(""))))
+		slog.Info("Claude provider registered")
+	} else {
+		slog.Warn("ANTHROPIC_API_KEY not set, Claude provider disabled")
+	}
+
+	if cfg.LLM.OpenAI.APIKey != "" {
+		registered = append(registered, llm.NewOpenAIProvider(cfg.LLM.OpenAI.Apply(llm.DefaultOpenAIConfig(""))))
+		slog.Info("OpenAI provider registered")
+	} else {
+		slog.Warn("OPENAI_API_KEY not set, OpenAI provider disabled")
+	}
+
+	if cfg.LLM.Gemini.APIKey != "" {
+		registered = append(registered, llm.NewGeminiProvider(cfg.LLM.Gemini.Apply(llm.DefaultGeminiConfig(""))))
+		slog.Info("Gemini provider registered")
+	} else {
+		slog.Warn("GOOGLE_API_KEY not set, Gemini provider disabled")
+	}
+
+	// Ollama needs no key; it is enabled by pointing at a server or picking a model.
+	var providerChecks []handler.ReachabilityCheck
+	if ollamaCfg := cfg.LLM.Ollama; ollamaCfg.BaseURL != "" || ollamaCfg.Model != "" {
+		ollama := llm.NewOllamaProvider(ollamaCfg.Apply(llm.DefaultOllamaConfig(ollamaCfg.BaseURL, ollamaCfg.Model)))
+		registered = append(registered, ollama)
+		providerChecks = append(providerChecks, handler.ReachabilityCheck{
+			Name:   string(llm.ProviderOllama),
+			EnvVar: "OLLAMA_BASE_URL",
+			Ping:   ollama.Ping,
+		})
+		if err := ollama.Ping(context.Background()); err != nil {
+			slog.Warn("Ollama provider registered but not reachable", slog.String("error", err.Error()))
+		} else {
+			slog.Info("Ollama provider registered", slog.String("model", ollama.Model()))
+		}
+	}
+
+	// Named OpenAI-compatible providers (vLLM, LM Studio, gateways, ...)
+	if path := cfg.LLM.ProvidersFile; path != "" {
+		decls, err := loadCompatibleProviders(path)
+		if err != nil {
+			slog.Error("failed to load LLM providers file", slog.String("path", path), slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		for _, d := range decls {
+			p := llm.NewOpenAICompatibleProvider(llm.ProviderType(d.Name), d.config())
+			registered = append(registered, p)
+			providerChecks = append(providerChecks, handler.ReachabilityCheck{
+				Name:   d.Name,
+				EnvVar: d.APIKeyEnv,
+				Ping:   p.Ping,
+			})
+			slog.Info("OpenAI-compatible provider registered",
+				slog.String("name", d.Name),
+				slog.String("base_url", d.BaseURL),
+				slog.String("model", d.Model),
+			)
+		}
+	}
+
+	if len(registered) == 0 {
+		// Keep the LLM endpoints mounted so they report the missing key.
+		slog.Warn("no LLM API key set, LLM endpoints will return errors")
+		registered = append(registered, llm.NewClaudeProvider(llm.DefaultClaudeConfig("")))
+	}
+
+	fallbackOrder := providerOrder(cfg.LLM.FallbackOrder)
+	adapter, err := llm.NewAdapter(pickDefaultProvider(fallbackOrder, registered), registered...)
+	if err != nil {
+		slog.Error("failed to initialise LLM adapter", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	adapter.SetFallbackOrder(fallbackOrder...)
+	slog.Info("LLM providers configured",
+		slog.String("default", string(adapter.DefaultProvider())),
+		slog.Any("fallback_order", fallbackOrder),
+	)
+
+	// Cached summaries are keyed by the templates that produced them.
+	registry, templatesErr := summarizer.LoadTemplates(cfg.Summarizer.TemplateDir)
+	var templates string
+	if templatesErr == nil {
+		templates = registry.Version()
+	}
+
+	// Every request goes through a Route so that failures fall back along
+	// LLM_FALLBACK_ORDER. Providers named in the order but not configured
+	// stay selectable and fall back immediately.
+	providers := make(map[string]llm.Provider)
+	for _, t := range append(adapter.AvailableProviders(), fallbackOrder...) {
+		providers[string(t)] = cache.Provider(adapter.Route(t), resultCache, templates)
+	}
+	defaultProvider := cache.Provider(adapter.Route(""), resultCache, templates)
+
+	handle(routeProviders, handler.HandleProviders(providerChecks...))
+
+	handle(routeClassify, handler.HandleClassify(defaultProvider, providers, hist))
+
+	// Jobs are only served with templates; nil otherwise
+	var runner *jobs.Runner
+	if templatesErr != nil {
+		slog.Warn("could not load prompt templates, summarize and process endpoints disabled",
+			slog.String("error", templatesErr.Error()),
+		)
+	} else {
+		sum := summarizer.NewSummarizer(registry, cfg.Summarizer.ConfidenceThreshold)
+		handle(routeSummarize, handler.HandleSummarize(sum, defaultProvider, providers, hist))
+
+		pipe := pipeline.New(extractors, sum)
+		handle(routeProcess, handler.HandleProcess(pipe, defaultProvider, providers, hist))
+		// Reading lists share the pipeline, cache and providers of process
+		handle(routeBatch, handler.HandleBatch(pipe, defaultProvider, providers, hist, handler.BatchConfig{
+			BatchOptions: cfg.Batch.Options(),
+			MaxURLs:      cfg.Batch.MaxURLs,
+			Timeout:      cfg.Batch.Timeout,
+		}))
+
+		// Background jobs for documents that outlast proxy timeouts
+		jobStore, err := jobs.NewStore(store.DB())
+		if err != nil {
+			slog.Error("failed to initialise job store", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		var notifier *jobs.Notifier
+		if cfg.Jobs.WebhookSecret != "" {
+			// Validate has already parsed the allowlist
+			allow, _ := jobs.ParseAllow(cfg.Jobs.WebhookAllow)
+			notifier = &jobs.Notifier{
+				Secret:  cfg.Jobs.WebhookSecret,
+				Timeout: cfg.Jobs.WebhookTimeout,
+				Allow:   allow,
+			}
+		}
+		processJob := handler.ProcessJob(pipe, defaultProvider, providers, hist)
+		runner = jobs.NewRunner(jobStore, func(ctx context.Context, job *model.Job, stage func(string)) (result any, err error) {
+			meter.Track(ctx, routeJobs, job.UserID, func(ctx context.Context) {
+				result, err = processJob(ctx, job, stage)
+			})
+			return result, err
+		}, jobs.Options{
+			Workers:     cfg.Jobs.Workers,
+			Timeout:     cfg.Jobs.Timeout,
+			MaxAttempts: cfg.Jobs.MaxAttempts,
+			Notifier:    notifier,
+		})
+		tasks.Go(func() { runner.Run(ctx) })
+
+		jobAccess := func(h http.Handler) http.Handler {
+			return requireAuth(auth.RequireScope(auth.ScopeSummarize)(h))
+		}
+		handle(routeJobs, handler.HandleCreateJob(jobStore, runner, providers, notifier))
+		mux.Handle("GET /api/jobs/{id}", jobAccess(handler.HandleGetJob(jobStore)))
+		mux.Handle("POST /api/jobs/{id}/cancel", jobAccess(handler.HandleCancelJob(jobStore, runner)))
+		// A retry runs the job again, so it shares the limits of creating one
+		mux.Handle("POST /api/jobs/{id}/retry", jobAccess(limiter.Wrap(routeJobs, handler.HandleRetryJob(jobStore, runner))))
+		slog.Info("background jobs enabled",
+			slog.Int("workers", cfg.Jobs.Workers),
+			slog.Bool("webhooks", notifier != nil),
+		)
+		slog.Info("prompt templates loaded",
+			slog.Int("template_count", len(registry.Categories())),
+		)
+	}
+
+	srv := &http.Server{
+		Addr:              cfg.Server.Addr,
+		Handler:           logging.Middleware(tracing.Middleware(metrics.Middleware(mux))),
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+	}
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		slog.Error("failed to listen", slog.String("addr", srv.Addr), slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	slog.Info("starting server",
+		slog.String("addr", ln.Addr().String()),
+		slog.Bool("tls", cfg.Server.TLS.Enabled()),
+		slog.String("version", Version),
+	)
+	exitCode := 0
+	if err := serve(ctx, srv, ln, cfg.Server.TLS); err != nil {
+		slog.Error("server failed", slog.String("error", err.Error()))
+		exitCode = 1
+	}
+
+	// Stop accepting work, let in-flight requests and background jobs
+	// finish, then flush spans and close the database. A second signal
+	// kills the process.
+	stop()
+	slog.Info("shutting down", slog.Duration("timeout", cfg.Server.ShutdownTimeout))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	// Jobs still running past the deadline return to the queue rather
+	// than be left running in the database
+	if err := shutdown(shutdownCtx, srv, &tasks, runner.Interrupt); err != nil {
+		slog.Error("shutdown incomplete", slog.String("error", err.Error()))
+		exitCode = 1
+	}
+	if spanExporter != nil {
+		if err := spanExporter.Shutdown(shutdownCtx); err != nil {
+			slog.Warn("failed to flush spans", slog.String("error", err.Error()))
+		}
+	}
+	if err := store.Close(); err != nil {
+		slog.Error("failed to close database", slog.String("error", err.Error()))
+		exitCode = 1
+	}
+	slog.Info("server stopped")
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
+}
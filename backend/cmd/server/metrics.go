@@ -0,0 +1,28 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/metrics"
+)
+
+// metricsHandler serves the Prometheus metrics. When token is set,
+// scrapers must send it as a bearer token; otherwise the endpoint is open,
+// and should be kept off public networks.
+func metricsHandler(token string) http.Handler {
+	h := metrics.Handler()
+	if token == "" {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+			http.Error(w, `{"error":"invalid metrics token"}`, http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	get := func(h http.Handler, token string) int {
+		req := httptest.NewRequest("GET", "/metrics", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := get(metricsHandler(""), ""); code != http.StatusOK {
+		t.Errorf("open endpoint: status = %d, want 200", code)
+	}
+
+	h := metricsHandler("scrape-me")
+	if code := get(h, ""); code != http.StatusUnauthorized {
+		t.Errorf("no token: status = %d, want 401", code)
+	}
+	if code := get(h, "wrong"); code != http.StatusUnauthorized {
+		t.Errorf("wrong token: status = %d, want 401", code)
+	}
+	if code := get(h, "scrape-me"); code != http.StatusOK {
+		t.Errorf("valid token: status = %d, want 200", code)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/llm"
+)
+
+// compatibleProvider declares one OpenAI-compatible endpoint, such as a vLLM,
+// LM Studio or LiteLLM server or an internal gateway.
+type compatibleProvider struct {
+	Name    string `json:"name"`
+	BaseURL string `json:"base_url"`
+	Model   string `json:"model"`
+	// APIKeyEnv names the environment variable holding the key, so that
+	// secrets stay out of the file. Keyless servers leave it empty.
+	APIKeyEnv string            `json:"api_key_env,omitempty"`
+	MaxTokens int               `json:"max_tokens,omitempty"`
+	Timeout   string            `json:"timeout,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	// ContextWindow and OutputReserve size prompts, in tokens. Without a
+	// context window a small one is assumed, whatever the model.
+	ContextWindow int `json:"context_window,omitempty"`
+	OutputReserve int `json:"output_reserve,omitempty"`
+}
+
+// builtinProviders are the names that compatible providers may not reuse.
+var builtinProviders = map[llm.ProviderType]bool{
+	llm.ProviderClaude: true,
+	llm.ProviderOpenAI: true,
+	llm.ProviderGemini: true,
+	llm.ProviderOllama: true,
+}
+
+// loadCompatibleProviders reads the JSON array of provider declarations in
+// path and validates it.
+func loadCompatibleProviders(path string) ([]compatibleProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read providers file: %w", err)
+	}
+	var decls []compatibleProvider
+	if err := json.Unmarshal(data, &decls); err != nil {
+		return nil, fmt.Errorf("parse providers file: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for i, d := range decls {
+		switch {
+		case d.Name == "":
+			return nil, fmt.Errorf("provider %d: name is required", i)
+		case builtinProviders[llm.ProviderType(d.Name)]:
+			return nil, fmt.Errorf("provider %q: name is reserved for a built-in provider", d.Name)
+		case seen[d.Name]:
+			return nil, fmt.Errorf("provider %q: declared more than once", d.Name)
+		case d.BaseURL == "":
+			return nil, fmt.Errorf("provider %q: base_url is required", d.Name)
+		case d.Model == "":
+			return nil, fmt.Errorf("provider %q: model is required", d.Name)
+		case d.ContextWindow < 0 || d.OutputReserve < 0:
+			return nil, fmt.Errorf("provider %q: context_window and output_reserve must not be negative", d.Name)
+		case d.ContextWindow > 0 && d.OutputReserve >= d.ContextWindow:
+			return nil, fmt.Errorf("provider %q: output_reserve must be smaller than context_window", d.Name)
+		}
+		if d.Timeout != "" {
+			if _, err := time.ParseDuration(d.Timeout); err != nil {
+				return nil, fmt.Errorf("provider %q: invalid timeout: %w", d.Name, err)
+			}
+		}
+		seen[d.Name] = true
+	}
+	return decls, nil
+}
+
+// config returns the provider configuration for d, starting from the OpenAI
+// defaults and reading the key from d.APIKeyEnv.
+func (d compatibleProvider) config() llm.Config {
+	var key string
+	if d.APIKeyEnv != "" {
+		key = os.Getenv(d.APIKeyEnv)
+	}
+	cfg := llm.DefaultOpenAIConfig(key)
+	cfg.BaseURL = d.BaseURL
+	cfg.Model = d.Model
+	cfg.Headers = d.Headers
+	cfg.ContextWindow = d.ContextWindow
+	cfg.OutputReserve = d.OutputReserve
+	if d.MaxTokens > 0 {
+		cfg.MaxTokens = d.MaxTokens
+	}
+	if d.Timeout != "" {
+		cfg.Timeout, _ = time.ParseDuration(d.Timeout)
+	}
+	return cfg
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeProvidersFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "providers.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadCompatibleProviders(t *testing.T) {
+	path := writeProvidersFile(t, `[
+		{"name": "vllm", "base_url": "http://vllm:8000/v1", "model": "qwen2.5-7b", "timeout": "90s"},
+		{"name": "gateway", "base_url": "https://llm.internal/v1", "model": "gpt-4o",
+		 "api_key_env": "GATEWAY_KEY", "max_tokens": 2048, "headers": {"X-Team": "infra"},
+		 "context_window": 128000, "output_reserve": 8192}
+	]`)
+	t.Setenv("GATEWAY_KEY", "secret")
+
+	decls, err := loadCompatibleProviders(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(decls) != 2 {
+		t.Fatalf("got %d providers, want 2", len(decls))
+	}
+
+	vllm := decls[0].config()
+	if vllm.APIKey != "" || vllm.Timeout != 90*time.Second || vllm.Model != "qwen2.5-7b" || vllm.ContextWindow != 0 {
+		t.Errorf("vllm config = %+v", vllm)
+	}
+	gw := decls[1].config()
+	if gw.APIKey != "secret" || gw.MaxTokens != 2048 || gw.Headers["X-Team"] != "infra" || gw.ContextWindow != 128000 || gw.OutputReserve != 8192 {
+		t.Errorf("gateway config = %+v", gw)
+	}
+}
+
+func TestLoadCompatibleProviders_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"malformed", `{`, "parse providers file"},
+		{"missing name", `[{"base_url": "http://x", "model": "m"}]`, "name is required"},
+		{"builtin name", `[{"name": "openai", "base_url": "http://x", "model": "m"}]`, "reserved"},
+		{"duplicate", `[{"name": "a", "base_url": "http://x", "model": "m"}, {"name": "a", "base_url": "http://y", "model": "m"}]`, "more than once"},
+		{"missing base_url", `[{"name": "a", "model": "m"}]`, "base_url is required"},
+		{"missing model", `[{"name": "a", "base_url": "http://x"}]`, "model is required"},
+		{"bad timeout", `[{"name": "a", "base_url": "http://x", "model": "m", "timeout": "soon"}]`, "invalid timeout"},
+		{"negative window", `[{"name": "a", "base_url": "http://x", "model": "m", "context_window": -1}]`, "must not be negative"},
+		{"reserve exceeds window", `[{"name": "a", "base_url": "http://x", "model": "m", "context_window": 4096, "output_reserve": 4096}]`, "smaller than context_window"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadCompatibleProviders(writeProvidersFile(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/config"
+	"github.com/rookiecj/scrum-agents/backend/internal/ratelimit"
+)
+
+// rateLimitOptions builds the rate limits from cfg, identifying clients
+// without a token by X-Forwarded-For if trustProxy is set. The LLM routes
+// get cfg.User and cfg.Anonymous and count towards the daily quota; other
+// routes are only limited when listed in cfg.Routes or cfg.AnonymousRoutes.
+// ok is false when limits are off.
+func rateLimitOptions(cfg config.RateLimit, trustProxy bool, db *sql.DB) (opts ratelimit.Options, ok bool, err error) {
+	if cfg.Disabled {
+		return ratelimit.Options{}, false, nil
+	}
+
+	opts = ratelimit.Options{
+		Routes:           make(map[string]ratelimit.Rule),
+		Daily:            ratelimit.Quota{User: cfg.DailyQuota.User, Anonymous: cfg.DailyQuota.Anonymous},
+		DB:               db,
+		TrustProxy:       trustProxy,
+		AnonymousMaxBody: cfg.AnonymousMaxBody,
+	}
+	for _, r := range llmRoutes {
+		opts.Routes[r] = ratelimit.Rule{User: cfg.User, Anonymous: cfg.Anonymous, Quota: true}
+	}
+
+	for _, overrides := range []struct {
+		name   string
+		limits map[string]ratelimit.Limit
+		set    func(*ratelimit.Rule, ratelimit.Limit)
+	}{
+		{"rate_limit.routes", cfg.Routes, func(r *ratelimit.Rule, l ratelimit.Limit) { r.User = l }},
+		{"rate_limit.anonymous_routes", cfg.AnonymousRoutes, func(r *ratelimit.Rule, l ratelimit.Limit) { r.Anonymous = l }},
+	} {
+		for pattern, l := range overrides.limits {
+			if !slices.Contains(policyRoutes, pattern) {
+				return opts, false, fmt.Errorf("%s: unknown route %q", overrides.name, pattern)
+			}
+			rule := opts.Routes[pattern]
+			overrides.set(&rule, l)
+			opts.Routes[pattern] = rule
+		}
+	}
+	return opts, true, nil
+}
+
+// logRateLimits logs the effective limits at startup.
+func logRateLimits(opts ratelimit.Options) {
+	for _, r := range policyRoutes {
+		rule, ok := opts.Routes[r]
+		if !ok {
+			continue
+		}
+		slog.Info("rate limit",
+			slog.String("route", r),
+			slog.String("user", rule.User.String()),
+			slog.String("anonymous", rule.Anonymous.String()),
+			slog.Bool("quota", rule.Quota),
+		)
+	}
+	slog.Info("daily quota",
+		slog.Int("user", opts.Daily.User),
+		slog.Int("anonymous", opts.Daily.Anonymous),
+		slog.Int64("anonymous_max_body", opts.AnonymousMaxBody),
+	)
+}
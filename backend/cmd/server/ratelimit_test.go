@@ -0,0 +1,74 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/config"
+	"github.com/rookiecj/scrum-agents/backend/internal/ratelimit"
+)
+
+func TestRateLimitOptions_Defaults(t *testing.T) {
+	cfg := config.Default().RateLimit
+	opts, ok, err := rateLimitOptions(cfg, false, nil)
+	if err != nil || !ok {
+		t.Fatalf("rateLimitOptions() = %v, %v", ok, err)
+	}
+	for _, r := range llmRoutes {
+		rule := opts.Routes[r]
+		if rule.User != cfg.User || rule.Anonymous != cfg.Anonymous || !rule.Quota {
+			t.Errorf("Routes[%q] = %+v", r, rule)
+		}
+	}
+	if _, ok := opts.Routes[routeDetect]; ok {
+		t.Errorf("%s limited by default", routeDetect)
+	}
+	if opts.Daily.User != cfg.DailyQuota.User || opts.Daily.Anonymous != cfg.DailyQuota.Anonymous {
+		t.Errorf("Daily = %+v, want %+v", opts.Daily, cfg.DailyQuota)
+	}
+	if opts.AnonymousMaxBody != cfg.AnonymousMaxBody || opts.TrustProxy {
+		t.Errorf("AnonymousMaxBody = %d, TrustProxy = %v", opts.AnonymousMaxBody, opts.TrustProxy)
+	}
+}
+
+func TestRateLimitOptions_Overrides(t *testing.T) {
+	cfg := config.Default().RateLimit
+	cfg.User = ratelimit.Every(60, time.Minute)
+	cfg.Routes = map[string]ratelimit.Limit{
+		routeSummarize: ratelimit.Every(10, time.Minute),
+		routeExtract:   ratelimit.Every(120, time.Minute),
+	}
+	cfg.AnonymousRoutes = map[string]ratelimit.Limit{routeClassify: {}}
+
+	opts, _, err := rateLimitOptions(cfg, true, nil)
+	if err != nil {
+		t.Fatalf("rateLimitOptions() error = %v", err)
+	}
+	want := map[string]ratelimit.Rule{
+		routeClassify:  {User: ratelimit.Every(60, time.Minute), Quota: true},
+		routeSummarize: {User: ratelimit.Every(10, time.Minute), Anonymous: cfg.Anonymous, Quota: true},
+		routeProcess:   {User: ratelimit.Every(60, time.Minute), Anonymous: cfg.Anonymous, Quota: true},
+		routeExtract:   {User: ratelimit.Every(120, time.Minute)},
+	}
+	for r, rule := range want {
+		if got := opts.Routes[r]; got != rule {
+			t.Errorf("Routes[%q] = %+v, want %+v", r, got, rule)
+		}
+	}
+	if !opts.TrustProxy {
+		t.Error("TrustProxy = false, want true")
+	}
+}
+
+func TestRateLimitOptions_Invalid(t *testing.T) {
+	cfg := config.Default().RateLimit
+	cfg.AnonymousRoutes = map[string]ratelimit.Limit{"POST /api/unknown": ratelimit.Every(1, time.Minute)}
+	if _, _, err := rateLimitOptions(cfg, false, nil); err == nil {
+		t.Error("rateLimitOptions() error = nil, want error for an unknown route")
+	}
+
+	cfg.Disabled = true
+	if _, ok, _ := rateLimitOptions(cfg, false, nil); ok {
+		t.Error("rateLimitOptions() ok = true with limits disabled")
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/config"
+)
+
+// serve serves srv on ln, over TLS if tls is enabled, until it fails or ctx
+// is done. It returns nil once ctx is done; the server keeps running until
+// shutdown stops it.
+func serve(ctx context.Context, srv *http.Server, ln net.Listener, tls config.TLS) error {
+	errc := make(chan error, 1)
+	go func() {
+		if tls.Enabled() {
+			errc <- srv.ServeTLS(ln, tls.CertFile, tls.KeyFile)
+		} else {
+			errc <- srv.Serve(ln)
+		}
+	}()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+// shutdown stops srv accepting connections, then waits for its in-flight
+// requests and then for tasks, until ctx is done. Connections still open at
+// that point are closed, which cancels their requests, and interrupt is
+// called to stop the tasks still running; shutdown then waits for them to
+// return, so the store can be closed safely.
+func shutdown(ctx context.Context, srv *http.Server, tasks *background, interrupt func()) error {
+	var errs []error
+	if err := srv.Shutdown(ctx); err != nil {
+		srv.Close()
+		errs = append(errs, fmt.Errorf("drain requests: %w", err))
+	}
+	if err := tasks.Wait(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("drain background jobs: %w", err))
+	}
+	interrupt()
+	tasks.Wait(context.Background())
+	return errors.Join(errs...)
+}
+
+// background tracks goroutines that use the store, so shutdown can wait
+// for them before it is closed.
+type background struct {
+	wg sync.WaitGroup
+}
+
+// Go runs f in a new goroutine.
+func (b *background) Go(f func()) {
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		f()
+	}()
+}
+
+// Wait waits for the goroutines to finish, or for ctx to be done.
+func (b *background) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/config"
+)
+
+// startServer serves h on a local port until the returned cancel is called.
+func startServer(t *testing.T, h http.Handler) (*http.Server, string, context.CancelFunc, <-chan error) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: h}
+	t.Cleanup(func() { srv.Close() })
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- serve(ctx, srv, ln, config.TLS{}) }()
+	return srv, "http://" + ln.Addr().String(), cancel, served
+}
+
+func TestShutdown_DrainsInFlightRequests(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	srv, url, cancel, served := startServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "done")
+	}))
+
+	resp := make(chan string, 1)
+	go func() {
+		res, err := http.Get(url)
+		if err != nil {
+			resp <- err.Error()
+			return
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		resp <- string(body)
+	}()
+	<-started
+
+	cancel()
+	if err := <-served; err != nil {
+		t.Fatalf("serve() = %v, want nil", err)
+	}
+	var jobs background
+	jobDone := make(chan struct{})
+	jobs.Go(func() { <-release; close(jobDone) })
+
+	ctx, stop := context.WithTimeout(context.Background(), 5*time.Second)
+	defer stop()
+	done := make(chan error, 1)
+	go func() { done <- shutdown(ctx, srv, &jobs, func() {}) }()
+
+	// New connections are refused while the request is in flight.
+	time.Sleep(20 * time.Millisecond)
+	if _, err := http.Get(url); err == nil {
+		t.Error("request after shutdown started succeeded, want refused")
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("shutdown() = %v before the request finished", err)
+	default:
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Errorf("shutdown() = %v, want nil", err)
+	}
+	if got := <-resp; got != "done" {
+		t.Errorf("in-flight response = %q, want %q", got, "done")
+	}
+	select {
+	case <-jobDone:
+	default:
+		t.Error("shutdown returned before the background job finished")
+	}
+}
+
+func TestShutdown_Deadline(t *testing.T) {
+	cancelled := make(chan struct{})
+	srv, url, cancel, served := startServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		close(cancelled)
+	}))
+	go http.Get(url)
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-served
+
+	ctx, stop := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer stop()
+	// A job still running past the deadline is interrupted, and shutdown
+	// waits for it to return.
+	var jobs background
+	release, jobDone := make(chan struct{}), make(chan struct{})
+	jobs.Go(func() { <-release; close(jobDone) })
+	err := shutdown(ctx, srv, &jobs, func() { close(release) })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("shutdown() = %v, want deadline exceeded", err)
+	}
+	select {
+	case <-jobDone:
+	default:
+		t.Error("shutdown returned before the interrupted job finished")
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Error("request still running after shutdown gave up")
+	}
+}
+
+func TestServe_ListenError(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln.Close()
+	err = serve(context.Background(), &http.Server{}, ln, config.TLS{})
+	if err == nil {
+		t.Error("serve() on a closed listener = nil, want error")
+	}
+}
+
+func TestBackground_Wait(t *testing.T) {
+	var jobs background
+	release := make(chan struct{})
+	jobs.Go(func() { <-release })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := jobs.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() with a running job = %v, want deadline exceeded", err)
+	}
+
+	close(release)
+	if err := jobs.Wait(context.Background()); err != nil {
+		t.Errorf("Wait() = %v, want nil", err)
+	}
+}
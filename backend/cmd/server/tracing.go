@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/config"
+	"github.com/rookiecj/scrum-agents/backend/internal/tracing"
+)
+
+// tracingExporter returns the span exporter selected by cfg:
+//
+//   - "log" writes each span to the JSON logs as a "trace: span" record;
+//   - "otlp" sends spans to the OpenTelemetry collector at cfg.OTLP;
+//   - "off" disables tracing, and nil is returned.
+func tracingExporter(cfg config.Tracing) (tracing.Exporter, error) {
+	switch cfg.Exporter {
+	case "", "off":
+		return nil, nil
+	case "log":
+		return tracing.NewLogExporter(nil), nil
+	case "otlp":
+		return tracing.NewOTLPExporter(tracing.OTLPOptions{
+			Endpoint:    cfg.OTLP.Endpoint,
+			Headers:     cfg.OTLP.Headers,
+			ServiceName: cfg.OTLP.ServiceName,
+		}), nil
+	default:
+		return nil, fmt.Errorf("tracing.exporter: unknown exporter %q (want off, log or otlp)", cfg.Exporter)
+	}
+}
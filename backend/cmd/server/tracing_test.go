@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/config"
+	"github.com/rookiecj/scrum-agents/backend/internal/tracing"
+)
+
+func TestTracingExporter(t *testing.T) {
+	tests := []struct {
+		exporter string
+		want     string // "nil", "log", "otlp" or "error"
+	}{
+		{exporter: "", want: "nil"},
+		{exporter: "off", want: "nil"},
+		{exporter: "log", want: "log"},
+		{exporter: "otlp", want: "otlp"},
+		{exporter: "jaeger", want: "error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.exporter, func(t *testing.T) {
+			e, err := tracingExporter(config.Tracing{
+				Exporter: tt.exporter,
+				OTLP:     config.OTLP{Headers: map[string]string{"Authorization": "Bearer k"}},
+			})
+			if e != nil {
+				defer e.Shutdown(context.Background())
+			}
+
+			var got string
+			switch e.(type) {
+			case nil:
+				got = "nil"
+			case *tracing.LogExporter:
+				got = "log"
+			case *tracing.OTLPExporter:
+				got = "otlp"
+			}
+			if err != nil {
+				got = "error"
+			}
+			if got != tt.want {
+				t.Errorf("tracingExporter() = %s (err %v), want %s", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package e2e_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -10,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/rookiecj/scrum-agents/backend/internal/classifier"
+	"github.com/rookiecj/scrum-agents/backend/internal/extractor"
 	"github.com/rookiecj/scrum-agents/backend/internal/handler"
 	"github.com/rookiecj/scrum-agents/backend/internal/model"
 	"github.com/rookiecj/scrum-agents/backend/internal/summarizer"
@@ -45,7 +47,7 @@ type summarizeResponse struct {
 // mockLLMClient is a test double that returns predictable LLM responses.
 type mockLLMClient struct{}
 
-func (m *mockLLMClient) Complete(prompt string) (string, error) {
+func (m *mockLLMClient) Complete(ctx context.Context, prompt string) (string, error) {
 	return `{"primary":"기술소개","confidence":0.85,"secondary":"튜토리얼","reasoning":"test"}`, nil
 }
 
@@ -57,18 +59,18 @@ func setupAPIServer() *httptest.Server {
 		w.Header().Set("Content-Type", "application/json")
 		fmt.Fprintf(w, `{"status":"ok"}`)
 	})
-	mux.HandleFunc("POST /api/detect", handler.HandleDetect())
-	mux.HandleFunc("POST /api/extract", handler.HandleExtract())
+	mux.HandleFunc("POST /api/detect", handler.HandleDetect(nil))
+	mux.HandleFunc("POST /api/extract", handler.HandleExtract(extractor.NewRegistry(), nil))
 
 	// LLM-dependent endpoints with mock client
 	mock := &mockLLMClient{}
 	cls := classifier.NewLLMClassifier(mock)
-	mux.HandleFunc("POST /api/classify", handler.HandleClassify(cls, nil))
+	mux.HandleFunc("POST /api/classify", handler.HandleClassify(cls, nil, nil))
 
 	registry, err := summarizer.LoadTemplates("../prompts")
 	if err == nil {
 		sum := summarizer.NewSummarizer(registry, 0.6)
-		mux.HandleFunc("POST /api/summarize", handler.HandleSummarize(sum, mock, nil))
+		mux.HandleFunc("POST /api/summarize", handler.HandleSummarize(sum, mock, nil, nil))
 	}
 
 	return httptest.NewServer(mux)
@@ -0,0 +1,220 @@
+package auth
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+)
+
+// APITokenPrefix starts every personal access token, which tells them apart
+// from JWTs.
+const APITokenPrefix = "sat_"
+
+// Scopes a personal access token can be granted. Session tokens from login
+// carry no scopes and may do everything.
+const (
+	ScopeExtract      = "extract"
+	ScopeClassify     = "classify"
+	ScopeSummarize    = "summarize"
+	ScopeHistoryRead  = "history:read"
+	ScopeHistoryWrite = "history:write"
+	ScopeUsageRead    = "usage:read"
+)
+
+// AllScopes returns all valid scopes.
+func AllScopes() []string {
+	return []string{ScopeExtract, ScopeClassify, ScopeSummarize, ScopeHistoryRead, ScopeHistoryWrite, ScopeUsageRead}
+}
+
+// ErrAPITokenNotFound is returned when a token does not exist or belongs to
+// another user.
+var ErrAPITokenNotFound = errors.New("api token not found")
+
+const createAPITokenTable = `
+	CREATE TABLE IF NOT EXISTS api_tokens (
+		id           INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id      INTEGER NOT NULL,
+		name         TEXT    NOT NULL,
+		token_hash   TEXT    NOT NULL UNIQUE,
+		prefix       TEXT    NOT NULL,
+		scopes       TEXT    NOT NULL,
+		expires_at   TEXT,
+		last_used_at TEXT,
+		created_at   TEXT    NOT NULL DEFAULT (datetime('now'))
+	);
+	CREATE INDEX IF NOT EXISTS idx_api_tokens_user ON api_tokens (user_id);`
+
+// ParseScopes validates scope names and returns them sorted and without
+// duplicates.
+func ParseScopes(scopes []string) ([]string, error) {
+	var out []string
+	for _, s := range scopes {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if !slices.Contains(AllScopes(), s) {
+			return nil, fmt.Errorf("unknown scope %q (want one of %s)", s, strings.Join(AllScopes(), ", "))
+		}
+		out = append(out, s)
+	}
+	if len(out) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+	slices.Sort(out)
+	return slices.Compact(out), nil
+}
+
+// CreateAPIToken creates a personal access token for the user and returns
+// it with the token string, which is not stored and cannot be shown again.
+// A nil expiresAt creates a token that does not expire.
+func (s *Store) CreateAPIToken(userID int64, name string, scopes []string, expiresAt *time.Time) (*model.APIToken, string, error) {
+	secret, err := randomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	token := APITokenPrefix + secret
+	prefix := token[:len(APITokenPrefix)+6]
+
+	var expires sql.NullString
+	if expiresAt != nil {
+		expires = sql.NullString{String: expiresAt.UTC().Format(timeLayout), Valid: true}
+	}
+	res, err := s.db.Exec(`INSERT INTO api_tokens (user_id, name, token_hash, prefix, scopes, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		userID, name, hashToken(token), prefix, strings.Join(scopes, " "), expires)
+	if err != nil {
+		return nil, "", fmt.Errorf("insert api token: %w", err)
+	}
+	id, _ := res.LastInsertId()
+
+	t := &model.APIToken{
+		ID:        id,
+		UserID:    userID,
+		Name:      name,
+		Prefix:    prefix,
+		Scopes:    scopes,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	if expiresAt != nil {
+		e := expiresAt.UTC().Truncate(time.Second)
+		t.ExpiresAt = &e
+	}
+	return t, token, nil
+}
+
+// ListAPITokens returns the user's tokens, newest first.
+func (s *Store) ListAPITokens(userID int64) ([]model.APIToken, error) {
+	rows, err := s.db.Query(`SELECT id, user_id, name, prefix, scopes, expires_at, last_used_at, created_at
+		FROM api_tokens WHERE user_id = ? ORDER BY id DESC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query api tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []model.APIToken{}
+	for rows.Next() {
+		var (
+			t                 model.APIToken
+			scopes, created   string
+			expires, lastUsed sql.NullString
+		)
+		if err := rows.Scan(&t.ID, &t.UserID, &t.Name, &t.Prefix, &scopes, &expires, &lastUsed, &created); err != nil {
+			return nil, fmt.Errorf("scan api token: %w", err)
+		}
+		t.Scopes = strings.Fields(scopes)
+		t.ExpiresAt = parseNullTime(expires)
+		t.LastUsedAt = parseNullTime(lastUsed)
+		t.CreatedAt, _ = time.Parse(timeLayout, created)
+		tokens = append(tokens, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query api tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// RevokeAPIToken deletes one of the user's tokens. Returns
+// ErrAPITokenNotFound if the user has no token with that ID.
+func (s *Store) RevokeAPIToken(userID, id int64) error {
+	res, err := s.db.Exec(`DELETE FROM api_tokens WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return fmt.Errorf("delete api token: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAPITokenNotFound
+	}
+	return nil
+}
+
+// ValidateAPIToken checks a personal access token and returns claims for
+// its user, limited to the token's scopes. It records when the token was
+// last used.
+func (s *Store) ValidateAPIToken(token string) (*Claims, error) {
+	var (
+		claims  Claims
+		scopes  string
+		expires sql.NullString
+	)
+	err := s.db.QueryRow(`SELECT t.id, t.user_id, u.email, t.scopes, t.expires_at
+		FROM api_tokens t JOIN users u ON u.id = t.user_id
+		WHERE t.token_hash = ?`, hashToken(token)).
+		Scan(&claims.TokenID, &claims.UserID, &claims.Email, &scopes, &expires)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query api token: %w", err)
+	}
+
+	now := time.Now().UTC().Format(timeLayout)
+	if expires.Valid && expires.String <= now {
+		return nil, ErrInvalidToken
+	}
+	if _, err := s.db.Exec(`UPDATE api_tokens SET last_used_at = ? WHERE id = ?`, now, claims.TokenID); err != nil {
+		return nil, fmt.Errorf("update api token: %w", err)
+	}
+
+	claims.Subject = fmt.Sprintf("%d", claims.UserID)
+	claims.Scopes = strings.Fields(scopes)
+	return &claims, nil
+}
+
+func parseNullTime(s sql.NullString) *time.Time {
+	if !s.Valid {
+		return nil
+	}
+	t, err := time.Parse(timeLayout, s.String)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// Validator checks a bearer token and returns the claims it carries.
+type Validator interface {
+	ValidateToken(token string) (*Claims, error)
+}
+
+// Authenticator accepts both session JWTs and personal access tokens.
+type Authenticator struct {
+	jwt   *JWTService
+	store *Store
+}
+
+// NewAuthenticator returns an Authenticator that checks JWTs with jwtSvc
+// and personal access tokens against store.
+func NewAuthenticator(jwtSvc *JWTService, store *Store) *Authenticator {
+	return &Authenticator{jwt: jwtSvc, store: store}
+}
+
+// ValidateToken validates token as a personal access token if it carries
+// APITokenPrefix and as a JWT otherwise.
+func (a *Authenticator) ValidateToken(token string) (*Claims, error) {
+	if strings.HasPrefix(token, APITokenPrefix) {
+		return a.store.ValidateAPIToken(token)
+	}
+	return a.jwt.ValidateToken(token)
+}
@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseScopes(t *testing.T) {
+	got, err := ParseScopes([]string{"summarize", " History:Read ", "summarize"})
+	if err != nil {
+		t.Fatalf("ParseScopes() error = %v", err)
+	}
+	if want := []string{ScopeHistoryRead, ScopeSummarize}; !slices.Equal(got, want) {
+		t.Errorf("ParseScopes() = %v, want %v", got, want)
+	}
+
+	for _, in := range [][]string{nil, {"admin"}, {"summarize", ""}} {
+		if _, err := ParseScopes(in); err == nil {
+			t.Errorf("ParseScopes(%q) error = nil, want error", in)
+		}
+	}
+}
+
+func TestStore_APITokens(t *testing.T) {
+	store := tempDB(t)
+	alice, _ := store.CreateUser("alice@example.com", "hash")
+	bob, _ := store.CreateUser("bob@example.com", "hash")
+
+	token, secret, err := store.CreateAPIToken(alice.ID, "ci", []string{ScopeSummarize}, nil)
+	if err != nil {
+		t.Fatalf("CreateAPIToken() error = %v", err)
+	}
+	if !strings.HasPrefix(secret, APITokenPrefix) || !strings.HasPrefix(secret, token.Prefix) {
+		t.Errorf("token %q, prefix %q", secret, token.Prefix)
+	}
+
+	claims, err := store.ValidateAPIToken(secret)
+	if err != nil {
+		t.Fatalf("ValidateAPIToken() error = %v", err)
+	}
+	if claims.UserID != alice.ID || claims.Email != "alice@example.com" || claims.TokenID != token.ID {
+		t.Errorf("claims = %+v", claims)
+	}
+	if !claims.HasScope(ScopeSummarize) || claims.HasScope(ScopeHistoryRead) {
+		t.Errorf("scopes = %v, want only summarize", claims.Scopes)
+	}
+
+	list, err := store.ListAPITokens(alice.ID)
+	if err != nil || len(list) != 1 {
+		t.Fatalf("ListAPITokens() = %v, %v", list, err)
+	}
+	if list[0].Name != "ci" || list[0].LastUsedAt == nil {
+		t.Errorf("listed token = %+v, want name ci with last use", list[0])
+	}
+	if list, _ := store.ListAPITokens(bob.ID); len(list) != 0 {
+		t.Errorf("bob's tokens = %v, want none", list)
+	}
+
+	if err := store.RevokeAPIToken(bob.ID, token.ID); !errors.Is(err, ErrAPITokenNotFound) {
+		t.Errorf("RevokeAPIToken(other user) error = %v, want ErrAPITokenNotFound", err)
+	}
+	if err := store.RevokeAPIToken(alice.ID, token.ID); err != nil {
+		t.Fatalf("RevokeAPIToken() error = %v", err)
+	}
+	if _, err := store.ValidateAPIToken(secret); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("ValidateAPIToken(revoked) error = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestStore_APITokenExpiry(t *testing.T) {
+	store := tempDB(t)
+	alice, _ := store.CreateUser("alice@example.com", "hash")
+
+	past := time.Now().Add(-time.Minute)
+	_, secret, err := store.CreateAPIToken(alice.ID, "old", []string{ScopeExtract}, &past)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.ValidateAPIToken(secret); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("ValidateAPIToken(expired) error = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestAuthenticator(t *testing.T) {
+	store := tempDB(t)
+	alice, _ := store.CreateUser("alice@example.com", "hash")
+	jwtSvc := NewJWTService("test-secret", time.Hour)
+	authn := NewAuthenticator(jwtSvc, store)
+
+	jwtToken, _ := jwtSvc.GenerateToken(alice.ID, alice.Email)
+	_, apiToken, _ := store.CreateAPIToken(alice.ID, "script", []string{ScopeHistoryRead}, nil)
+
+	var got *Claims
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = UserFromContext(r.Context())
+	})
+	h := Middleware(authn)(RequireScope(ScopeHistoryRead)(inner))
+	scoped := Middleware(authn)(RequireScope(ScopeSummarize)(inner))
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		token   string
+		want    int
+		wantAPI bool
+	}{
+		{"session token", h, jwtToken, http.StatusOK, false},
+		{"session token has every scope", scoped, jwtToken, http.StatusOK, false},
+		{"api token", h, apiToken, http.StatusOK, true},
+		{"api token without scope", scoped, apiToken, http.StatusForbidden, false},
+		{"unknown api token", h, APITokenPrefix + "nope", http.StatusUnauthorized, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+			tt.handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusOK && (got == nil || got.UserID != alice.ID || got.IsAPIToken() != tt.wantAPI) {
+				t.Errorf("claims = %+v, want user %d (api token %v)", got, alice.ID, tt.wantAPI)
+			}
+		})
+	}
+}
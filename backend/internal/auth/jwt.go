@@ -3,24 +3,44 @@ package auth
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var ErrInvalidToken = errors.New("invalid or expired token")
+var (
+	ErrInvalidToken = errors.New("invalid or expired token")
+	ErrRevokedToken = errors.New("token has been revoked")
+)
 
 // Claims represents the JWT payload.
 type Claims struct {
 	UserID int64  `json:"user_id"`
 	Email  string `json:"email"`
 	jwt.RegisteredClaims
+
+	// TokenID and Scopes are set for personal access tokens. Session
+	// tokens have no scopes and are not restricted.
+	TokenID int64    `json:"-"`
+	Scopes  []string `json:"-"`
+}
+
+// IsAPIToken reports whether the claims come from a personal access token.
+func (c *Claims) IsAPIToken() bool {
+	return c.TokenID != 0
+}
+
+// HasScope reports whether the claims allow scope.
+func (c *Claims) HasScope(scope string) bool {
+	return !c.IsAPIToken() || slices.Contains(c.Scopes, scope)
 }
 
 // JWTService handles token creation and validation.
 type JWTService struct {
-	secret []byte
-	expiry time.Duration
+	secret   []byte
+	expiry   time.Duration
+	denylist Denylist
 }
 
 // NewJWTService creates a new JWT service with the given secret and token expiry.
@@ -33,11 +53,23 @@ func NewJWTService(secret string, expiry time.Duration) *JWTService {
 
 // GenerateToken creates a signed JWT for the given user.
 func (s *JWTService) GenerateToken(userID int64, email string) (string, error) {
+	token, _, err := s.generate(userID, email)
+	return token, err
+}
+
+// generate creates a signed JWT with a unique ID (jti), so that it can be
+// revoked, and returns it with its claims.
+func (s *JWTService) generate(userID int64, email string) (string, *Claims, error) {
+	jti, err := randomToken(16)
+	if err != nil {
+		return "", nil, err
+	}
 	now := time.Now()
-	claims := Claims{
+	claims := &Claims{
 		UserID: userID,
 		Email:  email,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
 			ExpiresAt: jwt.NewNumericDate(now.Add(s.expiry)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			Subject:   fmt.Sprintf("%d", userID),
@@ -45,7 +77,26 @@ func (s *JWTService) GenerateToken(userID int64, email string) (string, error) {
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(s.secret)
+	signed, err := token.SignedString(s.secret)
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, claims, nil
+}
+
+// Expiry returns the lifetime of the tokens s issues.
+func (s *JWTService) Expiry() time.Duration {
+	return s.expiry
+}
+
+// Denylist reports whether a token ID has been revoked.
+type Denylist interface {
+	IsRevoked(jti string) (bool, error)
+}
+
+// SetDenylist makes ValidateToken reject tokens whose ID is on d.
+func (s *JWTService) SetDenylist(d Denylist) {
+	s.denylist = d
 }
 
 // ValidateToken parses and validates a JWT string, returning the claims.
@@ -65,5 +116,16 @@ func (s *JWTService) ValidateToken(tokenString string) (*Claims, error) {
 		return nil, ErrInvalidToken
 	}
 
+	if s.denylist != nil && claims.ID != "" {
+		revoked, err := s.denylist.IsRevoked(claims.ID)
+		if err != nil {
+			// Fail closed: a token that cannot be checked is not trusted.
+			return nil, fmt.Errorf("checking revocation: %w", err)
+		}
+		if revoked {
+			return nil, ErrRevokedToken
+		}
+	}
+
 	return claims, nil
 }
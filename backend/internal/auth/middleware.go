@@ -5,6 +5,8 @@ import (
 	"log/slog"
 	"net/http"
 	"strings"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/logging"
 )
 
 type contextKey string
@@ -18,14 +20,15 @@ func UserFromContext(ctx context.Context) *Claims {
 	return claims
 }
 
-// Middleware returns an HTTP middleware that validates Bearer tokens.
-// Requests without a valid token receive 401 Unauthorized.
-func Middleware(jwtSvc *JWTService) func(http.Handler) http.Handler {
+// Middleware returns an HTTP middleware that validates Bearer tokens with v,
+// a *JWTService or an *Authenticator that also accepts personal access
+// tokens. Requests without a valid token receive 401 Unauthorized.
+func Middleware(v Validator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
-				slog.Warn("auth: missing Authorization header",
+				slog.WarnContext(r.Context(), "auth: missing Authorization header",
 					slog.String("path", r.URL.Path),
 				)
 				http.Error(w, `{"error":"missing authorization header"}`, http.StatusUnauthorized)
@@ -33,7 +36,7 @@ func Middleware(jwtSvc *JWTService) func(http.Handler) http.Handler {
 			}
 
 			if !strings.HasPrefix(authHeader, "Bearer ") {
-				slog.Warn("auth: invalid Authorization format",
+				slog.WarnContext(r.Context(), "auth: invalid Authorization format",
 					slog.String("path", r.URL.Path),
 				)
 				http.Error(w, `{"error":"invalid authorization format"}`, http.StatusUnauthorized)
@@ -41,9 +44,9 @@ func Middleware(jwtSvc *JWTService) func(http.Handler) http.Handler {
 			}
 
 			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-			claims, err := jwtSvc.ValidateToken(tokenString)
+			claims, err := v.ValidateToken(tokenString)
 			if err != nil {
-				slog.Warn("auth: invalid token",
+				slog.WarnContext(r.Context(), "auth: invalid token",
 					slog.String("path", r.URL.Path),
 					slog.String("error", err.Error()),
 				)
@@ -51,8 +54,74 @@ func Middleware(jwtSvc *JWTService) func(http.Handler) http.Handler {
 				return
 			}
 
+			logging.AddAttrs(r.Context(), slog.Int64("user_id", claims.UserID))
+			if claims.IsAPIToken() {
+				logging.AddAttrs(r.Context(), slog.Int64("api_token_id", claims.TokenID))
+			}
 			ctx := context.WithValue(r.Context(), userContextKey, claims)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// OptionalMiddleware returns an HTTP middleware that attaches the user to the
+// request context when a Bearer token is present. Requests without an
+// Authorization header pass through anonymously; requests with an invalid
+// token receive 401 Unauthorized so clients can re-authenticate.
+func OptionalMiddleware(v Validator) func(http.Handler) http.Handler {
+	required := Middleware(v)
+	return func(next http.Handler) http.Handler {
+		withUser := required(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			withUser.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireScope returns an HTTP middleware that rejects personal access
+// tokens without scope with 403 Forbidden. Session tokens and requests
+// without a user pass through; authentication is left to Middleware.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if claims := UserFromContext(r.Context()); claims != nil && !claims.HasScope(scope) {
+				slog.WarnContext(r.Context(), "auth: token lacks scope",
+					slog.String("path", r.URL.Path),
+					slog.String("scope", scope),
+				)
+				http.Error(w, `{"error":"token lacks the `+scope+` scope"}`, http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireAdmin returns an HTTP middleware that lets only the users with one
+// of the given emails through; others receive 403 Forbidden. It must run
+// after Middleware.
+func RequireAdmin(emails []string) func(http.Handler) http.Handler {
+	admins := make(map[string]bool, len(emails))
+	for _, e := range emails {
+		if e = strings.ToLower(strings.TrimSpace(e)); e != "" {
+			admins[e] = true
+		}
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims := UserFromContext(r.Context())
+			if claims == nil || !admins[strings.ToLower(claims.Email)] {
+				slog.WarnContext(r.Context(), "auth: admin access denied",
+					slog.String("path", r.URL.Path),
+				)
+				http.Error(w, `{"error":"admin access required"}`, http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
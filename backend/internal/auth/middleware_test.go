@@ -87,3 +87,68 @@ func TestMiddleware_InvalidFormat(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
 	}
 }
+
+func TestOptionalMiddleware(t *testing.T) {
+	jwtSvc := NewJWTService("test-secret", time.Hour)
+	token, _ := jwtSvc.GenerateToken(7, "dave@example.com")
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+		wantUser   bool
+	}{
+		{name: "no header passes anonymously", header: "", wantStatus: http.StatusOK},
+		{name: "valid token attaches user", header: "Bearer " + token, wantStatus: http.StatusOK, wantUser: true},
+		{name: "invalid token rejected", header: "Bearer invalid-token", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotClaims *Claims
+			inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotClaims = UserFromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			})
+
+			handler := OptionalMiddleware(jwtSvc)(inner)
+			req := httptest.NewRequest("GET", "/api/detect", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantUser && (gotClaims == nil || gotClaims.UserID != 7) {
+				t.Error("expected user claims in context")
+			}
+			if !tt.wantUser && gotClaims != nil {
+				t.Error("expected no user claims in context")
+			}
+		})
+	}
+}
+
+func TestRequireAdmin(t *testing.T) {
+	jwtSvc := NewJWTService("test-secret", time.Hour)
+	h := Middleware(jwtSvc)(RequireAdmin([]string{" Admin@Example.com "})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+	for email, want := range map[string]int{
+		"admin@example.com": http.StatusOK,
+		"alice@example.com": http.StatusForbidden,
+	} {
+		token, _ := jwtSvc.GenerateToken(1, email)
+		req := httptest.NewRequest("GET", "/api/admin/usage", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("%s: status = %d, want %d", email, rec.Code, want)
+		}
+	}
+}
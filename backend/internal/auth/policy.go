@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/logging"
+)
+
+// Access is how much authentication a route requires.
+type Access string
+
+const (
+	// AccessPublic routes serve anyone. A valid token attributes the request
+	// to its user; an invalid one is rejected so clients can re-authenticate.
+	AccessPublic Access = "public"
+	// AccessAnonymous routes serve requests with or without a token, like
+	// AccessPublic, for routes that spend enough to limit callers without
+	// one; the rate limiter's anonymous tier sets those limits.
+	AccessAnonymous Access = "anonymous"
+	// AccessUser routes require a valid token.
+	AccessUser Access = "user"
+)
+
+// ParseAccess parses an access level name.
+func ParseAccess(s string) (Access, error) {
+	switch a := Access(strings.ToLower(strings.TrimSpace(s))); a {
+	case AccessPublic, AccessAnonymous, AccessUser:
+		return a, nil
+	default:
+		return "", fmt.Errorf("unknown access level %q (want public, anonymous or user)", s)
+	}
+}
+
+// Policy decides the access level of each route.
+type Policy struct {
+	// Routes maps ServeMux patterns, such as "POST /api/classify", to their
+	// access level. Routes not listed get Default.
+	Routes  map[string]Access
+	Default Access
+	// Scopes maps ServeMux patterns to the scope a personal access token
+	// needs to use the route. Routes not listed need none.
+	Scopes map[string]string
+}
+
+// Access returns the access level of the route registered as pattern.
+func (p Policy) Access(pattern string) Access {
+	if a, ok := p.Routes[pattern]; ok {
+		return a
+	}
+	if p.Default == "" {
+		return AccessUser
+	}
+	return p.Default
+}
+
+// ParseRoutes parses route overrides of the form
+// "POST /api/extract=user, POST /api/classify=anonymous".
+func ParseRoutes(s string) (map[string]Access, error) {
+	routes := make(map[string]Access)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		pattern, level, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("route %q: want PATTERN=LEVEL", entry)
+		}
+		a, err := ParseAccess(level)
+		if err != nil {
+			return nil, fmt.Errorf("route %q: %w", entry, err)
+		}
+		routes[strings.Join(strings.Fields(pattern), " ")] = a
+	}
+	return routes, nil
+}
+
+// Guard applies a Policy to the routes it registers.
+type Guard struct {
+	policy   Policy
+	required func(http.Handler) http.Handler
+	optional func(http.Handler) http.Handler
+}
+
+// NewGuard returns a Guard that checks tokens with v.
+func NewGuard(v Validator, policy Policy) *Guard {
+	return &Guard{
+		policy:   policy,
+		required: Middleware(v),
+		optional: OptionalMiddleware(v),
+	}
+}
+
+// Handle registers h on mux under pattern, wrapped for the pattern's access
+// level.
+func (g *Guard) Handle(mux *http.ServeMux, pattern string, h http.Handler) {
+	mux.Handle(pattern, g.Wrap(pattern, h))
+}
+
+// Wrap returns h wrapped for the access level and scope of pattern.
+func (g *Guard) Wrap(pattern string, h http.Handler) http.Handler {
+	if scope, ok := g.policy.Scopes[pattern]; ok {
+		h = RequireScope(scope)(h)
+	}
+	switch g.policy.Access(pattern) {
+	case AccessPublic:
+		return g.optional(h)
+	case AccessAnonymous:
+		return g.optional(markAnonymous(h))
+	default:
+		return g.required(h)
+	}
+}
+
+// markAnonymous tags the logs of requests without a user.
+func markAnonymous(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if UserFromContext(r.Context()) == nil {
+			logging.AddAttrs(r.Context(), slog.Bool("anonymous", true))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ClientIP returns the address of the client that sent r. With trustProxy,
+// the last address in X-Forwarded-For wins over the connection's remote
+// address: the proxy appends the address it saw, while anything before it
+// came from the client and may be forged.
+func ClientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		fwd := r.Header.Values("X-Forwarded-For")
+		if len(fwd) > 0 {
+			list := fwd[len(fwd)-1]
+			if i := strings.LastIndexByte(list, ','); i >= 0 {
+				list = list[i+1:]
+			}
+			if ip := strings.TrimSpace(list); ip != "" {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
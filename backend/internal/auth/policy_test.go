@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRoutes(t *testing.T) {
+	got, err := ParseRoutes(" POST /api/extract=user,  GET  /api/providers = PUBLIC ,")
+	if err != nil {
+		t.Fatalf("ParseRoutes() error = %v", err)
+	}
+	want := map[string]Access{
+		"POST /api/extract":  AccessUser,
+		"GET /api/providers": AccessPublic,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ParseRoutes() = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("route %q = %q, want %q", k, got[k], v)
+		}
+	}
+
+	for _, bad := range []string{"POST /api/extract", "POST /api/extract=admin"} {
+		if _, err := ParseRoutes(bad); err == nil {
+			t.Errorf("ParseRoutes(%q) error = nil, want error", bad)
+		}
+	}
+}
+
+func TestPolicy_Access(t *testing.T) {
+	p := Policy{Routes: map[string]Access{"POST /a": AccessPublic}}
+	if got := p.Access("POST /a"); got != AccessPublic {
+		t.Errorf("Access(listed) = %q, want public", got)
+	}
+	if got := p.Access("POST /b"); got != AccessUser {
+		t.Errorf("Access(unlisted) = %q, want user when Default is unset", got)
+	}
+	p.Default = AccessAnonymous
+	if got := p.Access("POST /b"); got != AccessAnonymous {
+		t.Errorf("Access(unlisted) = %q, want Default", got)
+	}
+}
+
+func TestGuard(t *testing.T) {
+	jwtSvc := NewJWTService("test-secret", time.Hour)
+	token, _ := jwtSvc.GenerateToken(7, "bob@example.com")
+
+	policy := Policy{
+		Routes: map[string]Access{
+			"POST /public": AccessPublic,
+			"POST /anon":   AccessAnonymous,
+			"POST /user":   AccessUser,
+		},
+	}
+	guard := NewGuard(jwtSvc, policy)
+	mux := http.NewServeMux()
+	for pattern := range policy.Routes {
+		guard.Handle(mux, pattern, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, err := io.ReadAll(r.Body); err != nil {
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+				return
+			}
+			if UserFromContext(r.Context()) != nil {
+				w.Header().Set("X-User", "yes")
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+	}
+
+	do := func(path, auth, body, addr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.RemoteAddr = addr
+		if auth != "" {
+			req.Header.Set("Authorization", "Bearer "+auth)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		token    string
+		body     string
+		addr     string
+		want     int
+		wantUser bool
+	}{
+		{"public anonymous", "/public", "", "", "10.0.0.1:1", http.StatusOK, false},
+		{"public with token", "/public", token, "", "10.0.0.1:1", http.StatusOK, true},
+		{"public with bad token", "/public", "bad", "", "10.0.0.1:1", http.StatusUnauthorized, false},
+		{"user without token", "/user", "", "", "10.0.0.1:1", http.StatusUnauthorized, false},
+		{"user with token", "/user", token, "", "10.0.0.1:1", http.StatusOK, true},
+		{"anonymous without token", "/anon", "", "{}", "10.0.0.2:1", http.StatusOK, false},
+		{"anonymous with token", "/anon", token, "{}", "10.0.0.2:1", http.StatusOK, true},
+		{"anonymous with bad token", "/anon", "bad", "{}", "10.0.0.2:1", http.StatusUnauthorized, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(tt.path, tt.token, tt.body, tt.addr)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if got := rec.Header().Get("X-User") == "yes"; got != tt.wantUser {
+				t.Errorf("user attached = %v, want %v", got, tt.wantUser)
+			}
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", nil)
+	req.RemoteAddr = "10.0.0.1:5000"
+	req.Header.Set("X-Forwarded-For", "198.51.100.7, 203.0.113.9")
+
+	if got := ClientIP(req, false); got != "10.0.0.1" {
+		t.Errorf("ClientIP(trust=false) = %q, want the remote address", got)
+	}
+	if got := ClientIP(req, true); got != "203.0.113.9" {
+		t.Errorf("ClientIP(trust=true) = %q, want the last forwarded address", got)
+	}
+}
+
+func TestClientIP_SpoofedForwardedFor(t *testing.T) {
+	// The proxy appends the address it saw; whatever the client sent comes
+	// first and must not change the key.
+	tests := []struct {
+		name string
+		fwd  []string
+		want string
+	}{
+		{"proxy only", []string{"203.0.113.9"}, "203.0.113.9"},
+		{"spoofed first entry", []string{"1.2.3.4, 203.0.113.9"}, "203.0.113.9"},
+		{"other spoofed first entry", []string{"5.6.7.8,203.0.113.9"}, "203.0.113.9"},
+		{"spoofed header line", []string{"1.2.3.4", "203.0.113.9"}, "203.0.113.9"},
+		{"empty", []string{""}, "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", nil)
+			req.RemoteAddr = "10.0.0.1:5000"
+			for _, v := range tt.fwd {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+			if got := ClientIP(req, true); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,305 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrInvalidRefreshToken is returned for refresh tokens that are unknown,
+	// expired or revoked.
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	// ErrRefreshTokenReused is returned when a refresh token is presented a
+	// second time. The token's whole family is revoked, since either the
+	// client or an attacker holds a stolen copy.
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+)
+
+// DefaultRefreshTTL is the lifetime of a refresh token. Each refresh issues a
+// new token with a fresh lifetime, so sessions in use do not expire.
+const DefaultRefreshTTL = 30 * 24 * time.Hour
+
+// timeLayout is how token times are stored; it sorts as text.
+const timeLayout = "2006-01-02 15:04:05"
+
+const createTokenTables = `
+	CREATE TABLE IF NOT EXISTS refresh_tokens (
+		id            INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id       INTEGER NOT NULL,
+		family        TEXT    NOT NULL,
+		token_hash    TEXT    NOT NULL UNIQUE,
+		access_jti    TEXT    NOT NULL DEFAULT '',
+		access_expiry TEXT    NOT NULL DEFAULT '',
+		expires_at    TEXT    NOT NULL,
+		used_at       TEXT,
+		revoked_at    TEXT,
+		created_at    TEXT    NOT NULL DEFAULT (datetime('now'))
+	);
+	CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family ON refresh_tokens (family);
+	CREATE TABLE IF NOT EXISTS revoked_tokens (
+		jti        TEXT PRIMARY KEY,
+		expires_at TEXT NOT NULL
+	);`
+
+// TokenPair is what a client receives on login and refresh.
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	// AccessExpiry is when AccessToken stops being accepted.
+	AccessExpiry time.Time
+}
+
+// Sessions issues access and refresh tokens and revokes them. Refresh
+// tokens are opaque random strings stored as SHA-256 hashes; each refresh
+// replaces the token with a new one of the same family.
+type Sessions struct {
+	store      *Store
+	jwt        *JWTService
+	refreshTTL time.Duration
+	now        func() time.Time
+}
+
+// NewSessions returns Sessions that keep refresh tokens in store and sign
+// access tokens with jwtSvc. Tokens revoked through it are rejected by
+// jwtSvc from then on.
+func NewSessions(store *Store, jwtSvc *JWTService, refreshTTL time.Duration) *Sessions {
+	if refreshTTL <= 0 {
+		refreshTTL = DefaultRefreshTTL
+	}
+	jwtSvc.SetDenylist(store)
+	return &Sessions{store: store, jwt: jwtSvc, refreshTTL: refreshTTL, now: time.Now}
+}
+
+// Issue starts a new session for the user.
+func (s *Sessions) Issue(userID int64, email string) (*TokenPair, error) {
+	family, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	return s.issue(s.store.db, userID, email, family)
+}
+
+// Refresh exchanges a refresh token for a new pair. A token that was already
+// exchanged revokes its family and yields ErrRefreshTokenReused.
+func (s *Sessions) Refresh(refreshToken string) (*TokenPair, error) {
+	now := s.now().UTC()
+	tx, err := s.store.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin refresh: %w", err)
+	}
+	defer tx.Rollback()
+
+	var (
+		id              int64
+		userID          int64
+		family, expires string
+		usedAt, revoked sql.NullString
+	)
+	err = tx.QueryRow(`SELECT id, user_id, family, expires_at, used_at, revoked_at
+		FROM refresh_tokens WHERE token_hash = ?`, hashToken(refreshToken)).
+		Scan(&id, &userID, &family, &expires, &usedAt, &revoked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query refresh token: %w", err)
+	}
+
+	switch {
+	case revoked.Valid:
+		return nil, ErrInvalidRefreshToken
+	case usedAt.Valid:
+		if err := revokeFamily(tx, family, now); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("commit revocation: %w", err)
+		}
+		return nil, ErrRefreshTokenReused
+	case expires <= now.Format(timeLayout):
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// Claim the token; a concurrent refresh with the same token loses here.
+	res, err := tx.Exec(`UPDATE refresh_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL`,
+		now.Format(timeLayout), id)
+	if err != nil {
+		return nil, fmt.Errorf("mark refresh token used: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, ErrRefreshTokenReused
+	}
+
+	var email string
+	if err := tx.QueryRow(`SELECT email FROM users WHERE id = ?`, userID).Scan(&email); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("query user: %w", err)
+	}
+
+	pair, err := s.issue(tx, userID, email, family)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit refresh: %w", err)
+	}
+	return pair, nil
+}
+
+// Logout revokes the access token described by claims and, if refreshToken
+// belongs to the same user, its family.
+func (s *Sessions) Logout(claims *Claims, refreshToken string) error {
+	now := s.now().UTC()
+	tx, err := s.store.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin logout: %w", err)
+	}
+	defer tx.Rollback()
+
+	if claims.ID != "" && claims.ExpiresAt != nil {
+		if err := revokeJTI(tx, claims.ID, claims.ExpiresAt.Time); err != nil {
+			return err
+		}
+	}
+	if refreshToken != "" {
+		var family string
+		err := tx.QueryRow(`SELECT family FROM refresh_tokens WHERE token_hash = ? AND user_id = ?`,
+			hashToken(refreshToken), claims.UserID).Scan(&family)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			// Unknown or someone else's token: nothing of the caller's to revoke.
+		case err != nil:
+			return fmt.Errorf("query refresh token: %w", err)
+		default:
+			if err := revokeFamily(tx, family, now); err != nil {
+				return err
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit logout: %w", err)
+	}
+	return nil
+}
+
+// execer is satisfied by *sql.DB and *sql.Tx.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+func (s *Sessions) issue(db execer, userID int64, email, family string) (*TokenPair, error) {
+	access, claims, err := s.jwt.generate(userID, email)
+	if err != nil {
+		return nil, fmt.Errorf("generate access token: %w", err)
+	}
+	refresh, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	expiry := claims.ExpiresAt.Time.UTC()
+	_, err = db.Exec(`INSERT INTO refresh_tokens
+		(user_id, family, token_hash, access_jti, access_expiry, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		userID, family, hashToken(refresh), claims.ID, expiry.Format(timeLayout),
+		s.now().UTC().Add(s.refreshTTL).Format(timeLayout))
+	if err != nil {
+		return nil, fmt.Errorf("insert refresh token: %w", err)
+	}
+	return &TokenPair{AccessToken: access, RefreshToken: refresh, AccessExpiry: expiry}, nil
+}
+
+// revokeFamily revokes every refresh token of family and denylists the
+// access tokens issued with them.
+func revokeFamily(db execer, family string, now time.Time) error {
+	rows, err := db.Query(`SELECT access_jti, access_expiry FROM refresh_tokens
+		WHERE family = ? AND access_jti != '' AND access_expiry > ?`, family, now.Format(timeLayout))
+	if err != nil {
+		return fmt.Errorf("query token family: %w", err)
+	}
+	type issued struct {
+		jti    string
+		expiry time.Time
+	}
+	var live []issued
+	for rows.Next() {
+		var jti, expiry string
+		if err := rows.Scan(&jti, &expiry); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan token family: %w", err)
+		}
+		t, _ := time.Parse(timeLayout, expiry)
+		live = append(live, issued{jti, t})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("query token family: %w", err)
+	}
+
+	for _, a := range live {
+		if err := revokeJTI(db, a.jti, a.expiry); err != nil {
+			return err
+		}
+	}
+	_, err = db.Exec(`UPDATE refresh_tokens SET revoked_at = ? WHERE family = ? AND revoked_at IS NULL`,
+		now.Format(timeLayout), family)
+	if err != nil {
+		return fmt.Errorf("revoke token family: %w", err)
+	}
+	return nil
+}
+
+// revokeJTI denylists an access token until it expires.
+func revokeJTI(db execer, jti string, expiry time.Time) error {
+	_, err := db.Exec(`INSERT OR IGNORE INTO revoked_tokens (jti, expires_at) VALUES (?, ?)`,
+		jti, expiry.UTC().Format(timeLayout))
+	if err != nil {
+		return fmt.Errorf("revoke access token: %w", err)
+	}
+	return nil
+}
+
+// IsRevoked reports whether the access token with the given ID was revoked.
+func (s *Store) IsRevoked(jti string) (bool, error) {
+	var n int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM revoked_tokens WHERE jti = ?`, jti).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("query revoked tokens: %w", err)
+	}
+	return n > 0, nil
+}
+
+// PurgeExpiredTokens deletes refresh tokens and denylist entries that have
+// expired, since expired tokens are rejected anyway.
+func (s *Store) PurgeExpiredTokens(now time.Time) error {
+	ts := now.UTC().Format(timeLayout)
+	if _, err := s.db.Exec(`DELETE FROM revoked_tokens WHERE expires_at <= ?`, ts); err != nil {
+		return fmt.Errorf("purge revoked tokens: %w", err)
+	}
+	if _, err := s.db.Exec(`DELETE FROM refresh_tokens WHERE expires_at <= ?`, ts); err != nil {
+		return fmt.Errorf("purge refresh tokens: %w", err)
+	}
+	return nil
+}
+
+// randomToken returns n random bytes, URL-safe base64 encoded.
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
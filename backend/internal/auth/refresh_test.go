@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newSessions(t *testing.T) (*Sessions, *JWTService, int64) {
+	t.Helper()
+	store := tempDB(t)
+	user, err := store.CreateUser("alice@example.com", "hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtSvc := NewJWTService("test-secret", time.Hour)
+	return NewSessions(store, jwtSvc, time.Hour), jwtSvc, user.ID
+}
+
+func TestSessions_Refresh(t *testing.T) {
+	s, jwtSvc, userID := newSessions(t)
+
+	first, err := s.Issue(userID, "alice@example.com")
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	second, err := s.Refresh(first.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken || second.AccessToken == first.AccessToken {
+		t.Error("Refresh() returned the same tokens")
+	}
+	claims, err := jwtSvc.ValidateToken(second.AccessToken)
+	if err != nil || claims.UserID != userID || claims.Email != "alice@example.com" {
+		t.Errorf("refreshed access token: claims = %+v, err = %v", claims, err)
+	}
+
+	third, err := s.Refresh(second.RefreshToken)
+	if err != nil {
+		t.Fatalf("second Refresh() error = %v", err)
+	}
+	if _, err := jwtSvc.ValidateToken(third.AccessToken); err != nil {
+		t.Errorf("third access token invalid: %v", err)
+	}
+}
+
+func TestSessions_ReuseRevokesFamily(t *testing.T) {
+	s, jwtSvc, userID := newSessions(t)
+
+	first, _ := s.Issue(userID, "alice@example.com")
+	second, err := s.Refresh(first.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := s.Issue(userID, "alice@example.com")
+
+	if _, err := s.Refresh(first.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("Refresh(reused) error = %v, want ErrRefreshTokenReused", err)
+	}
+	if _, err := s.Refresh(second.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh(latest of revoked family) error = %v, want ErrInvalidRefreshToken", err)
+	}
+	for name, tok := range map[string]string{"first": first.AccessToken, "second": second.AccessToken} {
+		if _, err := jwtSvc.ValidateToken(tok); !errors.Is(err, ErrRevokedToken) {
+			t.Errorf("%s access token: error = %v, want ErrRevokedToken", name, err)
+		}
+	}
+
+	// Other sessions of the same user are unaffected.
+	if _, err := jwtSvc.ValidateToken(other.AccessToken); err != nil {
+		t.Errorf("other session's access token: %v", err)
+	}
+	if _, err := s.Refresh(other.RefreshToken); err != nil {
+		t.Errorf("Refresh(other session) error = %v", err)
+	}
+}
+
+func TestSessions_RefreshInvalid(t *testing.T) {
+	s, _, userID := newSessions(t)
+
+	if _, err := s.Refresh("unknown"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh(unknown) error = %v, want ErrInvalidRefreshToken", err)
+	}
+
+	pair, _ := s.Issue(userID, "alice@example.com")
+	s.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if _, err := s.Refresh(pair.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh(expired) error = %v, want ErrInvalidRefreshToken", err)
+	}
+}
+
+func TestSessions_Logout(t *testing.T) {
+	s, jwtSvc, userID := newSessions(t)
+
+	pair, _ := s.Issue(userID, "alice@example.com")
+	claims, err := jwtSvc.ValidateToken(pair.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Another user's refresh token is left alone.
+	bob, _ := s.store.CreateUser("bob@example.com", "hash")
+	bobPair, _ := s.Issue(bob.ID, "bob@example.com")
+	if err := s.Logout(claims, bobPair.RefreshToken); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	if _, err := s.Refresh(bobPair.RefreshToken); err != nil {
+		t.Errorf("Refresh(bob) error = %v after alice logged out", err)
+	}
+	if _, err := jwtSvc.ValidateToken(pair.AccessToken); !errors.Is(err, ErrRevokedToken) {
+		t.Errorf("access token after logout: error = %v, want ErrRevokedToken", err)
+	}
+
+	if err := s.Logout(claims, pair.RefreshToken); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	if _, err := s.Refresh(pair.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh() after logout error = %v, want ErrInvalidRefreshToken", err)
+	}
+}
+
+func TestStore_PurgeExpiredTokens(t *testing.T) {
+	s, jwtSvc, userID := newSessions(t)
+	pair, _ := s.Issue(userID, "alice@example.com")
+	claims, _ := jwtSvc.ValidateToken(pair.AccessToken)
+	if err := s.Logout(claims, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.store.PurgeExpiredTokens(time.Now()); err != nil {
+		t.Fatalf("PurgeExpiredTokens() error = %v", err)
+	}
+	if revoked, _ := s.store.IsRevoked(claims.ID); !revoked {
+		t.Error("unexpired denylist entry purged")
+	}
+
+	if err := s.store.PurgeExpiredTokens(time.Now().Add(2 * time.Hour)); err != nil {
+		t.Fatalf("PurgeExpiredTokens() error = %v", err)
+	}
+	if revoked, _ := s.store.IsRevoked(claims.ID); revoked {
+		t.Error("expired denylist entry kept")
+	}
+	if _, err := s.Refresh(pair.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh(purged) error = %v, want ErrInvalidRefreshToken", err)
+	}
+}
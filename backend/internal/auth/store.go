@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/rookiecj/scrum-agents/backend/internal/model"
@@ -21,10 +23,20 @@ type Store struct {
 	db *sql.DB
 }
 
+// busyTimeout makes a connection wait this many milliseconds for another
+// one's write lock, instead of failing at once, as background jobs write
+// while requests do.
+const busyTimeout = 5000
+
 // NewStore opens (or creates) a SQLite database at the given path and
 // initialises the users table.
 func NewStore(dbPath string) (*Store, error) {
-	db, err := sql.Open("sqlite", dbPath)
+	// The pragma goes in the DSN so every pooled connection gets it.
+	sep := "?"
+	if strings.Contains(dbPath, "?") {
+		sep = "&"
+	}
+	db, err := sql.Open("sqlite", dbPath+sep+"_pragma=busy_timeout("+strconv.Itoa(busyTimeout)+")")
 	if err != nil {
 		return nil, fmt.Errorf("open database: %w", err)
 	}
@@ -44,6 +56,12 @@ func NewStore(dbPath string) (*Store, error) {
 	if _, err := db.Exec(createTable); err != nil {
 		return nil, fmt.Errorf("create users table: %w", err)
 	}
+	if _, err := db.Exec(createTokenTables); err != nil {
+		return nil, fmt.Errorf("create token tables: %w", err)
+	}
+	if _, err := db.Exec(createAPITokenTable); err != nil {
+		return nil, fmt.Errorf("create api tokens table: %w", err)
+	}
 
 	return &Store{db: db}, nil
 }
@@ -85,6 +103,12 @@ func (s *Store) GetUserByEmail(email string) (*model.User, error) {
 	return &u, nil
 }
 
+// DB returns the underlying database handle so that other packages can
+// keep their tables in the same SQLite file.
+func (s *Store) DB() *sql.DB {
+	return s.db
+}
+
 // Close closes the underlying database connection.
 func (s *Store) Close() error {
 	return s.db.Close()
@@ -82,3 +82,14 @@ func TestGetUserByEmail_NotFound(t *testing.T) {
 		t.Errorf("expected ErrUserNotFound, got %v", err)
 	}
 }
+
+func TestNewStore_BusyTimeout(t *testing.T) {
+	store := tempDB(t)
+	var ms int
+	if err := store.DB().QueryRow(`PRAGMA busy_timeout`).Scan(&ms); err != nil {
+		t.Fatal(err)
+	}
+	if ms != busyTimeout {
+		t.Errorf("busy_timeout = %d, want %d", ms, busyTimeout)
+	}
+}
@@ -0,0 +1,212 @@
+// Package cache stores extraction and LLM results so repeated requests for
+// the same URL or content are served without re-fetching or re-billing.
+//
+// Entries live in an in-memory LRU and, when a database is given, in a
+// SQLite table that survives restarts.
+package cache
+
+import (
+	"container/list"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Layer identifies the kind of result stored in the cache.
+type Layer string
+
+const (
+	LayerExtract   Layer = "extract"
+	LayerClassify  Layer = "classify"
+	LayerSummarize Layer = "summarize"
+)
+
+// Defaults used when Options leaves a field zero.
+const (
+	DefaultTTL              = 24 * time.Hour
+	DefaultMaxEntries       = 1000
+	DefaultMaxStoredEntries = 10000
+)
+
+const timeLayout = "2006-01-02 15:04:05"
+
+// Options configures a Cache.
+type Options struct {
+	// TTL is how long entries stay valid, unless overridden per layer.
+	TTL time.Duration
+	// LayerTTL overrides TTL for individual layers.
+	LayerTTL map[Layer]time.Duration
+	// MaxEntries bounds the in-memory LRU.
+	MaxEntries int
+	// DB, when non-nil, persists entries in SQLite.
+	DB *sql.DB
+	// MaxStoredEntries bounds the SQLite table per layer.
+	MaxStoredEntries int
+}
+
+// Cache is a two-tier cache of serialized results. A nil *Cache is valid
+// and caches nothing.
+type Cache struct {
+	opts Options
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type entry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// New creates a Cache. When opts.DB is set the cache table is created if needed.
+func New(opts Options) (*Cache, error) {
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultTTL
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = DefaultMaxEntries
+	}
+	if opts.MaxStoredEntries <= 0 {
+		opts.MaxStoredEntries = DefaultMaxStoredEntries
+	}
+
+	if opts.DB != nil {
+		const createTable = `
+			CREATE TABLE IF NOT EXISTS cache_entries (
+				layer      TEXT NOT NULL,
+				key        TEXT NOT NULL,
+				value      BLOB NOT NULL,
+				expires_at TEXT NOT NULL,
+				created_at TEXT NOT NULL DEFAULT (datetime('now')),
+				PRIMARY KEY (layer, key)
+			);`
+		if _, err := opts.DB.Exec(createTable); err != nil {
+			return nil, fmt.Errorf("create cache_entries table: %w", err)
+		}
+	}
+
+	return &Cache{
+		opts:  opts,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}, nil
+}
+
+// ttl returns the TTL for layer.
+func (c *Cache) ttl(layer Layer) time.Duration {
+	if d, ok := c.opts.LayerTTL[layer]; ok && d > 0 {
+		return d
+	}
+	return c.opts.TTL
+}
+
+// Get returns the value stored under key in layer, if present and not expired.
+func (c *Cache) Get(layer Layer, key string) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	mk := string(layer) + ":" + key
+
+	c.mu.Lock()
+	if el, ok := c.items[mk]; ok {
+		e := el.Value.(*entry)
+		if time.Now().Before(e.expires) {
+			c.ll.MoveToFront(el)
+			c.mu.Unlock()
+			return e.value, true
+		}
+		c.removeElement(el)
+	}
+	c.mu.Unlock()
+
+	if c.opts.DB == nil {
+		return nil, false
+	}
+
+	var value []byte
+	var expiresAt string
+	const q = `SELECT value, expires_at FROM cache_entries WHERE layer = ? AND key = ? AND expires_at > ?`
+	err := c.opts.DB.QueryRow(q, layer, key, time.Now().UTC().Format(timeLayout)).Scan(&value, &expiresAt)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			slog.Warn("cache: lookup failed", slog.String("layer", string(layer)), slog.String("error", err.Error()))
+		}
+		return nil, false
+	}
+
+	expires, _ := time.Parse(timeLayout, expiresAt)
+	c.setMemory(mk, value, expires)
+	return value, true
+}
+
+// Set stores value under key in layer.
+func (c *Cache) Set(layer Layer, key string, value []byte) {
+	if c == nil {
+		return
+	}
+	expires := time.Now().Add(c.ttl(layer)).UTC()
+	c.setMemory(string(layer)+":"+key, value, expires)
+
+	if c.opts.DB == nil {
+		return
+	}
+	const upsert = `
+		INSERT INTO cache_entries (layer, key, value, expires_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (layer, key) DO UPDATE SET
+			value = excluded.value, expires_at = excluded.expires_at, created_at = datetime('now')`
+	if _, err := c.opts.DB.Exec(upsert, layer, key, value, expires.Format(timeLayout)); err != nil {
+		slog.Warn("cache: store failed", slog.String("layer", string(layer)), slog.String("error", err.Error()))
+		return
+	}
+	c.prune(layer)
+}
+
+// prune removes expired rows and keeps at most MaxStoredEntries rows in layer.
+func (c *Cache) prune(layer Layer) {
+	const q = `
+		DELETE FROM cache_entries
+		WHERE expires_at <= ?
+		   OR (layer = ? AND key NOT IN (
+		       SELECT key FROM cache_entries WHERE layer = ?
+		       ORDER BY created_at DESC LIMIT ?))`
+	now := time.Now().UTC().Format(timeLayout)
+	if _, err := c.opts.DB.Exec(q, now, layer, layer, c.opts.MaxStoredEntries); err != nil {
+		slog.Warn("cache: prune failed", slog.String("layer", string(layer)), slog.String("error", err.Error()))
+	}
+}
+
+func (c *Cache) setMemory(mk string, value []byte, expires time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[mk]; ok {
+		e := el.Value.(*entry)
+		e.value, e.expires = value, expires
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	c.items[mk] = c.ll.PushFront(&entry{key: mk, value: value, expires: expires})
+	for c.ll.Len() > c.opts.MaxEntries {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+func (c *Cache) removeElement(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*entry).key)
+}
+
+// Len returns the number of entries held in memory.
+func (c *Cache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestCache_GetSet(t *testing.T) {
+	c, _ := New(Options{})
+
+	if _, ok := c.Get(LayerExtract, "k"); ok {
+		t.Fatal("expected miss on empty cache")
+	}
+	c.Set(LayerExtract, "k", []byte("v"))
+	if got, ok := c.Get(LayerExtract, "k"); !ok || string(got) != "v" {
+		t.Errorf("Get() = %q, %v; want %q, true", got, ok, "v")
+	}
+	if _, ok := c.Get(LayerClassify, "k"); ok {
+		t.Error("layers must not share keys")
+	}
+}
+
+func TestCache_TTL(t *testing.T) {
+	c, _ := New(Options{TTL: time.Hour, LayerTTL: map[Layer]time.Duration{LayerExtract: 10 * time.Millisecond}})
+	c.Set(LayerExtract, "k", []byte("v"))
+	c.Set(LayerClassify, "k", []byte("v"))
+
+	time.Sleep(20 * time.Millisecond)
+
+	if _, ok := c.Get(LayerExtract, "k"); ok {
+		t.Error("expected extract entry to expire")
+	}
+	if _, ok := c.Get(LayerClassify, "k"); !ok {
+		t.Error("expected classify entry to survive")
+	}
+}
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c, _ := New(Options{MaxEntries: 2})
+	c.Set(LayerExtract, "a", []byte("1"))
+	c.Set(LayerExtract, "b", []byte("2"))
+	c.Get(LayerExtract, "a") // a is now most recently used
+	c.Set(LayerExtract, "c", []byte("3"))
+
+	if c.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", c.Len())
+	}
+	if _, ok := c.Get(LayerExtract, "b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	if _, ok := c.Get(LayerExtract, "a"); !ok {
+		t.Error("expected a to be kept")
+	}
+}
+
+func TestCache_PersistsInSQLite(t *testing.T) {
+	db := openDB(t)
+	first, err := New(Options{DB: db})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Set(LayerSummarize, "k", []byte("summary"))
+
+	// A fresh cache on the same database starts with an empty memory tier.
+	second, _ := New(Options{DB: db})
+	got, ok := second.Get(LayerSummarize, "k")
+	if !ok || string(got) != "summary" {
+		t.Errorf("Get() = %q, %v; want persisted value", got, ok)
+	}
+	if second.Len() != 1 {
+		t.Errorf("expected hit to be promoted to memory, Len() = %d", second.Len())
+	}
+}
+
+func TestCache_PrunesStoredEntries(t *testing.T) {
+	db := openDB(t)
+	c, _ := New(Options{DB: db, MaxStoredEntries: 2})
+	for _, k := range []string{"a", "b", "c"} {
+		c.Set(LayerExtract, k, []byte(k))
+	}
+
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM cache_entries WHERE layer = ?`, LayerExtract).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("stored rows = %d, want 2", n)
+	}
+}
+
+func TestCache_Nil(t *testing.T) {
+	var c *Cache
+	c.Set(LayerExtract, "k", []byte("v"))
+	if _, ok := c.Get(LayerExtract, "k"); ok {
+		t.Error("nil cache must always miss")
+	}
+}
@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+)
+
+// Hits reports, per layer, whether a request was served from the cache.
+// Layers that were not consulted are absent.
+type Hits map[Layer]bool
+
+type bypassKey struct{}
+
+type hitsKey struct{}
+
+type hitsRecorder struct {
+	mu   sync.Mutex
+	hits Hits
+}
+
+// WithoutCache returns a context in which cached results are ignored.
+// Fresh results are still stored, refreshing the cache.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey{}, true)
+}
+
+func bypassed(ctx context.Context) bool {
+	b, _ := ctx.Value(bypassKey{}).(bool)
+	return b
+}
+
+// Track returns a context that records cache hits and misses, and a function
+// reporting them. The function returns nil if the cache was not consulted.
+func Track(ctx context.Context) (context.Context, func() Hits) {
+	rec := &hitsRecorder{}
+	return context.WithValue(ctx, hitsKey{}, rec), func() Hits {
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		if len(rec.hits) == 0 {
+			return nil
+		}
+		out := make(Hits, len(rec.hits))
+		for l, h := range rec.hits {
+			out[l] = h
+		}
+		return out
+	}
+}
+
+func record(ctx context.Context, layer Layer, hit bool) {
+	rec, ok := ctx.Value(hitsKey{}).(*hitsRecorder)
+	if !ok {
+		return
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if rec.hits == nil {
+		rec.hits = make(Hits)
+	}
+	rec.hits[layer] = hit
+}
+
+// Do returns the value cached under key in layer, or calls fn and caches its
+// result. Errors are never cached. The outcome is recorded for Track.
+func Do[T any](ctx context.Context, c *Cache, layer Layer, key string, fn func() (T, error)) (T, error) {
+	return do(ctx, c, layer, key, func() (T, bool, error) {
+		v, err := fn()
+		return v, true, err
+	})
+}
+
+// do is Do for values that may be uncacheable: fn reports whether its
+// result should be stored.
+func do[T any](ctx context.Context, c *Cache, layer Layer, key string, fn func() (T, bool, error)) (T, error) {
+	if c == nil {
+		v, _, err := fn()
+		return v, err
+	}
+
+	if !bypassed(ctx) {
+		if data, ok := c.Get(layer, key); ok {
+			var v T
+			if err := json.Unmarshal(data, &v); err == nil {
+				record(ctx, layer, true)
+				return v, nil
+			}
+		}
+	}
+	record(ctx, layer, false)
+
+	v, cacheable, err := fn()
+	if err != nil || !cacheable {
+		return v, err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		slog.WarnContext(ctx, "cache: encoding value failed", slog.String("layer", string(layer)), slog.String("error", err.Error()))
+		return v, nil
+	}
+	c.Set(layer, key, data)
+	return v, nil
+}
@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDo(t *testing.T) {
+	c, _ := New(Options{})
+	calls := 0
+	fn := func() (string, error) {
+		calls++
+		return "value", nil
+	}
+
+	ctx, hits := Track(context.Background())
+	Do(ctx, c, LayerClassify, "k", fn)
+	if h := hits(); h[LayerClassify] {
+		t.Errorf("first call hits = %v, want miss", h)
+	}
+
+	ctx, hits = Track(context.Background())
+	got, err := Do(ctx, c, LayerClassify, "k", fn)
+	if err != nil || got != "value" {
+		t.Fatalf("Do() = %q, %v", got, err)
+	}
+	if h := hits(); !h[LayerClassify] {
+		t.Errorf("second call hits = %v, want hit", h)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestDo_WithoutCacheRefreshes(t *testing.T) {
+	c, _ := New(Options{})
+	Do(context.Background(), c, LayerSummarize, "k", func() (string, error) { return "old", nil })
+
+	ctx, hits := Track(WithoutCache(context.Background()))
+	got, _ := Do(ctx, c, LayerSummarize, "k", func() (string, error) { return "new", nil })
+	if got != "new" || hits()[LayerSummarize] {
+		t.Errorf("bypass: got %q hits %v, want fresh value", got, hits())
+	}
+
+	got, _ = Do(context.Background(), c, LayerSummarize, "k", func() (string, error) { return "unused", nil })
+	if got != "new" {
+		t.Errorf("after bypass got %q, want refreshed value %q", got, "new")
+	}
+}
+
+func TestDo_ErrorsAreNotCached(t *testing.T) {
+	c, _ := New(Options{})
+	Do(context.Background(), c, LayerExtract, "k", func() (string, error) { return "", errors.New("boom") })
+
+	if _, ok := c.Get(LayerExtract, "k"); ok {
+		t.Error("expected failed result not to be cached")
+	}
+}
+
+func TestTrack_NoLookups(t *testing.T) {
+	_, hits := Track(context.Background())
+	if hits() != nil {
+		t.Errorf("hits = %v, want nil", hits())
+	}
+}
@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+)
+
+// Key derives a cache key from its parts by hashing them, so large inputs
+// such as article content produce short, fixed-size keys.
+func Key(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// trackingParams are query parameters that do not change the page content.
+var trackingParams = []string{"utm_", "fbclid", "gclid", "mc_cid", "mc_eid", "ref_src"}
+
+// NormalizeURL returns a canonical form of rawURL so that trivially different
+// links to the same page share a cache entry: the scheme and host are
+// lower-cased, default ports, fragments and tracking parameters are dropped,
+// query parameters are sorted and the slash of a root path is removed.
+// Other trailing slashes are kept, as many servers serve different pages
+// at /post and /post/. Unparseable URLs are returned unchanged.
+func NormalizeURL(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if (u.Scheme == "http" && strings.HasSuffix(u.Host, ":80")) ||
+		(u.Scheme == "https" && strings.HasSuffix(u.Host, ":443")) {
+		u.Host = u.Host[:strings.LastIndex(u.Host, ":")]
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	q := u.Query()
+	for name := range q {
+		for _, prefix := range trackingParams {
+			if strings.HasPrefix(strings.ToLower(name), prefix) {
+				q.Del(name)
+				break
+			}
+		}
+	}
+	// Encode sorts by key.
+	u.RawQuery = q.Encode()
+
+	if u.Path == "/" {
+		u.Path = ""
+		u.RawPath = ""
+	}
+	return u.String()
+}
@@ -0,0 +1,42 @@
+package cache
+
+import "testing"
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "https://Example.com/post/", want: "https://example.com/post/"},
+		{in: "https://example.com:443/post#section", want: "https://example.com/post"},
+		{in: "http://example.com:80/post", want: "http://example.com/post"},
+		{in: "https://example.com/post?utm_source=x&b=2&a=1&fbclid=y", want: "https://example.com/post?a=1&b=2"},
+		{in: "https://example.com/", want: "https://example.com"},
+		{in: "https://example.com/?utm_source=x", want: "https://example.com"},
+		{in: "not a url", want: "not a url"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeURL(tt.in); got != tt.want {
+			t.Errorf("NormalizeURL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeURL_TrailingSlash(t *testing.T) {
+	if Key(NormalizeURL("https://example.com/a")) == Key(NormalizeURL("https://example.com/a/")) {
+		t.Error("/a and /a/ share a key, want different keys")
+	}
+	if NormalizeURL("https://example.com") != NormalizeURL("https://example.com/") {
+		t.Error("the root with and without a slash differ, want the same URL")
+	}
+}
+
+func TestKey(t *testing.T) {
+	if Key("a", "bc") == Key("ab", "c") {
+		t.Error("keys with different part boundaries must differ")
+	}
+	if Key("claude", "model", "content") != Key("claude", "model", "content") {
+		t.Error("keys must be deterministic")
+	}
+}
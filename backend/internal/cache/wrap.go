@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/classifier"
+	"github.com/rookiecj/scrum-agents/backend/internal/extractor"
+	"github.com/rookiecj/scrum-agents/backend/internal/llm"
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+	"github.com/rookiecj/scrum-agents/backend/internal/tokens"
+)
+
+// Extractors returns a copy of reg whose extractors cache their results in c,
+// keyed by normalized URL.
+func Extractors(reg *extractor.Registry, c *Cache) *extractor.Registry {
+	if c == nil {
+		return reg
+	}
+	return reg.Wrap(func(next extractor.Extractor) extractor.Extractor {
+		return &cachedExtractor{next: next, cache: c}
+	})
+}
+
+type cachedExtractor struct {
+	next  extractor.Extractor
+	cache *Cache
+}
+
+func (e *cachedExtractor) Extract(ctx context.Context, url string) (*model.ExtractedContent, error) {
+	return Do(ctx, e.cache, LayerExtract, Key(NormalizeURL(url)), func() (*model.ExtractedContent, error) {
+		return e.next.Extract(ctx, url)
+	})
+}
+
+// Provider wraps p so that classifications and completions are cached in c.
+// Keys combine the provider, its model, the prompt version and a hash of the
+// input: classifications use classifier.PromptVersion and completions
+// templates, the version of the summary templates in use. Editing either
+// prompt is therefore a cache miss. Calls that fell back to another provider
+// are not cached, as they would be served later as p's answer.
+func Provider(p llm.Provider, c *Cache, templates string) llm.Provider {
+	if c == nil {
+		return p
+	}
+	return &cachedProvider{Provider: p, cache: c, templates: templates}
+}
+
+type cachedProvider struct {
+	llm.Provider
+	cache     *Cache
+	templates string
+}
+
+// served pairs a cached value with the provider that produced it, so a hit
+// still reports the original provider.
+type served[T any] struct {
+	Value    T                `json:"value"`
+	Provider llm.ProviderType `json:"provider"`
+}
+
+func (p *cachedProvider) key(kind, version, input string) string {
+	var modelName string
+	if m, ok := p.Provider.(interface{ Model() string }); ok {
+		modelName = m.Model()
+	}
+	return Key(kind, string(p.Provider.Name()), modelName, version, input)
+}
+
+// Budget reports the budget of the wrapped provider.
+func (p *cachedProvider) Budget() tokens.Budget {
+	return tokens.Of(p.Provider)
+}
+
+// call runs fn through Do, recording the serving provider on both hits and
+// misses. A result served by another provider than p's is not cached.
+func call[T any](ctx context.Context, p *cachedProvider, layer Layer, key string, fn func(context.Context) (T, error)) (T, error) {
+	res, err := do(ctx, p.cache, layer, key, func() (served[T], bool, error) {
+		inner, servedBy := llm.TrackServed(ctx)
+		v, err := fn(inner)
+		provider := servedBy()
+		if provider == "" {
+			provider = p.Provider.Name()
+		}
+		return served[T]{Value: v, Provider: provider}, provider == p.Provider.Name(), err
+	})
+	if err != nil {
+		return res.Value, err
+	}
+	llm.RecordServed(ctx, res.Provider)
+	return res.Value, nil
+}
+
+func (p *cachedProvider) Classify(ctx context.Context, content string) (*model.ClassificationResult, error) {
+	return call(ctx, p, LayerClassify, p.key("classify", classifier.PromptVersion(), content), func(ctx context.Context) (*model.ClassificationResult, error) {
+		return p.Provider.Classify(ctx, content)
+	})
+}
+
+func (p *cachedProvider) Complete(ctx context.Context, prompt string) (string, error) {
+	return call(ctx, p, LayerSummarize, p.key("complete", p.templates, prompt), func(ctx context.Context) (string, error) {
+		return p.Provider.Complete(ctx, prompt)
+	})
+}
+
+// Stream serves a cached completion as a single token; otherwise it streams
+// from the provider and caches the full text.
+func (p *cachedProvider) Stream(ctx context.Context, prompt string, onToken func(string) error) (string, error) {
+	key := p.key("complete", p.templates, prompt)
+	streamed := false
+	text, err := call(ctx, p, LayerSummarize, key, func(ctx context.Context) (string, error) {
+		streamed = true
+		return p.Provider.Stream(ctx, prompt, onToken)
+	})
+	if err != nil || streamed {
+		return text, err
+	}
+	return text, onToken(text)
+}
@@ -0,0 +1,187 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/classifier"
+	"github.com/rookiecj/scrum-agents/backend/internal/extractor"
+	"github.com/rookiecj/scrum-agents/backend/internal/llm"
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+	"github.com/rookiecj/scrum-agents/backend/internal/tokens"
+)
+
+func TestExtractors_CachesByNormalizedURL(t *testing.T) {
+	fetches := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		fmt.Fprint(w, `<html><head><title>Popular</title></head><body><p>Everyone shares this.</p></body></html>`)
+	}))
+	defer srv.Close()
+
+	c, _ := New(Options{})
+	reg := Extractors(extractor.NewRegistry(), c)
+	ext, _ := reg.For(model.LinkTypeArticle)
+
+	for _, u := range []string{srv.URL + "/post", srv.URL + "/post?utm_source=slack", srv.URL + "/post#comments"} {
+		got, err := ext.Extract(context.Background(), u)
+		if err != nil {
+			t.Fatalf("Extract(%q) error = %v", u, err)
+		}
+		if got.LinkInfo.Title != "Popular" {
+			t.Errorf("title = %q, want %q", got.LinkInfo.Title, "Popular")
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("fetches = %d, want 1", fetches)
+	}
+}
+
+// countingProvider is an llm.Provider that counts calls. With fallback set
+// it reports its calls as served by that provider, like a Route that fell
+// back.
+type countingProvider struct {
+	name      llm.ProviderType
+	model     string
+	fallback  llm.ProviderType
+	completes int
+	classifys int
+}
+
+func (p *countingProvider) Complete(ctx context.Context, prompt string) (string, error) {
+	p.completes++
+	if p.fallback != "" {
+		llm.RecordServed(ctx, p.fallback)
+	}
+	return "summary of " + prompt, nil
+}
+
+func (p *countingProvider) Stream(ctx context.Context, prompt string, onToken func(string) error) (string, error) {
+	p.completes++
+	text := "summary of " + prompt
+	return text, onToken(text)
+}
+
+func (p *countingProvider) Classify(ctx context.Context, content string) (*model.ClassificationResult, error) {
+	p.classifys++
+	return &model.ClassificationResult{Primary: model.CategoryNews, Confidence: 0.9}, nil
+}
+
+func (p *countingProvider) Summarize(ctx context.Context, content string, category model.ContentCategory) (string, error) {
+	return "", nil
+}
+
+func (p *countingProvider) Name() llm.ProviderType { return p.name }
+
+func (p *countingProvider) Model() string { return p.model }
+
+func TestProvider_CachesClassifyAndComplete(t *testing.T) {
+	c, _ := New(Options{})
+	inner := &countingProvider{name: llm.ProviderClaude, model: "m1"}
+	p := Provider(inner, c, "v1")
+
+	for i := 0; i < 2; i++ {
+		if _, err := p.Classify(context.Background(), "content"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := p.Complete(context.Background(), "prompt"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if inner.classifys != 1 || inner.completes != 1 {
+		t.Errorf("calls: classify=%d complete=%d, want 1 each", inner.classifys, inner.completes)
+	}
+
+	// A different model must not share entries.
+	other := &countingProvider{name: llm.ProviderClaude, model: "m2"}
+	Provider(other, c, "v1").Complete(context.Background(), "prompt")
+	if other.completes != 1 {
+		t.Errorf("expected miss for a different model")
+	}
+}
+
+func TestProvider_KeysIncludePromptVersion(t *testing.T) {
+	c, _ := New(Options{})
+	inner := &countingProvider{name: llm.ProviderClaude, model: "m1"}
+	Provider(inner, c, "v1").Complete(context.Background(), "prompt")
+	Provider(inner, c, "v2").Complete(context.Background(), "prompt")
+	if inner.completes != 2 {
+		t.Errorf("completes = %d, want a miss after the templates changed", inner.completes)
+	}
+
+	// The classification key carries the classifier's own prompt version.
+	want := Key("classify", string(llm.ProviderClaude), "m1", classifier.PromptVersion(), "content")
+	Provider(inner, c, "v1").Classify(context.Background(), "content")
+	if _, ok := c.Get(LayerClassify, want); !ok {
+		t.Error("classification not cached under the prompt version key")
+	}
+}
+
+func TestProvider_FallbackIsNotCached(t *testing.T) {
+	c, _ := New(Options{})
+	inner := &countingProvider{name: llm.ProviderClaude, fallback: llm.ProviderOpenAI}
+	p := Provider(inner, c, "v1")
+
+	for i := 0; i < 2; i++ {
+		ctx, served := llm.TrackServed(context.Background())
+		if _, err := p.Complete(ctx, "prompt"); err != nil {
+			t.Fatal(err)
+		}
+		if served() != llm.ProviderOpenAI {
+			t.Errorf("served = %q, want %q", served(), llm.ProviderOpenAI)
+		}
+	}
+	if inner.completes != 2 {
+		t.Errorf("completes = %d, want the fallback answer left uncached", inner.completes)
+	}
+}
+
+func TestProvider_HitReportsServingProvider(t *testing.T) {
+	c, _ := New(Options{})
+	p := Provider(&countingProvider{name: llm.ProviderOpenAI}, c, "v1")
+	p.Complete(context.Background(), "prompt")
+
+	ctx, served := llm.TrackServed(context.Background())
+	ctx, hits := Track(ctx)
+	p.Complete(ctx, "prompt")
+
+	if served() != llm.ProviderOpenAI {
+		t.Errorf("served = %q, want %q", served(), llm.ProviderOpenAI)
+	}
+	if !hits()[LayerSummarize] {
+		t.Errorf("hits = %v, want summarize hit", hits())
+	}
+}
+
+func TestProvider_ReportsBudget(t *testing.T) {
+	c, _ := New(Options{})
+	claude := llm.NewClaudeProvider(llm.DefaultClaudeConfig("k"))
+	if got, want := tokens.Of(Provider(claude, c, "v1")), claude.Budget(); got != want {
+		t.Errorf("budget = %+v, want the wrapped provider's %+v", got, want)
+	}
+	if got := tokens.Of(Provider(&countingProvider{}, c, "v1")); got != tokens.Default {
+		t.Errorf("budget = %+v, want tokens.Default", got)
+	}
+}
+
+func TestProvider_StreamHitEmitsSingleToken(t *testing.T) {
+	c, _ := New(Options{})
+	inner := &countingProvider{name: llm.ProviderClaude}
+	p := Provider(inner, c, "v1")
+	p.Complete(context.Background(), "prompt")
+
+	var tokens []string
+	got, err := p.Stream(context.Background(), "prompt", func(s string) error {
+		tokens = append(tokens, s)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 1 || tokens[0] != got || inner.completes != 1 {
+		t.Errorf("tokens = %v, completes = %d; want cached text once", tokens, inner.completes)
+	}
+}
@@ -1,10 +1,11 @@
 package classifier
 
 import (
+	"context"
 	"github.com/rookiecj/scrum-agents/backend/internal/model"
 )
 
 // Classifier classifies content into a category.
 type Classifier interface {
-	Classify(content string) (*model.ClassificationResult, error)
+	Classify(ctx context.Context, content string) (*model.ClassificationResult, error)
 }
@@ -1,15 +1,17 @@
 package classifier
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
 	"github.com/rookiecj/scrum-agents/backend/internal/model"
+	"github.com/rookiecj/scrum-agents/backend/internal/tokens"
 )
 
 // LLMClient is the interface for making LLM API calls.
 type LLMClient interface {
-	Complete(prompt string) (string, error)
+	Complete(ctx context.Context, prompt string) (string, error)
 }
 
 // LLMClassifier classifies content using an LLM provider.
@@ -23,10 +25,11 @@ func NewLLMClassifier(client LLMClient) *LLMClassifier {
 }
 
 // Classify sends the content to the LLM and parses the classification result.
-func (c *LLMClassifier) Classify(content string) (*model.ClassificationResult, error) {
-	prompt := ClassificationPrompt(content)
+// The prompt is sized to the client's token budget (see tokens.Of).
+func (c *LLMClassifier) Classify(ctx context.Context, content string) (*model.ClassificationResult, error) {
+	prompt, truncated := ClassificationPrompt(content, tokens.Of(c.Client))
 
-	response, err := c.Client.Complete(prompt)
+	response, err := c.Client.Complete(ctx, prompt)
 	if err != nil {
 		return nil, fmt.Errorf("LLM classification failed: %w", err)
 	}
@@ -40,6 +43,7 @@ func (c *LLMClassifier) Classify(content string) (*model.ClassificationResult, e
 		return nil, fmt.Errorf("invalid primary category: %s", result.Primary)
 	}
 
+	result.Truncated = truncated
 	return &result, nil
 }
 
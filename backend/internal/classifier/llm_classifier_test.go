@@ -1,10 +1,14 @@
 package classifier
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/rookiecj/scrum-agents/backend/internal/model"
+	"github.com/rookiecj/scrum-agents/backend/internal/tokens"
 )
 
 // mockLLMClient is a test double for LLMClient.
@@ -13,7 +17,7 @@ type mockLLMClient struct {
 	err      error
 }
 
-func (m *mockLLMClient) Complete(prompt string) (string, error) {
+func (m *mockLLMClient) Complete(ctx context.Context, prompt string) (string, error) {
 	return m.response, m.err
 }
 
@@ -84,7 +88,7 @@ func TestLLMClassifier_Classify(t *testing.T) {
 			client := &mockLLMClient{response: tt.llmResponse, err: tt.llmErr}
 			classifier := NewLLMClassifier(client)
 
-			result, err := classifier.Classify("some content")
+			result, err := classifier.Classify(context.Background(), "some content")
 
 			if tt.wantErr {
 				if err == nil {
@@ -107,11 +111,14 @@ func TestLLMClassifier_Classify(t *testing.T) {
 
 func TestClassificationPrompt(t *testing.T) {
 	content := "This is a test article about how TCP works."
-	prompt := ClassificationPrompt(content)
+	prompt, truncated := ClassificationPrompt(content, tokens.Default)
 
 	if prompt == "" {
 		t.Error("expected non-empty prompt")
 	}
+	if truncated {
+		t.Error("short content should not be truncated")
+	}
 
 	// Check that all categories are mentioned
 	categories := model.AllCategories()
@@ -128,16 +135,46 @@ func TestClassificationPrompt(t *testing.T) {
 }
 
 func TestClassificationPrompt_Truncation(t *testing.T) {
-	// Create content longer than 4000 chars
-	longContent := make([]byte, 5000)
-	for i := range longContent {
-		longContent[i] = 'a'
+	large := tokens.Budget{ContextWindow: 200000, OutputReserve: 4096, Estimator: tokens.DefaultEstimator}
+	tests := []struct {
+		name          string
+		content       string
+		budget        tokens.Budget
+		wantTruncated bool
+	}{
+		{"fits default budget", strings.Repeat("한국어 문장입니다. ", 200), tokens.Default, false},
+		{"exceeds default budget", strings.Repeat("한국어 문장입니다. ", 600), tokens.Default, true},
+		{"fits large window", strings.Repeat("한국어 문장입니다. ", 600), large, false},
+		{"capped in large window", strings.Repeat("한국어 문장입니다. ", 5000), large, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prompt, truncated := ClassificationPrompt(tt.content, tt.budget)
+			if truncated != tt.wantTruncated {
+				t.Fatalf("truncated = %v, want %v", truncated, tt.wantTruncated)
+			}
+			if !utf8.ValidString(prompt) {
+				t.Error("prompt is not valid UTF-8")
+			}
+			if n := tt.budget.Count(prompt); n > tt.budget.Input() {
+				t.Errorf("prompt is %d tokens, budget %d", n, tt.budget.Input())
+			}
+			if truncated && !strings.Contains(prompt, "입니다...") {
+				t.Error("content should be cut after a sentence and marked")
+			}
+		})
 	}
+}
 
-	prompt := ClassificationPrompt(string(longContent))
-	// Content should be truncated + "..." appended
-	if !containsString(prompt, "...") {
-		t.Error("long content should be truncated with ...")
+func TestLLMClassifier_Classify_ReportsTruncation(t *testing.T) {
+	c := NewLLMClassifier(&mockLLMClient{response: `{"primary":"튜토리얼","confidence":0.9}`})
+	result, err := c.Classify(context.Background(), strings.Repeat("긴 글. ", 5000))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Truncated {
+		t.Error("classifying long content should report truncation")
 	}
 }
 
@@ -1,9 +1,38 @@
 package classifier
 
-import "fmt"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 
-// ClassificationPrompt returns the prompt used to classify content.
-func ClassificationPrompt(content string) string {
+	"github.com/rookiecj/scrum-agents/backend/internal/tokens"
+)
+
+// MaxContentTokens caps the content sent for classification. The category
+// shows in the opening of a text, so even models with large context windows
+// get no more than this.
+const MaxContentTokens = 8000
+
+// ClassificationPrompt returns the prompt used to classify content. Content
+// is cut on a sentence boundary to fit the input budget b and
+// MaxContentTokens, and the second result reports whether it was.
+func ClassificationPrompt(content string, b tokens.Budget) (string, bool) {
+	room := min(b.Input()-b.Count(classificationPrompt("")), MaxContentTokens)
+	content, truncated := b.Fit(content, room)
+	if truncated {
+		content += "..."
+	}
+	return classificationPrompt(content), truncated
+}
+
+// PromptVersion returns a short hash of the classification prompt, so results
+// cached under an older prompt are not served after it changes.
+func PromptVersion() string {
+	sum := sha256.Sum256([]byte(classificationPrompt("")))
+	return hex.EncodeToString(sum[:8])
+}
+
+func classificationPrompt(content string) string {
 	return fmt.Sprintf(`You are a content classifier. Classify the following content into exactly one of these categories:
 
 1. 원리소개 - Explains a principle, concept, or how something works (e.g., "How TCP works", "양자컴퓨팅 원리")
@@ -19,12 +48,5 @@ Respond ONLY with a JSON object in this exact format:
 Content to classify:
 ---
 %s
----`, truncate(content, 4000))
-}
-
-func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	return s[:maxLen] + "..."
+---`, content)
 }
@@ -0,0 +1,468 @@
+// Package config defines the server configuration: defaults, a YAML file,
+// and environment variables overriding both, validated before the server
+// starts.
+package config
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/auth"
+	"github.com/rookiecj/scrum-agents/backend/internal/cache"
+	"github.com/rookiecj/scrum-agents/backend/internal/extractor"
+	"github.com/rookiecj/scrum-agents/backend/internal/llm"
+	"github.com/rookiecj/scrum-agents/backend/internal/pipeline"
+	"github.com/rookiecj/scrum-agents/backend/internal/ratelimit"
+	"github.com/rookiecj/scrum-agents/backend/internal/usage"
+)
+
+// Config is the server configuration.
+type Config struct {
+	Server     Server     `yaml:"server"`
+	Database   Database   `yaml:"database"`
+	Auth       Auth       `yaml:"auth"`
+	Access     Access     `yaml:"access"`
+	RateLimit  RateLimit  `yaml:"rate_limit"`
+	Cache      Cache      `yaml:"cache"`
+	LLM        LLM        `yaml:"llm"`
+	Usage      Usage      `yaml:"usage"`
+	Summarizer Summarizer `yaml:"summarizer"`
+	Extractor  Extractor  `yaml:"extractor"`
+	Batch      Batch      `yaml:"batch"`
+	Jobs       Jobs       `yaml:"jobs"`
+	Metrics    Metrics    `yaml:"metrics"`
+	Tracing    Tracing    `yaml:"tracing"`
+}
+
+// Server configures the HTTP listener.
+type Server struct {
+	Addr string `yaml:"addr"`
+	TLS  TLS    `yaml:"tls"`
+	// ReadHeaderTimeout bounds the time to read a request's headers, and
+	// ReadTimeout the whole request. Zero disables a timeout.
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	// WriteTimeout bounds the time to serve a request, so it must leave
+	// room for LLM calls and their retries. Streamed summaries clear it.
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	// IdleTimeout closes keep-alive connections left idle that long.
+	IdleTimeout time.Duration `yaml:"idle_timeout"`
+	// ShutdownTimeout is how long in-flight requests and background jobs
+	// may take to finish once the server is asked to stop.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+// TLS enables HTTPS when both files are set.
+type TLS struct {
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+}
+
+// Enabled reports whether a certificate is configured.
+func (t TLS) Enabled() bool {
+	return t.CertFile != ""
+}
+
+// Database configures the SQLite database.
+type Database struct {
+	Path string `yaml:"path"`
+}
+
+// Auth configures sign-in tokens.
+type Auth struct {
+	// JWTSecret signs access tokens. An insecure default is used, with a
+	// warning, when it is empty.
+	JWTSecret  string        `yaml:"jwt_secret"`
+	AccessTTL  time.Duration `yaml:"access_ttl"`
+	RefreshTTL time.Duration `yaml:"refresh_ttl"`
+}
+
+// Access configures which routes need a token.
+type Access struct {
+	// Anonymous serves the LLM routes other than batch and jobs to callers
+	// without a token, within the anonymous rate limits.
+	Anonymous bool `yaml:"anonymous"`
+	// Routes sets the access level of single routes, keyed by ServeMux
+	// pattern such as "POST /api/extract".
+	Routes map[string]auth.Access `yaml:"routes"`
+	// TrustProxy identifies clients by the last X-Forwarded-For address
+	// instead of the connection's. Enable it only behind a proxy that
+	// appends the header.
+	TrustProxy bool `yaml:"trust_proxy"`
+}
+
+// RateLimit limits how often callers may use the LLM routes.
+type RateLimit struct {
+	// Disabled turns rate limits and daily quotas off.
+	Disabled bool `yaml:"disabled"`
+	// User limits each signed-in caller, and Anonymous each client address
+	// without a token, per LLM route. Limits read N/PERIOD, such as 30/m,
+	// or "off".
+	User      ratelimit.Limit `yaml:"user"`
+	Anonymous ratelimit.Limit `yaml:"anonymous"`
+	// Routes and AnonymousRoutes override the limits of single routes,
+	// keyed by ServeMux pattern. Routes other than the LLM routes are only
+	// limited when listed here.
+	Routes          map[string]ratelimit.Limit `yaml:"routes"`
+	AnonymousRoutes map[string]ratelimit.Limit `yaml:"anonymous_routes"`
+	// AnonymousMaxBody caps request bodies of callers without a token, in
+	// bytes. Zero means no cap.
+	AnonymousMaxBody int64 `yaml:"anonymous_max_body"`
+	// DailyQuota counts LLM requests per caller and UTC day.
+	DailyQuota DailyQuota `yaml:"daily_quota"`
+}
+
+// DailyQuota is the number of LLM requests a user, or a client address
+// without a token, may make per UTC day. Zero means no quota.
+type DailyQuota struct {
+	User      int `yaml:"user"`
+	Anonymous int `yaml:"anonymous"`
+}
+
+// Cache configures the result cache of extraction, classification and
+// summaries.
+type Cache struct {
+	Disabled bool `yaml:"disabled"`
+	// TTL is the lifetime of LLM results, and ExtractTTL that of extracted
+	// content.
+	TTL        time.Duration `yaml:"ttl"`
+	ExtractTTL time.Duration `yaml:"extract_ttl"`
+	// MaxEntries bounds the entries kept in memory.
+	MaxEntries int `yaml:"max_entries"`
+	// Persist also keeps entries in the database, so they outlive restarts.
+	Persist bool `yaml:"persist"`
+}
+
+// Options returns the cache options, keeping entries in db if Persist is
+// set.
+func (c Cache) Options(db *sql.DB) cache.Options {
+	opts := cache.Options{
+		TTL:        c.TTL,
+		LayerTTL:   map[cache.Layer]time.Duration{cache.LayerExtract: c.ExtractTTL},
+		MaxEntries: c.MaxEntries,
+	}
+	if c.Persist {
+		opts.DB = db
+	}
+	return opts
+}
+
+// LLM configures the built-in providers and how requests fall back between
+// them.
+type LLM struct {
+	// FallbackOrder lists provider names in the order failed requests fall
+	// back along; the first configured one is the default provider.
+	FallbackOrder []string `yaml:"fallback_order"`
+	Claude        Provider `yaml:"claude"`
+	OpenAI        Provider `yaml:"openai"`
+	Gemini        Provider `yaml:"gemini"`
+	// Ollama is enabled when its base URL or model is set.
+	Ollama Provider `yaml:"ollama"`
+	// ProvidersFile declares OpenAI-compatible providers, in JSON.
+	ProvidersFile string `yaml:"providers_file"`
+}
+
+// Provider configures one LLM provider. Hosted providers are enabled by
+// their API key.
+type Provider struct {
+	APIKey        string        `yaml:"api_key"`
+	Model         string        `yaml:"model"`
+	BaseURL       string        `yaml:"base_url"`
+	Timeout       time.Duration `yaml:"timeout"`
+	MaxTokens     int           `yaml:"max_tokens"`
+	MaxRetries    int           `yaml:"max_retries"`
+	ContextWindow int           `yaml:"context_window"`
+}
+
+// Apply returns base with the fields set in p replacing its own.
+func (p Provider) Apply(base llm.Config) llm.Config {
+	if p.APIKey != "" {
+		base.APIKey = p.APIKey
+	}
+	if p.Model != "" {
+		base.Model = p.Model
+	}
+	if p.BaseURL != "" {
+		base.BaseURL = p.BaseURL
+	}
+	if p.Timeout != 0 {
+		base.Timeout = p.Timeout
+	}
+	if p.MaxTokens != 0 {
+		base.MaxTokens = p.MaxTokens
+	}
+	if p.MaxRetries != 0 {
+		base.MaxRetries = p.MaxRetries
+	}
+	if p.ContextWindow != 0 {
+		base.ContextWindow = p.ContextWindow
+	}
+	return base
+}
+
+// fromLLM returns the provider settings of c.
+func fromLLM(c llm.Config) Provider {
+	return Provider{
+		APIKey:        c.APIKey,
+		Model:         c.Model,
+		BaseURL:       c.BaseURL,
+		Timeout:       c.Timeout,
+		MaxTokens:     c.MaxTokens,
+		MaxRetries:    c.MaxRetries,
+		ContextWindow: c.ContextWindow,
+	}
+}
+
+// Usage configures LLM cost accounting.
+type Usage struct {
+	// Prices maps model names to their price in US dollars per million
+	// tokens. It starts from the built-in prices.
+	Prices usage.Prices `yaml:"prices"`
+	// PricesFile is a JSON object of further prices, merged over Prices
+	// when the configuration is loaded.
+	PricesFile string `yaml:"prices_file"`
+	// Admins are the emails of users who may see the usage of all users.
+	Admins []string `yaml:"admins"`
+}
+
+// Summarizer configures summary generation.
+type Summarizer struct {
+	// TemplateDir holds the prompt templates.
+	TemplateDir string `yaml:"template_dir"`
+	// ConfidenceThreshold is the classification confidence below which
+	// the generic template is used.
+	ConfidenceThreshold float64 `yaml:"confidence_threshold"`
+}
+
+// Extractor bounds content extraction.
+type Extractor struct {
+	// Timeout bounds each fetch; zero leaves it to the request.
+	Timeout time.Duration `yaml:"timeout"`
+	// MaxPDFBytes is the largest PDF downloaded.
+	MaxPDFBytes int64 `yaml:"max_pdf_bytes"`
+}
+
+// Limits returns the extractor limits.
+func (e Extractor) Limits() extractor.Limits {
+	return extractor.Limits{Timeout: e.Timeout, MaxPDFSize: e.MaxPDFBytes}
+}
+
+// Batch bounds POST /api/batch.
+type Batch struct {
+	// Workers is the number of URLs processed at once per batch.
+	Workers int `yaml:"workers"`
+	// PerHost is the number of fetches running at once against one host.
+	PerHost int `yaml:"per_host"`
+	// MaxURLs is the most URLs accepted per batch.
+	MaxURLs int `yaml:"max_urls"`
+	// Timeout bounds a batch request. It replaces server.write_timeout for
+	// the route, as a batch of max_urls URLs can take longer.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// Options returns the concurrency limits of a batch.
+func (b Batch) Options() pipeline.BatchOptions {
+	return pipeline.BatchOptions{Workers: b.Workers, PerHost: b.PerHost}
+}
+
+// Jobs configures the background jobs of POST /api/jobs.
+type Jobs struct {
+	// Workers is the number of jobs run at once.
+	Workers int `yaml:"workers"`
+	// Timeout bounds each run of a job.
+	Timeout time.Duration `yaml:"timeout"`
+	// MaxAttempts is the number of runs after which a job interrupted by a
+	// shutdown or crash fails instead of running again.
+	MaxAttempts int `yaml:"max_attempts"`
+	// WebhookSecret signs completion webhooks. Jobs cannot have a callback
+	// URL when it is empty.
+	WebhookSecret string `yaml:"webhook_secret"`
+	// WebhookTimeout bounds each webhook delivery attempt.
+	WebhookTimeout time.Duration `yaml:"webhook_timeout"`
+	// WebhookAllow lists the IP addresses and CIDR networks callbacks may
+	// reach although they are internal, such as loopback or private
+	// addresses, which are refused otherwise.
+	WebhookAllow []string `yaml:"webhook_allow"`
+}
+
+// Metrics configures GET /metrics.
+type Metrics struct {
+	// Token is the bearer token scrapers must send. The endpoint is open
+	// when it is empty, and should then be kept off public networks.
+	Token string `yaml:"token"`
+}
+
+// Tracing configures where spans go.
+type Tracing struct {
+	// Exporter is "off", "log", which writes spans to the logs, or "otlp".
+	Exporter string `yaml:"exporter"`
+	OTLP     OTLP   `yaml:"otlp"`
+}
+
+// OTLP configures the OpenTelemetry collector spans are sent to.
+type OTLP struct {
+	// Endpoint defaults to http://localhost:4318.
+	Endpoint    string            `yaml:"endpoint"`
+	Headers     map[string]string `yaml:"headers"`
+	ServiceName string            `yaml:"service_name"`
+}
+
+// Default returns the configuration used when nothing is set.
+func Default() Config {
+	ollama := fromLLM(llm.DefaultOllamaConfig("", ""))
+	// An empty URL and model keep Ollama disabled until one is set.
+	ollama.BaseURL, ollama.Model = "", ""
+	return Config{
+		Server: Server{
+			Addr:              ":8080",
+			ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout:       30 * time.Second,
+			WriteTimeout:      5 * time.Minute,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   30 * time.Second,
+		},
+		Database: Database{Path: "scrum-agents.db"},
+		Auth: Auth{
+			AccessTTL:  15 * time.Minute,
+			RefreshTTL: auth.DefaultRefreshTTL,
+		},
+		RateLimit: RateLimit{
+			User:             ratelimit.Every(30, time.Minute),
+			Anonymous:        ratelimit.Every(5, time.Minute),
+			AnonymousMaxBody: 64 << 10,
+			DailyQuota:       DailyQuota{User: 500, Anonymous: 50},
+		},
+		Cache: Cache{
+			TTL:        cache.DefaultTTL,
+			ExtractTTL: time.Hour,
+			MaxEntries: cache.DefaultMaxEntries,
+		},
+		LLM: LLM{
+			Claude: fromLLM(llm.DefaultClaudeConfig("")),
+			OpenAI: fromLLM(llm.DefaultOpenAIConfig("")),
+			Gemini: fromLLM(llm.DefaultGeminiConfig("")),
+			Ollama: ollama,
+		},
+		// A copy, so loading prices never changes the defaults.
+		Usage: Usage{Prices: usage.DefaultPrices.Merge(nil)},
+		Summarizer: Summarizer{
+			TemplateDir:         "prompts",
+			ConfidenceThreshold: 0.6,
+		},
+		Extractor: Extractor{
+			MaxPDFBytes: extractor.DefaultMaxPDFSize,
+		},
+		Batch: Batch{
+			Workers: pipeline.DefaultBatchWorkers,
+			PerHost: pipeline.DefaultBatchPerHost,
+			MaxURLs: pipeline.DefaultBatchMaxURLs,
+			Timeout: pipeline.DefaultBatchTimeout,
+		},
+		Jobs: Jobs{
+			Workers:        2,
+			Timeout:        10 * time.Minute,
+			MaxAttempts:    3,
+			WebhookTimeout: 10 * time.Second,
+		},
+		Tracing: Tracing{Exporter: "off"},
+	}
+}
+
+// Load returns the defaults overridden by the YAML file at path, if path is
+// not empty, and then by environment variables (see envVars), validated.
+func Load(path string) (Config, error) {
+	cfg := Default()
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Config{}, fmt.Errorf("read config file: %w", err)
+		}
+		if err := decode(data, &cfg); err != nil {
+			return Config{}, fmt.Errorf("parse config file %s: %w", path, err)
+		}
+	}
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return Config{}, err
+	}
+	if path := cfg.Usage.PricesFile; path != "" {
+		if err := cfg.Usage.loadPrices(path); err != nil {
+			return Config{}, err
+		}
+	}
+	cfg.normalize()
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// loadPrices merges the prices in the JSON file at path over u.Prices.
+func (u *Usage) loadPrices(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read prices file: %w", err)
+	}
+	var prices usage.Prices
+	if err := json.Unmarshal(data, &prices); err != nil {
+		return fmt.Errorf("parse prices file %s: %w", path, err)
+	}
+	u.Prices = u.Prices.Merge(prices)
+	return nil
+}
+
+// decode reads YAML into cfg, rejecting unknown keys so typos surface.
+func decode(data []byte, cfg *Config) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// redacted replaces secrets in printed configurations.
+const redacted = "REDACTED"
+
+// Redacted returns a copy of c with its secrets replaced, for printing.
+func (c Config) Redacted() Config {
+	redact := func(s *string) {
+		if *s != "" {
+			*s = redacted
+		}
+	}
+	redact(&c.Auth.JWTSecret)
+	redact(&c.Jobs.WebhookSecret)
+	redact(&c.Metrics.Token)
+	redact(&c.LLM.Claude.APIKey)
+	redact(&c.LLM.OpenAI.APIKey)
+	redact(&c.LLM.Gemini.APIKey)
+	redact(&c.LLM.Ollama.APIKey)
+	// Collector headers usually carry an API key.
+	if len(c.Tracing.OTLP.Headers) > 0 {
+		headers := make(map[string]string, len(c.Tracing.OTLP.Headers))
+		for k, v := range c.Tracing.OTLP.Headers {
+			redact(&v)
+			headers[k] = v
+		}
+		c.Tracing.OTLP.Headers = headers
+	}
+	return c
+}
+
+// Write writes c to w as YAML.
+func (c Config) Write(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return err
+	}
+	return enc.Close()
+}
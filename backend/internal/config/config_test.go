@@ -0,0 +1,312 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/auth"
+	"github.com/rookiecj/scrum-agents/backend/internal/llm"
+	"github.com/rookiecj/scrum-agents/backend/internal/ratelimit"
+	"github.com/rookiecj/scrum-agents/backend/internal/usage"
+)
+
+// clearEnv unsets every override for the duration of the test.
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, e := range envVars {
+		t.Setenv(e.name, "")
+	}
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	clearEnv(t)
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Addr != ":8080" || cfg.Summarizer.TemplateDir != "prompts" || cfg.Summarizer.ConfidenceThreshold != 0.6 {
+		t.Errorf("defaults = %+v", cfg)
+	}
+	if cfg.LLM.Claude.Model != llm.DefaultClaudeConfig("").Model {
+		t.Errorf("claude model = %q", cfg.LLM.Claude.Model)
+	}
+	if cfg.LLM.Ollama.BaseURL != "" || cfg.LLM.Ollama.Model != "" {
+		t.Errorf("ollama enabled by default: %+v", cfg.LLM.Ollama)
+	}
+}
+
+func TestLoad_FileAndEnv(t *testing.T) {
+	clearEnv(t)
+	path := writeFile(t, `
+server:
+  addr: 127.0.0.1:9000
+  tls:
+    cert_file: cert.pem
+    key_file: key.pem
+auth:
+  access_ttl: 5m
+llm:
+  fallback_order: [OpenAI, " claude", ""]
+  claude:
+    model: claude-haiku-4-5
+    timeout: 45s
+    max_tokens: 2048
+  ollama:
+    model: qwen3
+summarizer:
+  confidence_threshold: 0.75
+extractor:
+  timeout: 20s
+`)
+	t.Setenv("DB_PATH", "/data/app.db")
+	t.Setenv("OPENAI_API_KEY", "sk-env")
+	t.Setenv("JWT_ACCESS_TTL", "10m")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Addr != "127.0.0.1:9000" || !cfg.Server.TLS.Enabled() {
+		t.Errorf("server = %+v", cfg.Server)
+	}
+	if cfg.Database.Path != "/data/app.db" || cfg.LLM.OpenAI.APIKey != "sk-env" {
+		t.Errorf("env overrides not applied: db %q, openai key %q", cfg.Database.Path, cfg.LLM.OpenAI.APIKey)
+	}
+	if cfg.Auth.AccessTTL != 10*time.Minute {
+		t.Errorf("access_ttl = %v, want the env value 10m", cfg.Auth.AccessTTL)
+	}
+	if got := strings.Join(cfg.LLM.FallbackOrder, ","); got != "openai,claude" {
+		t.Errorf("fallback_order = %q", got)
+	}
+	claude := cfg.LLM.Claude.Apply(llm.DefaultClaudeConfig(""))
+	if claude.Model != "claude-haiku-4-5" || claude.Timeout != 45*time.Second || claude.MaxTokens != 2048 {
+		t.Errorf("claude = %+v", claude)
+	}
+	// Settings absent from the file keep their defaults.
+	if claude.MaxRetries != 3 || cfg.Extractor.MaxPDFBytes == 0 {
+		t.Errorf("defaults lost: retries %d, max pdf %d", claude.MaxRetries, cfg.Extractor.MaxPDFBytes)
+	}
+	if cfg.LLM.Ollama.Model != "qwen3" || cfg.Extractor.Timeout != 20*time.Second || cfg.Summarizer.ConfidenceThreshold != 0.75 {
+		t.Errorf("ollama %+v, extractor %+v, summarizer %+v", cfg.LLM.Ollama, cfg.Extractor, cfg.Summarizer)
+	}
+}
+
+func TestLoad_ServiceSettings(t *testing.T) {
+	clearEnv(t)
+	path := writeFile(t, `
+access:
+  anonymous: true
+  routes:
+    "POST  /api/extract": USER
+rate_limit:
+  user: 60/m
+  anonymous: "off"
+  anonymous_routes:
+    POST /api/classify: 1/m
+  daily_quota:
+    user: 1000
+cache:
+  ttl: 2h
+  persist: true
+usage:
+  prices:
+    llama3: {input: 0.1, output: 0.2}
+  admins: [ops@example.com]
+metrics:
+  token: scrape-me
+tracing:
+  exporter: otlp
+  otlp:
+    endpoint: http://collector:4318
+`)
+	t.Setenv("SERVER_READ_HEADER_TIMEOUT", "3s")
+	t.Setenv("AUTH_ROUTES", "POST /api/process=user")
+	t.Setenv("AUTH_TRUST_PROXY", "true")
+	t.Setenv("RATE_LIMIT_ROUTES", "POST /api/summarize=10/m")
+	t.Setenv("QUOTA_DAILY_ANONYMOUS", "0")
+	t.Setenv("CACHE_MAX_ENTRIES", "50")
+	t.Setenv("ADMIN_EMAILS", " cfo@example.com, ")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "Authorization=Bearer k")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.ReadHeaderTimeout != 3*time.Second {
+		t.Errorf("read_header_timeout = %v, want 3s", cfg.Server.ReadHeaderTimeout)
+	}
+	wantRoutes := map[string]auth.Access{"POST /api/extract": auth.AccessUser, "POST /api/process": auth.AccessUser}
+	if !cfg.Access.Anonymous || !cfg.Access.TrustProxy || len(cfg.Access.Routes) != 2 ||
+		cfg.Access.Routes["POST /api/extract"] != wantRoutes["POST /api/extract"] || cfg.Access.Routes["POST /api/process"] != wantRoutes["POST /api/process"] {
+		t.Errorf("access = %+v", cfg.Access)
+	}
+	rl := cfg.RateLimit
+	if rl.User != ratelimit.Every(60, time.Minute) || rl.Anonymous.Enabled() ||
+		rl.Routes["POST /api/summarize"] != ratelimit.Every(10, time.Minute) ||
+		rl.AnonymousRoutes["POST /api/classify"] != ratelimit.Every(1, time.Minute) {
+		t.Errorf("rate_limit = %+v", rl)
+	}
+	if rl.DailyQuota != (DailyQuota{User: 1000, Anonymous: 0}) || rl.AnonymousMaxBody != Default().RateLimit.AnonymousMaxBody {
+		t.Errorf("daily_quota = %+v, anonymous_max_body = %d", rl.DailyQuota, rl.AnonymousMaxBody)
+	}
+	if cfg.Cache.TTL != 2*time.Hour || cfg.Cache.ExtractTTL != time.Hour || cfg.Cache.MaxEntries != 50 || !cfg.Cache.Persist {
+		t.Errorf("cache = %+v", cfg.Cache)
+	}
+	if cfg.Usage.Prices["llama3"] != (usage.Price{Input: 0.1, Output: 0.2}) || cfg.Usage.Prices["gpt-4o"] != usage.DefaultPrices["gpt-4o"] {
+		t.Errorf("prices = %+v, want defaults and the file's", cfg.Usage.Prices)
+	}
+	if !slices.Equal(cfg.Usage.Admins, []string{"cfo@example.com"}) {
+		t.Errorf("admins = %q, want the env list", cfg.Usage.Admins)
+	}
+	if cfg.Metrics.Token != "scrape-me" {
+		t.Errorf("metrics token = %q", cfg.Metrics.Token)
+	}
+	if cfg.Tracing.Exporter != "otlp" || cfg.Tracing.OTLP.Endpoint != "http://collector:4318" || cfg.Tracing.OTLP.Headers["Authorization"] != "Bearer k" {
+		t.Errorf("tracing = %+v", cfg.Tracing)
+	}
+}
+
+func TestLoad_PricesFile(t *testing.T) {
+	clearEnv(t)
+	path := filepath.Join(t.TempDir(), "prices.json")
+	os.WriteFile(path, []byte(`{"gpt-4o": {"input": 1, "output": 4}, "llama3": {"input": 0.1, "output": 0.1}}`), 0o600)
+	t.Setenv("LLM_PRICES_FILE", path)
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prices := cfg.Usage.Prices
+	if prices["gpt-4o"] != (usage.Price{Input: 1, Output: 4}) || prices["llama3"].Input != 0.1 {
+		t.Errorf("prices = %+v, want file entries", prices)
+	}
+	if prices["claude-sonnet-4"] != usage.DefaultPrices["claude-sonnet-4"] {
+		t.Error("defaults not kept")
+	}
+	if usage.DefaultPrices["gpt-4o"].Input != 2.5 {
+		t.Error("loading prices changed the defaults")
+	}
+
+	for _, body := range []string{`[1]`, `{"m": {"input": -1}}`} {
+		os.WriteFile(path, []byte(body), 0o600)
+		if _, err := Load(""); err == nil {
+			t.Errorf("Load() with prices %s error = nil, want error", body)
+		}
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	got, err := parseHeaders(" Authorization = Bearer k ,x-team=ai,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got["Authorization"] != "Bearer k" || got["x-team"] != "ai" {
+		t.Errorf("parseHeaders() = %v", got)
+	}
+	if _, err := parseHeaders("novalue"); err == nil {
+		t.Error("parseHeaders(novalue) error = nil, want error")
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	clearEnv(t)
+	tests := []struct {
+		name, file, env, value, want string
+	}{
+		{name: "unknown key", file: "server:\n  adress: :80\n", want: "field adress not found"},
+		{name: "bad duration", file: "auth:\n  access_ttl: soon\n", want: "line 2"},
+		{name: "invalid setting", file: "summarizer:\n  confidence_threshold: 2\n", want: "summarizer.confidence_threshold"},
+		{name: "bad env", env: "EXTRACT_TIMEOUT", value: "fast", want: "EXTRACT_TIMEOUT"},
+		{name: "bad limit", file: "rate_limit:\n  user: lots\n", want: `limit "lots"`},
+		{name: "bad access level", file: "access:\n  routes:\n    POST /api/extract: admin\n", want: "access.routes"},
+		{name: "bad env routes", env: "AUTH_ROUTES", value: "POST /api/extract", want: "AUTH_ROUTES"},
+		{name: "bad env boolean", env: "CACHE_PERSIST", value: "yes please", want: "CACHE_PERSIST"},
+		{name: "bad env headers", env: "OTEL_EXPORTER_OTLP_HEADERS", value: "novalue", want: "OTEL_EXPORTER_OTLP_HEADERS"},
+		{name: "unknown exporter", env: "TRACING", value: "jaeger", want: "tracing.exporter"},
+		{name: "missing file", file: "-", want: "read config file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := ""
+			switch tt.file {
+			case "":
+			case "-":
+				path = filepath.Join(t.TempDir(), "missing.yaml")
+			default:
+				path = writeFile(t, tt.file)
+			}
+			if tt.env != "" {
+				t.Setenv(tt.env, tt.value)
+			}
+			_, err := Load(path)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load() error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_AnthropicKeyWins(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("CLAUDE_API_KEY", "old")
+	t.Setenv("ANTHROPIC_API_KEY", "new")
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.LLM.Claude.APIKey != "new" {
+		t.Errorf("claude key = %q, want ANTHROPIC_API_KEY", cfg.LLM.Claude.APIKey)
+	}
+}
+
+func TestRedactedWrite(t *testing.T) {
+	cfg := Default()
+	cfg.Auth.JWTSecret = "jwt-secret"
+	cfg.LLM.Claude.APIKey = "sk-ant-secret"
+	cfg.Jobs.WebhookSecret = "whsec-secret"
+	cfg.Metrics.Token = "scrape-secret"
+	cfg.Tracing.OTLP.Headers = map[string]string{"Authorization": "Bearer otlp-secret"}
+	cfg.RateLimit.Routes = map[string]ratelimit.Limit{"POST /api/summarize": ratelimit.Every(10, time.Minute)}
+
+	var buf bytes.Buffer
+	if err := cfg.Redacted().Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, secret := range []string{"jwt-secret", "sk-ant", "whsec", "scrape-secret", "otlp-secret"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("secret %q printed:\n%s", secret, out)
+		}
+	}
+	if !strings.Contains(out, "jwt_secret: "+redacted) || !strings.Contains(out, "access_ttl: 15m0s") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	if cfg.LLM.Claude.APIKey != "sk-ant-secret" || cfg.Tracing.OTLP.Headers["Authorization"] != "Bearer otlp-secret" {
+		t.Error("Redacted modified the original")
+	}
+
+	// The dump is a valid config file.
+	var back Config
+	if err := decode(buf.Bytes(), &back); err != nil {
+		t.Fatalf("decoding dump: %v", err)
+	}
+	if back.Auth.AccessTTL != cfg.Auth.AccessTTL || back.LLM.Gemini.Model != cfg.LLM.Gemini.Model ||
+		back.RateLimit.User != cfg.RateLimit.User || back.RateLimit.Routes["POST /api/summarize"] != cfg.RateLimit.Routes["POST /api/summarize"] ||
+		len(back.Usage.Prices) != len(cfg.Usage.Prices) {
+		t.Errorf("round trip lost settings: %+v", back)
+	}
+}
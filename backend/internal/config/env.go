@@ -0,0 +1,230 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/auth"
+	"github.com/rookiecj/scrum-agents/backend/internal/ratelimit"
+)
+
+// envVar overrides one setting from an environment variable.
+type envVar struct {
+	name string
+	set  func(c *Config, v string) error
+}
+
+// envVars are the environment variables that override the file. The older
+// names, such as DB_PATH and the provider API keys, keep their meaning.
+var envVars = []envVar{
+	{"LISTEN_ADDR", str(func(c *Config) *string { return &c.Server.Addr })},
+	{"TLS_CERT_FILE", str(func(c *Config) *string { return &c.Server.TLS.CertFile })},
+	{"TLS_KEY_FILE", str(func(c *Config) *string { return &c.Server.TLS.KeyFile })},
+	{"SERVER_READ_HEADER_TIMEOUT", duration(func(c *Config) *time.Duration { return &c.Server.ReadHeaderTimeout })},
+	{"SERVER_READ_TIMEOUT", duration(func(c *Config) *time.Duration { return &c.Server.ReadTimeout })},
+	{"SERVER_WRITE_TIMEOUT", duration(func(c *Config) *time.Duration { return &c.Server.WriteTimeout })},
+	{"SERVER_IDLE_TIMEOUT", duration(func(c *Config) *time.Duration { return &c.Server.IdleTimeout })},
+	{"SHUTDOWN_TIMEOUT", duration(func(c *Config) *time.Duration { return &c.Server.ShutdownTimeout })},
+	{"DB_PATH", str(func(c *Config) *string { return &c.Database.Path })},
+	{"JWT_SECRET", str(func(c *Config) *string { return &c.Auth.JWTSecret })},
+	{"JWT_ACCESS_TTL", duration(func(c *Config) *time.Duration { return &c.Auth.AccessTTL })},
+	{"JWT_REFRESH_TTL", duration(func(c *Config) *time.Duration { return &c.Auth.RefreshTTL })},
+	{"AUTH_ANONYMOUS", boolean(func(c *Config) *bool { return &c.Access.Anonymous })},
+	// AUTH_ROUTES and the route limits below add to the routes of the file.
+	{"AUTH_ROUTES", func(c *Config, v string) error {
+		routes, err := auth.ParseRoutes(v)
+		if err != nil {
+			return err
+		}
+		if c.Access.Routes == nil {
+			c.Access.Routes = make(map[string]auth.Access)
+		}
+		for pattern, a := range routes {
+			c.Access.Routes[pattern] = a
+		}
+		return nil
+	}},
+	{"AUTH_TRUST_PROXY", boolean(func(c *Config) *bool { return &c.Access.TrustProxy })},
+	{"RATE_LIMIT_DISABLED", boolean(func(c *Config) *bool { return &c.RateLimit.Disabled })},
+	{"RATE_LIMIT_USER", limit(func(c *Config) *ratelimit.Limit { return &c.RateLimit.User })},
+	{"RATE_LIMIT_ANONYMOUS", limit(func(c *Config) *ratelimit.Limit { return &c.RateLimit.Anonymous })},
+	{"RATE_LIMIT_ROUTES", routeLimits(func(c *Config) *map[string]ratelimit.Limit { return &c.RateLimit.Routes })},
+	{"RATE_LIMIT_ANONYMOUS_ROUTES", routeLimits(func(c *Config) *map[string]ratelimit.Limit { return &c.RateLimit.AnonymousRoutes })},
+	{"RATE_LIMIT_ANONYMOUS_MAX_BODY", size(func(c *Config) *int64 { return &c.RateLimit.AnonymousMaxBody })},
+	{"QUOTA_DAILY_USER", count(func(c *Config) *int { return &c.RateLimit.DailyQuota.User })},
+	{"QUOTA_DAILY_ANONYMOUS", count(func(c *Config) *int { return &c.RateLimit.DailyQuota.Anonymous })},
+	{"CACHE_DISABLED", boolean(func(c *Config) *bool { return &c.Cache.Disabled })},
+	{"CACHE_TTL", duration(func(c *Config) *time.Duration { return &c.Cache.TTL })},
+	{"CACHE_EXTRACT_TTL", duration(func(c *Config) *time.Duration { return &c.Cache.ExtractTTL })},
+	{"CACHE_MAX_ENTRIES", count(func(c *Config) *int { return &c.Cache.MaxEntries })},
+	{"CACHE_PERSIST", boolean(func(c *Config) *bool { return &c.Cache.Persist })},
+	{"LLM_FALLBACK_ORDER", func(c *Config, v string) error {
+		c.LLM.FallbackOrder = strings.Split(v, ",")
+		return nil
+	}},
+	{"LLM_PROVIDERS_FILE", str(func(c *Config) *string { return &c.LLM.ProvidersFile })},
+	// CLAUDE_API_KEY is the older name; ANTHROPIC_API_KEY wins when both
+	// are set.
+	{"CLAUDE_API_KEY", str(func(c *Config) *string { return &c.LLM.Claude.APIKey })},
+	{"ANTHROPIC_API_KEY", str(func(c *Config) *string { return &c.LLM.Claude.APIKey })},
+	{"CLAUDE_MODEL", str(func(c *Config) *string { return &c.LLM.Claude.Model })},
+	{"OPENAI_API_KEY", str(func(c *Config) *string { return &c.LLM.OpenAI.APIKey })},
+	{"OPENAI_MODEL", str(func(c *Config) *string { return &c.LLM.OpenAI.Model })},
+	{"GOOGLE_API_KEY", str(func(c *Config) *string { return &c.LLM.Gemini.APIKey })},
+	{"GEMINI_MODEL", str(func(c *Config) *string { return &c.LLM.Gemini.Model })},
+	{"OLLAMA_BASE_URL", str(func(c *Config) *string { return &c.LLM.Ollama.BaseURL })},
+	{"OLLAMA_MODEL", str(func(c *Config) *string { return &c.LLM.Ollama.Model })},
+	{"LLM_PRICES_FILE", str(func(c *Config) *string { return &c.Usage.PricesFile })},
+	{"ADMIN_EMAILS", func(c *Config, v string) error {
+		c.Usage.Admins = strings.Split(v, ",")
+		return nil
+	}},
+	{"PROMPTS_DIR", str(func(c *Config) *string { return &c.Summarizer.TemplateDir })},
+	{"SUMMARY_CONFIDENCE_THRESHOLD", func(c *Config, v string) error {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", v)
+		}
+		c.Summarizer.ConfidenceThreshold = f
+		return nil
+	}},
+	{"BATCH_WORKERS", count(func(c *Config) *int { return &c.Batch.Workers })},
+	{"BATCH_PER_HOST", count(func(c *Config) *int { return &c.Batch.PerHost })},
+	{"BATCH_MAX_URLS", count(func(c *Config) *int { return &c.Batch.MaxURLs })},
+	{"BATCH_TIMEOUT", duration(func(c *Config) *time.Duration { return &c.Batch.Timeout })},
+	{"JOB_WORKERS", count(func(c *Config) *int { return &c.Jobs.Workers })},
+	{"JOB_TIMEOUT", duration(func(c *Config) *time.Duration { return &c.Jobs.Timeout })},
+	{"JOB_MAX_ATTEMPTS", count(func(c *Config) *int { return &c.Jobs.MaxAttempts })},
+	{"WEBHOOK_SECRET", str(func(c *Config) *string { return &c.Jobs.WebhookSecret })},
+	{"WEBHOOK_TIMEOUT", duration(func(c *Config) *time.Duration { return &c.Jobs.WebhookTimeout })},
+	{"WEBHOOK_ALLOW", func(c *Config, v string) error {
+		c.Jobs.WebhookAllow = strings.Split(v, ",")
+		return nil
+	}},
+	{"EXTRACT_TIMEOUT", duration(func(c *Config) *time.Duration { return &c.Extractor.Timeout })},
+	{"EXTRACT_MAX_PDF_BYTES", size(func(c *Config) *int64 { return &c.Extractor.MaxPDFBytes })},
+	{"METRICS_TOKEN", str(func(c *Config) *string { return &c.Metrics.Token })},
+	{"TRACING", str(func(c *Config) *string { return &c.Tracing.Exporter })},
+	{"OTEL_EXPORTER_OTLP_ENDPOINT", str(func(c *Config) *string { return &c.Tracing.OTLP.Endpoint })},
+	{"OTEL_EXPORTER_OTLP_HEADERS", func(c *Config, v string) error {
+		headers, err := parseHeaders(v)
+		if err != nil {
+			return err
+		}
+		c.Tracing.OTLP.Headers = headers
+		return nil
+	}},
+	{"OTEL_SERVICE_NAME", str(func(c *Config) *string { return &c.Tracing.OTLP.ServiceName })},
+}
+
+func str(field func(*Config) *string) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		*field(c) = v
+		return nil
+	}
+}
+
+func duration(field func(*Config) *time.Duration) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", v)
+		}
+		*field(c) = d
+		return nil
+	}
+}
+
+func count(field func(*Config) *int) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", v)
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+func size(field func(*Config) *int64) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid byte count %q", v)
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+func boolean(field func(*Config) *bool) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", v)
+		}
+		*field(c) = b
+		return nil
+	}
+}
+
+func limit(field func(*Config) *ratelimit.Limit) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		l, err := ratelimit.ParseLimit(v)
+		if err != nil {
+			return err
+		}
+		*field(c) = l
+		return nil
+	}
+}
+
+func routeLimits(field func(*Config) *map[string]ratelimit.Limit) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		limits, err := ratelimit.ParseRouteLimits(v)
+		if err != nil {
+			return err
+		}
+		m := field(c)
+		if *m == nil {
+			*m = make(map[string]ratelimit.Limit)
+		}
+		for pattern, l := range limits {
+			(*m)[pattern] = l
+		}
+		return nil
+	}
+}
+
+// parseHeaders parses a comma-separated list of key=value pairs.
+func parseHeaders(s string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, "=")
+		if k = strings.TrimSpace(k); !ok || k == "" {
+			return nil, fmt.Errorf("invalid header %q, want key=value", pair)
+		}
+		headers[k] = strings.TrimSpace(v)
+	}
+	return headers, nil
+}
+
+// applyEnv applies the variables in envVars that lookup finds set to a
+// non-empty value.
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	for _, e := range envVars {
+		v, ok := lookup(e.name)
+		if v = strings.TrimSpace(v); !ok || v == "" {
+			continue
+		}
+		if err := e.set(c, v); err != nil {
+			return fmt.Errorf("%s: %w", e.name, err)
+		}
+	}
+	return nil
+}
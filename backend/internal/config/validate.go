@@ -0,0 +1,142 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/auth"
+	"github.com/rookiecj/scrum-agents/backend/internal/jobs"
+	"github.com/rookiecj/scrum-agents/backend/internal/ratelimit"
+)
+
+// normalize lowercases names, drops blank list entries and collapses the
+// spaces in route patterns, so file and environment settings compare
+// alike.
+func (c *Config) normalize() {
+	var order []string
+	for _, name := range c.LLM.FallbackOrder {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			order = append(order, name)
+		}
+	}
+	c.LLM.FallbackOrder = order
+
+	var admins []string
+	for _, email := range c.Usage.Admins {
+		if email = strings.TrimSpace(email); email != "" {
+			admins = append(admins, email)
+		}
+	}
+	c.Usage.Admins = admins
+
+	if len(c.Access.Routes) > 0 {
+		routes := make(map[string]auth.Access, len(c.Access.Routes))
+		for pattern, a := range c.Access.Routes {
+			routes[routePattern(pattern)] = auth.Access(strings.ToLower(strings.TrimSpace(string(a))))
+		}
+		c.Access.Routes = routes
+	}
+	for _, m := range []*map[string]ratelimit.Limit{&c.RateLimit.Routes, &c.RateLimit.AnonymousRoutes} {
+		if len(*m) > 0 {
+			limits := make(map[string]ratelimit.Limit, len(*m))
+			for pattern, l := range *m {
+				limits[routePattern(pattern)] = l
+			}
+			*m = limits
+		}
+	}
+
+	c.Tracing.Exporter = strings.ToLower(strings.TrimSpace(c.Tracing.Exporter))
+	if c.Tracing.Exporter == "" {
+		c.Tracing.Exporter = "off"
+	}
+}
+
+// routePattern collapses the spaces in a ServeMux pattern.
+func routePattern(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Validate reports every invalid setting of c.
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	_, _, err := net.SplitHostPort(c.Server.Addr)
+	check(err == nil, "server.addr: invalid address %q", c.Server.Addr)
+	check(c.Server.TLS.Enabled() == (c.Server.TLS.KeyFile != ""),
+		"server.tls: cert_file and key_file must be set together")
+	check(c.Server.ReadHeaderTimeout >= 0, "server.read_header_timeout: must not be negative")
+	check(c.Server.ReadTimeout >= 0, "server.read_timeout: must not be negative")
+	check(c.Server.WriteTimeout >= 0, "server.write_timeout: must not be negative")
+	check(c.Server.IdleTimeout >= 0, "server.idle_timeout: must not be negative")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout: must be positive")
+	check(c.Database.Path != "", "database.path: is required")
+	check(c.Auth.AccessTTL > 0, "auth.access_ttl: must be positive")
+	check(c.Auth.RefreshTTL > 0, "auth.refresh_ttl: must be positive")
+	for pattern, a := range c.Access.Routes {
+		_, err := auth.ParseAccess(string(a))
+		check(err == nil, "access.routes: %q: %v", pattern, err)
+	}
+	// Anonymous callers are only kept apart from users by their stricter
+	// limits and quota.
+	check(!c.Access.Anonymous || !c.RateLimit.Disabled,
+		"access.anonymous: requires rate limiting, but rate_limit.disabled is set")
+	check(c.RateLimit.AnonymousMaxBody >= 0, "rate_limit.anonymous_max_body: must not be negative")
+	check(c.RateLimit.DailyQuota.User >= 0, "rate_limit.daily_quota.user: must not be negative")
+	check(c.RateLimit.DailyQuota.Anonymous >= 0, "rate_limit.daily_quota.anonymous: must not be negative")
+	check(c.Cache.TTL > 0, "cache.ttl: must be positive")
+	check(c.Cache.ExtractTTL > 0, "cache.extract_ttl: must be positive")
+	check(c.Cache.MaxEntries > 0, "cache.max_entries: must be positive")
+
+	for _, pc := range []struct {
+		name string
+		p    Provider
+	}{
+		{"claude", c.LLM.Claude},
+		{"openai", c.LLM.OpenAI},
+		{"gemini", c.LLM.Gemini},
+		{"ollama", c.LLM.Ollama},
+	} {
+		name, p := pc.name, pc.p
+		check(p.Timeout >= 0, "llm.%s.timeout: must not be negative", name)
+		check(p.MaxTokens >= 0, "llm.%s.max_tokens: must not be negative", name)
+		check(p.MaxRetries >= 0, "llm.%s.max_retries: must not be negative", name)
+		check(p.ContextWindow >= 0, "llm.%s.context_window: must not be negative", name)
+		check(p.ContextWindow == 0 || p.MaxTokens < p.ContextWindow,
+			"llm.%s.max_tokens: must be less than context_window", name)
+	}
+
+	if err := c.Usage.Prices.Validate(); err != nil {
+		check(false, "usage.prices: %v", err)
+	}
+	check(c.Summarizer.TemplateDir != "", "summarizer.template_dir: is required")
+	check(c.Summarizer.ConfidenceThreshold > 0 && c.Summarizer.ConfidenceThreshold <= 1,
+		"summarizer.confidence_threshold: must be in (0, 1]")
+	check(c.Extractor.Timeout >= 0, "extractor.timeout: must not be negative")
+	check(c.Extractor.MaxPDFBytes > 0, "extractor.max_pdf_bytes: must be positive")
+	check(c.Batch.Workers > 0, "batch.workers: must be positive")
+	check(c.Batch.PerHost > 0, "batch.per_host: must be positive")
+	check(c.Batch.MaxURLs > 0, "batch.max_urls: must be positive")
+	check(c.Batch.Timeout > 0, "batch.timeout: must be positive")
+	check(c.Jobs.Workers > 0, "jobs.workers: must be positive")
+	check(c.Jobs.Timeout > 0, "jobs.timeout: must be positive")
+	check(c.Jobs.MaxAttempts > 0, "jobs.max_attempts: must be positive")
+	check(c.Jobs.WebhookTimeout > 0, "jobs.webhook_timeout: must be positive")
+	if _, err := jobs.ParseAllow(c.Jobs.WebhookAllow); err != nil {
+		check(false, "jobs.webhook_allow: %v", err)
+	}
+	switch c.Tracing.Exporter {
+	case "off", "log", "otlp":
+	default:
+		check(false, "tracing.exporter: unknown exporter %q (want off, log or otlp)", c.Tracing.Exporter)
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/auth"
+	"github.com/rookiecj/scrum-agents/backend/internal/usage"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   string // "" for valid
+	}{
+		{name: "defaults", modify: func(*Config) {}},
+		{name: "host and port", modify: func(c *Config) { c.Server.Addr = "0.0.0.0:443" }},
+		{name: "bad address", modify: func(c *Config) { c.Server.Addr = "8080" }, want: "server.addr"},
+		{name: "cert without key", modify: func(c *Config) { c.Server.TLS.CertFile = "cert.pem" }, want: "server.tls"},
+		{name: "negative write timeout", modify: func(c *Config) { c.Server.WriteTimeout = -1 }, want: "server.write_timeout"},
+		{name: "no shutdown timeout", modify: func(c *Config) { c.Server.ShutdownTimeout = 0 }, want: "server.shutdown_timeout"},
+		{name: "no database", modify: func(c *Config) { c.Database.Path = "" }, want: "database.path"},
+		{name: "zero access ttl", modify: func(c *Config) { c.Auth.AccessTTL = 0 }, want: "auth.access_ttl"},
+		{name: "negative timeout", modify: func(c *Config) { c.LLM.OpenAI.Timeout = -1 }, want: "llm.openai.timeout"},
+		{name: "output exceeds window", modify: func(c *Config) { c.LLM.Ollama.MaxTokens = c.LLM.Ollama.ContextWindow }, want: "llm.ollama.max_tokens"},
+		{name: "zero threshold", modify: func(c *Config) { c.Summarizer.ConfidenceThreshold = 0 }, want: "confidence_threshold"},
+		{name: "no batch workers", modify: func(c *Config) { c.Batch.Workers = 0 }, want: "batch.workers"},
+		{name: "no job workers", modify: func(c *Config) { c.Jobs.Workers = 0 }, want: "jobs.workers"},
+		{name: "no job timeout", modify: func(c *Config) { c.Jobs.Timeout = 0 }, want: "jobs.timeout"},
+		{name: "bad webhook allow", modify: func(c *Config) { c.Jobs.WebhookAllow = []string{"intranet"} }, want: "jobs.webhook_allow"},
+		{name: "bad access level", modify: func(c *Config) { c.Access.Routes = map[string]auth.Access{"POST /api/extract": "admin"} }, want: "access.routes"},
+		{name: "anonymous without limits", modify: func(c *Config) { c.Access.Anonymous, c.RateLimit.Disabled = true, true }, want: "access.anonymous"},
+		{name: "negative quota", modify: func(c *Config) { c.RateLimit.DailyQuota.User = -1 }, want: "rate_limit.daily_quota.user"},
+		{name: "zero cache ttl", modify: func(c *Config) { c.Cache.TTL = 0 }, want: "cache.ttl"},
+		{name: "negative price", modify: func(c *Config) { c.Usage.Prices["m"] = usage.Price{Input: -1} }, want: "usage.prices"},
+		{name: "unknown exporter", modify: func(c *Config) { c.Tracing.Exporter = "jaeger" }, want: "tracing.exporter"},
+		{name: "no pdf limit", modify: func(c *Config) { c.Extractor.MaxPDFBytes = 0 }, want: "extractor.max_pdf_bytes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.modify(&cfg)
+			err := cfg.Validate()
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate_ReportsAll(t *testing.T) {
+	cfg := Default()
+	cfg.Database.Path = ""
+	cfg.Summarizer.TemplateDir = ""
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "database.path") || !strings.Contains(err.Error(), "summarizer.template_dir") {
+		t.Errorf("Validate() = %v, want both errors", err)
+	}
+}
@@ -1,6 +1,7 @@
 package extractor
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -22,8 +23,8 @@ func NewArticleExtractor() *ArticleExtractor {
 }
 
 // Extract fetches and extracts the main content from a web article URL.
-func (e *ArticleExtractor) Extract(rawURL string) (*model.ExtractedContent, error) {
-	req, err := http.NewRequest("GET", rawURL, nil)
+func (e *ArticleExtractor) Extract(ctx context.Context, rawURL string) (*model.ExtractedContent, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("creating request: %w", err)
 	}
@@ -45,16 +46,16 @@ func (e *ArticleExtractor) Extract(rawURL string) (*model.ExtractedContent, erro
 	}
 
 	html := string(body)
-	title := extractTitle(html)
-	content := extractMainContent(html)
+	info := model.LinkInfo{
+		URL:      rawURL,
+		LinkType: model.LinkTypeArticle,
+		Title:    extractTitle(html),
+	}
+	extractMetadata(html, rawURL).apply(&info)
 
 	return &model.ExtractedContent{
-		LinkInfo: model.LinkInfo{
-			URL:      rawURL,
-			LinkType: model.LinkTypeArticle,
-			Title:    title,
-		},
-		Content: content,
+		LinkInfo: info,
+		Content:  extractMainContent(html),
 	}, nil
 }
 
@@ -80,63 +81,6 @@ func extractTitle(html string) string {
 	return strings.TrimSpace(html[start : start+end])
 }
 
-// extractMainContent extracts text from the HTML body, removing tags and scripts.
-func extractMainContent(html string) string {
-	// Remove script and style blocks
-	content := removeBlocks(html, "script")
-	content = removeBlocks(content, "style")
-	content = removeBlocks(content, "nav")
-	content = removeBlocks(content, "header")
-	content = removeBlocks(content, "footer")
-
-	// Extract body content
-	bodyStart := strings.Index(strings.ToLower(content), "<body")
-	if bodyStart != -1 {
-		bodyStart = strings.Index(content[bodyStart:], ">")
-		if bodyStart != -1 {
-			absBodyStart := strings.Index(strings.ToLower(content), "<body")
-			bodyStart = absBodyStart + bodyStart + 1
-		}
-		bodyEnd := strings.Index(strings.ToLower(content[bodyStart:]), "</body>")
-		if bodyEnd != -1 {
-			content = content[bodyStart : bodyStart+bodyEnd]
-		} else {
-			content = content[bodyStart:]
-		}
-	}
-
-	// Strip remaining HTML tags
-	content = stripTags(content)
-
-	// Normalize whitespace
-	content = normalizeWhitespace(content)
-
-	return strings.TrimSpace(content)
-}
-
-// removeBlocks removes all occurrences of a block-level tag and its contents.
-func removeBlocks(html, tag string) string {
-	result := html
-	lowerResult := strings.ToLower(result)
-	openTag := "<" + tag
-	closeTag := "</" + tag + ">"
-
-	for {
-		start := strings.Index(lowerResult, openTag)
-		if start == -1 {
-			break
-		}
-		end := strings.Index(lowerResult[start:], closeTag)
-		if end == -1 {
-			break
-		}
-		end = start + end + len(closeTag)
-		result = result[:start] + result[end:]
-		lowerResult = strings.ToLower(result)
-	}
-	return result
-}
-
 // stripTags removes all HTML tags from a string.
 func stripTags(html string) string {
 	var result strings.Builder
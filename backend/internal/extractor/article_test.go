@@ -1,6 +1,7 @@
 package extractor
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -58,7 +59,7 @@ func TestArticleExtractor_Extract(t *testing.T) {
 			defer server.Close()
 
 			ext := &ArticleExtractor{Client: server.Client()}
-			result, err := ext.Extract(server.URL)
+			result, err := ext.Extract(context.Background(), server.URL)
 
 			if tt.wantErr {
 				if err == nil {
@@ -83,6 +84,21 @@ func TestArticleExtractor_Extract(t *testing.T) {
 	}
 }
 
+func TestArticleExtractor_Extract_Canceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><head><title>Late</title></head><body><p>Too late.</p></body></html>`))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ext := &ArticleExtractor{Client: server.Client()}
+	if _, err := ext.Extract(ctx, server.URL); err == nil {
+		t.Error("expected error for canceled context, got nil")
+	}
+}
+
 func TestExtractTitle(t *testing.T) {
 	tests := []struct {
 		name string
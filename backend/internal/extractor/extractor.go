@@ -1,10 +1,78 @@
 package extractor
 
 import (
+	"context"
+	"net/http"
+	"time"
+
 	"github.com/rookiecj/scrum-agents/backend/internal/model"
 )
 
 // Extractor defines the interface for content extractors.
 type Extractor interface {
-	Extract(url string) (*model.ExtractedContent, error)
+	Extract(ctx context.Context, url string) (*model.ExtractedContent, error)
+}
+
+// Registry maps link types to their extractors, with a generic HTML
+// extractor used for types that have no dedicated extractor.
+type Registry struct {
+	extractors map[model.LinkType]Extractor
+	fallback   Extractor
+}
+
+// NewRegistry creates a Registry with the default extractor for every
+// supported link type.
+func NewRegistry() *Registry {
+	return NewRegistryWithLimits(Limits{})
+}
+
+// Limits bounds the work extractors do for one URL. Zero fields mean the
+// defaults.
+type Limits struct {
+	// Timeout bounds each fetch, including reading the body. Zero leaves
+	// it to the request's context.
+	Timeout time.Duration
+	// MaxPDFSize is the largest PDF downloaded, in bytes. Defaults to
+	// DefaultMaxPDFSize.
+	MaxPDFSize int64
+}
+
+// NewRegistryWithLimits creates a Registry like NewRegistry whose extractors
+// observe l.
+func NewRegistryWithLimits(l Limits) *Registry {
+	client := func() *http.Client { return &http.Client{Timeout: l.Timeout} }
+	article := func() Extractor { return &ArticleExtractor{Client: client()} }
+	return &Registry{
+		extractors: map[model.LinkType]Extractor{
+			model.LinkTypeArticle:    article(),
+			model.LinkTypeYouTube:    &YouTubeExtractor{Client: client()},
+			model.LinkTypePDF:        &PDFExtractor{Client: client(), MaxSize: l.MaxPDFSize},
+			model.LinkTypeTwitter:    &TwitterExtractor{Client: client()},
+			model.LinkTypeNewsletter: &NewsletterExtractor{Client: client()},
+		},
+		fallback: article(),
+	}
+}
+
+// For returns the extractor for the given link type. The second return
+// value is false when the generic fallback extractor is returned instead.
+func (r *Registry) For(linkType model.LinkType) (Extractor, bool) {
+	if ext, ok := r.extractors[linkType]; ok {
+		return ext, true
+	}
+	return r.fallback, false
+}
+
+// Wrap returns a copy of the registry with every extractor, including the
+// fallback, replaced by wrap(extractor). It is used to add cross-cutting
+// behaviour such as caching.
+func (r *Registry) Wrap(wrap func(Extractor) Extractor) *Registry {
+	out := &Registry{
+		extractors: make(map[model.LinkType]Extractor, len(r.extractors)),
+		fallback:   wrap(r.fallback),
+	}
+	for lt, ext := range r.extractors {
+		out.extractors[lt] = wrap(ext)
+	}
+	return out
 }
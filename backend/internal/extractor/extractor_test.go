@@ -0,0 +1,70 @@
+package extractor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+)
+
+func TestRegistry_For(t *testing.T) {
+	r := NewRegistry()
+
+	tests := []struct {
+		linkType model.LinkType
+		want     any
+		wantOK   bool
+	}{
+		{model.LinkTypeArticle, &ArticleExtractor{}, true},
+		{model.LinkTypeYouTube, &YouTubeExtractor{}, true},
+		{model.LinkTypePDF, &PDFExtractor{}, true},
+		{model.LinkTypeTwitter, &TwitterExtractor{}, true},
+		{model.LinkTypeNewsletter, &NewsletterExtractor{}, true},
+		{model.LinkTypeUnknown, &ArticleExtractor{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.linkType), func(t *testing.T) {
+			ext, ok := r.For(tt.linkType)
+			if ok != tt.wantOK {
+				t.Errorf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ext == nil {
+				t.Fatal("expected non-nil extractor")
+			}
+			if got, want := typeName(ext), typeName(tt.want); got != want {
+				t.Errorf("extractor = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func typeName(v any) string {
+	switch v.(type) {
+	case *ArticleExtractor:
+		return "article"
+	case *YouTubeExtractor:
+		return "youtube"
+	case *PDFExtractor:
+		return "pdf"
+	case *TwitterExtractor:
+		return "twitter"
+	case *NewsletterExtractor:
+		return "newsletter"
+	}
+	return "unknown"
+}
+
+func TestNewRegistryWithLimits(t *testing.T) {
+	r := NewRegistryWithLimits(Limits{Timeout: 5 * time.Second, MaxPDFSize: 1 << 20})
+
+	ext, _ := r.For(model.LinkTypePDF)
+	pdf, ok := ext.(*PDFExtractor)
+	if !ok || pdf.MaxSize != 1<<20 || pdf.Client.Timeout != 5*time.Second {
+		t.Errorf("pdf extractor = %+v, want limits applied", ext)
+	}
+	ext, _ = r.For(model.LinkTypeUnknown)
+	if article, ok := ext.(*ArticleExtractor); !ok || article.Client.Timeout != 5*time.Second {
+		t.Errorf("fallback extractor = %+v, want timeout applied", ext)
+	}
+}
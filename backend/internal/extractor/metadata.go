@@ -0,0 +1,342 @@
+package extractor
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+)
+
+// pageMeta is the metadata a page declares about itself in <meta> and
+// <link> tags and schema.org JSON-LD.
+type pageMeta struct {
+	title       string
+	author      string
+	published   string
+	modified    string
+	siteName    string
+	canonical   string
+	language    string
+	image       string
+	description string
+}
+
+// articleTypes are the schema.org types whose JSON-LD describes the page's
+// content rather than, say, its breadcrumbs or publisher.
+var articleTypes = map[string]bool{
+	"Article": true, "NewsArticle": true, "BlogPosting": true, "TechArticle": true,
+	"ScholarlyArticle": true, "Report": true, "SocialMediaPosting": true,
+	"DiscussionForumPosting": true, "AnalysisNewsArticle": true, "OpinionNewsArticle": true,
+	"ReportageNewsArticle": true, "ReviewNewsArticle": true, "LiveBlogPosting": true,
+	"WebPage": true, "ItemPage": true, "VideoObject": true,
+}
+
+// extractMetadata reads the metadata of the HTML page at pageURL. Relative
+// canonical and image URLs are resolved against pageURL. Each field is
+// taken from the first source that has it: JSON-LD, then OpenGraph, then
+// Twitter cards, then other <meta> tags.
+func extractMetadata(src, pageURL string) pageMeta {
+	doc, err := html.Parse(strings.NewReader(src))
+	if err != nil {
+		return pageMeta{}
+	}
+
+	metas := map[string]string{}
+	var canonical, imageSrc, lang, title string
+	var ld ldObject
+	for n := range doc.Descendants() {
+		if n.Type != html.ElementNode {
+			continue
+		}
+		switch n.DataAtom {
+		case atom.Html:
+			lang = attr(n, "lang")
+		case atom.Title:
+			if title == "" {
+				title = strings.TrimSpace(innerText(n))
+			}
+		case atom.Meta:
+			content := strings.TrimSpace(attr(n, "content"))
+			if content == "" {
+				continue
+			}
+			for _, key := range []string{"property", "name", "itemprop", "http-equiv"} {
+				if k := strings.ToLower(strings.TrimSpace(attr(n, key))); k != "" {
+					if _, ok := metas[k]; !ok {
+						metas[k] = content
+					}
+				}
+			}
+		case atom.Link:
+			for _, rel := range strings.Fields(strings.ToLower(attr(n, "rel"))) {
+				switch {
+				case rel == "canonical" && canonical == "":
+					canonical = attr(n, "href")
+				case rel == "image_src" && imageSrc == "":
+					imageSrc = attr(n, "href")
+				}
+			}
+		case atom.Script:
+			if ld == nil && strings.EqualFold(strings.TrimSpace(attr(n, "type")), "application/ld+json") {
+				ld = findArticle(innerJSON(n))
+			}
+		}
+	}
+
+	return pageMeta{
+		title: firstOf(ld.text("headline"), metas["og:title"], metas["twitter:title"], ld.text("name"), title),
+		author: firstOf(ld.names("author"), ld.names("creator"), metas["author"], nonURL(metas["article:author"]),
+			metas["parsely-author"], metas["sailthru.author"], metas["dc.creator"], metas["citation_author"],
+			strings.TrimPrefix(metas["byl"], "By "), metas["twitter:creator"]),
+		published: normalizeDate(firstOf(ld.text("datePublished"), ld.text("uploadDate"), metas["article:published_time"],
+			metas["datepublished"], metas["pubdate"], metas["publish-date"], metas["date"], metas["dc.date"],
+			metas["citation_publication_date"])),
+		modified: normalizeDate(firstOf(ld.text("dateModified"), metas["article:modified_time"],
+			metas["og:updated_time"], metas["datemodified"], metas["last-modified"])),
+		siteName:    firstOf(metas["og:site_name"], ld.object("publisher").text("name"), metas["application-name"]),
+		canonical:   resolveURL(pageURL, firstOf(canonical, metas["og:url"], ld.id("mainEntityOfPage"), ld.text("url"))),
+		language:    normalizeLanguage(firstOf(ld.text("inLanguage"), lang, metas["content-language"], metas["og:locale"])),
+		image:       resolveURL(pageURL, firstOf(metas["og:image"], metas["og:image:url"], metas["twitter:image"], metas["twitter:image:src"], ld.image(), imageSrc)),
+		description: firstOf(metas["og:description"], metas["twitter:description"], metas["description"], ld.text("description")),
+	}
+}
+
+// apply fills the fields of info that the page declares. Title is replaced
+// because page titles often carry a site suffix that og:title and JSON-LD
+// headlines lack; the other fields are only set when empty.
+func (m pageMeta) apply(info *model.LinkInfo) {
+	if m.title != "" {
+		info.Title = m.title
+	}
+	set := func(dst *string, v string) {
+		if *dst == "" {
+			*dst = v
+		}
+	}
+	set(&info.Author, m.author)
+	set(&info.Date, m.published)
+	set(&info.Modified, m.modified)
+	set(&info.SiteName, m.siteName)
+	set(&info.CanonicalURL, m.canonical)
+	set(&info.Language, m.language)
+	set(&info.Image, m.image)
+	set(&info.Description, m.description)
+}
+
+// ldObject is a decoded JSON-LD object. A nil ldObject has no fields.
+type ldObject map[string]any
+
+// innerJSON returns the raw text of a <script> element.
+func innerJSON(n *html.Node) string {
+	var sb strings.Builder
+	for c := range n.ChildNodes() {
+		if c.Type == html.TextNode {
+			sb.WriteString(c.Data)
+		}
+	}
+	return sb.String()
+}
+
+// findArticle returns the first object in a JSON-LD document whose @type is
+// one of articleTypes, looking inside arrays and @graph.
+func findArticle(src string) ldObject {
+	var v any
+	if err := json.Unmarshal([]byte(strings.TrimSpace(src)), &v); err != nil {
+		return nil
+	}
+	var walk func(v any) ldObject
+	walk = func(v any) ldObject {
+		switch v := v.(type) {
+		case []any:
+			for _, e := range v {
+				if o := walk(e); o != nil {
+					return o
+				}
+			}
+		case map[string]any:
+			o := ldObject(v)
+			for _, t := range o.list("@type") {
+				if s, ok := t.(string); ok && articleTypes[s] {
+					return o
+				}
+			}
+			if g, ok := o["@graph"]; ok {
+				return walk(g)
+			}
+		}
+		return nil
+	}
+	return walk(v)
+}
+
+// list returns the value of key as a list; single values are wrapped.
+func (o ldObject) list(key string) []any {
+	switch v := o[key].(type) {
+	case nil:
+		return nil
+	case []any:
+		return v
+	default:
+		return []any{v}
+	}
+}
+
+// text returns the value of key if it is a string.
+func (o ldObject) text(key string) string {
+	s, _ := o[key].(string)
+	return strings.TrimSpace(s)
+}
+
+// object returns the value of key if it is an object, or the first object
+// of a list.
+func (o ldObject) object(key string) ldObject {
+	for _, v := range o.list(key) {
+		if m, ok := v.(map[string]any); ok {
+			return m
+		}
+	}
+	return nil
+}
+
+// names returns the names of the people or organizations under key, which
+// may be strings, objects with a name, or a list of either, joined by
+// commas.
+func (o ldObject) names(key string) string {
+	var names []string
+	for _, v := range o.list(key) {
+		var name string
+		switch v := v.(type) {
+		case string:
+			name = nonURL(strings.TrimSpace(v))
+		case map[string]any:
+			name = ldObject(v).text("name")
+		}
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// id returns the value of key if it is a URL string, or the @id or url of
+// an object.
+func (o ldObject) id(key string) string {
+	if s := o.text(key); s != "" {
+		return s
+	}
+	obj := o.object(key)
+	return firstOf(obj.text("@id"), obj.text("url"))
+}
+
+// image returns the URL of the first image, which may be a URL string or an
+// ImageObject.
+func (o ldObject) image() string {
+	for _, v := range o.list("image") {
+		switch v := v.(type) {
+		case string:
+			return strings.TrimSpace(v)
+		case map[string]any:
+			if u := ldObject(v).text("url"); u != "" {
+				return u
+			}
+		}
+	}
+	return ""
+}
+
+func firstOf(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// nonURL returns s unless it is a URL. Facebook's article:author and some
+// JSON-LD authors are profile URLs rather than names.
+func nonURL(s string) string {
+	if strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") {
+		return ""
+	}
+	return s
+}
+
+// resolveURL resolves ref against base, leaving it unchanged if either does
+// not parse. Only http and https results are kept.
+func resolveURL(base, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if b, err := url.Parse(base); err == nil {
+		r = b.ResolveReference(r)
+	}
+	if r.Scheme != "http" && r.Scheme != "https" {
+		return ""
+	}
+	return r.String()
+}
+
+// dateLayouts are the date formats found in page metadata, most common
+// first. Layouts without a time of day yield dates.
+var dateLayouts = []struct {
+	layout  string
+	hasTime bool
+}{
+	{time.RFC3339, true},
+	{"2006-01-02T15:04:05Z0700", true},
+	{"2006-01-02T15:04Z07:00", true},
+	{"2006-01-02T15:04:05", true},
+	{"2006-01-02 15:04:05", true},
+	{"2006-01-02T15:04", true},
+	{time.RFC1123Z, true},
+	{time.RFC1123, true},
+	{"2006-01-02", false},
+	{"2006/01/02", false},
+	{"2006.01.02", false},
+	{"20060102", false},
+	{"January 2, 2006", false},
+	{"Jan 2, 2006", false},
+}
+
+// normalizeDate rewrites a date as RFC 3339, or as YYYY-MM-DD if it has no
+// time of day. Dates in other formats are returned unchanged.
+func normalizeDate(s string) string {
+	s = strings.TrimSpace(s)
+	for _, l := range dateLayouts {
+		t, err := time.Parse(l.layout, s)
+		if err != nil {
+			continue
+		}
+		if !l.hasTime {
+			return t.Format("2006-01-02")
+		}
+		return t.Format(time.RFC3339)
+	}
+	return s
+}
+
+// normalizeLanguage rewrites a language tag such as "ko_KR" or "EN-us" in
+// BCP 47 form ("ko-KR", "en-US").
+func normalizeLanguage(s string) string {
+	parts := strings.FieldsFunc(s, func(r rune) bool { return r == '-' || r == '_' })
+	if len(parts) == 0 {
+		return ""
+	}
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		if len(parts[i]) == 2 {
+			parts[i] = strings.ToUpper(parts[i])
+		}
+	}
+	return strings.Join(parts, "-")
+}
@@ -0,0 +1,198 @@
+package extractor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+)
+
+func TestExtractMetadata(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want pageMeta
+	}{
+		{
+			name: "opengraph and meta tags",
+			html: `<html lang="en"><head>
+				<title>Why Postgres | Backend Weekly</title>
+				<meta property="og:title" content="Why Postgres">
+				<meta property="og:site_name" content="Backend Weekly">
+				<meta property="og:description" content="The case for boring databases &amp; tools.">
+				<meta property="og:image" content="/img/lead.png">
+				<meta property="og:url" content="https://example.com/p/why-postgres">
+				<meta property="article:published_time" content="2024-02-14T09:30:00+09:00">
+				<meta property="article:modified_time" content="2024-02-15">
+				<meta property="article:author" content="https://facebook.com/marco">
+				<meta name="author" content="Marco Bellini">
+			</head><body></body></html>`,
+			want: pageMeta{
+				title:       "Why Postgres",
+				author:      "Marco Bellini",
+				published:   "2024-02-14T09:30:00+09:00",
+				modified:    "2024-02-15",
+				siteName:    "Backend Weekly",
+				canonical:   "https://example.com/p/why-postgres",
+				language:    "en",
+				image:       "https://example.com/img/lead.png",
+				description: "The case for boring databases & tools.",
+			},
+		},
+		{
+			name: "json-ld takes precedence",
+			html: `<html><head>
+				<title>Page title</title>
+				<meta property="og:title" content="OG title">
+				<meta name="author" content="Meta Author">
+				<link rel="canonical" href="/posts/42">
+				<script type="application/ld+json">{"@context":"https://schema.org","@graph":[
+					{"@type":"BreadcrumbList","name":"Home"},
+					{"@type":["BlogPosting"],"headline":"LD headline",
+					 "author":[{"@type":"Person","name":"Kim"},{"@type":"Person","name":"Lee"}],
+					 "datePublished":"2024-06-03 10:21:00","dateModified":"2024-06-04T08:00:00Z",
+					 "publisher":{"@type":"Organization","name":"Tech Daily"},
+					 "inLanguage":"ko_KR","image":{"@type":"ImageObject","url":"https://cdn.example.com/a.jpg"}}
+				]}</script>
+			</head><body></body></html>`,
+			want: pageMeta{
+				title:     "LD headline",
+				author:    "Kim, Lee",
+				published: "2024-06-03T10:21:00Z",
+				modified:  "2024-06-04T08:00:00Z",
+				siteName:  "Tech Daily",
+				canonical: "https://example.com/posts/42",
+				language:  "ko-KR",
+				image:     "https://cdn.example.com/a.jpg",
+			},
+		},
+		{
+			name: "twitter card",
+			html: `<html><head>
+				<meta name="twitter:title" content="A tweet">
+				<meta name="twitter:creator" content="@user">
+				<meta name="twitter:image" content="https://pbs.example.com/1.jpg">
+				<meta name="twitter:description" content="Hello world">
+				<meta property="og:locale" content="en_US">
+			</head></html>`,
+			want: pageMeta{
+				title:       "A tweet",
+				author:      "@user",
+				language:    "en-US",
+				image:       "https://pbs.example.com/1.jpg",
+				description: "Hello world",
+			},
+		},
+		{
+			name: "invalid json-ld is ignored",
+			html: `<html><head><title>T</title>
+				<script type="application/ld+json">{"@type": "Article", </script>
+				<meta name="date" content="2023.11.05">
+				<meta name="byl" content="By Jane Doe">
+			</head></html>`,
+			want: pageMeta{
+				title:     "T",
+				author:    "Jane Doe",
+				published: "2023-11-05",
+			},
+		},
+		{
+			name: "non-http image is dropped",
+			html: `<html><head><meta property="og:image" content="javascript:alert(1)"></head></html>`,
+			want: pageMeta{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := extractMetadata(tt.html, "https://example.com/p/why-postgres?utm_source=x")
+			if got != tt.want {
+				t.Errorf("extractMetadata() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPageMeta_Apply(t *testing.T) {
+	info := model.LinkInfo{
+		URL:    "https://example.com",
+		Title:  "Page | Site",
+		Author: "@creator",
+	}
+	pageMeta{title: "Page", author: "Someone", siteName: "Site", published: "2024-01-02"}.apply(&info)
+
+	want := model.LinkInfo{
+		URL:      "https://example.com",
+		Title:    "Page",
+		Author:   "@creator",
+		Date:     "2024-01-02",
+		SiteName: "Site",
+	}
+	if info != want {
+		t.Errorf("apply() = %+v, want %+v", info, want)
+	}
+}
+
+func TestArticleExtractor_Extract_Metadata(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html lang="ko"><head>
+			<title>고루틴 정리 :: 개발 블로그</title>
+			<meta property="og:title" content="고루틴 정리">
+			<meta property="og:site_name" content="개발 블로그">
+			<meta property="og:image" content="/thumb.png">
+			<meta property="article:published_time" content="2024-03-01T12:00:00+09:00">
+			<meta name="author" content="홍길동">
+		</head><body><article><p>본문입니다.</p></article></body></html>`))
+	}))
+	defer srv.Close()
+
+	got, err := NewArticleExtractor().Extract(context.Background(), srv.URL+"/entry/1")
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	info := got.LinkInfo
+	if info.Title != "고루틴 정리" || info.Author != "홍길동" || info.SiteName != "개발 블로그" ||
+		info.Date != "2024-03-01T12:00:00+09:00" || info.Language != "ko" || info.Image != srv.URL+"/thumb.png" {
+		t.Errorf("LinkInfo = %+v", info)
+	}
+}
+
+func TestNormalizeDate(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"2024-02-14T09:30:00+09:00", "2024-02-14T09:30:00+09:00"},
+		{"2024-02-14T09:30:00+0900", "2024-02-14T09:30:00+09:00"},
+		{"2024-02-14T09:30:00", "2024-02-14T09:30:00Z"},
+		{"2024-02-14", "2024-02-14"},
+		{"2024.02.14", "2024-02-14"},
+		{"February 14, 2024", "2024-02-14"},
+		{"Wed, 14 Feb 2024 09:30:00 GMT", "2024-02-14T09:30:00Z"},
+		{"2주 전", "2주 전"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeDate(tt.in); got != tt.want {
+			t.Errorf("normalizeDate(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeLanguage(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"ko", "ko"},
+		{"ko_KR", "ko-KR"},
+		{"EN-us", "en-US"},
+		{"zh-Hant-TW", "zh-Hant-TW"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeLanguage(tt.in); got != tt.want {
+			t.Errorf("normalizeLanguage(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
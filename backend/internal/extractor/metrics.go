@@ -0,0 +1,57 @@
+package extractor
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/metrics"
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+	"github.com/rookiecj/scrum-agents/backend/internal/tracing"
+)
+
+var (
+	extractionsTotal = metrics.NewCounter("extractions_total",
+		"Content extractions, by link type and outcome.",
+		"link_type", "outcome")
+	extractionDuration = metrics.NewHistogram("extraction_duration_seconds",
+		"Time taken by content extractions, by link type.",
+		metrics.DefBuckets, "link_type")
+)
+
+// Instrument returns a copy of the registry whose extractors count, time and
+// trace their extractions by link type. The fallback extractor is reported
+// as model.LinkTypeUnknown.
+func (r *Registry) Instrument() *Registry {
+	out := &Registry{
+		extractors: make(map[model.LinkType]Extractor, len(r.extractors)),
+		fallback:   &instrumented{next: r.fallback, linkType: model.LinkTypeUnknown},
+	}
+	for lt, ext := range r.extractors {
+		out.extractors[lt] = &instrumented{next: ext, linkType: lt}
+	}
+	return out
+}
+
+type instrumented struct {
+	next     Extractor
+	linkType model.LinkType
+}
+
+func (e *instrumented) Extract(ctx context.Context, url string) (*model.ExtractedContent, error) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "extract",
+		slog.String("link_type", string(e.linkType)),
+		slog.String("url", url),
+	)
+	defer span.End()
+	content, err := e.next.Extract(ctx, url)
+	span.RecordError(err)
+	outcome := "success"
+	if err != nil {
+		outcome = "failure"
+	}
+	extractionsTotal.Inc(string(e.linkType), outcome)
+	extractionDuration.Observe(time.Since(start).Seconds(), string(e.linkType))
+	return content, err
+}
@@ -0,0 +1,54 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+)
+
+type stubExtractor struct{ err error }
+
+func (s stubExtractor) Extract(context.Context, string) (*model.ExtractedContent, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &model.ExtractedContent{}, nil
+}
+
+func TestRegistry_Instrument(t *testing.T) {
+	r := (&Registry{
+		extractors: map[model.LinkType]Extractor{
+			model.LinkTypePDF:     stubExtractor{},
+			model.LinkTypeTwitter: stubExtractor{err: errors.New("blocked")},
+		},
+		fallback: stubExtractor{},
+	}).Instrument()
+
+	before := func(lt model.LinkType, outcome string) func() float64 {
+		n := extractionsTotal.Value(string(lt), outcome)
+		return func() float64 { return extractionsTotal.Value(string(lt), outcome) - n }
+	}
+	pdf := before(model.LinkTypePDF, "success")
+	twitter := before(model.LinkTypeTwitter, "failure")
+	unknown := before(model.LinkTypeUnknown, "success")
+
+	for _, lt := range []model.LinkType{model.LinkTypePDF, model.LinkTypePDF, model.LinkTypeTwitter, model.LinkTypeUnknown} {
+		ext, _ := r.For(lt)
+		ext.Extract(context.Background(), "https://example.com")
+	}
+
+	if got := pdf(); got != 2 {
+		t.Errorf("pdf successes = %v, want 2", got)
+	}
+	if got := twitter(); got != 1 {
+		t.Errorf("twitter failures = %v, want 1", got)
+	}
+	if got := unknown(); got != 1 {
+		t.Errorf("fallback successes = %v, want 1", got)
+	}
+	if _, ok := r.For(model.LinkTypePDF); !ok {
+		t.Error("instrumented registry lost its pdf extractor")
+	}
+}
@@ -1,6 +1,7 @@
 package extractor
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -22,8 +23,8 @@ func NewNewsletterExtractor() *NewsletterExtractor {
 }
 
 // Extract fetches a newsletter page and extracts the article body.
-func (e *NewsletterExtractor) Extract(rawURL string) (*model.ExtractedContent, error) {
-	req, err := http.NewRequest("GET", rawURL, nil)
+func (e *NewsletterExtractor) Extract(ctx context.Context, rawURL string) (*model.ExtractedContent, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("creating request: %w", err)
 	}
@@ -45,8 +46,6 @@ func (e *NewsletterExtractor) Extract(rawURL string) (*model.ExtractedContent, e
 	}
 
 	html := string(body)
-	title := extractTitle(html)
-	author := extractNewsletterAuthor(html)
 	content := extractNewsletterContent(html)
 
 	partialExtraction := isPaywalled(html)
@@ -64,14 +63,16 @@ func (e *NewsletterExtractor) Extract(rawURL string) (*model.ExtractedContent, e
 		content = content + "\n\n---\n[Note: This content may be partially extracted due to paywall restrictions]"
 	}
 
+	info := model.LinkInfo{
+		URL:      rawURL,
+		LinkType: model.LinkTypeNewsletter,
+		Title:    extractTitle(html),
+	}
+	extractMetadata(html, rawURL).apply(&info)
+
 	return &model.ExtractedContent{
-		LinkInfo: model.LinkInfo{
-			URL:      rawURL,
-			LinkType: model.LinkTypeNewsletter,
-			Title:    title,
-			Author:   author,
-		},
-		Content: content,
+		LinkInfo: info,
+		Content:  content,
 	}, nil
 }
 
@@ -131,19 +132,6 @@ func extractBlock(html, openPattern, closeTag string) string {
 	return html[contentStart : contentStart+end]
 }
 
-// extractNewsletterAuthor extracts the author name from newsletter meta tags.
-func extractNewsletterAuthor(html string) string {
-	author := extractOGMeta(html, "author")
-	if author != "" {
-		return author
-	}
-	author = extractOGMeta(html, "article:author")
-	if author != "" {
-		return author
-	}
-	return extractOGMeta(html, "og:site_name")
-}
-
 // isPaywalled checks if the newsletter content appears to be behind a paywall.
 func isPaywalled(html string) bool {
 	lower := strings.ToLower(html)
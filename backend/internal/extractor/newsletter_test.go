@@ -1,6 +1,7 @@
 package extractor
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -87,7 +88,7 @@ func TestNewsletterExtractor_Extract(t *testing.T) {
 			defer server.Close()
 
 			ext := &NewsletterExtractor{Client: server.Client()}
-			result, err := ext.Extract(server.URL + "/newsletter/post")
+			result, err := ext.Extract(context.Background(), server.URL+"/newsletter/post")
 
 			if tt.wantErr {
 				if err == nil {
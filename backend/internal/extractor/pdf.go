@@ -1,6 +1,8 @@
 package extractor
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,11 +12,15 @@ import (
 	"github.com/rookiecj/scrum-agents/backend/internal/model"
 )
 
-const maxPDFSize = 10 * 1024 * 1024 // 10MB
+// DefaultMaxPDFSize is the largest PDF downloaded by default.
+const DefaultMaxPDFSize = 10 * 1024 * 1024 // 10MB
 
 // PDFExtractor extracts text content from PDF URLs.
 type PDFExtractor struct {
 	Client *http.Client
+	// MaxSize is the largest PDF downloaded, in bytes. Zero means
+	// DefaultMaxPDFSize.
+	MaxSize int64
 }
 
 // NewPDFExtractor creates a new PDFExtractor.
@@ -25,8 +31,8 @@ func NewPDFExtractor() *PDFExtractor {
 }
 
 // Extract downloads a PDF and extracts text content.
-func (e *PDFExtractor) Extract(rawURL string) (*model.ExtractedContent, error) {
-	req, err := http.NewRequest("GET", rawURL, nil)
+func (e *PDFExtractor) Extract(ctx context.Context, rawURL string) (*model.ExtractedContent, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("creating request: %w", err)
 	}
@@ -42,27 +48,39 @@ func (e *PDFExtractor) Extract(rawURL string) (*model.ExtractedContent, error) {
 		return nil, fmt.Errorf("unexpected status %d for PDF %s", resp.StatusCode, rawURL)
 	}
 
+	maxSize := e.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxPDFSize
+	}
+
 	// Check Content-Length if available
-	if resp.ContentLength > maxPDFSize {
-		return nil, fmt.Errorf("PDF exceeds maximum size of 10MB (size: %d bytes)", resp.ContentLength)
+	if resp.ContentLength > maxSize {
+		return nil, fmt.Errorf("PDF exceeds maximum size of %s (size: %d bytes)", formatSize(maxSize), resp.ContentLength)
 	}
 
 	// Read with a limit to enforce size restriction
-	limited := io.LimitReader(resp.Body, maxPDFSize+1)
+	limited := io.LimitReader(resp.Body, maxSize+1)
 	data, err := io.ReadAll(limited)
 	if err != nil {
 		return nil, fmt.Errorf("reading PDF body: %w", err)
 	}
 
-	if len(data) > maxPDFSize {
-		return nil, fmt.Errorf("PDF exceeds maximum size of 10MB (size: >%d bytes)", maxPDFSize)
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("PDF exceeds maximum size of %s (size: >%d bytes)", formatSize(maxSize), maxSize)
 	}
 
-	title := extractPDFTitle(data)
-	text := extractPDFText(data)
+	title, text, err := readPDF(data)
+	if errors.Is(err, errPDFEncrypted) {
+		return nil, fmt.Errorf("could not extract text from PDF: %w", err)
+	}
+	if err != nil {
+		// Not a PDF we can parse; look for uncompressed text in the raw bytes.
+		title = extractPDFTitle(data)
+		text = extractPDFText(data)
+	}
 
 	if text == "" {
-		return nil, fmt.Errorf("could not extract text from PDF (possibly image-based or encrypted)")
+		return nil, fmt.Errorf("could not extract text from PDF (possibly image-based)")
 	}
 
 	return &model.ExtractedContent{
@@ -75,6 +93,21 @@ func (e *PDFExtractor) Extract(rawURL string) (*model.ExtractedContent, error) {
 	}, nil
 }
 
+// readPDF parses data and returns the document title and the text of its
+// pages. Malformed input is reported as an error rather than a panic.
+func readPDF(data []byte) (title, text string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("parsing PDF: %v", r)
+		}
+	}()
+	doc, err := openPDF(data)
+	if err != nil {
+		return "", "", err
+	}
+	return strings.TrimSpace(doc.title()), doc.text(), nil
+}
+
 // extractPDFTitle attempts to extract the title from PDF metadata.
 func extractPDFTitle(data []byte) string {
 	s := string(data)
@@ -87,8 +120,8 @@ func extractPDFTitle(data []byte) string {
 	return ""
 }
 
-// extractPDFText extracts text from PDF content streams.
-// This is a basic extractor that handles simple text PDFs.
+// extractPDFText extracts text from uncompressed PDF content streams by
+// scanning the raw bytes. It is the fallback for files readPDF rejects.
 func extractPDFText(data []byte) string {
 	s := string(data)
 	var texts []string
@@ -151,3 +184,12 @@ func decodePDFString(s string) string {
 	s = strings.ReplaceAll(s, "\\\\", "\\")
 	return s
 }
+
+// formatSize formats a byte count in whole megabytes when it is one, as in
+// "10MB", and in bytes otherwise.
+func formatSize(n int64) string {
+	if n%(1<<20) == 0 {
+		return fmt.Sprintf("%dMB", n>>20)
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
@@ -1,6 +1,7 @@
 package extractor
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -11,13 +12,16 @@ import (
 func TestPDFExtractor_Extract(t *testing.T) {
 	// Create a minimal PDF with extractable text
 	simplePDF := buildSimplePDF("Hello World from PDF")
+	encrypted := simpleDoc()
+	encrypted.add("<</Filter /Standard /V 2 /R 3>>")
 
 	tests := []struct {
-		name       string
-		handler    http.HandlerFunc
-		wantErr    bool
-		errContain string
-		wantTitle  string
+		name        string
+		handler     http.HandlerFunc
+		wantErr     bool
+		errContain  string
+		wantTitle   string
+		wantContent string
 	}{
 		{
 			name: "basic PDF with text",
@@ -25,12 +29,32 @@ func TestPDFExtractor_Extract(t *testing.T) {
 				w.Header().Set("Content-Type", "application/pdf")
 				w.Write(simplePDF)
 			},
+			wantTitle:   "Test PDF Document",
+			wantContent: "Hello World from PDF",
+		},
+		{
+			name: "compressed PDF with xref stream",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/pdf")
+				w.Write(simpleDoc().compressedFile("/Root 1 0 R /Info 6 0 R"))
+			},
+			wantTitle:   "Déjà",
+			wantContent: "Compressed text",
+		},
+		{
+			name: "encrypted PDF",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/pdf")
+				w.Write(encrypted.file("/Root 1 0 R /Encrypt 7 0 R"))
+			},
+			wantErr:    true,
+			errContain: "encrypted",
 		},
 		{
 			name: "PDF too large",
 			handler: func(w http.ResponseWriter, r *http.Request) {
 				w.Header().Set("Content-Type", "application/pdf")
-				w.Header().Set("Content-Length", fmt.Sprintf("%d", maxPDFSize+1))
+				w.Header().Set("Content-Length", fmt.Sprintf("%d", DefaultMaxPDFSize+1))
 				w.Write([]byte("%PDF-1.4"))
 			},
 			wantErr:    true,
@@ -61,7 +85,7 @@ func TestPDFExtractor_Extract(t *testing.T) {
 			defer server.Close()
 
 			ext := &PDFExtractor{Client: server.Client()}
-			result, err := ext.Extract(server.URL + "/test.pdf")
+			result, err := ext.Extract(context.Background(), server.URL+"/test.pdf")
 
 			if tt.wantErr {
 				if err == nil {
@@ -81,6 +105,12 @@ func TestPDFExtractor_Extract(t *testing.T) {
 			if result.LinkInfo.LinkType != "pdf" {
 				t.Errorf("LinkType = %q, want %q", result.LinkInfo.LinkType, "pdf")
 			}
+			if tt.wantTitle != "" && result.LinkInfo.Title != tt.wantTitle {
+				t.Errorf("Title = %q, want %q", result.LinkInfo.Title, tt.wantTitle)
+			}
+			if tt.wantContent != "" && result.Content != tt.wantContent {
+				t.Errorf("Content = %q, want %q", result.Content, tt.wantContent)
+			}
 		})
 	}
 }
@@ -193,3 +223,18 @@ startxref
 0
 %%%%EOF`, text))
 }
+
+func TestPDFExtractor_MaxSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Flushing first sends the body chunked, without a Content-Length.
+		w.(http.Flusher).Flush()
+		w.Write([]byte("%PDF-1.4 " + strings.Repeat("x", 100)))
+	}))
+	defer server.Close()
+
+	ext := &PDFExtractor{Client: server.Client(), MaxSize: 64}
+	_, err := ext.Extract(context.Background(), server.URL+"/big.pdf")
+	if err == nil || !strings.Contains(err.Error(), "maximum size of 64 bytes") {
+		t.Errorf("error = %v, want maximum size error", err)
+	}
+}
@@ -0,0 +1,604 @@
+package extractor
+
+import (
+	"strconv"
+	"strings"
+)
+
+// pdfFont decodes the strings shown with a font into text and glyph widths.
+type pdfFont struct {
+	// composite is set for Type0 fonts, whose codes are one to four bytes
+	// long and select glyphs by CID.
+	composite bool
+	// toUnicode is the font's ToUnicode CMap, if any. It takes precedence
+	// over every other way of mapping codes to text.
+	toUnicode *pdfCMap
+	// encoding maps the codes of a composite font to CIDs; nil means the
+	// Identity encoding.
+	encoding *pdfCMap
+	// utf16 is set for composite fonts using a predefined UCS-2 or UTF-16
+	// encoding, whose codes are the text.
+	utf16 bool
+	// simple maps the codes of a simple font to text.
+	simple [256]string
+
+	widths       map[int]float64 // by code for simple fonts, by CID for composite ones
+	widthRanges  []pdfWidthRange
+	defaultWidth float64
+	// scale converts glyph space widths to text space.
+	scale float64
+}
+
+type pdfWidthRange struct {
+	first, last int
+	width       float64
+}
+
+// pdfGlyph is one decoded character code.
+type pdfGlyph struct {
+	text string
+	// width is the horizontal displacement in unscaled text space.
+	width float64
+	// space is set for the single-byte code 32, to which word spacing applies.
+	space bool
+}
+
+// pdfFallbackFont is used for text shown before any font is selected.
+var pdfFallbackFont = func() *pdfFont {
+	f := &pdfFont{defaultWidth: 500, scale: 0.001}
+	for i, r := range standardEncoding {
+		if r != 0 {
+			f.simple[i] = string(r)
+		}
+	}
+	return f
+}()
+
+// decode splits s into character codes.
+func (f *pdfFont) decode(s []byte) []pdfGlyph {
+	glyphs := make([]pdfGlyph, 0, len(s))
+	for len(s) > 0 {
+		n := 1
+		if f.composite {
+			n = f.codeLen(s)
+		}
+		code := s[:n]
+		s = s[n:]
+
+		g := pdfGlyph{width: f.width(code) * f.scale, space: n == 1 && code[0] == ' '}
+		text, ok := f.toUnicode.lookup(code)
+		switch {
+		case ok:
+			g.text = text
+		case !f.composite:
+			g.text = f.simple[code[0]]
+		case f.utf16:
+			g.text = utf16BEString(code)
+		}
+		glyphs = append(glyphs, g)
+	}
+	return glyphs
+}
+
+func (f *pdfFont) codeLen(s []byte) int {
+	if n := f.encoding.codeLen(s); n > 0 {
+		return n
+	}
+	if n := f.toUnicode.codeLen(s); n > 0 {
+		return n
+	}
+	return min(2, len(s))
+}
+
+// width returns the glyph width of code in glyph space.
+func (f *pdfFont) width(code []byte) float64 {
+	key := int(code[0])
+	if f.composite {
+		key = f.cid(code)
+	}
+	if w, ok := f.widths[key]; ok {
+		return w
+	}
+	for _, r := range f.widthRanges {
+		if key >= r.first && key <= r.last {
+			return r.width
+		}
+	}
+	return f.defaultWidth
+}
+
+func (f *pdfFont) cid(code []byte) int {
+	if f.encoding != nil {
+		return f.encoding.cid(code)
+	}
+	return codeValue(code)
+}
+
+// font returns the font named name in the resource dictionary res.
+func (d *pdfDocument) font(res pdfDict, name pdfName) *pdfFont {
+	v := d.dict(res["Font"])[name]
+	ref, isRef := v.(pdfRef)
+	if isRef {
+		if f, ok := d.fonts[ref]; ok {
+			return f
+		}
+	}
+	f := d.loadFont(d.dict(v))
+	if isRef {
+		d.fonts[ref] = f
+	}
+	return f
+}
+
+func (d *pdfDocument) loadFont(fd pdfDict) *pdfFont {
+	if fd == nil {
+		return pdfFallbackFont
+	}
+	f := &pdfFont{scale: 0.001, widths: make(map[int]float64)}
+	if s, ok := d.resolve(fd["ToUnicode"]).(*pdfStream); ok {
+		if data, err := d.decodeStream(s); err == nil {
+			f.toUnicode = parseCMap(data)
+		}
+	}
+	if fd["Subtype"] == pdfName("Type0") {
+		d.loadCompositeFont(f, fd)
+	} else {
+		d.loadSimpleFont(f, fd)
+	}
+	return f
+}
+
+func (d *pdfDocument) loadSimpleFont(f *pdfFont, fd pdfDict) {
+	subtype, _ := fd["Subtype"].(pdfName)
+	base := &standardEncoding
+	if subtype == "TrueType" {
+		base = &winAnsiEncoding
+	}
+
+	var differences pdfArray
+	switch enc := d.resolve(fd["Encoding"]).(type) {
+	case pdfName:
+		base = namedEncoding(enc, base)
+	case pdfDict:
+		if name, ok := d.resolve(enc["BaseEncoding"]).(pdfName); ok {
+			base = namedEncoding(name, base)
+		}
+		differences = d.array(enc["Differences"])
+	}
+	for i, r := range base {
+		if r != 0 {
+			f.simple[i] = string(r)
+		}
+	}
+	code := 0
+	for _, v := range differences {
+		switch v := d.resolve(v).(type) {
+		case int:
+			code = v
+		case pdfName:
+			if code >= 0 && code < 256 {
+				f.simple[code] = glyphText(string(v))
+			}
+			code++
+		}
+	}
+
+	first, _ := d.resolve(fd["FirstChar"]).(int)
+	widths := d.array(fd["Widths"])
+	for i, w := range widths {
+		if n, ok := d.number(w); ok {
+			f.widths[first+i] = n
+		}
+	}
+	f.defaultWidth = 500
+	if base, _ := fd["BaseFont"].(pdfName); strings.Contains(string(base), "Courier") {
+		f.defaultWidth = 600
+	}
+	if w, ok := d.number(d.dict(fd["FontDescriptor"])["MissingWidth"]); ok && w > 0 {
+		f.defaultWidth = w
+	}
+
+	// Type3 glyph widths are given in the font's own glyph space.
+	if subtype == "Type3" {
+		if m := d.array(fd["FontMatrix"]); len(m) == 6 {
+			if a, ok := d.number(m[0]); ok && a != 0 {
+				f.scale = a
+			}
+		}
+	}
+}
+
+func namedEncoding(name pdfName, def *[256]rune) *[256]rune {
+	switch name {
+	case "WinAnsiEncoding":
+		return &winAnsiEncoding
+	case "MacRomanEncoding":
+		return &macRomanEncoding
+	case "StandardEncoding":
+		return &standardEncoding
+	}
+	return def
+}
+
+func (d *pdfDocument) loadCompositeFont(f *pdfFont, fd pdfDict) {
+	f.composite = true
+	switch enc := d.resolve(fd["Encoding"]).(type) {
+	case pdfName:
+		// Predefined CMaps other than Identity and the Unicode ones need
+		// tables we do not carry; such fonts rely on ToUnicode for text.
+		name := string(enc)
+		f.utf16 = strings.Contains(name, "UCS2") || strings.Contains(name, "UTF16")
+	case *pdfStream:
+		if data, err := d.decodeStream(enc); err == nil {
+			f.encoding = parseCMap(data)
+		}
+	}
+
+	var desc pdfDict
+	if arr := d.array(fd["DescendantFonts"]); len(arr) > 0 {
+		desc = d.dict(arr[0])
+	}
+	f.defaultWidth = 1000
+	if w, ok := d.number(desc["DW"]); ok {
+		f.defaultWidth = w
+	}
+
+	w := d.array(desc["W"])
+	for i := 0; i+1 < len(w); {
+		first, ok := d.resolve(w[i]).(int)
+		if !ok {
+			return
+		}
+		if arr, ok := d.resolve(w[i+1]).(pdfArray); ok {
+			for j, v := range arr {
+				if n, ok := d.number(v); ok {
+					f.widths[first+j] = n
+				}
+			}
+			i += 2
+			continue
+		}
+		if i+2 >= len(w) {
+			return
+		}
+		last, _ := d.resolve(w[i+1]).(int)
+		width, _ := d.number(w[i+2])
+		f.widthRanges = append(f.widthRanges, pdfWidthRange{first: first, last: last, width: width})
+		i += 3
+	}
+}
+
+// pdfCMap is a parsed CMap: a ToUnicode map from codes to text, or an
+// encoding from codes to CIDs. A nil *pdfCMap maps nothing.
+type pdfCMap struct {
+	space     []pdfCodeRange
+	chars     map[string]string
+	bfRanges  []pdfCMapRange
+	cids      map[string]int
+	cidRanges []pdfCMapRange
+	// defaultLen is the code length used when no codespace range matches.
+	defaultLen int
+}
+
+type pdfCodeRange struct {
+	lo, hi []byte
+}
+
+type pdfCMapRange struct {
+	lo, hi []byte
+	dst    []byte   // first destination, as UTF-16BE
+	arr    []string // destinations listed one by one
+	cid    int      // first CID
+}
+
+// parseCMap parses the operators of a CMap that matter for text
+// extraction: codespace, bfchar, bfrange, cidchar and cidrange.
+func parseCMap(data []byte) *pdfCMap {
+	m := &pdfCMap{chars: make(map[string]string), cids: make(map[string]int)}
+	l := &pdfLexer{data: data, noRefs: true}
+	var operands []any
+	for {
+		obj, err := l.readObject()
+		if err != nil {
+			break
+		}
+		op, ok := obj.(pdfKeyword)
+		if !ok {
+			operands = append(operands, obj)
+			continue
+		}
+
+		switch op {
+		case "endcodespacerange":
+			for i := 0; i+1 < len(operands); i += 2 {
+				lo, ok1 := operands[i].(pdfString)
+				hi, ok2 := operands[i+1].(pdfString)
+				if ok1 && ok2 && len(lo) == len(hi) && len(lo) > 0 {
+					m.space = append(m.space, pdfCodeRange{lo: []byte(lo), hi: []byte(hi)})
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok := operands[i].(pdfString)
+				if !ok {
+					continue
+				}
+				m.noteLen(src)
+				switch dst := operands[i+1].(type) {
+				case pdfString:
+					m.chars[string(src)] = utf16BEString([]byte(dst))
+				case pdfName:
+					m.chars[string(src)] = glyphText(string(dst))
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].(pdfString)
+				hi, ok2 := operands[i+1].(pdfString)
+				if !ok1 || !ok2 || len(lo) != len(hi) {
+					continue
+				}
+				m.noteLen(lo)
+				r := pdfCMapRange{lo: []byte(lo), hi: []byte(hi)}
+				switch dst := operands[i+2].(type) {
+				case pdfString:
+					r.dst = []byte(dst)
+				case pdfArray:
+					for _, v := range dst {
+						s, _ := v.(pdfString)
+						r.arr = append(r.arr, utf16BEString([]byte(s)))
+					}
+				}
+				m.bfRanges = append(m.bfRanges, r)
+			}
+		case "endcidchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].(pdfString)
+				cid, ok2 := operands[i+1].(int)
+				if ok1 && ok2 {
+					m.noteLen(src)
+					m.cids[string(src)] = cid
+				}
+			}
+		case "endcidrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].(pdfString)
+				hi, ok2 := operands[i+1].(pdfString)
+				cid, ok3 := operands[i+2].(int)
+				if ok1 && ok2 && ok3 && len(lo) == len(hi) {
+					m.noteLen(lo)
+					m.cidRanges = append(m.cidRanges, pdfCMapRange{lo: []byte(lo), hi: []byte(hi), cid: cid})
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+	return m
+}
+
+func (m *pdfCMap) noteLen(code pdfString) {
+	if m.defaultLen == 0 {
+		m.defaultLen = len(code)
+	}
+}
+
+// codeLen returns the length of the code at the start of s, or 0 if the
+// CMap does not say.
+func (m *pdfCMap) codeLen(s []byte) int {
+	if m == nil {
+		return 0
+	}
+	for n := 1; n <= 4 && n <= len(s); n++ {
+		for _, r := range m.space {
+			if len(r.lo) == n && inCodeSpace(s[:n], r) {
+				return n
+			}
+		}
+	}
+	return min(m.defaultLen, len(s))
+}
+
+// inCodeSpace reports whether every byte of code lies within the
+// corresponding bytes of r.
+func inCodeSpace(code []byte, r pdfCodeRange) bool {
+	for i, c := range code {
+		if c < r.lo[i] || c > r.hi[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// lookup returns the text for code.
+func (m *pdfCMap) lookup(code []byte) (string, bool) {
+	if m == nil {
+		return "", false
+	}
+	if t, ok := m.chars[string(code)]; ok {
+		return t, true
+	}
+	for _, r := range m.bfRanges {
+		off, ok := r.offset(code)
+		if !ok {
+			continue
+		}
+		if r.arr != nil {
+			if off < len(r.arr) {
+				return r.arr[off], true
+			}
+			return "", false
+		}
+		if len(r.dst) == 0 {
+			return "", false
+		}
+		// The offset is added to the last UTF-16 code unit of the destination.
+		dst := append([]byte(nil), r.dst...)
+		n := len(dst)
+		v := int(dst[n-1]) + off
+		if n >= 2 {
+			v += int(dst[n-2]) << 8
+			dst[n-2] = byte(v >> 8)
+		}
+		dst[n-1] = byte(v)
+		return utf16BEString(dst), true
+	}
+	return "", false
+}
+
+// cid returns the CID for code, or 0 (the notdef glyph).
+func (m *pdfCMap) cid(code []byte) int {
+	if cid, ok := m.cids[string(code)]; ok {
+		return cid
+	}
+	for _, r := range m.cidRanges {
+		if off, ok := r.offset(code); ok {
+			return r.cid + off
+		}
+	}
+	return 0
+}
+
+// offset returns the position of code within r.
+func (r pdfCMapRange) offset(code []byte) (int, bool) {
+	if len(code) != len(r.lo) {
+		return 0, false
+	}
+	v, lo, hi := codeValue(code), codeValue(r.lo), codeValue(r.hi)
+	if v < lo || v > hi {
+		return 0, false
+	}
+	return v - lo, true
+}
+
+func codeValue(code []byte) int {
+	v := 0
+	for _, c := range code {
+		v = v<<8 | int(c)
+	}
+	return v
+}
+
+// glyphText returns the text for a glyph name, following the Adobe Glyph
+// List conventions: uniXXXX and uXXXX names, ligatures joined with
+// underscores and suffixes after a period. Ligature glyphs are spelled out.
+func glyphText(name string) string {
+	if i := strings.IndexByte(name, '.'); i > 0 {
+		name = name[:i]
+	}
+	if strings.Contains(name, "_") {
+		var sb strings.Builder
+		for _, part := range strings.Split(name, "_") {
+			sb.WriteString(glyphText(part))
+		}
+		return sb.String()
+	}
+	if t, ok := glyphNames[name]; ok {
+		return t
+	}
+	if strings.HasPrefix(name, "uni") && len(name) >= 7 && (len(name)-3)%4 == 0 {
+		var sb strings.Builder
+		for i := 3; i < len(name); i += 4 {
+			v, err := strconv.ParseUint(name[i:i+4], 16, 16)
+			if err != nil {
+				return ""
+			}
+			sb.WriteRune(rune(v))
+		}
+		return sb.String()
+	}
+	if strings.HasPrefix(name, "u") && len(name) >= 5 && len(name) <= 7 {
+		if v, err := strconv.ParseUint(name[1:], 16, 32); err == nil {
+			return string(rune(v))
+		}
+	}
+	if len(name) == 1 && (name[0] >= 'a' && name[0] <= 'z' || name[0] >= 'A' && name[0] <= 'Z') {
+		return name
+	}
+	return ""
+}
+
+// glyphNames maps the glyph names used by the standard encodings, and a
+// few common extras, to text.
+var glyphNames = func() map[string]string {
+	m := map[string]string{
+		"quoteleft": "‘", "quoteright": "’", "quotedblleft": "“", "quotedblright": "”",
+		"quotesinglbase": "‚", "quotedblbase": "„", "guilsinglleft": "‹", "guilsinglright": "›",
+		"endash": "–", "emdash": "—", "bullet": "•", "dagger": "†", "daggerdbl": "‡",
+		"ellipsis": "…", "perthousand": "‰", "trademark": "™", "minus": "−", "fraction": "⁄",
+		"florin": "ƒ", "dotlessi": "ı", "Lslash": "Ł", "lslash": "ł", "OE": "Œ", "oe": "œ",
+		"Scaron": "Š", "scaron": "š", "Zcaron": "Ž", "zcaron": "ž", "Ydieresis": "Ÿ",
+		"circumflex": "ˆ", "tilde": "˜", "breve": "˘", "dotaccent": "˙", "ring": "˚",
+		"hungarumlaut": "˝", "ogonek": "˛", "caron": "ˇ", "Euro": "€", "nbspace": "\u00a0",
+		"ff": "ff", "fi": "fi", "fl": "fl", "ffi": "ffi", "ffl": "ffl",
+		"alpha": "α", "beta": "β", "gamma": "γ", "delta": "δ", "epsilon": "ε", "lambda": "λ",
+		"pi": "π", "sigma": "σ", "theta": "θ", "omega": "ω",
+	}
+	ascii := strings.Fields(`space exclam quotedbl numbersign dollar percent ampersand
+		quotesingle parenleft parenright asterisk plus comma hyphen period slash zero one
+		two three four five six seven eight nine colon semicolon less equal greater
+		question at`)
+	for i, name := range ascii {
+		m[name] = string(rune(0x20 + i))
+	}
+	punct := strings.Fields(`bracketleft backslash bracketright asciicircum underscore
+		grave`)
+	for i, name := range punct {
+		m[name] = string(rune(0x5B + i))
+	}
+	for i, name := range strings.Fields("braceleft bar braceright asciitilde") {
+		m[name] = string(rune(0x7B + i))
+	}
+	latin1 := strings.Fields(`exclamdown cent sterling currency yen brokenbar section
+		dieresis copyright ordfeminine guillemotleft logicalnot sfthyphen registered macron
+		degree plusminus twosuperior threesuperior acute mu paragraph periodcentered cedilla
+		onesuperior ordmasculine guillemotright onequarter onehalf threequarters questiondown
+		Agrave Aacute Acircumflex Atilde Adieresis Aring AE Ccedilla Egrave Eacute
+		Ecircumflex Edieresis Igrave Iacute Icircumflex Idieresis Eth Ntilde Ograve Oacute
+		Ocircumflex Otilde Odieresis multiply Oslash Ugrave Uacute Ucircumflex Udieresis
+		Yacute Thorn germandbls agrave aacute acircumflex atilde adieresis aring ae ccedilla
+		egrave eacute ecircumflex edieresis igrave iacute icircumflex idieresis eth ntilde
+		ograve oacute ocircumflex otilde odieresis divide oslash ugrave uacute ucircumflex
+		udieresis yacute thorn ydieresis`)
+	for i, name := range latin1 {
+		m[name] = string(rune(0xA1 + i))
+	}
+	return m
+}()
+
+// The simple font encodings. Zero marks an undefined code.
+var standardEncoding, winAnsiEncoding, macRomanEncoding [256]rune
+
+func init() {
+	for c := 0x20; c < 0x7F; c++ {
+		standardEncoding[c] = rune(c)
+		winAnsiEncoding[c] = rune(c)
+		macRomanEncoding[c] = rune(c)
+	}
+
+	standardEncoding['\''] = '’'
+	standardEncoding['`'] = '‘'
+	for c, r := range map[int]rune{
+		0xA1: '¡', 0xA2: '¢', 0xA3: '£', 0xA4: '⁄', 0xA5: '¥', 0xA6: 'ƒ', 0xA7: '§', 0xA8: '¤',
+		0xA9: '\'', 0xAA: '“', 0xAB: '«', 0xAC: '‹', 0xAD: '›', 0xAE: 'ﬁ', 0xAF: 'ﬂ',
+		0xB1: '–', 0xB2: '†', 0xB3: '‡', 0xB4: '·', 0xB6: '¶', 0xB7: '•', 0xB8: '‚', 0xB9: '„',
+		0xBA: '”', 0xBB: '»', 0xBC: '…', 0xBD: '‰', 0xBF: '¿',
+		0xC1: '`', 0xC2: '´', 0xC3: 'ˆ', 0xC4: '˜', 0xC5: '¯', 0xC6: '˘', 0xC7: '˙', 0xC8: '¨',
+		0xCA: '˚', 0xCB: '¸', 0xCD: '˝', 0xCE: '˛', 0xCF: 'ˇ', 0xD0: '—',
+		0xE1: 'Æ', 0xE3: 'ª', 0xE8: 'Ł', 0xE9: 'Ø', 0xEA: 'Œ', 0xEB: 'º',
+		0xF1: 'æ', 0xF5: 'ı', 0xF8: 'ł', 0xF9: 'ø', 0xFA: 'œ', 0xFB: 'ß',
+	} {
+		standardEncoding[c] = r
+	}
+
+	for i, r := range []rune("€\x00‚ƒ„…†‡ˆ‰Š‹Œ\x00Ž\x00\x00‘’“”•–—˜™š›œ\x00žŸ") {
+		winAnsiEncoding[0x80+i] = r
+	}
+	for c := 0xA0; c <= 0xFF; c++ {
+		winAnsiEncoding[c] = rune(c)
+	}
+
+	for i, r := range []rune("ÄÅÇÉÑÖÜáàâäãåçéèêëíìîïñóòôöõúùûü†°¢£§•¶ß®©™´¨≠ÆØ∞±≤≥¥µ∂∑∏π∫ªºΩæø" +
+		"¿¡¬√ƒ≈∆«»…\u00a0ÀÃÕŒœ–—“”‘’÷◊ÿŸ⁄€‹›ﬁﬂ‡·‚„‰ÂÊÁËÈÍÎÏÌÓÔ\uf8ffÒÚÛÙıˆ˜¯˘˙˚¸˝˛ˇ") {
+		macRomanEncoding[0x80+i] = r
+	}
+}
@@ -0,0 +1,168 @@
+package extractor
+
+import "testing"
+
+const testToUnicode = `/CIDInit /ProcSet findresource begin
+12 dict begin
+begincmap
+/CMapName /Adobe-Identity-UCS def
+1 begincodespacerange
+<0000> <FFFF>
+endcodespacerange
+2 beginbfchar
+<0003> <0020>
+<0010> <D55C>
+endbfchar
+2 beginbfrange
+<0020> <0022> <C548>
+<0030> <0031> [<AE00> <0066006C>]
+endbfrange
+endcmap
+CMapName currentdict /CMap defineresource pop
+end
+end`
+
+func TestParseCMap(t *testing.T) {
+	m := parseCMap([]byte(testToUnicode))
+
+	tests := []struct {
+		code   string
+		want   string
+		wantOK bool
+	}{
+		{"\x00\x03", " ", true},
+		{"\x00\x10", "한", true},
+		{"\x00\x20", "안", true},
+		{"\x00\x22", "않", true},
+		{"\x00\x30", "글", true},
+		{"\x00\x31", "fl", true},
+		{"\x00\x40", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := m.lookup([]byte(tt.code))
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("lookup(%q) = %q, %v, want %q, %v", tt.code, got, ok, tt.want, tt.wantOK)
+		}
+	}
+	if n := m.codeLen([]byte("\x00\x10\x00")); n != 2 {
+		t.Errorf("codeLen() = %d, want 2", n)
+	}
+}
+
+func TestParseCMap_CIDRanges(t *testing.T) {
+	m := parseCMap([]byte(`2 begincodespacerange <00> <80> <8140> <FEFE> endcodespacerange
+1 begincidchar <41> 34 endcidchar
+1 begincidrange <8140> <817E> 633 endcidrange`))
+
+	if n := m.codeLen([]byte("A\x81\x40")); n != 1 {
+		t.Errorf("codeLen(single byte) = %d, want 1", n)
+	}
+	if n := m.codeLen([]byte("\x81\x40")); n != 2 {
+		t.Errorf("codeLen(double byte) = %d, want 2", n)
+	}
+	if cid := m.cid([]byte("A")); cid != 34 {
+		t.Errorf("cid(A) = %d, want 34", cid)
+	}
+	if cid := m.cid([]byte("\x81\x42")); cid != 635 {
+		t.Errorf("cid(8142) = %d, want 635", cid)
+	}
+}
+
+func TestPDFFont_Decode(t *testing.T) {
+	d := &pdfDocument{}
+	d.reset()
+
+	simple := d.loadFont(pdfDict{
+		"Subtype":   pdfName("Type1"),
+		"Encoding":  pdfDict{"BaseEncoding": pdfName("WinAnsiEncoding"), "Differences": pdfArray{1, pdfName("fi"), pdfName("uni2019")}},
+		"FirstChar": 65,
+		"Widths":    pdfArray{722, 667},
+	})
+	glyphs := simple.decode([]byte("\x01A\x02B\x93 "))
+	var text string
+	for _, g := range glyphs {
+		text += g.text
+	}
+	if text != "fiA’B“ " {
+		t.Errorf("decoded text = %q, want %q", text, "fiA’B“ ")
+	}
+	if glyphs[1].width != 0.722 || glyphs[0].width != 0.5 {
+		t.Errorf("widths = %v, %v, want 0.722 and the 0.5 default", glyphs[1].width, glyphs[0].width)
+	}
+	if !glyphs[5].space {
+		t.Error("expected code 32 to take word spacing")
+	}
+
+	composite := d.loadFont(pdfDict{
+		"Subtype":  pdfName("Type0"),
+		"Encoding": pdfName("Identity-H"),
+		"DescendantFonts": pdfArray{pdfDict{
+			"DW": 1000,
+			"W":  pdfArray{16, pdfArray{920}, 32, 34, 500},
+		}},
+		"ToUnicode": &pdfStream{dict: pdfDict{}, raw: []byte(testToUnicode)},
+	})
+	glyphs = composite.decode([]byte("\x00\x10\x00\x21\x00\x03\x00\x99"))
+	if len(glyphs) != 4 {
+		t.Fatalf("got %d glyphs, want 4", len(glyphs))
+	}
+	if glyphs[0].text != "한" || glyphs[1].text != "앉" || glyphs[2].text != " " || glyphs[3].text != "" {
+		t.Errorf("decoded = %q %q %q %q", glyphs[0].text, glyphs[1].text, glyphs[2].text, glyphs[3].text)
+	}
+	if glyphs[0].width != 0.92 || glyphs[1].width != 0.5 || glyphs[3].width != 1 {
+		t.Errorf("widths = %v %v %v", glyphs[0].width, glyphs[1].width, glyphs[3].width)
+	}
+
+	ucs2 := d.loadFont(pdfDict{"Subtype": pdfName("Type0"), "Encoding": pdfName("UniKS-UCS2-H")})
+	if g := ucs2.decode([]byte("\xd5\x5c\xae\x00")); len(g) != 2 || g[0].text+g[1].text != "한글" {
+		t.Errorf("UCS2 decode = %+v", g)
+	}
+}
+
+func TestGlyphText(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"A", "A"},
+		{"eacute", "é"},
+		{"quoteright", "’"},
+		{"ffi", "ffi"},
+		{"f_f_l", "ffl"},
+		{"uniD55CAE00", "한글"},
+		{"u1F600", "😀"},
+		{"a.sc", "a"},
+		{"ydieresis", "ÿ"},
+		{"at", "@"},
+		{"g123", ""},
+	}
+	for _, tt := range tests {
+		if got := glyphText(tt.name); got != tt.want {
+			t.Errorf("glyphText(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEncodingTables(t *testing.T) {
+	tests := []struct {
+		name string
+		enc  *[256]rune
+		code byte
+		want rune
+	}{
+		{"standard quoteright", &standardEncoding, '\'', '’'},
+		{"standard fi", &standardEncoding, 0xAE, 'ﬁ'},
+		{"standard germandbls", &standardEncoding, 0xFB, 'ß'},
+		{"WinAnsi euro", &winAnsiEncoding, 0x80, '€'},
+		{"WinAnsi Ydieresis", &winAnsiEncoding, 0x9F, 'Ÿ'},
+		{"WinAnsi eacute", &winAnsiEncoding, 0xE9, 'é'},
+		{"MacRoman Adieresis", &macRomanEncoding, 0x80, 'Ä'},
+		{"MacRoman nbsp", &macRomanEncoding, 0xCA, '\u00a0'},
+		{"MacRoman caron", &macRomanEncoding, 0xFF, 'ˇ'},
+	}
+	for _, tt := range tests {
+		if got := tt.enc[tt.code]; got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
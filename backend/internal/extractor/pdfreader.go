@@ -0,0 +1,1056 @@
+package extractor
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/ascii85"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+)
+
+// This file implements just enough of a PDF object reader to pull text out
+// of real-world files: classic and stream cross-reference tables, object
+// streams, incremental updates and the common stream filters. Files whose
+// cross-reference data is damaged are recovered by scanning for object
+// headers, as most viewers do.
+
+// maxPDFDecodedSize bounds the decompressed size of all the streams of a
+// document together, so many small streams that each inflate to a lot
+// cannot exhaust memory either.
+const maxPDFDecodedSize = 64 << 20
+
+var (
+	errPDFEncrypted = errors.New("PDF is encrypted")
+	errPDFSyntax    = errors.New("PDF syntax error")
+	errPDFTooLarge  = errors.New("PDF streams decompress to more than the limit")
+)
+
+// PDF object types. Integers are int, reals float64, booleans bool and
+// null nil.
+type (
+	pdfName    string
+	pdfKeyword string
+	pdfString  string
+	pdfArray   []any
+	pdfDict    map[pdfName]any
+	pdfRef     struct{ num, gen int }
+	pdfStream  struct {
+		dict pdfDict
+		raw  []byte
+	}
+)
+
+// pdfLexer reads PDF objects from data, starting at pos.
+type pdfLexer struct {
+	data []byte
+	pos  int
+	// noRefs disables "n g R" references, which never occur in content
+	// streams and CMaps.
+	noRefs bool
+}
+
+func isPDFSpace(c byte) bool {
+	switch c {
+	case 0, '\t', '\n', '\f', '\r', ' ':
+		return true
+	}
+	return false
+}
+
+func isPDFDelim(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if c == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		if !isPDFSpace(c) {
+			return
+		}
+		l.pos++
+	}
+}
+
+// hasKeyword reports whether kw starts at the current position.
+func (l *pdfLexer) hasKeyword(kw string) bool {
+	return bytes.HasPrefix(l.data[l.pos:], []byte(kw))
+}
+
+// readObject reads the next object. Keywords, including content stream
+// operators, are returned as pdfKeyword. It returns io.EOF at the end of data.
+func (l *pdfLexer) readObject() (any, error) {
+	return l.readObjectDepth(0)
+}
+
+func (l *pdfLexer) readObjectDepth(depth int) (any, error) {
+	if depth > 100 {
+		return nil, fmt.Errorf("%w: objects nested too deeply", errPDFSyntax)
+	}
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, io.EOF
+	}
+
+	c := l.data[l.pos]
+	switch {
+	case c == '/':
+		return l.readName(), nil
+	case c == '(':
+		return l.readLiteralString(), nil
+	case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+		l.pos += 2
+		return l.readDict(depth)
+	case c == '<':
+		return l.readHexString(), nil
+	case c == '[':
+		l.pos++
+		return l.readArray(depth)
+	case isPDFDelim(c):
+		// Stray closing delimiters and PostScript braces.
+		l.pos++
+		return pdfKeyword(c), nil
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		return l.readNumber(), nil
+	}
+
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelim(l.data[l.pos]) {
+		l.pos++
+	}
+	switch kw := string(l.data[start:l.pos]); kw {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	default:
+		return pdfKeyword(kw), nil
+	}
+}
+
+// readNumber reads an integer or real, and an "n g R" reference when the
+// integer is followed by one.
+func (l *pdfLexer) readNumber() any {
+	start := l.pos
+	isReal := false
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if c == '.' {
+			isReal = true
+		} else if !(c >= '0' && c <= '9') && !((c == '+' || c == '-') && l.pos == start) {
+			break
+		}
+		l.pos++
+	}
+	tok := string(l.data[start:l.pos])
+	if isReal {
+		f, _ := strconv.ParseFloat(tok, 64)
+		return f
+	}
+	n, err := strconv.Atoi(tok)
+	if err != nil {
+		return 0
+	}
+	if l.noRefs || n < 0 {
+		return n
+	}
+
+	save := l.pos
+	l.skipSpace()
+	genStart := l.pos
+	for l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '9' {
+		l.pos++
+	}
+	if l.pos > genStart {
+		gen, _ := strconv.Atoi(string(l.data[genStart:l.pos]))
+		l.skipSpace()
+		if l.pos < len(l.data) && l.data[l.pos] == 'R' &&
+			(l.pos+1 == len(l.data) || isPDFSpace(l.data[l.pos+1]) || isPDFDelim(l.data[l.pos+1])) {
+			l.pos++
+			return pdfRef{num: n, gen: gen}
+		}
+	}
+	l.pos = save
+	return n
+}
+
+func (l *pdfLexer) readName() pdfName {
+	l.pos++ // '/'
+	var name []byte
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if isPDFSpace(c) || isPDFDelim(c) {
+			break
+		}
+		if c == '#' && l.pos+2 < len(l.data) {
+			if v, err := strconv.ParseUint(string(l.data[l.pos+1:l.pos+3]), 16, 8); err == nil {
+				name = append(name, byte(v))
+				l.pos += 3
+				continue
+			}
+		}
+		name = append(name, c)
+		l.pos++
+	}
+	return pdfName(name)
+}
+
+func (l *pdfLexer) readLiteralString() pdfString {
+	l.pos++ // '('
+	var buf []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return pdfString(buf)
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				continue
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				// Line continuation.
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(v)
+				} else {
+					c = e
+				}
+			}
+		}
+		buf = append(buf, c)
+	}
+	return pdfString(buf)
+}
+
+func (l *pdfLexer) readHexString() pdfString {
+	l.pos++ // '<'
+	var buf []byte
+	var hi byte
+	odd := false
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		if c == '>' {
+			break
+		}
+		v, ok := hexValue(c)
+		if !ok {
+			continue
+		}
+		if odd {
+			buf = append(buf, hi<<4|v)
+		} else {
+			hi = v
+		}
+		odd = !odd
+	}
+	if odd {
+		buf = append(buf, hi<<4)
+	}
+	return pdfString(buf)
+}
+
+func hexValue(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+func (l *pdfLexer) readArray(depth int) (pdfArray, error) {
+	var arr pdfArray
+	for {
+		l.skipSpace()
+		if l.pos >= len(l.data) {
+			return arr, nil
+		}
+		if l.data[l.pos] == ']' {
+			l.pos++
+			return arr, nil
+		}
+		obj, err := l.readObjectDepth(depth + 1)
+		if err != nil {
+			return arr, err
+		}
+		arr = append(arr, obj)
+	}
+}
+
+func (l *pdfLexer) readDict(depth int) (pdfDict, error) {
+	dict := make(pdfDict)
+	for {
+		l.skipSpace()
+		if l.pos >= len(l.data) {
+			return dict, nil
+		}
+		if l.hasKeyword(">>") {
+			l.pos += 2
+			return dict, nil
+		}
+		key, err := l.readObjectDepth(depth + 1)
+		if err != nil {
+			return dict, err
+		}
+		name, ok := key.(pdfName)
+		if !ok {
+			// Skip junk such as a stray keyword.
+			continue
+		}
+		l.skipSpace()
+		if l.hasKeyword(">>") {
+			l.pos += 2
+			return dict, nil
+		}
+		val, err := l.readObjectDepth(depth + 1)
+		if err != nil {
+			return dict, err
+		}
+		dict[name] = val
+	}
+}
+
+// skipInlineImage skips an inline image up to and including its EI
+// operator. It is called after the BI operator has been read.
+func (l *pdfLexer) skipInlineImage() {
+	for {
+		obj, err := l.readObject()
+		if err != nil {
+			return
+		}
+		if obj == pdfKeyword("ID") {
+			break
+		}
+	}
+	l.pos++ // single whitespace after ID
+	for i := l.pos; i < len(l.data); i++ {
+		j := bytes.Index(l.data[i:], []byte("EI"))
+		if j < 0 {
+			break
+		}
+		i += j
+		end := i + 2
+		if isPDFSpace(l.data[i-1]) && (end == len(l.data) || isPDFSpace(l.data[end])) {
+			l.pos = end
+			return
+		}
+	}
+	l.pos = len(l.data)
+}
+
+// pdfXref locates an object: at a byte offset, or at an index within an
+// object stream. The zero value marks a free object.
+type pdfXref struct {
+	offset int
+	stream int
+	index  int
+}
+
+// pdfObjStm is a decoded object stream.
+type pdfObjStm struct {
+	data    []byte
+	nums    []int
+	offsets []int
+}
+
+// pdfDocument is an opened PDF file.
+type pdfDocument struct {
+	data    []byte
+	xref    map[int]pdfXref
+	trailer pdfDict
+
+	objects   map[int]any
+	resolving map[int]bool
+	objStms   map[int]*pdfObjStm
+	fonts     map[pdfRef]*pdfFont
+
+	// inflated counts the bytes decompressed so far, against
+	// maxPDFDecodedSize. Decoding the same stream again counts again.
+	inflated int64
+}
+
+// openPDF parses the cross-reference data of a PDF file. It returns
+// errPDFEncrypted for encrypted files.
+func openPDF(data []byte) (*pdfDocument, error) {
+	if !bytes.Contains(data[:min(len(data), 1024)], []byte("%PDF-")) {
+		return nil, errors.New("not a PDF file")
+	}
+	d := &pdfDocument{data: data}
+	d.reset()
+
+	if err := d.loadXref(); err != nil || d.catalog() == nil {
+		d.reconstruct()
+	}
+	if d.trailer["Encrypt"] != nil {
+		return nil, errPDFEncrypted
+	}
+	if d.catalog() == nil {
+		return nil, errors.New("PDF has no document catalog")
+	}
+	return d, nil
+}
+
+// reset clears the cross-reference table and every cache.
+func (d *pdfDocument) reset() {
+	d.xref = make(map[int]pdfXref)
+	d.trailer = nil
+	d.objects = make(map[int]any)
+	d.resolving = make(map[int]bool)
+	d.objStms = make(map[int]*pdfObjStm)
+	d.fonts = make(map[pdfRef]*pdfFont)
+}
+
+// loadXref reads the cross-reference sections from the last startxref
+// backwards through the /Prev chain. Entries from newer sections win.
+func (d *pdfDocument) loadXref() error {
+	i := bytes.LastIndex(d.data, []byte("startxref"))
+	if i < 0 {
+		return errors.New("startxref not found")
+	}
+	l := &pdfLexer{data: d.data, pos: i + len("startxref"), noRefs: true}
+	obj, _ := l.readObject()
+	offset, ok := obj.(int)
+	if !ok {
+		return errors.New("invalid startxref")
+	}
+
+	seen := make(map[int]bool)
+	for {
+		if seen[offset] || offset <= 0 || offset >= len(d.data) {
+			return fmt.Errorf("invalid cross-reference offset %d", offset)
+		}
+		seen[offset] = true
+
+		trailer, err := d.readXrefSection(offset)
+		if err != nil {
+			return err
+		}
+		if d.trailer == nil {
+			d.trailer = trailer
+		}
+		// Hybrid files keep the entries of compressed objects in a stream.
+		if stm, ok := trailer["XRefStm"].(int); ok && !seen[stm] {
+			seen[stm] = true
+			if _, err := d.readXrefSection(stm); err != nil {
+				return err
+			}
+		}
+		prev, ok := trailer["Prev"].(int)
+		if !ok {
+			return nil
+		}
+		offset = prev
+	}
+}
+
+// readXrefSection reads a cross-reference table or stream at offset and
+// returns its trailer dictionary.
+func (d *pdfDocument) readXrefSection(offset int) (pdfDict, error) {
+	l := &pdfLexer{data: d.data, pos: offset}
+	l.skipSpace()
+	if l.hasKeyword("xref") {
+		l.pos += len("xref")
+		return d.readXrefTable(l)
+	}
+
+	obj, err := d.readIndirect(offset)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := obj.(*pdfStream)
+	if !ok || s.dict["Type"] != pdfName("XRef") {
+		return nil, errors.New("no cross-reference section at offset")
+	}
+	return s.dict, d.readXrefStream(s)
+}
+
+func (d *pdfDocument) readXrefTable(l *pdfLexer) (pdfDict, error) {
+	for {
+		l.skipSpace()
+		if l.hasKeyword("trailer") {
+			l.pos += len("trailer")
+			obj, err := l.readObject()
+			if err != nil {
+				return nil, err
+			}
+			trailer, ok := obj.(pdfDict)
+			if !ok {
+				return nil, errors.New("invalid trailer")
+			}
+			return trailer, nil
+		}
+
+		l.noRefs = true
+		first, ok1 := mustRead(l).(int)
+		count, ok2 := mustRead(l).(int)
+		if !ok1 || !ok2 || first < 0 || count < 0 {
+			return nil, errors.New("invalid cross-reference subsection")
+		}
+		for i := 0; i < count; i++ {
+			offset, ok1 := mustRead(l).(int)
+			_, ok2 := mustRead(l).(int)
+			kind, ok3 := mustRead(l).(pdfKeyword)
+			if !ok1 || !ok2 || !ok3 {
+				return nil, errors.New("invalid cross-reference entry")
+			}
+			e := pdfXref{}
+			if kind == "n" {
+				e.offset = offset
+			}
+			d.setXref(first+i, e)
+		}
+		l.noRefs = false
+	}
+}
+
+func mustRead(l *pdfLexer) any {
+	obj, _ := l.readObject()
+	return obj
+}
+
+func (d *pdfDocument) readXrefStream(s *pdfStream) error {
+	data, err := d.decodeStream(s)
+	if err != nil {
+		return err
+	}
+
+	w := d.array(s.dict["W"])
+	if len(w) < 3 {
+		return errors.New("invalid cross-reference stream /W")
+	}
+	var widths [3]int
+	rowLen := 0
+	for i := range widths {
+		widths[i], _ = d.resolve(w[i]).(int)
+		if widths[i] < 0 || widths[i] > 8 {
+			return errors.New("invalid cross-reference stream /W")
+		}
+		rowLen += widths[i]
+	}
+	if rowLen == 0 {
+		return errors.New("invalid cross-reference stream /W")
+	}
+
+	index := d.array(s.dict["Index"])
+	if index == nil {
+		size, _ := d.resolve(s.dict["Size"]).(int)
+		index = pdfArray{0, size}
+	}
+
+	pos := 0
+	for i := 0; i+1 < len(index); i += 2 {
+		first, _ := d.resolve(index[i]).(int)
+		count, _ := d.resolve(index[i+1]).(int)
+		for j := 0; j < count && pos+rowLen <= len(data); j++ {
+			var f [3]int
+			for k, n := range widths {
+				for _, b := range data[pos : pos+n] {
+					f[k] = f[k]<<8 | int(b)
+				}
+				pos += n
+			}
+			if widths[0] == 0 {
+				f[0] = 1
+			}
+			switch f[0] {
+			case 0:
+				d.setXref(first+j, pdfXref{})
+			case 1:
+				d.setXref(first+j, pdfXref{offset: f[1]})
+			case 2:
+				d.setXref(first+j, pdfXref{stream: f[1], index: f[2]})
+			}
+		}
+	}
+	return nil
+}
+
+// setXref records e for object num unless a newer section already did.
+func (d *pdfDocument) setXref(num int, e pdfXref) {
+	if _, ok := d.xref[num]; !ok {
+		d.xref[num] = e
+	}
+}
+
+var pdfObjHeader = regexp.MustCompile(`(\d+)[\x00\t\n\f\r ]+\d+[\x00\t\n\f\r ]+obj\b`)
+
+// reconstruct rebuilds the cross-reference table by scanning the file for
+// object headers, for files whose xref data is missing or damaged.
+func (d *pdfDocument) reconstruct() {
+	d.reset()
+	for _, m := range pdfObjHeader.FindAllSubmatchIndex(d.data, -1) {
+		if m[0] > 0 && !isPDFSpace(d.data[m[0]-1]) && !isPDFDelim(d.data[m[0]-1]) {
+			continue
+		}
+		num, err := strconv.Atoi(string(d.data[m[2]:m[3]]))
+		if err != nil {
+			continue
+		}
+		// Later definitions come from incremental updates and win.
+		d.xref[num] = pdfXref{offset: m[0]}
+	}
+
+	nums := make([]int, 0, len(d.xref))
+	for num := range d.xref {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+
+	// Index objects that only exist inside object streams.
+	for _, num := range nums {
+		s, ok := d.object(num).(*pdfStream)
+		if !ok || s.dict["Type"] != pdfName("ObjStm") {
+			continue
+		}
+		if stm := d.objStm(num); stm != nil {
+			for i, n := range stm.nums {
+				if _, ok := d.xref[n]; !ok {
+					d.xref[n] = pdfXref{stream: num, index: i}
+				}
+			}
+		}
+	}
+	d.objects = make(map[int]any)
+
+	if i := bytes.LastIndex(d.data, []byte("trailer")); i >= 0 {
+		l := &pdfLexer{data: d.data, pos: i + len("trailer")}
+		if obj, err := l.readObject(); err == nil {
+			d.trailer, _ = obj.(pdfDict)
+		}
+	}
+	if d.catalog() != nil {
+		return
+	}
+	for _, num := range nums {
+		var dict pdfDict
+		switch v := d.object(num).(type) {
+		case pdfDict:
+			dict = v
+		case *pdfStream:
+			dict = v.dict
+		}
+		switch {
+		case dict["Type"] == pdfName("XRef") && dict["Root"] != nil:
+			d.trailer = dict
+		case dict["Type"] == pdfName("Catalog"):
+			d.trailer = pdfDict{"Root": pdfRef{num: num}}
+		}
+	}
+}
+
+// catalog returns the document catalog, or nil.
+func (d *pdfDocument) catalog() pdfDict {
+	if d.trailer == nil {
+		return nil
+	}
+	return d.dict(d.trailer["Root"])
+}
+
+// readIndirect reads the indirect object "n g obj ... endobj" at offset.
+func (d *pdfDocument) readIndirect(offset int) (any, error) {
+	l := &pdfLexer{data: d.data, pos: offset, noRefs: true}
+	_, ok1 := mustRead(l).(int)
+	_, ok2 := mustRead(l).(int)
+	kw, ok3 := mustRead(l).(pdfKeyword)
+	if !ok1 || !ok2 || !ok3 || kw != "obj" {
+		return nil, fmt.Errorf("%w: no object at offset %d", errPDFSyntax, offset)
+	}
+	l.noRefs = false
+	obj, err := l.readObject()
+	if err != nil {
+		return nil, err
+	}
+
+	dict, ok := obj.(pdfDict)
+	if !ok {
+		return obj, nil
+	}
+	l.skipSpace()
+	if !l.hasKeyword("stream") {
+		return dict, nil
+	}
+	l.pos += len("stream")
+	if l.pos < len(l.data) && l.data[l.pos] == '\r' {
+		l.pos++
+	}
+	if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+		l.pos++
+	}
+	return &pdfStream{dict: dict, raw: d.streamData(l.pos, dict)}, nil
+}
+
+// streamData returns the raw bytes of a stream starting at start, trusting
+// /Length only when it ends at the endstream keyword.
+func (d *pdfDocument) streamData(start int, dict pdfDict) []byte {
+	if n, ok := d.resolve(dict["Length"]).(int); ok && n >= 0 && start+n <= len(d.data) {
+		l := &pdfLexer{data: d.data, pos: start + n}
+		l.skipSpace()
+		if l.hasKeyword("endstream") {
+			return d.data[start : start+n]
+		}
+	}
+	i := bytes.Index(d.data[start:], []byte("endstream"))
+	if i < 0 {
+		return d.data[start:]
+	}
+	raw := d.data[start : start+i]
+	raw = bytes.TrimSuffix(raw, []byte("\n"))
+	return bytes.TrimSuffix(raw, []byte("\r"))
+}
+
+// resolve follows references until it reaches a direct object. Missing
+// objects resolve to nil.
+func (d *pdfDocument) resolve(v any) any {
+	for i := 0; i < 32; i++ {
+		ref, ok := v.(pdfRef)
+		if !ok {
+			return v
+		}
+		v = d.object(ref.num)
+	}
+	return nil
+}
+
+func (d *pdfDocument) object(num int) any {
+	if v, ok := d.objects[num]; ok {
+		return v
+	}
+	if d.resolving[num] {
+		return nil
+	}
+	d.resolving[num] = true
+	defer delete(d.resolving, num)
+
+	var v any
+	e := d.xref[num]
+	switch {
+	case e.stream > 0:
+		v = d.objStmObject(e.stream, e.index, num)
+	case e.offset > 0:
+		v, _ = d.readIndirect(e.offset)
+	}
+	d.objects[num] = v
+	return v
+}
+
+// objStm returns the decoded object stream num, or nil.
+func (d *pdfDocument) objStm(num int) *pdfObjStm {
+	if stm, ok := d.objStms[num]; ok {
+		return stm
+	}
+	d.objStms[num] = nil
+
+	s, ok := d.object(num).(*pdfStream)
+	if !ok {
+		return nil
+	}
+	data, err := d.decodeStream(s)
+	if err != nil {
+		return nil
+	}
+	n, _ := d.resolve(s.dict["N"]).(int)
+	first, _ := d.resolve(s.dict["First"]).(int)
+
+	stm := &pdfObjStm{data: data}
+	l := &pdfLexer{data: data, noRefs: true}
+	for i := 0; i < n; i++ {
+		objNum, ok1 := mustRead(l).(int)
+		off, ok2 := mustRead(l).(int)
+		if !ok1 || !ok2 || first+off >= len(data) {
+			break
+		}
+		stm.nums = append(stm.nums, objNum)
+		stm.offsets = append(stm.offsets, first+off)
+	}
+	d.objStms[num] = stm
+	return stm
+}
+
+func (d *pdfDocument) objStmObject(stmNum, index, num int) any {
+	stm := d.objStm(stmNum)
+	if stm == nil {
+		return nil
+	}
+	if index >= len(stm.nums) || stm.nums[index] != num {
+		index = -1
+		for i, n := range stm.nums {
+			if n == num {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return nil
+		}
+	}
+	l := &pdfLexer{data: stm.data, pos: stm.offsets[index]}
+	obj, _ := l.readObject()
+	return obj
+}
+
+// dict resolves v and returns it if it is a dictionary.
+func (d *pdfDocument) dict(v any) pdfDict {
+	dict, _ := d.resolve(v).(pdfDict)
+	return dict
+}
+
+// array resolves v and returns it if it is an array.
+func (d *pdfDocument) array(v any) pdfArray {
+	arr, _ := d.resolve(v).(pdfArray)
+	return arr
+}
+
+// number resolves v and returns it as a float64.
+func (d *pdfDocument) number(v any) (float64, bool) {
+	switch n := d.resolve(v).(type) {
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// title returns the document title from the info dictionary.
+func (d *pdfDocument) title() string {
+	info := d.dict(d.trailer["Info"])
+	s, _ := d.resolve(info["Title"]).(pdfString)
+	return decodePDFTextString(s)
+}
+
+// decodeStream returns the decoded contents of s.
+func (d *pdfDocument) decodeStream(s *pdfStream) ([]byte, error) {
+	var filters []any
+	var params []any
+	switch f := d.resolve(s.dict["Filter"]).(type) {
+	case pdfName:
+		filters = pdfArray{f}
+		params = pdfArray{s.dict["DecodeParms"]}
+	case pdfArray:
+		filters = f
+		params = d.array(s.dict["DecodeParms"])
+	}
+
+	data := s.raw
+	for i, f := range filters {
+		name, _ := d.resolve(f).(pdfName)
+		var p pdfDict
+		if i < len(params) {
+			p = d.dict(params[i])
+		}
+		var err error
+		if data, err = d.applyFilter(name, data, p); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+func (d *pdfDocument) applyFilter(name pdfName, data []byte, params pdfDict) ([]byte, error) {
+	switch name {
+	case "FlateDecode", "Fl":
+		out, err := d.inflate(data)
+		if err != nil {
+			return nil, err
+		}
+		return d.applyPredictor(out, params), nil
+	case "ASCIIHexDecode", "AHx":
+		return []byte((&pdfLexer{data: append([]byte{'<'}, data...)}).readHexString()), nil
+	case "ASCII85Decode", "A85":
+		return decodeASCII85(data)
+	case "Crypt":
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unsupported PDF filter %s", name)
+	}
+}
+
+// inflate decompresses zlib data, tolerating a missing header and
+// truncated or corrupt trailing data. Output past the document's budget is
+// cut off, and once the budget is spent every stream fails.
+func (d *pdfDocument) inflate(data []byte) ([]byte, error) {
+	budget := maxPDFDecodedSize - d.inflated
+	if budget <= 0 {
+		return nil, errPDFTooLarge
+	}
+	var r io.Reader
+	if zr, err := zlib.NewReader(bytes.NewReader(data)); err == nil {
+		r = zr
+	} else {
+		r = flate.NewReader(bytes.NewReader(data))
+	}
+	out, err := io.ReadAll(io.LimitReader(r, budget))
+	d.inflated += int64(len(out))
+	if err != nil && len(out) == 0 {
+		return nil, fmt.Errorf("inflating stream: %w", err)
+	}
+	return out, nil
+}
+
+// applyPredictor reverses the PNG predictors used mostly by cross-reference
+// streams. TIFF predictors are left as they are.
+func (d *pdfDocument) applyPredictor(data []byte, params pdfDict) []byte {
+	intParam := func(key pdfName, def int) int {
+		if v, ok := d.resolve(params[key]).(int); ok && v > 0 {
+			return v
+		}
+		return def
+	}
+	if intParam("Predictor", 1) < 10 {
+		return data
+	}
+	colors := intParam("Colors", 1)
+	bpc := intParam("BitsPerComponent", 8)
+	columns := intParam("Columns", 1)
+	bpp := max(1, colors*bpc/8)
+	rowLen := (colors*bpc*columns + 7) / 8
+
+	out := make([]byte, 0, len(data))
+	prev := make([]byte, rowLen)
+	for i := 0; i+1+rowLen <= len(data); i += rowLen + 1 {
+		filter := data[i]
+		row := append([]byte(nil), data[i+1:i+1+rowLen]...)
+		for j := range row {
+			var left, upLeft byte
+			if j >= bpp {
+				left, upLeft = row[j-bpp], prev[j-bpp]
+			}
+			switch filter {
+			case 1:
+				row[j] += left
+			case 2:
+				row[j] += prev[j]
+			case 3:
+				row[j] += byte((int(left) + int(prev[j])) / 2)
+			case 4:
+				row[j] += paeth(left, prev[j], upLeft)
+			}
+		}
+		out = append(out, row...)
+		prev = row
+	}
+	return out
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	switch {
+	case pa <= pb && pa <= pc:
+		return a
+	case pb <= pc:
+		return b
+	}
+	return c
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func decodeASCII85(data []byte) ([]byte, error) {
+	data = bytes.TrimPrefix(bytes.TrimSpace(data), []byte("<~"))
+	if i := bytes.Index(data, []byte("~>")); i >= 0 {
+		data = data[:i]
+	}
+	out := make([]byte, 4*len(data)+4)
+	n, _, err := ascii85.Decode(out, data, true)
+	if err != nil {
+		return nil, fmt.Errorf("decoding ASCII85: %w", err)
+	}
+	return out[:n], nil
+}
+
+// decodePDFTextString decodes a text string such as a document title:
+// UTF-16BE or UTF-8 with a byte order mark, else PDFDocEncoding.
+func decodePDFTextString(s pdfString) string {
+	b := []byte(s)
+	switch {
+	case bytes.HasPrefix(b, []byte{0xFE, 0xFF}):
+		return utf16BEString(b[2:])
+	case bytes.HasPrefix(b, []byte{0xEF, 0xBB, 0xBF}):
+		return string(b[3:])
+	}
+	runes := make([]rune, 0, len(b))
+	for _, c := range b {
+		if r, ok := pdfDocEncoding[c]; ok {
+			runes = append(runes, r)
+		} else {
+			runes = append(runes, rune(c))
+		}
+	}
+	return string(runes)
+}
+
+// pdfDocEncoding lists where PDFDocEncoding differs from Latin-1.
+var pdfDocEncoding = map[byte]rune{
+	0x18: '˘', 0x19: 'ˇ', 0x1A: 'ˆ', 0x1B: '˙', 0x1C: '˝', 0x1D: '˛', 0x1E: '˚', 0x1F: '˜',
+	0x80: '•', 0x81: '†', 0x82: '‡', 0x83: '…', 0x84: '—', 0x85: '–', 0x86: 'ƒ', 0x87: '⁄',
+	0x88: '‹', 0x89: '›', 0x8A: '−', 0x8B: '‰', 0x8C: '„', 0x8D: '“', 0x8E: '”', 0x8F: '‘',
+	0x90: '’', 0x91: '‚', 0x92: '™', 0x93: 'ﬁ', 0x94: 'ﬂ', 0x95: 'Ł', 0x96: 'Œ', 0x97: 'Š',
+	0x98: 'Ÿ', 0x99: 'Ž', 0x9A: 'ı', 0x9B: 'ł', 0x9C: 'œ', 0x9D: 'š', 0x9E: 'ž', 0xA0: '€',
+}
+
+// utf16BEString decodes big-endian UTF-16. A trailing odd byte is dropped.
+func utf16BEString(b []byte) string {
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+	}
+	return string(utf16.Decode(units))
+}
@@ -0,0 +1,308 @@
+package extractor
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testPDF assembles PDF files from object bodies. Object n is objs[n-1].
+type testPDF struct {
+	objs []string
+}
+
+func (p *testPDF) add(body string) int {
+	p.objs = append(p.objs, body)
+	return len(p.objs)
+}
+
+// addStream adds a Flate-compressed stream with the extra dictionary entries in dict.
+func (p *testPDF) addStream(dict string, data []byte) int {
+	return p.add(flateStream(dict, data))
+}
+
+func flateStream(dict string, data []byte) string {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(data)
+	zw.Close()
+	return fmt.Sprintf("<<%s /Filter /FlateDecode /Length %d>>\nstream\n%s\nendstream", dict, buf.Len(), buf.Bytes())
+}
+
+// file returns the PDF with a classic cross-reference table.
+func (p *testPDF) file(trailer string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(p.objs))
+	for i, body := range p.objs {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, body)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(p.objs)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<</Size %d %s>>\nstartxref\n%d\n%%%%EOF\n", len(p.objs)+1, trailer, xref)
+	return buf.Bytes()
+}
+
+// compressedFile returns the PDF with every object but the streams packed
+// into an object stream, indexed by a PNG-predicted cross-reference stream.
+func (p *testPDF) compressedFile(trailer string) []byte {
+	stmNum := len(p.objs) + 1
+	xrefNum := stmNum + 1
+
+	var header, bodies bytes.Buffer
+	index := make(map[int]int)
+	for i, body := range p.objs {
+		if strings.Contains(body, "\nstream\n") {
+			continue
+		}
+		index[i+1] = len(index)
+		fmt.Fprintf(&header, "%d %d ", i+1, bodies.Len())
+		bodies.WriteString(body + "\n")
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n")
+	offsets := make(map[int]int)
+	for i, body := range p.objs {
+		if _, ok := index[i+1]; !ok {
+			offsets[i+1] = buf.Len()
+			fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, body)
+		}
+	}
+	offsets[stmNum] = buf.Len()
+	stm := flateStream(fmt.Sprintf("/Type /ObjStm /N %d /First %d", len(index), header.Len()),
+		append(header.Bytes(), bodies.Bytes()...))
+	fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", stmNum, stm)
+	offsets[xrefNum] = buf.Len()
+
+	// Rows of W [1 4 2], each behind a PNG Up filter byte.
+	var rows []byte
+	prev := make([]byte, 7)
+	for num := 0; num <= xrefNum; num++ {
+		row := make([]byte, 7)
+		if i, ok := index[num]; ok {
+			row[0], row[3], row[4], row[6] = 2, byte(stmNum>>8), byte(stmNum), byte(i)
+		} else if off, ok := offsets[num]; ok {
+			row[0], row[1], row[2], row[3], row[4] = 1, byte(off>>24), byte(off>>16), byte(off>>8), byte(off)
+		}
+		rows = append(rows, 2)
+		for j := range row {
+			rows = append(rows, row[j]-prev[j])
+		}
+		prev = row
+	}
+	xs := flateStream(fmt.Sprintf("/Type /XRef /Size %d /W [1 4 2] /DecodeParms <</Predictor 12 /Columns 7>> %s",
+		xrefNum+1, trailer), rows)
+	fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\nstartxref\n%d\n%%%%EOF\n", xrefNum, xs, offsets[xrefNum])
+	return buf.Bytes()
+}
+
+func TestPDFLexer_ReadObject(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  any
+	}{
+		{"integer", "42", 42},
+		{"real", "-.5", -0.5},
+		{"name with escape", "/A#20B", pdfName("A B")},
+		{"literal string", `(a (nested) \(x\) \101\n)`, pdfString("a (nested) (x) A\n")},
+		{"line continuation", "(ab\\\ncd)", pdfString("abcd")},
+		{"hex string", "<48 65 6C6C 6>", pdfString("Hell`")},
+		{"array", "[1 /N (s) true null]", pdfArray{1, pdfName("N"), pdfString("s"), true, nil}},
+		{"dict with reference", "<</Root 1 0 R /Size 3>>", pdfDict{"Root": pdfRef{num: 1}, "Size": 3}},
+		{"keyword", "endobj", pdfKeyword("endobj")},
+		{"comment skipped", "% comment\n7", 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &pdfLexer{data: []byte(tt.input)}
+			got, err := l.readObject()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readObject() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPDFLexer_NoRefs(t *testing.T) {
+	// "0 0 1 RG" in a content stream is a colour, not a reference.
+	l := &pdfLexer{data: []byte("1 0 R 0 0 1 RG"), noRefs: true}
+	var got []any
+	for {
+		obj, err := l.readObject()
+		if err != nil {
+			break
+		}
+		got = append(got, obj)
+	}
+	want := []any{1, 0, pdfKeyword("R"), 0, 0, 1, pdfKeyword("RG")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("objects = %#v, want %#v", got, want)
+	}
+}
+
+func simpleDoc() *testPDF {
+	p := &testPDF{}
+	p.add("<</Type /Catalog /Pages 2 0 R>>")
+	p.add("<</Type /Pages /Kids [3 0 R] /Count 1>>")
+	p.add("<</Type /Page /Parent 2 0 R /Contents 4 0 R /Resources <</Font <</F1 5 0 R>>>>>>")
+	p.addStream("", []byte("BT /F1 12 Tf 72 700 Td (Compressed text) Tj ET"))
+	p.add("<</Type /Font /Subtype /Type1 /BaseFont /Helvetica>>")
+	p.add("<</Title <FEFF004400e9006a00e0>>>")
+	return p
+}
+
+func TestOpenPDF(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      []byte
+		wantTitle string
+	}{
+		{"xref table", simpleDoc().file("/Root 1 0 R /Info 6 0 R"), "Déjà"},
+		{"xref and object streams", simpleDoc().compressedFile("/Root 1 0 R /Info 6 0 R"), "Déjà"},
+		{"damaged xref", bytes.Replace(simpleDoc().file("/Root 1 0 R /Info 6 0 R"), []byte("startxref\n"), []byte("startxref\n9"), 1), "Déjà"},
+		// Without a trailer the catalog is found by scanning; the info
+		// dictionary is lost.
+		{"no xref or trailer", func() []byte {
+			data := simpleDoc().file("/Root 1 0 R /Info 6 0 R")
+			return data[:bytes.Index(data, []byte("xref\n0"))]
+		}(), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := openPDF(tt.data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := doc.title(); got != tt.wantTitle {
+				t.Errorf("title() = %q, want %q", got, tt.wantTitle)
+			}
+			if got := doc.text(); got != "Compressed text" {
+				t.Errorf("text() = %q, want %q", got, "Compressed text")
+			}
+		})
+	}
+}
+
+func TestOpenPDF_IncrementalUpdate(t *testing.T) {
+	base := simpleDoc().file("/Root 1 0 R /Info 6 0 R")
+	prev := bytes.LastIndex(base, []byte("xref\n0"))
+
+	var buf bytes.Buffer
+	buf.Write(base)
+	off := buf.Len()
+	buf.WriteString("6 0 obj\n<</Title (Revised)>>\nendobj\n")
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n6 1\n%010d 00000 n \ntrailer\n<</Size 7 /Root 1 0 R /Info 6 0 R /Prev %d>>\nstartxref\n%d\n%%%%EOF\n", off, prev, xref)
+
+	doc, err := openPDF(buf.Bytes())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := doc.title(); got != "Revised" {
+		t.Errorf("title() = %q, want the updated %q", got, "Revised")
+	}
+	if got := doc.text(); got != "Compressed text" {
+		t.Errorf("text() = %q, want objects from the original section", got)
+	}
+}
+
+func TestOpenPDF_Errors(t *testing.T) {
+	if _, err := openPDF([]byte("<html></html>")); err == nil {
+		t.Error("expected error for non-PDF data")
+	}
+	if _, err := openPDF([]byte("%PDF-1.4\n%%EOF")); err == nil {
+		t.Error("expected error for a PDF without catalog")
+	}
+
+	p := simpleDoc()
+	p.add("<</Filter /Standard /V 2 /R 3>>")
+	_, err := openPDF(p.file("/Root 1 0 R /Encrypt 7 0 R"))
+	if !errors.Is(err, errPDFEncrypted) {
+		t.Errorf("error = %v, want errPDFEncrypted", err)
+	}
+}
+
+func TestPDFDocument_DecodeStream(t *testing.T) {
+	d := &pdfDocument{}
+	d.reset()
+	tests := []struct {
+		name    string
+		dict    pdfDict
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{"unfiltered", pdfDict{}, "plain", "plain", false},
+		{"ASCIIHex", pdfDict{"Filter": pdfName("ASCIIHexDecode")}, "48 69>", "Hi", false},
+		{"ASCII85", pdfDict{"Filter": pdfName("ASCII85Decode")}, "<~87cURDZ~>", "Hello", false},
+		{"filter chain", pdfDict{"Filter": pdfArray{pdfName("AHx"), pdfName("A85")}}, "3837635552445a7e3e>", "Hello", false},
+		{"unsupported", pdfDict{"Filter": pdfName("DCTDecode")}, "\xff\xd8", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := d.decodeStream(&pdfStream{dict: tt.dict, raw: []byte(tt.raw)})
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("decodeStream() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPDFDocument_InflateBudget(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(bytes.Repeat([]byte("BT (x) Tj ET\n"), 100))
+	zw.Close()
+	s := &pdfStream{dict: pdfDict{"Filter": pdfName("FlateDecode")}, raw: buf.Bytes()}
+
+	d := &pdfDocument{}
+	d.reset()
+	// Earlier streams have used all but 10 bytes of the budget.
+	d.inflated = maxPDFDecodedSize - 10
+	got, err := d.decodeStream(s)
+	if err != nil || len(got) != 10 {
+		t.Fatalf("decodeStream() = %d bytes, %v; want the 10 left", len(got), err)
+	}
+	if _, err := d.decodeStream(s); !errors.Is(err, errPDFTooLarge) {
+		t.Errorf("decodeStream() past the budget = %v, want errPDFTooLarge", err)
+	}
+}
+
+func TestDecodePDFTextString(t *testing.T) {
+	tests := []struct {
+		input pdfString
+		want  string
+	}{
+		{"Plain", "Plain"},
+		{"\xfe\xff\xd5\x5c\xae\x00", "한글"},
+		{"\xef\xbb\xbfUTF-8 \xc3\xa9", "UTF-8 é"},
+		{"caf\xe9 \x93", "café ﬁ"},
+	}
+	for _, tt := range tests {
+		if got := decodePDFTextString(tt.input); got != tt.want {
+			t.Errorf("decodePDFTextString(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
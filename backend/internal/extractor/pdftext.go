@@ -0,0 +1,410 @@
+package extractor
+
+import (
+	"math"
+	"strings"
+)
+
+// Text is taken from page content streams in the order it is drawn, which
+// for the files produced by word processors and TeX is reading order, also
+// across columns. Glyph positions decide where words and lines break, since
+// many producers position words individually instead of drawing spaces.
+
+// Thresholds for breaking text, relative to the font size.
+const (
+	pdfWordGap      = 0.15 // horizontal gap that separates words
+	pdfLineShift    = 0.5  // vertical shift that starts a new line
+	pdfParagraphGap = 1.8  // vertical shift that starts a new paragraph
+	pdfMaxFormDepth = 8    // nesting limit for form XObjects
+)
+
+// pdfMatrix is an affine transform [a b c d e f].
+type pdfMatrix [6]float64
+
+var pdfIdentity = pdfMatrix{1, 0, 0, 1, 0, 0}
+
+// mul returns m × n, that is m applied first.
+func (m pdfMatrix) mul(n pdfMatrix) pdfMatrix {
+	return pdfMatrix{
+		m[0]*n[0] + m[1]*n[2],
+		m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2],
+		m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4],
+		m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+func pdfTranslate(x, y float64) pdfMatrix {
+	return pdfMatrix{1, 0, 0, 1, x, y}
+}
+
+// pdfGState is the part of the graphics state that affects text placement.
+type pdfGState struct {
+	ctm       pdfMatrix
+	font      *pdfFont
+	fontSize  float64
+	charSpace float64
+	wordSpace float64
+	hScale    float64
+	leading   float64
+	rise      float64
+}
+
+// pdfPage is a page and the resources it inherits.
+type pdfPage struct {
+	dict      pdfDict
+	resources pdfDict
+}
+
+// text returns the text of every page, in page order.
+func (d *pdfDocument) text() string {
+	var w pdfTextWriter
+	for _, p := range d.pages() {
+		w.breakPage()
+		d.runContent(&w, d.pageContents(p.dict), p.resources, pdfIdentity, 0)
+	}
+	return tidyPDFText(w.sb.String())
+}
+
+// pages walks the page tree.
+func (d *pdfDocument) pages() []pdfPage {
+	var pages []pdfPage
+	seen := make(map[pdfRef]bool)
+	var walk func(v any, res pdfDict, depth int)
+	walk = func(v any, res pdfDict, depth int) {
+		if ref, ok := v.(pdfRef); ok {
+			if seen[ref] {
+				return
+			}
+			seen[ref] = true
+		}
+		node := d.dict(v)
+		if node == nil || depth > 64 {
+			return
+		}
+		if r := d.dict(node["Resources"]); r != nil {
+			res = r
+		}
+		if kids := d.array(node["Kids"]); kids != nil && node["Type"] != pdfName("Page") {
+			for _, kid := range kids {
+				walk(kid, res, depth+1)
+			}
+			return
+		}
+		pages = append(pages, pdfPage{dict: node, resources: res})
+	}
+	walk(d.catalog()["Pages"], nil, 0)
+	return pages
+}
+
+// pageContents returns the decoded content streams of page, joined.
+func (d *pdfDocument) pageContents(page pdfDict) []byte {
+	var streams []any
+	switch c := d.resolve(page["Contents"]).(type) {
+	case *pdfStream:
+		streams = []any{c}
+	case pdfArray:
+		streams = c
+	}
+	var out []byte
+	for _, v := range streams {
+		s, ok := d.resolve(v).(*pdfStream)
+		if !ok {
+			continue
+		}
+		data, err := d.decodeStream(s)
+		if err != nil {
+			continue
+		}
+		out = append(out, data...)
+		out = append(out, '\n')
+	}
+	return out
+}
+
+// runContent interprets the text operators of a content stream.
+func (d *pdfDocument) runContent(w *pdfTextWriter, data []byte, res pdfDict, ctm pdfMatrix, depth int) {
+	gs := pdfGState{ctm: ctm, font: pdfFallbackFont, hScale: 1}
+	var stack []pdfGState
+	tm, tlm := pdfIdentity, pdfIdentity
+	var operands []any
+
+	num := func(i int) float64 {
+		if i >= len(operands) {
+			return 0
+		}
+		switch v := operands[i].(type) {
+		case int:
+			return float64(v)
+		case float64:
+			return v
+		}
+		return 0
+	}
+	matrix := func() pdfMatrix {
+		return pdfMatrix{num(0), num(1), num(2), num(3), num(4), num(5)}
+	}
+	nextLine := func(tx, ty float64) {
+		tlm = pdfTranslate(tx, ty).mul(tlm)
+		tm = tlm
+	}
+	show := func(v any) {
+		if s, ok := v.(pdfString); ok {
+			d.showText(w, &gs, &tm, s)
+		}
+	}
+
+	l := &pdfLexer{data: data, noRefs: true}
+	for {
+		obj, err := l.readObject()
+		if err != nil {
+			return
+		}
+		op, ok := obj.(pdfKeyword)
+		if !ok {
+			operands = append(operands, obj)
+			continue
+		}
+
+		switch op {
+		case "q":
+			stack = append(stack, gs)
+		case "Q":
+			if n := len(stack); n > 0 {
+				gs, stack = stack[n-1], stack[:n-1]
+			}
+		case "cm":
+			if len(operands) == 6 {
+				gs.ctm = matrix().mul(gs.ctm)
+			}
+		case "BT":
+			tm, tlm = pdfIdentity, pdfIdentity
+		case "Tf":
+			if len(operands) == 2 {
+				name, _ := operands[0].(pdfName)
+				gs.font = d.font(res, name)
+				gs.fontSize = num(1)
+			}
+		case "Tc":
+			gs.charSpace = num(0)
+		case "Tw":
+			gs.wordSpace = num(0)
+		case "Tz":
+			gs.hScale = num(0) / 100
+		case "TL":
+			gs.leading = num(0)
+		case "Ts":
+			gs.rise = num(0)
+		case "Td":
+			nextLine(num(0), num(1))
+		case "TD":
+			gs.leading = -num(1)
+			nextLine(num(0), num(1))
+		case "Tm":
+			if len(operands) == 6 {
+				tlm = matrix()
+				tm = tlm
+			}
+		case "T*":
+			nextLine(0, -gs.leading)
+		case "Tj":
+			if len(operands) > 0 {
+				show(operands[0])
+			}
+		case "'":
+			nextLine(0, -gs.leading)
+			if len(operands) > 0 {
+				show(operands[0])
+			}
+		case "\"":
+			if len(operands) == 3 {
+				gs.wordSpace, gs.charSpace = num(0), num(1)
+				nextLine(0, -gs.leading)
+				show(operands[2])
+			}
+		case "TJ":
+			if len(operands) == 0 {
+				break
+			}
+			arr, _ := operands[0].(pdfArray)
+			for _, v := range arr {
+				switch v := v.(type) {
+				case pdfString:
+					show(v)
+				case int, float64:
+					adj, _ := d.number(v)
+					tm = pdfTranslate(-adj/1000*gs.fontSize*gs.hScale, 0).mul(tm)
+				}
+			}
+		case "Do":
+			if len(operands) == 1 && depth < pdfMaxFormDepth {
+				name, _ := operands[0].(pdfName)
+				d.runForm(w, res, name, gs.ctm, depth)
+			}
+		case "BI":
+			l.skipInlineImage()
+		}
+		operands = operands[:0]
+	}
+}
+
+// runForm runs the form XObject named name, if it is one.
+func (d *pdfDocument) runForm(w *pdfTextWriter, res pdfDict, name pdfName, ctm pdfMatrix, depth int) {
+	s, ok := d.resolve(d.dict(res["XObject"])[name]).(*pdfStream)
+	if !ok || s.dict["Subtype"] != pdfName("Form") {
+		return
+	}
+	data, err := d.decodeStream(s)
+	if err != nil {
+		return
+	}
+	m := pdfIdentity
+	if arr := d.array(s.dict["Matrix"]); len(arr) == 6 {
+		for i, v := range arr {
+			m[i], _ = d.number(v)
+		}
+	}
+	formRes := d.dict(s.dict["Resources"])
+	if formRes == nil {
+		formRes = res
+	}
+	d.runContent(w, data, formRes, m.mul(ctm), depth+1)
+}
+
+// showText writes the glyphs of s and advances the text matrix past them.
+func (d *pdfDocument) showText(w *pdfTextWriter, gs *pdfGState, tm *pdfMatrix, s pdfString) {
+	scaled := func() pdfMatrix {
+		return pdfMatrix{gs.fontSize * gs.hScale, 0, 0, gs.fontSize, 0, gs.rise}.mul(*tm).mul(gs.ctm)
+	}
+	for _, g := range gs.font.decode([]byte(s)) {
+		start := scaled()
+		tx := (g.width*gs.fontSize + gs.charSpace) * gs.hScale
+		if g.space {
+			tx += gs.wordSpace * gs.hScale
+		}
+		*tm = pdfTranslate(tx, 0).mul(*tm)
+		end := scaled()
+
+		w.glyph(g.text, pdfGlyphPos{
+			x: start[4], y: start[5],
+			endX: end[4], endY: end[5],
+			dirX: start[0], dirY: start[1],
+			size: math.Hypot(start[2], start[3]),
+		})
+	}
+}
+
+// pdfGlyphPos is the placement of a glyph in device space.
+type pdfGlyphPos struct {
+	x, y       float64 // origin
+	endX, endY float64 // origin of the next glyph
+	dirX, dirY float64 // baseline direction, not normalized
+	size       float64 // font size
+}
+
+// Breaks between text, from weakest to strongest.
+const (
+	pdfNoBreak = iota
+	pdfSpaceBreak
+	pdfLineBreak
+	pdfParagraphBreak
+)
+
+// pdfTextWriter assembles glyphs into lines and paragraphs.
+type pdfTextWriter struct {
+	sb      strings.Builder
+	last    pdfGlyphPos
+	hasLast bool
+	pending int
+}
+
+// breakPage starts a new page: a paragraph break, and no geometric
+// comparison with the previous glyph.
+func (w *pdfTextWriter) breakPage() {
+	w.pending = pdfParagraphBreak
+	w.hasLast = false
+}
+
+// glyph adds a glyph's text at pos. Glyphs without text still move the
+// position that the next glyph is compared with.
+func (w *pdfTextWriter) glyph(text string, pos pdfGlyphPos) {
+	if w.hasLast {
+		w.pending = max(w.pending, w.breakBefore(pos))
+	}
+	w.last, w.hasLast = pos, true
+
+	if strings.TrimSpace(text) == "" {
+		if text != "" {
+			w.pending = max(w.pending, pdfSpaceBreak)
+		}
+		return
+	}
+	if w.sb.Len() > 0 {
+		switch w.pending {
+		case pdfSpaceBreak:
+			w.sb.WriteByte(' ')
+		case pdfLineBreak:
+			w.sb.WriteByte('\n')
+		case pdfParagraphBreak:
+			w.sb.WriteString("\n\n")
+		}
+	}
+	w.pending = pdfNoBreak
+	w.sb.WriteString(text)
+}
+
+// breakBefore decides how pos relates to the end of the previous glyph,
+// measuring along and across the previous glyph's baseline.
+func (w *pdfTextWriter) breakBefore(pos pdfGlyphPos) int {
+	prev := w.last
+	size := max(prev.size, pos.size)
+	if size == 0 {
+		return pdfNoBreak
+	}
+	ux, uy := prev.dirX, prev.dirY
+	if n := math.Hypot(ux, uy); n > 0 {
+		ux, uy = ux/n, uy/n
+	} else {
+		ux, uy = 1, 0
+	}
+	dx, dy := pos.x-prev.endX, pos.y-prev.endY
+	along := dx*ux + dy*uy
+	across := math.Abs(dy*ux - dx*uy)
+
+	switch {
+	case across > pdfParagraphGap*size:
+		return pdfParagraphBreak
+	case across > pdfLineShift*size:
+		return pdfLineBreak
+	case along > pdfWordGap*size || along < -size:
+		return pdfSpaceBreak
+	}
+	return pdfNoBreak
+}
+
+var pdfLigatures = strings.NewReplacer(
+	"ﬀ", "ff", "ﬁ", "fi", "ﬂ", "fl", "ﬃ", "ffi", "ﬄ", "ffl",
+	"\u00ad", "", "\x00", "",
+)
+
+// tidyPDFText spells out ligatures, collapses runs of spaces and keeps at
+// most one blank line between paragraphs.
+func tidyPDFText(s string) string {
+	s = pdfLigatures.Replace(s)
+	var out []string
+	blank := false
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			blank = len(out) > 0
+			continue
+		}
+		if blank {
+			out = append(out, "")
+			blank = false
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,150 @@
+package extractor
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// buildTextPDF returns a PDF with one page per content stream. fonts adds
+// the font objects and returns the /Font resource dictionary, which the
+// pages inherit from the page tree.
+func buildTextPDF(fonts func(*testPDF) string, contents ...string) []byte {
+	p := &testPDF{}
+	catalog := p.add("<</Type /Catalog /Pages 2 0 R>>")
+	p.add("")
+	res := fonts(p)
+
+	var kids []string
+	for _, c := range contents {
+		content := p.addStream("", []byte(c))
+		page := p.add(fmt.Sprintf("<</Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents %d 0 R>>", content))
+		kids = append(kids, fmt.Sprintf("%d 0 R", page))
+	}
+	p.objs[1] = fmt.Sprintf("<</Type /Pages /Kids [%s] /Count %d /Resources <</Font %s>>>>",
+		strings.Join(kids, " "), len(kids), res)
+	return p.file(fmt.Sprintf("/Root %d 0 R", catalog))
+}
+
+func helvetica(p *testPDF) string {
+	return fmt.Sprintf("<</F1 %d 0 R>>", p.add("<</Type /Font /Subtype /Type1 /BaseFont /Helvetica>>"))
+}
+
+func TestPDFDocument_Text(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents []string
+		want     string
+	}{
+		{
+			name:     "kerning joins, wide gaps separate words",
+			contents: []string{"BT /F1 10 Tf 72 700 Td [(Hel) -30 (lo) -250 (world)] TJ ET"},
+			want:     "Hello world",
+		},
+		{
+			name:     "words positioned individually",
+			contents: []string{"BT /F1 10 Tf 72 700 Td (One) Tj 20 0 Td (two) Tj ET"},
+			want:     "One two",
+		},
+		{
+			name: "lines and paragraphs",
+			contents: []string{"BT /F1 10 Tf 12 TL 72 700 Td (First line) Tj T* (second line) Tj " +
+				"0 -40 Td (New paragraph) Tj ET"},
+			want: "First line\nsecond line\n\nNew paragraph",
+		},
+		{
+			name: "two columns in drawing order",
+			contents: []string{"BT /F1 10 Tf 72 700 Td (Left top) Tj 0 -12 Td (left bottom) Tj ET " +
+				"BT /F1 10 Tf 320 700 Td (Right top) Tj ET"},
+			want: "Left top\nleft bottom\nRight top",
+		},
+		{
+			name:     "text matrix and scaling",
+			contents: []string{"q 2 0 0 2 0 0 cm BT /F1 5 Tf 1 0 0 1 36 350 Tm (Scaled) Tj ( text) Tj ET Q"},
+			want:     "Scaled text",
+		},
+		{
+			name:     "inline image skipped",
+			contents: []string{"BT /F1 10 Tf 72 700 Td (Before) Tj ET BI /W 2 /H 1 /BPC 8 /CS /G ID \x00EI)\xff EI BT /F1 10 Tf 72 688 Td (after) Tj ET"},
+			want:     "Before\nafter",
+		},
+		{
+			name:     "pages",
+			contents: []string{"BT /F1 10 Tf 72 700 Td (Page one) Tj ET", "BT /F1 10 Tf 72 700 Td (Page two) Tj ET"},
+			want:     "Page one\n\nPage two",
+		},
+		{
+			name:     "ligatures spelled out",
+			contents: []string{"BT /F1 10 Tf 72 700 Td (\\256nd) Tj ET"},
+			want:     "find",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := openPDF(buildTextPDF(helvetica, tt.contents...))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := doc.text(); got != tt.want {
+				t.Errorf("text() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPDFDocument_Text_CIDFont(t *testing.T) {
+	// A subset CID font as produced for Korean text: Identity-H codes that
+	// are glyph ids, readable only through the ToUnicode CMap.
+	fonts := func(p *testPDF) string {
+		toUnicode := p.addStream("", []byte(`begincmap
+1 begincodespacerange <0000> <FFFF> endcodespacerange
+5 beginbfchar
+<0001> <C548> <0002> <B155> <0003> <D558> <0004> <C138> <0005> <C694>
+endbfchar
+1 beginbfrange <0006> <0007> [<C138> <ACC4>] endbfrange
+endcmap`))
+		desc := p.add("<</Type /Font /Subtype /CIDFontType2 /BaseFont /ABCDEF+NanumGothic /DW 1000 /W [1 [920 920]]>>")
+		font := p.add(fmt.Sprintf("<</Type /Font /Subtype /Type0 /BaseFont /ABCDEF+NanumGothic /Encoding /Identity-H "+
+			"/DescendantFonts [%d 0 R] /ToUnicode %d 0 R>>", desc, toUnicode))
+		return fmt.Sprintf("<</K1 %d 0 R>>", font)
+	}
+	content := "BT /K1 11 Tf 72 700 Td <0001000200030004> Tj [<0005>] TJ 60 0 Td <00060007> Tj ET"
+
+	doc, err := openPDF(buildTextPDF(fonts, content))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := doc.text(), "안녕하세요 세계"; got != want {
+		t.Errorf("text() = %q, want %q", got, want)
+	}
+}
+
+func TestPDFDocument_Text_FormXObject(t *testing.T) {
+	p := &testPDF{}
+	p.add("<</Type /Catalog /Pages 2 0 R>>")
+	p.add("<</Type /Pages /Kids [3 0 R] /Count 1>>")
+	p.add("<</Type /Page /Parent 2 0 R /Contents 4 0 R /Resources <</XObject <</Fm1 5 0 R>>>>>>")
+	p.addStream("", []byte("BT /F1 10 Tf 72 700 Td (ignored: no font yet) Tj ET q 1 0 0 1 0 -100 cm /Fm1 Do Q"))
+	p.addStream("/Type /XObject /Subtype /Form /BBox [0 0 612 792] /Resources <</Font <</F1 6 0 R>> /XObject <</Fm1 5 0 R>>>>",
+		[]byte("BT /F1 10 Tf 72 700 Td (Inside the form) Tj ET /Fm1 Do"))
+	p.add("<</Type /Font /Subtype /Type1 /BaseFont /Helvetica>>")
+
+	doc, err := openPDF(p.file("/Root 1 0 R"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := doc.text()
+	if !strings.Contains(got, "Inside the form") {
+		t.Errorf("text() = %q, want the form's text", got)
+	}
+	if n := strings.Count(got, "Inside the form"); n != pdfMaxFormDepth {
+		t.Errorf("self-referencing form ran %d times, want the depth limit %d", n, pdfMaxFormDepth)
+	}
+}
+
+func TestTidyPDFText(t *testing.T) {
+	got := tidyPDFText("\n  a   b \n\n\n\nc\u00ad\x00d ﬂ\n\n")
+	if want := "a b\n\ncd fl"; got != want {
+		t.Errorf("tidyPDFText() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,640 @@
+package extractor
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// The main content of a page is found the way Readability finds it. Markup
+// that is never content (scripts, navigation, hidden elements) and elements
+// whose class or id mark them as page furniture (sidebars, cookie banners,
+// comment sections) are removed first. Every paragraph then scores its
+// ancestors by the amount of prose it holds; tags, class and id names and
+// semantic hints such as <article> nudge the scores, and links dilute them.
+// The best scoring element, with any siblings that look like part of the
+// same article, is the content.
+
+var (
+	// unlikelyRe matches class and id names of page furniture.
+	unlikelyRe = regexp.MustCompile(`(?i)-ad-|(^|\s)ads?([-_\s]|$)|ad-break|adbox|advert|banner|breadcrumb|combx|comment|community|consent|cookie|disqus|footer|gdpr|header|menu|modal|newsletter|pager|pagination|popup|promo|related|remark|replies|reply|recommend|rss|share|sharing|shoutbox|sidebar|skyscraper|social|sponsor|subscribe|toolbar|vote|widget`)
+	// maybeRe rescues elements that unlikelyRe matches but may hold content,
+	// such as "post-comment-count" inside "post-body".
+	maybeRe = regexp.MustCompile(`(?i)article|body|column|content|main|post|entry|story|text|blog`)
+	// widgetRe matches the widgets blog platforms insert into the article
+	// body under names maybeRe would rescue: Jetpack's sharing, likes and
+	// related posts (like-post-wrapper, jp-relatedposts) and WordAds.
+	widgetRe = regexp.MustCompile(`(?i)sharedaddy|jp-relatedposts|jetpack-likes|wordads`)
+	// positiveRe and negativeRe adjust the score of class and id names.
+	// Besides the usual names they cover the containers of Korean blog
+	// platforms: Tistory (tt_article_useless_p_margin, article-view),
+	// Naver Blog (se-main-container, post-view) and Brunch (wrap_body).
+	positiveRe = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|post|text|blog|story|tt_article|article-view|se-main-container|post-view|wrap_body`)
+	negativeRe = regexp.MustCompile(`(?i)-ad-|(^|\s)ads?([-_\s]|$)|hidden|^hid$|\shid$|^hid\s|banner|byline|combx|comment|com-|contact|cookie|consent|footer|gdpr|masthead|media|meta|outbrain|promo|related|reply|recommend|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|subscribe|newsletter|tags|tool|widget`)
+)
+
+// removedTags are elements that never hold article text.
+var removedTags = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Iframe: true, atom.Svg: true, atom.Canvas: true, atom.Object: true,
+	atom.Embed: true, atom.Nav: true, atom.Aside: true, atom.Footer: true,
+	atom.Header: true, atom.Button: true, atom.Select: true, atom.Input: true,
+	atom.Textarea: true, atom.Dialog: true, atom.Link: true, atom.Meta: true,
+}
+
+// removedRoles are ARIA roles of page furniture.
+var removedRoles = map[string]bool{
+	"navigation": true, "banner": true, "contentinfo": true, "complementary": true,
+	"dialog": true, "alertdialog": true, "menu": true, "menubar": true, "search": true,
+}
+
+// Minimum sizes, in Latin-equivalent characters (see textLen).
+const (
+	minParagraphLen = 25  // shorter paragraphs do not score
+	minContentLen   = 140 // shorter picks fall back to the whole body
+)
+
+// extractMainContent returns the text of the main content of an HTML page,
+// with paragraphs separated by blank lines.
+func extractMainContent(src string) string {
+	doc, err := html.Parse(strings.NewReader(src))
+	if err != nil {
+		return ""
+	}
+	body := findElement(doc, atom.Body)
+	if body == nil {
+		body = doc
+	}
+	pruneUnlikely(body)
+
+	if nodes := mainContent(body); nodes != nil {
+		if text := renderText(nodes...); textLen(text) >= minContentLen {
+			return text
+		}
+	}
+	return renderText(body)
+}
+
+// findElement returns the first element of type a in n, in document order.
+func findElement(n *html.Node, a atom.Atom) *html.Node {
+	for d := range n.Descendants() {
+		if d.Type == html.ElementNode && d.DataAtom == a {
+			return d
+		}
+	}
+	return nil
+}
+
+// pruneUnlikely removes the elements under root that are never content.
+func pruneUnlikely(root *html.Node) {
+	var remove []*html.Node
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := range n.ChildNodes() {
+			switch {
+			case c.Type == html.CommentNode:
+				remove = append(remove, c)
+			case c.Type != html.ElementNode:
+			case isUnlikely(c):
+				remove = append(remove, c)
+			default:
+				walk(c)
+			}
+		}
+	}
+	walk(root)
+	for _, n := range remove {
+		n.Parent.RemoveChild(n)
+	}
+}
+
+// isUnlikely reports whether element n is markup or page furniture.
+func isUnlikely(n *html.Node) bool {
+	if removedTags[n.DataAtom] || removedRoles[attr(n, "role")] {
+		return true
+	}
+	if _, hidden := attrValue(n, "hidden"); hidden || attr(n, "aria-hidden") == "true" {
+		return true
+	}
+	style := strings.ReplaceAll(strings.ToLower(attr(n, "style")), " ", "")
+	if strings.Contains(style, "display:none") || strings.Contains(style, "visibility:hidden") {
+		return true
+	}
+	switch n.DataAtom {
+	case atom.Body, atom.Article, atom.Main, atom.A:
+		return false
+	}
+	names := attr(n, "class") + " " + attr(n, "id")
+	if widgetRe.MatchString(names) {
+		return true
+	}
+	return unlikelyRe.MatchString(names) && !maybeRe.MatchString(names)
+}
+
+// mainContent returns the top scoring element and the siblings that belong
+// with it, or nil if no paragraph scored.
+func mainContent(body *html.Node) []*html.Node {
+	scores := scoreParagraphs(body)
+
+	var top *html.Node
+	var topScore float64
+	for n, s := range scores {
+		s *= 1 - linkDensity(n)
+		scores[n] = s
+		if top == nil || s > topScore || s == topScore && isBefore(n, top) {
+			top, topScore = n, s
+		}
+	}
+	if top == nil {
+		return nil
+	}
+	// A candidate that is the only element of its parent is better
+	// replaced by the parent, which may carry the title or more text.
+	for top.Parent != nil && top.Parent != body && elementChildren(top.Parent) == 1 {
+		top = top.Parent
+	}
+	// Editors such as Naver's SmartEditor wrap every block in its own
+	// component, so the best paragraph group is one component of many. An
+	// ancestor scoring higher than the candidate holds more of the article
+	// and takes its place; the walk stops once scores fall too low.
+	if s, ok := scores[top]; ok {
+		topScore = s
+	}
+	last := topScore
+	for a := top.Parent; a != nil && a != body; a = a.Parent {
+		s, ok := scores[a]
+		if !ok {
+			continue
+		}
+		if s < last/3 {
+			break
+		}
+		if s > last {
+			top, topScore = a, s
+			break
+		}
+		last = s
+	}
+	if top.Parent == nil {
+		return []*html.Node{top}
+	}
+
+	threshold := max(10, topScore*0.2)
+	topClass := attr(top, "class")
+	var nodes []*html.Node
+	for s := range top.Parent.ChildNodes() {
+		if s.Type != html.ElementNode {
+			continue
+		}
+		if s == top || belongsWith(s, scores, threshold, topScore, topClass) {
+			cleanConditionally(s)
+			nodes = append(nodes, s)
+		}
+	}
+	return nodes
+}
+
+// belongsWith reports whether sibling s of the top candidate is part of the
+// same article.
+func belongsWith(s *html.Node, scores map[*html.Node]float64, threshold, topScore float64, topClass string) bool {
+	bonus := 0.0
+	if topClass != "" && attr(s, "class") == topClass {
+		bonus = topScore * 0.2
+	}
+	if score, ok := scores[s]; ok && score+bonus >= threshold {
+		return true
+	}
+	if s.DataAtom != atom.P {
+		return false
+	}
+	text := innerText(s)
+	n, density := textLen(text), linkDensity(s)
+	if n > 80 && density < 0.25 {
+		return true
+	}
+	return n > 0 && n <= 80 && density == 0 && endsSentence(text)
+}
+
+// scoreParagraphs scores the ancestors of every paragraph under root.
+func scoreParagraphs(root *html.Node) map[*html.Node]float64 {
+	scores := make(map[*html.Node]float64)
+	for n := range root.Descendants() {
+		if !isParagraph(n) {
+			continue
+		}
+		text := innerText(n)
+		length := textLen(text)
+		if length < minParagraphLen {
+			continue
+		}
+		score := 1 + float64(strings.Count(text, ",")+strings.Count(text, "，")+strings.Count(text, "、"))
+		score += float64(min(length/100, 3))
+
+		level := 0
+		for a := range n.Ancestors() {
+			if a.Type != html.ElementNode || a == root.Parent || level > 4 {
+				break
+			}
+			if _, ok := scores[a]; !ok {
+				scores[a] = initialScore(a)
+			}
+			switch level {
+			case 0:
+				scores[a] += score
+			case 1:
+				scores[a] += score / 2
+			default:
+				scores[a] += score / float64(level*3)
+			}
+			level++
+		}
+	}
+	return scores
+}
+
+// isParagraph reports whether n holds a paragraph of text: a paragraph-like
+// element, or a division without block-level children, which many editors
+// use in place of <p>.
+func isParagraph(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	switch n.DataAtom {
+	case atom.P, atom.Pre, atom.Td, atom.Blockquote:
+		return true
+	case atom.Div, atom.Section:
+		for c := range n.ChildNodes() {
+			if c.Type == html.ElementNode && c.DataAtom != atom.Br && blockBreak[c.DataAtom] >= lineBreak {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// initialScore is the score of a candidate before its paragraphs count.
+func initialScore(n *html.Node) float64 {
+	score := classWeight(n)
+	switch n.DataAtom {
+	case atom.Article:
+		score += 10
+	case atom.Main, atom.Div:
+		score += 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		score += 3
+	case atom.Address, atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li, atom.Form:
+		score -= 3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		score -= 5
+	}
+	if attr(n, "itemprop") == "articleBody" {
+		score += 25
+	}
+	return score
+}
+
+// classWeight scores the class and id names of n.
+func classWeight(n *html.Node) float64 {
+	var w float64
+	for _, name := range []string{attr(n, "class"), attr(n, "id")} {
+		if name == "" {
+			continue
+		}
+		if negativeRe.MatchString(name) {
+			w -= 25
+		}
+		if positiveRe.MatchString(name) {
+			w += 25
+		}
+	}
+	return w
+}
+
+// cleanConditionally removes the lists, tables and divisions under n that
+// look like link lists or widgets rather than part of the text.
+func cleanConditionally(n *html.Node) {
+	var remove []*html.Node
+	for d := range n.Descendants() {
+		if d.Type != html.ElementNode || d.Parent == nil {
+			continue
+		}
+		switch d.DataAtom {
+		case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+			if classWeight(d) < 0 || linkDensity(d) > 0.33 {
+				remove = append(remove, d)
+			}
+		case atom.Form, atom.Table, atom.Ul, atom.Ol, atom.Div, atom.Section:
+			if isFurniture(d) {
+				remove = append(remove, d)
+			}
+		}
+	}
+	for _, d := range remove {
+		if d.Parent != nil && !isDetached(d, n) {
+			d.Parent.RemoveChild(d)
+		}
+	}
+}
+
+// isFurniture reports whether d is a widget, link list or form rather than
+// text, judging by its class weight, links and the prose it holds.
+func isFurniture(d *html.Node) bool {
+	weight := classWeight(d)
+	if weight < 0 {
+		return true
+	}
+	text := innerText(d)
+	if strings.Count(text, ",")+strings.Count(text, "，") >= 10 {
+		return false
+	}
+	density := linkDensity(d)
+	if weight < 25 && density > 0.2 || weight >= 25 && density > 0.5 {
+		return true
+	}
+	var paragraphs, items, inputs int
+	for c := range d.Descendants() {
+		switch c.DataAtom {
+		case atom.P:
+			paragraphs++
+		case atom.Li:
+			items++
+		case atom.Input, atom.Textarea, atom.Select:
+			inputs++
+		}
+	}
+	if inputs > paragraphs/3 {
+		return true
+	}
+	return d.DataAtom != atom.Ul && d.DataAtom != atom.Ol && items > paragraphs+10
+}
+
+// isDetached reports whether d was removed from under root along with an
+// ancestor.
+func isDetached(d, root *html.Node) bool {
+	for a := range d.Ancestors() {
+		if a == root {
+			return false
+		}
+	}
+	return true
+}
+
+// linkDensity returns the share of n's text that is inside links.
+func linkDensity(n *html.Node) float64 {
+	total := textLen(innerText(n))
+	if total == 0 {
+		return 0
+	}
+	var links int
+	for d := range n.Descendants() {
+		if d.Type == html.ElementNode && d.DataAtom == atom.A {
+			links += textLen(innerText(d))
+		}
+	}
+	return min(float64(links)/float64(total), 1)
+}
+
+// zeroWidth removes invisible characters that editors such as Naver's
+// insert to keep empty elements open. strings.Fields does not treat them as
+// space.
+var zeroWidth = strings.NewReplacer("\u200b", "", "\u200c", "", "\u200d", "", "\ufeff", "")
+
+// innerText returns the text under n with whitespace collapsed.
+func innerText(n *html.Node) string {
+	var sb strings.Builder
+	for d := range n.Descendants() {
+		if d.Type == html.TextNode {
+			sb.WriteString(zeroWidth.Replace(d.Data))
+			sb.WriteByte(' ')
+		}
+	}
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+// textLen measures text in Latin-equivalent characters: characters of
+// scripts such as Hangul and CJK, which say about as much as two Latin
+// letters, count twice.
+func textLen(s string) int {
+	n := 0
+	for _, r := range s {
+		n++
+		if utf8.RuneLen(r) >= 3 {
+			n++
+		}
+	}
+	return n
+}
+
+func endsSentence(s string) bool {
+	return strings.HasSuffix(s, ".") || strings.HasSuffix(s, "다") || strings.HasSuffix(s, "요") ||
+		strings.HasSuffix(s, "!") || strings.HasSuffix(s, "?") || strings.HasSuffix(s, "。")
+}
+
+func elementChildren(n *html.Node) int {
+	count := 0
+	for c := range n.ChildNodes() {
+		if c.Type == html.ElementNode {
+			count++
+		} else if c.Type == html.TextNode && strings.TrimSpace(c.Data) != "" {
+			return 0
+		}
+	}
+	return count
+}
+
+// isBefore reports whether a comes before b in document order, which breaks
+// ties between equal scores deterministically.
+func isBefore(a, b *html.Node) bool {
+	depth := func(n *html.Node) int {
+		d := 0
+		for range n.Ancestors() {
+			d++
+		}
+		return d
+	}
+	// Walk both up to the same depth, then to their common parent.
+	da, db := depth(a), depth(b)
+	for ; da > db; da-- {
+		if a.Parent == b {
+			return false
+		}
+		a = a.Parent
+	}
+	for ; db > da; db-- {
+		if b.Parent == a {
+			return true
+		}
+		b = b.Parent
+	}
+	for a.Parent != b.Parent {
+		a, b = a.Parent, b.Parent
+	}
+	for s := a; s != nil; s = s.NextSibling {
+		if s == b {
+			return true
+		}
+	}
+	return false
+}
+
+func attr(n *html.Node, key string) string {
+	v, _ := attrValue(n, key)
+	return v
+}
+
+func attrValue(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// Breaks between blocks of text, from weakest to strongest.
+const (
+	noBreak = iota
+	spaceBreak
+	lineBreak
+	paragraphBreak
+)
+
+// blockBreak is the break that elements put around their text.
+var blockBreak = map[atom.Atom]int{
+	atom.P: paragraphBreak, atom.Pre: paragraphBreak, atom.Blockquote: paragraphBreak,
+	atom.H1: paragraphBreak, atom.H2: paragraphBreak, atom.H3: paragraphBreak,
+	atom.H4: paragraphBreak, atom.H5: paragraphBreak, atom.H6: paragraphBreak,
+	atom.Ul: paragraphBreak, atom.Ol: paragraphBreak, atom.Dl: paragraphBreak,
+	atom.Table: paragraphBreak, atom.Figure: paragraphBreak, atom.Hr: paragraphBreak,
+	atom.Div: lineBreak, atom.Section: lineBreak, atom.Article: lineBreak,
+	atom.Main: lineBreak, atom.Li: lineBreak, atom.Tr: lineBreak, atom.Br: lineBreak,
+	atom.Dt: lineBreak, atom.Dd: lineBreak, atom.Figcaption: lineBreak,
+	atom.Caption: lineBreak, atom.Address: lineBreak, atom.Form: lineBreak,
+	atom.Td: spaceBreak, atom.Th: spaceBreak,
+}
+
+// textWriter renders nodes as plain text, one paragraph per block.
+type textWriter struct {
+	sb      strings.Builder
+	pending int
+	pre     int   // depth of <pre> elements
+	lists   []int // item counters of enclosing lists; -1 for <ul>
+}
+
+// renderText returns the text of nodes, in order.
+func renderText(nodes ...*html.Node) string {
+	var w textWriter
+	for _, n := range nodes {
+		w.node(n)
+		w.breakAt(paragraphBreak)
+	}
+	return strings.TrimSpace(w.sb.String())
+}
+
+func (w *textWriter) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.text(n.Data)
+		return
+	case html.ElementNode, html.DocumentNode:
+	default:
+		return
+	}
+
+	brk := blockBreak[n.DataAtom]
+	w.breakAt(brk)
+	switch n.DataAtom {
+	case atom.Pre:
+		w.pre++
+		defer func() { w.pre-- }()
+	case atom.Ul:
+		w.lists = append(w.lists, -1)
+		defer func() { w.lists = w.lists[:len(w.lists)-1] }()
+	case atom.Ol:
+		start := 1
+		if v, err := strconv.Atoi(attr(n, "start")); err == nil {
+			start = v
+		}
+		w.lists = append(w.lists, start)
+		defer func() { w.lists = w.lists[:len(w.lists)-1] }()
+	case atom.Li:
+		w.listMarker()
+	case atom.Img:
+		return
+	}
+	for c := range n.ChildNodes() {
+		w.node(c)
+	}
+	w.breakAt(brk)
+}
+
+// listMarker writes the bullet or number of a list item.
+func (w *textWriter) listMarker() {
+	if len(w.lists) == 0 {
+		return
+	}
+	i := len(w.lists) - 1
+	if w.lists[i] < 0 {
+		w.write("- ")
+		return
+	}
+	w.write(strconv.Itoa(w.lists[i]) + ". ")
+	w.lists[i]++
+}
+
+func (w *textWriter) text(s string) {
+	s = zeroWidth.Replace(s)
+	if w.pre > 0 {
+		w.write(s)
+		return
+	}
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		if s != "" {
+			w.breakAt(spaceBreak)
+		}
+		return
+	}
+	if startsWithSpace(s) {
+		w.breakAt(spaceBreak)
+	}
+	w.write(strings.Join(fields, " "))
+	if endsWithSpace(s) {
+		w.breakAt(spaceBreak)
+	}
+}
+
+// write writes s after any pending break.
+func (w *textWriter) write(s string) {
+	if w.sb.Len() > 0 {
+		switch w.pending {
+		case spaceBreak:
+			w.sb.WriteByte(' ')
+		case lineBreak:
+			w.sb.WriteByte('\n')
+		case paragraphBreak:
+			w.sb.WriteString("\n\n")
+		}
+	}
+	w.pending = noBreak
+	w.sb.WriteString(s)
+}
+
+func (w *textWriter) breakAt(level int) {
+	w.pending = max(w.pending, level)
+}
+
+func startsWithSpace(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return strings.TrimSpace(string(r)) == ""
+}
+
+func endsWithSpace(s string) bool {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return strings.TrimSpace(string(r)) == ""
+}
@@ -0,0 +1,207 @@
+package extractor
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+// Pages under testdata/readability carry the markup common blog platforms
+// put around an article: sidebars, comments and banners and, in the
+// *_smarteditor, *_odyssey, *_apollo and *_block pages, the platform's real
+// page structure with inline scripts and state blobs, ad units, share and
+// like widgets and deeply nested editor components. The article text is
+// written for the tests so the pages can be kept in the repository. The
+// extracted text is compared with <page>.golden.
+func TestExtractMainContent_Golden(t *testing.T) {
+	// Text from around the article that must never be extracted.
+	noise := map[string][]string{
+		"tistory":    {"카테고리", "관련글", "댓글", "쿠키"},
+		"naver_blog": {"이웃추가", "공감", "댓글", "이 블로그 인기글"},
+		"velog":      {"시리즈", "댓글", "관심 있을 만한 포스트"},
+		"brunch":     {"작가의 이전글", "구독", "댓글"},
+		"wordpress":  {"Sponsored", "Share this", "Related", "Leave a Reply", "Recent Posts", "cookies"},
+		"medium":     {"Follow", "Clap", "More from", "Recommended from Medium"},
+		"substack":   {"Subscribe", "Discussion about this post", "Start Writing"},
+		"news":       {"쿠키", "[광고]", "관련 기사", "많이 본 뉴스", "등록번호"},
+
+		"naver_smarteditor": {"이웃추가", "공감", "댓글", "이 블로그 인기글", "www.sqlite.org", "blogId"},
+		"tistory_odyssey":   {"카테고리의 다른 글", "관련글", "댓글", "반응형", "저작자표시", "tiara"},
+		"medium_apollo":     {"Follow", "Listen", "Share", "Written by", "More from", "Recommended from Medium", "APOLLO_STATE"},
+		"wordpress_block":   {"Share this", "Like this", "Related", "Advertisements", "Leave a Reply", "Tom H.", "Cookies"},
+	}
+
+	pages, err := filepath.Glob(filepath.Join("testdata", "readability", "*.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) == 0 {
+		t.Fatal("no pages in testdata/readability")
+	}
+
+	for _, page := range pages {
+		name := strings.TrimSuffix(filepath.Base(page), ".html")
+		t.Run(name, func(t *testing.T) {
+			src, err := os.ReadFile(page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := extractMainContent(string(src))
+
+			golden := strings.TrimSuffix(page, ".html") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, []byte(got+"\n"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("reading golden file (run with -update to create it): %v", err)
+			}
+			if got != strings.TrimSuffix(string(want), "\n") {
+				t.Errorf("extractMainContent() mismatch\n--- got ---\n%s\n--- want ---\n%s", got, want)
+			}
+
+			for _, s := range noise[name] {
+				if strings.Contains(got, s) {
+					t.Errorf("extracted text contains %q", s)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractMainContent(t *testing.T) {
+	para := func(s string) string { return "<p>" + s + "</p>" }
+	long := "Go schedules goroutines onto a small pool of threads, which keeps them cheap to create, and cheap to block."
+
+	tests := []struct {
+		name        string
+		html        string
+		contains    []string
+		notContains []string
+	}{
+		{
+			name: "picks article over sidebar",
+			html: `<html><body>
+				<div class="sidebar"><ul><li><a href="/a">Archive</a></li><li><a href="/b">Tags</a></li></ul></div>
+				<article>` + para(long) + para(long) + para(long) + `</article>
+				<div class="comments"><p>Nice post, thanks for sharing it with everyone.</p></div>
+			</body></html>`,
+			contains:    []string{"Go schedules goroutines"},
+			notContains: []string{"Archive", "Nice post"},
+		},
+		{
+			name: "drops hidden elements",
+			html: `<html><body><article>` + para(long) + para(long) +
+				`<div style="display: none">Hidden promo text that should not appear</div>` +
+				`<p hidden>Also hidden from readers of the page</p>` +
+				`<div aria-hidden="true">Screen reader hidden text</div>` +
+				para(long) + `</article></body></html>`,
+			contains:    []string{"Go schedules goroutines"},
+			notContains: []string{"Hidden promo", "Also hidden", "Screen reader"},
+		},
+		{
+			name: "drops link lists inside content",
+			html: `<html><body><article>` + para(long) + para(long) +
+				`<ul><li><a href="/1">First related post</a></li><li><a href="/2">Second related post</a></li></ul>` +
+				para(long) + `</article></body></html>`,
+			contains:    []string{"Go schedules goroutines"},
+			notContains: []string{"related post"},
+		},
+		{
+			name: "keeps preformatted code",
+			html: `<html><body><article>` + para(long) + para(long) +
+				"<pre><code>func main() {\n\tgo work()\n}</code></pre>" +
+				para(long) + `</article></body></html>`,
+			contains: []string{"func main() {\n\tgo work()\n}"},
+		},
+		{
+			name: "renders list markers",
+			html: `<html><body><article>` + para(long) + para(long) +
+				`<ol start="3"><li>Measure first, and keep measuring.</li><li>Then change one thing at a time.</li></ol>` +
+				`<ul><li>Short item with enough words, in it.</li></ul>` +
+				para(long) + `</article></body></html>`,
+			contains: []string{"3. Measure first", "4. Then change", "- Short item"},
+		},
+		{
+			name:        "short page falls back to body",
+			html:        `<html><body><nav><a href="/">Home</a></nav><div>Just a short note.</div></body></html>`,
+			contains:    []string{"Just a short note."},
+			notContains: []string{"Home"},
+		},
+		{
+			name:     "strips zero-width characters",
+			html:     "<html><body><article>" + para(long) + para(long) + "<p>\u200b</p><p>끝\u200b까지</p>" + para(long) + "</article></body></html>",
+			contains: []string{"끝까지"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := extractMainContent(tt.html)
+			for _, s := range tt.contains {
+				if !strings.Contains(got, s) {
+					t.Errorf("extractMainContent() = %q, want it to contain %q", got, s)
+				}
+			}
+			for _, s := range tt.notContains {
+				if strings.Contains(got, s) {
+					t.Errorf("extractMainContent() = %q, want it not to contain %q", got, s)
+				}
+			}
+			if strings.ContainsRune(got, '\u200b') {
+				t.Errorf("extractMainContent() = %q, contains a zero-width space", got)
+			}
+		})
+	}
+}
+
+func TestTextLen(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"hello", 5},
+		{"café", 4},
+		{"안녕하세요", 10},
+		{"Go 언어", 7},
+	}
+	for _, tt := range tests {
+		if got := textLen(tt.in); got != tt.want {
+			t.Errorf("textLen(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLinkDensity(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want float64
+	}{
+		{"no links", `<div>plain text here</div>`, 0},
+		{"all links", `<div><a href="#">onetwo</a></div>`, 1},
+		{"half links", `<div>abc <a href="#">defg</a></div>`, 0.5},
+		{"empty", `<div></div>`, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := html.Parse(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatal(err)
+			}
+			div := doc.FirstChild.LastChild.FirstChild // html > body > div
+			if got := linkDensity(div); got != tt.want {
+				t.Errorf("linkDensity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
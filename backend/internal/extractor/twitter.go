@@ -1,6 +1,7 @@
 package extractor
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -23,8 +24,8 @@ func NewTwitterExtractor() *TwitterExtractor {
 }
 
 // Extract fetches a tweet page and extracts the tweet text and metadata.
-func (e *TwitterExtractor) Extract(rawURL string) (*model.ExtractedContent, error) {
-	req, err := http.NewRequest("GET", rawURL, nil)
+func (e *TwitterExtractor) Extract(ctx context.Context, rawURL string) (*model.ExtractedContent, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("creating request: %w", err)
 	}
@@ -50,11 +51,7 @@ func (e *TwitterExtractor) Extract(rawURL string) (*model.ExtractedContent, erro
 	}
 
 	html := string(body)
-	title := extractOGMeta(html, "og:title")
-	description := extractOGMeta(html, "og:description")
-	author := extractTweetAuthor(html)
-
-	content := description
+	content := extractOGMeta(html, "og:description")
 	if content == "" {
 		// Fallback: try to extract from page content
 		content = extractMainContent(html)
@@ -64,14 +61,18 @@ func (e *TwitterExtractor) Extract(rawURL string) (*model.ExtractedContent, erro
 		return nil, fmt.Errorf("could not extract tweet content (tweet may be private or protected)")
 	}
 
+	// The tweet's author is its creator, not whoever the page's other
+	// author tags name.
+	info := model.LinkInfo{
+		URL:      rawURL,
+		LinkType: model.LinkTypeTwitter,
+		Author:   extractOGMeta(html, "twitter:creator"),
+	}
+	extractMetadata(html, rawURL).apply(&info)
+
 	return &model.ExtractedContent{
-		LinkInfo: model.LinkInfo{
-			URL:      rawURL,
-			LinkType: model.LinkTypeTwitter,
-			Title:    title,
-			Author:   author,
-		},
-		Content: content,
+		LinkInfo: info,
+		Content:  content,
 	}, nil
 }
 
@@ -94,16 +95,6 @@ func extractOGMeta(html, property string) string {
 	return ""
 }
 
-// extractTweetAuthor extracts the tweet author from meta tags.
-func extractTweetAuthor(html string) string {
-	// Try twitter:creator or og:site_name
-	author := extractOGMeta(html, "twitter:creator")
-	if author != "" {
-		return author
-	}
-	return extractOGMeta(html, "og:site_name")
-}
-
 // decodeHTMLEntities decodes common HTML entities.
 func decodeHTMLEntities(s string) string {
 	s = strings.ReplaceAll(s, "&amp;", "&")
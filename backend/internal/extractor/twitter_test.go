@@ -1,6 +1,7 @@
 package extractor
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -82,7 +83,7 @@ func TestTwitterExtractor_Extract(t *testing.T) {
 			defer server.Close()
 
 			ext := &TwitterExtractor{Client: server.Client()}
-			result, err := ext.Extract(server.URL + "/tweet/123")
+			result, err := ext.Extract(context.Background(), server.URL+"/tweet/123")
 
 			if tt.wantErr {
 				if err == nil {
@@ -1,6 +1,7 @@
 package extractor
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -32,7 +33,7 @@ type VideoMetadata struct {
 }
 
 // Extract fetches the transcript from a YouTube video URL.
-func (e *YouTubeExtractor) Extract(rawURL string) (*model.ExtractedContent, error) {
+func (e *YouTubeExtractor) Extract(ctx context.Context, rawURL string) (*model.ExtractedContent, error) {
 	videoID, err := extractVideoID(rawURL)
 	if err != nil {
 		return nil, fmt.Errorf("extracting video ID: %w", err)
@@ -40,7 +41,7 @@ func (e *YouTubeExtractor) Extract(rawURL string) (*model.ExtractedContent, erro
 
 	// Fetch the YouTube page to get metadata and transcript data
 	pageURL := "https://www.youtube.com/watch?v=" + videoID
-	req, err := http.NewRequest("GET", pageURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("creating request: %w", err)
 	}
@@ -73,7 +74,7 @@ func (e *YouTubeExtractor) Extract(rawURL string) (*model.ExtractedContent, erro
 	captionsURL, err := extractCaptionsURL(pageHTML)
 	if err == nil {
 		// Fetch the transcript
-		transcript, fetchErr := e.fetchTranscript(captionsURL)
+		transcript, fetchErr := e.fetchTranscript(ctx, captionsURL)
 		if fetchErr == nil && transcript != "" {
 			return &model.ExtractedContent{
 				LinkInfo: linkInfo,
@@ -198,7 +199,7 @@ func extractCaptionsURL(html string) (string, error) {
 }
 
 // fetchTranscript downloads and parses the caption XML into plain text.
-func (e *YouTubeExtractor) fetchTranscript(captionsURL string) (string, error) {
+func (e *YouTubeExtractor) fetchTranscript(ctx context.Context, captionsURL string) (string, error) {
 	// Append fmt=json3 for JSON format, or use XML
 	if !strings.Contains(captionsURL, "fmt=") {
 		if strings.Contains(captionsURL, "?") {
@@ -208,7 +209,12 @@ func (e *YouTubeExtractor) fetchTranscript(captionsURL string) (string, error) {
 		}
 	}
 
-	resp, err := e.Client.Get(captionsURL)
+	req, err := http.NewRequestWithContext(ctx, "GET", captionsURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("creating captions request: %w", err)
+	}
+
+	resp, err := e.Client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("fetching captions: %w", err)
 	}
@@ -1,6 +1,7 @@
 package extractor
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -181,7 +182,7 @@ func TestYouTubeExtractor_Extract_NoCaptions_FallbackToDescription(t *testing.T)
 	defer server.Close()
 
 	ext := &YouTubeExtractor{Client: youtubeTestClient(server)}
-	result, err := ext.Extract("https://www.youtube.com/watch?v=test123")
+	result, err := ext.Extract(context.Background(), "https://www.youtube.com/watch?v=test123")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -220,7 +221,7 @@ var ytInitialPlayerResponse = {
 	defer server.Close()
 
 	ext := &YouTubeExtractor{Client: youtubeTestClient(server)}
-	result, err := ext.Extract("https://www.youtube.com/watch?v=abc123")
+	result, err := ext.Extract(context.Background(), "https://www.youtube.com/watch?v=abc123")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -250,7 +251,7 @@ func TestYouTubeExtractor_ImplementsExtractor(t *testing.T) {
 
 	// Verify the extract method would return correct link type
 	// by testing with an unreachable URL
-	result, err := ext.Extract("https://www.youtube.com/watch?v=nonexistent")
+	result, err := ext.Extract(context.Background(), "https://www.youtube.com/watch?v=nonexistent")
 	if err == nil && result != nil {
 		if result.LinkInfo.LinkType != model.LinkTypeYouTube {
 			t.Errorf("link type = %q, want %q", result.LinkInfo.LinkType, model.LinkTypeYouTube)
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/auth"
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+)
+
+// maxAPITokenNameLen bounds the name a user gives a token.
+const maxAPITokenNameLen = 100
+
+// CreateAPITokenRequest is the request body for POST /api/tokens.
+type CreateAPITokenRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days,omitempty"` // 0 creates a token that does not expire
+}
+
+// CreateAPITokenResponse is the response body for POST /api/tokens. Token
+// is only ever returned here.
+type CreateAPITokenResponse struct {
+	Token    string          `json:"token,omitempty"`
+	APIToken *model.APIToken `json:"api_token,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// APITokenListResponse is the response body for GET /api/tokens.
+type APITokenListResponse struct {
+	Items []model.APIToken `json:"items"`
+	Error string           `json:"error,omitempty"`
+}
+
+// HandleCreateAPIToken returns a handler for POST /api/tokens.
+func HandleCreateAPIToken(store *auth.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, status, msg := sessionUser(r)
+		if user == nil {
+			writeJSON(w, status, CreateAPITokenResponse{Error: msg})
+			return
+		}
+
+		var req CreateAPITokenRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, CreateAPITokenResponse{Error: "invalid request body"})
+			return
+		}
+		req.Name = strings.TrimSpace(req.Name)
+		if req.Name == "" || len(req.Name) > maxAPITokenNameLen {
+			writeJSON(w, http.StatusBadRequest, CreateAPITokenResponse{Error: "name must be 1 to 100 characters"})
+			return
+		}
+		scopes, err := auth.ParseScopes(req.Scopes)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, CreateAPITokenResponse{Error: err.Error()})
+			return
+		}
+		if req.ExpiresInDays < 0 {
+			writeJSON(w, http.StatusBadRequest, CreateAPITokenResponse{Error: "expires_in_days must not be negative"})
+			return
+		}
+		var expiresAt *time.Time
+		if req.ExpiresInDays > 0 {
+			t := time.Now().AddDate(0, 0, req.ExpiresInDays)
+			expiresAt = &t
+		}
+
+		token, secret, err := store.CreateAPIToken(user.UserID, req.Name, scopes, expiresAt)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "tokens: create failed",
+				slog.String("handler", "tokens"),
+				slog.String("error", err.Error()),
+			)
+			writeJSON(w, http.StatusInternalServerError, CreateAPITokenResponse{Error: "internal server error"})
+			return
+		}
+
+		slog.InfoContext(r.Context(), "tokens: created",
+			slog.String("handler", "tokens"),
+			slog.Int64("token_id", token.ID),
+			slog.Any("scopes", token.Scopes),
+		)
+		writeJSON(w, http.StatusCreated, CreateAPITokenResponse{Token: secret, APIToken: token})
+	}
+}
+
+// HandleListAPITokens returns a handler for GET /api/tokens.
+func HandleListAPITokens(store *auth.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, status, msg := sessionUser(r)
+		if user == nil {
+			writeJSON(w, status, APITokenListResponse{Error: msg})
+			return
+		}
+
+		tokens, err := store.ListAPITokens(user.UserID)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "tokens: list failed",
+				slog.String("handler", "tokens"),
+				slog.String("error", err.Error()),
+			)
+			writeJSON(w, http.StatusInternalServerError, APITokenListResponse{Error: "internal server error"})
+			return
+		}
+		writeJSON(w, http.StatusOK, APITokenListResponse{Items: tokens})
+	}
+}
+
+// HandleRevokeAPIToken returns a handler for DELETE /api/tokens/{id}.
+func HandleRevokeAPIToken(store *auth.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, status, msg := sessionUser(r)
+		if user == nil {
+			writeJSON(w, status, APITokenListResponse{Error: msg})
+			return
+		}
+
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, APITokenListResponse{Error: "invalid token id"})
+			return
+		}
+
+		if err := store.RevokeAPIToken(user.UserID, id); err != nil {
+			if errors.Is(err, auth.ErrAPITokenNotFound) {
+				writeJSON(w, http.StatusNotFound, APITokenListResponse{Error: "token not found"})
+				return
+			}
+			slog.ErrorContext(r.Context(), "tokens: revoke failed",
+				slog.String("handler", "tokens"),
+				slog.Int64("token_id", id),
+				slog.String("error", err.Error()),
+			)
+			writeJSON(w, http.StatusInternalServerError, APITokenListResponse{Error: "internal server error"})
+			return
+		}
+
+		slog.InfoContext(r.Context(), "tokens: revoked",
+			slog.String("handler", "tokens"),
+			slog.Int64("token_id", id),
+		)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// sessionUser returns the user of a request authenticated with a session
+// token. Personal access tokens cannot manage tokens, so that a leaked one
+// cannot mint more; for those and for anonymous requests it returns the
+// status and message to reply with.
+func sessionUser(r *http.Request) (*auth.Claims, int, string) {
+	user := auth.UserFromContext(r.Context())
+	if user == nil {
+		return nil, http.StatusUnauthorized, "authentication required"
+	}
+	if user.IsAPIToken() {
+		return nil, http.StatusForbidden, "api tokens cannot manage tokens; log in instead"
+	}
+	return user, 0, ""
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/auth"
+)
+
+// newTokenMux wires the token routes the same way cmd/server/main.go does.
+func newTokenMux(store *auth.Store, jwtSvc *auth.JWTService) *http.ServeMux {
+	requireAuth := auth.Middleware(auth.NewAuthenticator(jwtSvc, store))
+
+	mux := http.NewServeMux()
+	mux.Handle("POST /api/tokens", requireAuth(HandleCreateAPIToken(store)))
+	mux.Handle("GET /api/tokens", requireAuth(HandleListAPITokens(store)))
+	mux.Handle("DELETE /api/tokens/{id}", requireAuth(HandleRevokeAPIToken(store)))
+	return mux
+}
+
+func TestAPITokens_Lifecycle(t *testing.T) {
+	store := testStore(t)
+	jwtSvc := auth.NewJWTService("test-secret", time.Hour)
+	mux := newTokenMux(store, jwtSvc)
+	session := login(t, store, auth.NewSessions(store, jwtSvc, 0)).Token
+
+	rec := doRequest(mux, "POST", "/api/tokens", session, CreateAPITokenRequest{
+		Name: "ci", Scopes: []string{"summarize", "history:read"}, ExpiresInDays: 30,
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body = %s", rec.Code, rec.Body)
+	}
+	var created CreateAPITokenResponse
+	json.NewDecoder(rec.Body).Decode(&created)
+	if !strings.HasPrefix(created.Token, auth.APITokenPrefix) || created.APIToken == nil || created.APIToken.ExpiresAt == nil {
+		t.Fatalf("create response = %+v", created)
+	}
+
+	rec = doRequest(mux, "GET", "/api/tokens", session, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list status = %d", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), created.Token) {
+		t.Error("list response contains the token")
+	}
+	var list APITokenListResponse
+	json.NewDecoder(rec.Body).Decode(&list)
+	if len(list.Items) != 1 || list.Items[0].Name != "ci" {
+		t.Fatalf("list = %+v", list)
+	}
+
+	// A personal access token cannot manage tokens.
+	for _, r := range []struct{ method, path string }{
+		{"GET", "/api/tokens"},
+		{"POST", "/api/tokens"},
+		{"DELETE", "/api/tokens/1"},
+	} {
+		if rec := doRequest(mux, r.method, r.path, created.Token, CreateAPITokenRequest{Name: "x", Scopes: []string{"summarize"}}); rec.Code != http.StatusForbidden {
+			t.Errorf("%s %s with api token: status = %d, want 403", r.method, r.path, rec.Code)
+		}
+	}
+
+	if rec := doRequest(mux, "DELETE", "/api/tokens/999", session, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("revoke unknown: status = %d, want 404", rec.Code)
+	}
+	if rec := doRequest(mux, "DELETE", "/api/tokens/1", session, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("revoke status = %d", rec.Code)
+	}
+	if rec := doRequest(mux, "GET", "/api/tokens", created.Token, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("revoked token: status = %d, want 401", rec.Code)
+	}
+}
+
+func TestAPITokens_CreateValidation(t *testing.T) {
+	store := testStore(t)
+	jwtSvc := auth.NewJWTService("test-secret", time.Hour)
+	mux := newTokenMux(store, jwtSvc)
+	session := login(t, store, auth.NewSessions(store, jwtSvc, 0)).Token
+
+	tests := []struct {
+		name string
+		body any
+	}{
+		{"missing name", CreateAPITokenRequest{Scopes: []string{"summarize"}}},
+		{"long name", CreateAPITokenRequest{Name: strings.Repeat("x", 101), Scopes: []string{"summarize"}}},
+		{"no scopes", CreateAPITokenRequest{Name: "ci"}},
+		{"unknown scope", CreateAPITokenRequest{Name: "ci", Scopes: []string{"admin"}}},
+		{"negative expiry", CreateAPITokenRequest{Name: "ci", Scopes: []string{"summarize"}, ExpiresInDays: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := doRequest(mux, "POST", "/api/tokens", session, tt.body); rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", rec.Code)
+			}
+		})
+	}
+	if rec := doRequest(mux, "POST", "/api/tokens", "", CreateAPITokenRequest{}); rec.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous: status = %d, want 401", rec.Code)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/mail"
 	"strings"
+	"time"
 
 	"github.com/rookiecj/scrum-agents/backend/internal/auth"
 	"golang.org/x/crypto/bcrypt"
@@ -28,8 +29,24 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+// LoginResponse carries a short-lived access token ("token") and the
+// refresh token that renews it.
 type LoginResponse struct {
-	Token string `json:"token,omitempty"`
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int    `json:"expires_in,omitempty"` // Seconds until Token expires
+	Error        string `json:"error,omitempty"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+type LogoutResponse struct {
 	Error string `json:"error,omitempty"`
 }
 
@@ -38,7 +55,7 @@ func HandleSignup(store *auth.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req SignupRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			slog.Warn("signup: invalid request body",
+			slog.WarnContext(r.Context(), "signup: invalid request body",
 				slog.String("handler", "signup"),
 				slog.String("error", err.Error()),
 			)
@@ -63,7 +80,7 @@ func HandleSignup(store *auth.Store) http.HandlerFunc {
 		// Hash password
 		hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 		if err != nil {
-			slog.Error("signup: password hashing failed",
+			slog.ErrorContext(r.Context(), "signup: password hashing failed",
 				slog.String("handler", "signup"),
 				slog.String("error", err.Error()),
 			)
@@ -77,7 +94,7 @@ func HandleSignup(store *auth.Store) http.HandlerFunc {
 				writeJSON(w, http.StatusConflict, SignupResponse{Error: "email already registered"})
 				return
 			}
-			slog.Error("signup: create user failed",
+			slog.ErrorContext(r.Context(), "signup: create user failed",
 				slog.String("handler", "signup"),
 				slog.String("error", err.Error()),
 			)
@@ -85,7 +102,7 @@ func HandleSignup(store *auth.Store) http.HandlerFunc {
 			return
 		}
 
-		slog.Info("signup: user created",
+		slog.InfoContext(r.Context(), "signup: user created",
 			slog.String("handler", "signup"),
 			slog.Int64("user_id", user.ID),
 			slog.String("email", user.Email),
@@ -98,11 +115,11 @@ func HandleSignup(store *auth.Store) http.HandlerFunc {
 }
 
 // HandleLogin returns a handler for POST /api/login.
-func HandleLogin(store *auth.Store, jwtSvc *auth.JWTService) http.HandlerFunc {
+func HandleLogin(store *auth.Store, sessions *auth.Sessions) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req LoginRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			slog.Warn("login: invalid request body",
+			slog.WarnContext(r.Context(), "login: invalid request body",
 				slog.String("handler", "login"),
 				slog.String("error", err.Error()),
 			)
@@ -118,7 +135,7 @@ func HandleLogin(store *auth.Store, jwtSvc *auth.JWTService) http.HandlerFunc {
 				writeJSON(w, http.StatusUnauthorized, LoginResponse{Error: "invalid email or password"})
 				return
 			}
-			slog.Error("login: query user failed",
+			slog.ErrorContext(r.Context(), "login: query user failed",
 				slog.String("handler", "login"),
 				slog.String("error", err.Error()),
 			)
@@ -131,9 +148,9 @@ func HandleLogin(store *auth.Store, jwtSvc *auth.JWTService) http.HandlerFunc {
 			return
 		}
 
-		token, err := jwtSvc.GenerateToken(user.ID, user.Email)
+		pair, err := sessions.Issue(user.ID, user.Email)
 		if err != nil {
-			slog.Error("login: token generation failed",
+			slog.ErrorContext(r.Context(), "login: token generation failed",
 				slog.String("handler", "login"),
 				slog.String("error", err.Error()),
 			)
@@ -141,10 +158,88 @@ func HandleLogin(store *auth.Store, jwtSvc *auth.JWTService) http.HandlerFunc {
 			return
 		}
 
-		slog.Info("login: success",
+		slog.InfoContext(r.Context(), "login: success",
 			slog.String("handler", "login"),
 			slog.Int64("user_id", user.ID),
 		)
-		writeJSON(w, http.StatusOK, LoginResponse{Token: token})
+		writeJSON(w, http.StatusOK, loginResponse(pair))
+	}
+}
+
+// HandleRefresh returns a handler for POST /api/token/refresh. It exchanges
+// a refresh token for a new access and refresh token; the old refresh token
+// stops working.
+func HandleRefresh(sessions *auth.Sessions) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req RefreshRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+			writeJSON(w, http.StatusBadRequest, LoginResponse{Error: "refresh_token is required"})
+			return
+		}
+
+		pair, err := sessions.Refresh(req.RefreshToken)
+		switch {
+		case errors.Is(err, auth.ErrRefreshTokenReused):
+			slog.WarnContext(r.Context(), "refresh: token reused, session revoked",
+				slog.String("handler", "refresh"),
+			)
+			writeJSON(w, http.StatusUnauthorized, LoginResponse{Error: "invalid or expired refresh token"})
+			return
+		case errors.Is(err, auth.ErrInvalidRefreshToken):
+			writeJSON(w, http.StatusUnauthorized, LoginResponse{Error: "invalid or expired refresh token"})
+			return
+		case err != nil:
+			slog.ErrorContext(r.Context(), "refresh: token refresh failed",
+				slog.String("handler", "refresh"),
+				slog.String("error", err.Error()),
+			)
+			writeJSON(w, http.StatusInternalServerError, LoginResponse{Error: "internal server error"})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, loginResponse(pair))
+	}
+}
+
+// HandleLogout returns a handler for POST /api/logout. It must be mounted
+// behind auth.Middleware. The caller's access token is revoked, and so is
+// the session of the refresh token in the body, if one is sent.
+func HandleLogout(sessions *auth.Sessions) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims := auth.UserFromContext(r.Context())
+		if claims == nil {
+			writeJSON(w, http.StatusUnauthorized, LogoutResponse{Error: "authentication required"})
+			return
+		}
+
+		var req LogoutRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeJSON(w, http.StatusBadRequest, LogoutResponse{Error: "invalid request body"})
+				return
+			}
+		}
+
+		if err := sessions.Logout(claims, req.RefreshToken); err != nil {
+			slog.ErrorContext(r.Context(), "logout: revocation failed",
+				slog.String("handler", "logout"),
+				slog.String("error", err.Error()),
+			)
+			writeJSON(w, http.StatusInternalServerError, LogoutResponse{Error: "internal server error"})
+			return
+		}
+
+		slog.InfoContext(r.Context(), "logout: success",
+			slog.String("handler", "logout"),
+		)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func loginResponse(pair *auth.TokenPair) LoginResponse {
+	return LoginResponse{
+		Token:        pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		ExpiresIn:    int(time.Until(pair.AccessExpiry).Round(time.Second).Seconds()),
 	}
 }
@@ -133,7 +133,7 @@ func TestHandleLogin(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	handler := HandleLogin(store, jwtSvc)
+	handler := HandleLogin(store, auth.NewSessions(store, jwtSvc, 0))
 
 	tests := []struct {
 		name       string
@@ -185,8 +185,8 @@ func TestHandleLogin(t *testing.T) {
 				t.Fatalf("failed to decode response: %v", err)
 			}
 
-			if tt.wantToken && resp.Token == "" {
-				t.Error("expected token in response")
+			if tt.wantToken && (resp.Token == "" || resp.RefreshToken == "" || resp.ExpiresIn <= 0) {
+				t.Errorf("expected token, refresh token and expiry in response, got %+v", resp)
 			}
 			if tt.wantErr && resp.Error == "" {
 				t.Error("expected error in response")
@@ -204,7 +204,7 @@ func TestHandleLogin_TokenIsValid(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	handler := HandleLogin(store, jwtSvc)
+	handler := HandleLogin(store, auth.NewSessions(store, jwtSvc, 0))
 
 	body := `{"email":"alice@example.com","password":"password123"}`
 	req := httptest.NewRequest("POST", "/api/login", bytes.NewBufferString(body))
@@ -224,3 +224,97 @@ func TestHandleLogin_TokenIsValid(t *testing.T) {
 		t.Errorf("token email = %q, want %q", claims.Email, "alice@example.com")
 	}
 }
+
+// login signs alice in through HandleLogin and returns the response.
+func login(t *testing.T, store *auth.Store, sessions *auth.Sessions) LoginResponse {
+	t.Helper()
+	hash, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	if _, err := store.CreateUser("alice@example.com", string(hash)); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("POST", "/api/login",
+		bytes.NewBufferString(`{"email":"alice@example.com","password":"password123"}`))
+	rec := httptest.NewRecorder()
+	HandleLogin(store, sessions).ServeHTTP(rec, req)
+
+	var resp LoginResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || resp.Token == "" {
+		t.Fatalf("login failed: %d %+v", rec.Code, resp)
+	}
+	return resp
+}
+
+func refresh(sessions *auth.Sessions, token string) (*httptest.ResponseRecorder, LoginResponse) {
+	body, _ := json.Marshal(RefreshRequest{RefreshToken: token})
+	rec := httptest.NewRecorder()
+	HandleRefresh(sessions).ServeHTTP(rec, httptest.NewRequest("POST", "/api/token/refresh", bytes.NewReader(body)))
+	var resp LoginResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	return rec, resp
+}
+
+func TestHandleRefresh(t *testing.T) {
+	store := testStore(t)
+	jwtSvc := auth.NewJWTService("test-secret", time.Hour)
+	sessions := auth.NewSessions(store, jwtSvc, 0)
+	first := login(t, store, sessions)
+
+	rec, second := refresh(sessions, first.RefreshToken)
+	if rec.Code != http.StatusOK || second.Token == "" || second.RefreshToken == "" {
+		t.Fatalf("refresh: status = %d, resp = %+v", rec.Code, second)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Error("refresh token was not rotated")
+	}
+	if _, err := jwtSvc.ValidateToken(second.Token); err != nil {
+		t.Errorf("refreshed access token invalid: %v", err)
+	}
+
+	// Presenting the first token again revokes the whole session.
+	if rec, _ := refresh(sessions, first.RefreshToken); rec.Code != http.StatusUnauthorized {
+		t.Errorf("reuse: status = %d, want 401", rec.Code)
+	}
+	if rec, _ := refresh(sessions, second.RefreshToken); rec.Code != http.StatusUnauthorized {
+		t.Errorf("after reuse: status = %d, want 401", rec.Code)
+	}
+	if _, err := jwtSvc.ValidateToken(second.Token); err == nil {
+		t.Error("access token of revoked session still valid")
+	}
+
+	for _, body := range []string{`{}`, `not json`} {
+		rec := httptest.NewRecorder()
+		HandleRefresh(sessions).ServeHTTP(rec, httptest.NewRequest("POST", "/api/token/refresh", bytes.NewBufferString(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("body %q: status = %d, want 400", body, rec.Code)
+		}
+	}
+}
+
+func TestHandleLogout(t *testing.T) {
+	store := testStore(t)
+	jwtSvc := auth.NewJWTService("test-secret", time.Hour)
+	sessions := auth.NewSessions(store, jwtSvc, 0)
+	resp := login(t, store, sessions)
+
+	handler := auth.Middleware(jwtSvc)(HandleLogout(sessions))
+	logout := func(body string) int {
+		req := httptest.NewRequest("POST", "/api/logout", bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+resp.Token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := logout(`{"refresh_token":"` + resp.RefreshToken + `"}`); code != http.StatusNoContent {
+		t.Fatalf("logout: status = %d, want 204", code)
+	}
+	if _, err := jwtSvc.ValidateToken(resp.Token); err == nil {
+		t.Error("access token still valid after logout")
+	}
+	if code := logout(""); code != http.StatusUnauthorized {
+		t.Errorf("second logout: status = %d, want 401", code)
+	}
+	if rec, _ := refresh(sessions, resp.RefreshToken); rec.Code != http.StatusUnauthorized {
+		t.Errorf("refresh after logout: status = %d, want 401", rec.Code)
+	}
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/cache"
+	"github.com/rookiecj/scrum-agents/backend/internal/history"
+	"github.com/rookiecj/scrum-agents/backend/internal/llm"
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+	"github.com/rookiecj/scrum-agents/backend/internal/pipeline"
+	"github.com/rookiecj/scrum-agents/backend/internal/ratelimit"
+)
+
+// BatchRequest is the request body for the batch endpoint.
+type BatchRequest struct {
+	URLs     []string `json:"urls"`
+	Provider string   `json:"provider,omitempty"`
+	NoCache  bool     `json:"no_cache,omitempty"`
+}
+
+// BatchStats aggregates the outcomes of a batch.
+type BatchStats struct {
+	Total     int `json:"total"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	// FailedByStage counts the failures per pipeline stage.
+	FailedByStage map[pipeline.Stage]int `json:"failed_by_stage,omitempty"`
+	// Categories counts the successful items per primary category.
+	Categories map[model.ContentCategory]int `json:"categories,omitempty"`
+	// CachedSummaries counts the summaries served from the cache.
+	CachedSummaries int     `json:"cached_summaries"`
+	DurationMs      float64 `json:"duration_ms"`
+}
+
+// BatchResponse is the response body for the batch endpoint. Items are in
+// the order of the requested URLs and each reports its own error, if any.
+type BatchResponse struct {
+	Items []ProcessResponse `json:"items,omitempty"`
+	Stats *BatchStats       `json:"stats,omitempty"`
+	Error string            `json:"error,omitempty"`
+}
+
+// BatchConfig bounds the batch endpoint.
+type BatchConfig struct {
+	pipeline.BatchOptions
+	// MaxURLs is the most URLs accepted per request. Defaults to
+	// pipeline.DefaultBatchMaxURLs.
+	MaxURLs int
+	// Timeout bounds the work of a request, and replaces the server's
+	// write deadline for its response. Defaults to
+	// pipeline.DefaultBatchTimeout.
+	Timeout time.Duration
+}
+
+// batchWriteGrace is the time left after a batch's timeout to write its
+// response.
+const batchWriteGrace = 30 * time.Second
+
+// HandleBatch returns a handler that runs detect, extract, classify and
+// summarize for many URLs, like HandleProcess for each, with at most
+// cfg.Workers URLs in progress and cfg.PerHost fetches per host at once.
+// Each URL counts as one request towards the caller's daily quota. The
+// response is 200 once the batch has run, even if items failed; items
+// still running at cfg.Timeout fail, so the others are still delivered.
+func HandleBatch(pipe *pipeline.Pipeline, defaultClient pipeline.Client, providers map[string]llm.Provider, hist *history.Store, cfg BatchConfig) http.HandlerFunc {
+	maxURLs := cfg.MaxURLs
+	if maxURLs <= 0 {
+		maxURLs = pipeline.DefaultBatchMaxURLs
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = pipeline.DefaultBatchTimeout
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req BatchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.WarnContext(r.Context(), "batch: invalid request body",
+				slog.String("handler", "batch"),
+				slog.String("error", err.Error()),
+			)
+			writeJSON(w, http.StatusBadRequest, BatchResponse{Error: "invalid request body"})
+			return
+		}
+
+		if len(req.URLs) == 0 {
+			writeJSON(w, http.StatusBadRequest, BatchResponse{Error: "urls is required"})
+			return
+		}
+		if len(req.URLs) > maxURLs {
+			writeJSON(w, http.StatusBadRequest, BatchResponse{Error: fmt.Sprintf("too many urls: at most %d per batch", maxURLs)})
+			return
+		}
+
+		// Select LLM client based on requested provider
+		var client pipeline.Client = defaultClient
+		if req.Provider != "" {
+			if p, ok := providers[req.Provider]; ok {
+				client = p
+			} else {
+				writeJSON(w, http.StatusBadRequest, BatchResponse{Error: "provider not available: " + req.Provider})
+				return
+			}
+		}
+
+		// Every URL counts towards the caller's daily quota, and a batch the
+		// quota cannot cover runs none of them.
+		if !ratelimit.Charge(r.Context(), len(req.URLs)-1) {
+			writeJSON(w, http.StatusTooManyRequests, BatchResponse{
+				Error: fmt.Sprintf("daily quota exceeded: a batch of %d urls needs %d requests of quota", len(req.URLs), len(req.URLs)),
+			})
+			return
+		}
+
+		// Each item tracks its own cache hits and serving provider.
+		hits := make([]func() cache.Hits, len(req.URLs))
+		served := make([]func() llm.ProviderType, len(req.URLs))
+		opts := cfg.BatchOptions
+		opts.Context = func(ctx context.Context, i int) context.Context {
+			ctx, hits[i] = cacheContext(ctx, req.NoCache)
+			ctx, served[i] = llm.TrackServed(ctx)
+			return ctx
+		}
+
+		// A batch can outlast the server's write deadline, which would drop
+		// its response after every URL was paid for. The deadline moves past
+		// the batch's own timeout, and the workers stop at that timeout, so
+		// they never run on for a response that can no longer be written.
+		start := time.Now()
+		ctx, cancel := context.WithDeadline(r.Context(), start.Add(timeout))
+		defer cancel()
+		if err := http.NewResponseController(w).SetWriteDeadline(start.Add(timeout + batchWriteGrace)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			slog.WarnContext(r.Context(), "batch: cannot extend write deadline",
+				slog.String("handler", "batch"),
+				slog.String("error", err.Error()),
+			)
+		}
+		results := pipe.RunBatch(ctx, req.URLs, client, opts)
+		stats := &BatchStats{Total: len(results)}
+		items := make([]ProcessResponse, len(results))
+		for i, res := range results {
+			item := ProcessResponse{Result: res.Result}
+			if hits[i] != nil {
+				item.Cache = hits[i]()
+			}
+			if res.Result != nil {
+				countClassification(res.Result.Classification)
+			}
+			if res.Err != nil {
+				item.Stage = pipeline.FailedStage(res.Err)
+				item.Error = res.Err.Error()
+				stats.Failed++
+				if item.Stage != "" {
+					if stats.FailedByStage == nil {
+						stats.FailedByStage = make(map[pipeline.Stage]int)
+					}
+					stats.FailedByStage[item.Stage]++
+				}
+				items[i] = item
+				continue
+			}
+
+			item.Provider = servedProvider(served[i], req.Provider)
+			stats.Succeeded++
+			if stats.Categories == nil {
+				stats.Categories = make(map[model.ContentCategory]int)
+			}
+			stats.Categories[res.Result.Summary.Category]++
+			if item.Cache[cache.LayerSummarize] {
+				stats.CachedSummaries++
+			}
+			recordHistory(r, hist, processEntry(res.Result, item.Provider), item)
+			items[i] = item
+		}
+		stats.DurationMs = float64(time.Since(start).Nanoseconds()) / 1e6
+
+		slog.InfoContext(r.Context(), "batch: done",
+			slog.String("handler", "batch"),
+			slog.Int("total", stats.Total),
+			slog.Int("succeeded", stats.Succeeded),
+			slog.Int("failed", stats.Failed),
+			slog.Float64("duration_ms", stats.DurationMs),
+		)
+		writeJSON(w, http.StatusOK, BatchResponse{Items: items, Stats: stats})
+	}
+}
@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/auth"
+	"github.com/rookiecj/scrum-agents/backend/internal/extractor"
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+	"github.com/rookiecj/scrum-agents/backend/internal/pipeline"
+	"github.com/rookiecj/scrum-agents/backend/internal/ratelimit"
+)
+
+func TestHandleBatch(t *testing.T) {
+	pipe := pipeline.New(extractor.NewRegistry(), newTestSummarizer(t))
+	htmlServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>Test Page</title></head><body><p>Hello from test server.</p></body></html>`))
+	}))
+	defer htmlServer.Close()
+
+	client := &mockPipelineClient{
+		classification: &model.ClassificationResult{Primary: model.CategoryTutorial, Confidence: 0.9},
+		summary:        "## 요약",
+	}
+	handler := HandleBatch(pipe, client, nil, nil, BatchConfig{MaxURLs: 3})
+
+	body, _ := json.Marshal(BatchRequest{URLs: []string{htmlServer.URL + "/a", htmlServer.URL + "/missing", htmlServer.URL + "/b"}})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/api/batch", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+
+	var resp BatchResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Items) != 3 {
+		t.Fatalf("items = %d, want 3", len(resp.Items))
+	}
+	for _, i := range []int{0, 2} {
+		if it := resp.Items[i]; it.Error != "" || it.Summary == nil || it.LinkInfo.Title != "Test Page" {
+			t.Errorf("items[%d] = %+v, want a summary", i, it)
+		}
+	}
+	if it := resp.Items[1]; it.Stage != pipeline.StageExtract || it.Error == "" {
+		t.Errorf("items[1]: stage = %q, error = %q, want extract failure", it.Stage, it.Error)
+	}
+
+	s := resp.Stats
+	if s == nil || s.Total != 3 || s.Succeeded != 2 || s.Failed != 1 {
+		t.Fatalf("stats = %+v, want 3 total, 2 succeeded, 1 failed", s)
+	}
+	if s.FailedByStage[pipeline.StageExtract] != 1 || s.Categories[model.CategoryTutorial] != 2 {
+		t.Errorf("stats = %+v, want 1 extract failure and 2 tutorials", s)
+	}
+}
+
+func TestHandleBatch_OutlastsWriteTimeout(t *testing.T) {
+	pipe := pipeline.New(extractor.NewRegistry(), newTestSummarizer(t))
+	htmlServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delay := 200 * time.Millisecond
+		if r.URL.Path == "/slow" {
+			delay = 2 * time.Second
+		}
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>Test Page</title></head><body><p>Hello from test server.</p></body></html>`))
+	}))
+	defer htmlServer.Close()
+
+	client := &mockPipelineClient{
+		classification: &model.ClassificationResult{Primary: model.CategoryTutorial, Confidence: 0.9},
+		summary:        "## 요약",
+	}
+	// The server's write timeout passes before the first item is done,
+	// and the slow item outlasts the batch's own timeout.
+	srv := httptest.NewUnstartedServer(HandleBatch(pipe, client, nil, nil, BatchConfig{Timeout: 500 * time.Millisecond}))
+	srv.Config.WriteTimeout = 50 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	body, _ := json.Marshal(BatchRequest{URLs: []string{htmlServer.URL + "/a", htmlServer.URL + "/slow"}})
+	res, err := http.Post(srv.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST = %v, want the response after the write timeout", err)
+	}
+	defer res.Body.Close()
+	var resp BatchResponse
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if res.StatusCode != http.StatusOK || len(resp.Items) != 2 {
+		t.Fatalf("status = %d, items = %d; want 200 and 2 items", res.StatusCode, len(resp.Items))
+	}
+	if it := resp.Items[0]; it.Error != "" || it.Summary == nil {
+		t.Errorf("items[0] = %+v, want a summary", it)
+	}
+	if it := resp.Items[1]; it.Error == "" {
+		t.Errorf("items[1] = %+v, want a failure at the batch timeout", it)
+	}
+}
+
+func TestHandleBatch_BadRequests(t *testing.T) {
+	pipe := pipeline.New(extractor.NewRegistry(), newTestSummarizer(t))
+	handler := HandleBatch(pipe, &mockPipelineClient{}, nil, nil, BatchConfig{MaxURLs: 2})
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "invalid body", body: `not json`},
+		{name: "no urls", body: `{"urls":[]}`},
+		{name: "too many urls", body: `{"urls":["https://a.example","https://b.example","https://c.example"]}`},
+		{name: "unknown provider", body: `{"urls":["https://a.example"],"provider":"nope"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest("POST", "/api/batch", bytes.NewBufferString(tt.body)))
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400", rec.Code)
+			}
+			var resp BatchResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || resp.Error == "" {
+				t.Errorf("response = %+v, %v, want an error", resp, err)
+			}
+		})
+	}
+}
+
+func TestHandleBatch_Quota(t *testing.T) {
+	var fetches atomic.Int32
+	htmlServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>Test Page</title></head><body><p>Hello.</p></body></html>`))
+	}))
+	defer htmlServer.Close()
+
+	store, err := auth.NewStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	limiter, err := ratelimit.New(ratelimit.Options{
+		Routes: map[string]ratelimit.Rule{"POST /api/batch": {Quota: true}},
+		Daily:  ratelimit.Quota{Anonymous: 4},
+		DB:     store.DB(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pipe := pipeline.New(extractor.NewRegistry(), newTestSummarizer(t))
+	client := &mockPipelineClient{
+		classification: &model.ClassificationResult{Primary: model.CategoryTutorial, Confidence: 0.9},
+		summary:        "## 요약",
+	}
+	h := limiter.Wrap("POST /api/batch", HandleBatch(pipe, client, nil, nil, BatchConfig{MaxURLs: 10}))
+
+	send := func(n int) *httptest.ResponseRecorder {
+		urls := make([]string, n)
+		for i := range urls {
+			urls[i] = fmt.Sprintf("%s/%d", htmlServer.URL, i)
+		}
+		body, _ := json.Marshal(BatchRequest{URLs: urls})
+		req := httptest.NewRequest("POST", "/api/batch", bytes.NewReader(body))
+		req.RemoteAddr = "10.0.0.1:1234"
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Five URLs need five requests of a quota of four.
+	rec := send(5)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Fatalf("batch over quota: status = %d, Retry-After %q, want 429", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if n := fetches.Load(); n != 0 {
+		t.Errorf("refused batch fetched %d urls, want none", n)
+	}
+
+	// The refused request itself counted, so three remain.
+	if rec := send(3); rec.Code != http.StatusOK || rec.Header().Get("X-RateLimit-Quota-Remaining") != "0" {
+		t.Fatalf("batch within quota: status = %d, remaining %q, want 200 with 0 left", rec.Code, rec.Header().Get("X-RateLimit-Quota-Remaining"))
+	}
+	if rec := send(1); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("batch after quota spent: status = %d, want 429", rec.Code)
+	}
+}
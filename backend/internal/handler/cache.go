@@ -0,0 +1,16 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/cache"
+)
+
+// cacheContext starts tracking cache hits for a request and, when noCache is
+// set, makes it skip cached results.
+func cacheContext(ctx context.Context, noCache bool) (context.Context, func() cache.Hits) {
+	if noCache {
+		ctx = cache.WithoutCache(ctx)
+	}
+	return cache.Track(ctx)
+}
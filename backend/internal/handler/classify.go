@@ -5,28 +5,38 @@ import (
 	"log/slog"
 	"net/http"
 
+	"github.com/rookiecj/scrum-agents/backend/internal/cache"
 	"github.com/rookiecj/scrum-agents/backend/internal/classifier"
+	"github.com/rookiecj/scrum-agents/backend/internal/history"
 	"github.com/rookiecj/scrum-agents/backend/internal/llm"
 	"github.com/rookiecj/scrum-agents/backend/internal/model"
 )
 
 type ClassifyRequest struct {
-	Content  string `json:"content"`
-	Provider string `json:"provider,omitempty"`
+	Content  string          `json:"content"`
+	Provider string          `json:"provider,omitempty"`
+	LinkInfo *model.LinkInfo `json:"link_info,omitempty"`
+	NoCache  bool            `json:"no_cache,omitempty"`
 }
 
 type ClassifyResponse struct {
 	Classification *model.ClassificationResult `json:"classification,omitempty"`
+	Provider       string                      `json:"provider,omitempty"`
+	Cache          cache.Hits                  `json:"cache,omitempty"`
 	Error          string                      `json:"error,omitempty"`
 }
 
 // HandleClassify returns a handler that classifies content.
-// It accepts an optional "provider" field in the request to select the LLM provider.
-func HandleClassify(defaultClassifier classifier.Classifier, providers map[string]llm.Provider) http.HandlerFunc {
+// It accepts an optional "provider" field in the request to select the LLM provider,
+// and an optional "link_info" field that is stored alongside the result in hist.
+// The response names the provider that served the request, which may differ
+// from the requested one when a fallback order is configured, and whether the
+// result came from the cache; a "no_cache" flag bypasses cached results.
+func HandleClassify(defaultClassifier classifier.Classifier, providers map[string]llm.Provider, hist *history.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req ClassifyRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			slog.Warn("classify: invalid request body",
+			slog.WarnContext(r.Context(), "classify: invalid request body",
 				slog.String("handler", "classify"),
 				slog.String("error", err.Error()),
 			)
@@ -35,7 +45,7 @@ func HandleClassify(defaultClassifier classifier.Classifier, providers map[strin
 		}
 
 		if req.Content == "" {
-			slog.Warn("classify: empty content",
+			slog.WarnContext(r.Context(), "classify: empty content",
 				slog.String("handler", "classify"),
 			)
 			writeJSON(w, http.StatusBadRequest, ClassifyResponse{Error: "content is required"})
@@ -53,21 +63,26 @@ func HandleClassify(defaultClassifier classifier.Classifier, providers map[strin
 			}
 		}
 
-		result, err := cls.Classify(req.Content)
+		ctx, hits := cacheContext(r.Context(), req.NoCache)
+		ctx, served := llm.TrackServed(ctx)
+		result, err := cls.Classify(ctx, req.Content)
 		if err != nil {
-			slog.Error("classify: classification failed",
+			slog.ErrorContext(r.Context(), "classify: classification failed",
 				slog.String("handler", "classify"),
 				slog.String("error", err.Error()),
 			)
-			writeJSON(w, http.StatusInternalServerError, ClassifyResponse{Error: "classification failed: " + err.Error()})
+			writeJSON(w, llmErrorStatus(w, err), ClassifyResponse{Error: "classification failed: " + err.Error()})
 			return
 		}
 
-		slog.Debug("classify: success",
+		slog.DebugContext(r.Context(), "classify: success",
 			slog.String("handler", "classify"),
 			slog.String("primary", string(result.Primary)),
 			slog.Float64("confidence", result.Confidence),
 		)
-		writeJSON(w, http.StatusOK, ClassifyResponse{Classification: result})
+		countClassification(result)
+		resp := ClassifyResponse{Classification: result, Provider: servedProvider(served, req.Provider), Cache: hits()}
+		recordHistory(r, hist, historyEntryFor(model.HistoryClassify, req.LinkInfo, result.Primary, resp.Provider), resp)
+		writeJSON(w, http.StatusOK, resp)
 	}
 }
@@ -2,11 +2,13 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/rookiecj/scrum-agents/backend/internal/llm"
 	"github.com/rookiecj/scrum-agents/backend/internal/model"
 )
 
@@ -15,7 +17,7 @@ type mockClassifier struct {
 	err    error
 }
 
-func (m *mockClassifier) Classify(content string) (*model.ClassificationResult, error) {
+func (m *mockClassifier) Classify(ctx context.Context, content string) (*model.ClassificationResult, error) {
 	return m.result, m.err
 }
 
@@ -67,7 +69,7 @@ func TestHandleClassify(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			cls := &mockClassifier{result: tt.result, err: tt.classErr}
-			handler := HandleClassify(cls, nil)
+			handler := HandleClassify(cls, nil, nil)
 
 			req := httptest.NewRequest("POST", "/api/classify", bytes.NewBufferString(tt.body))
 			req.Header.Set("Content-Type", "application/json")
@@ -100,3 +102,60 @@ func TestHandleClassify(t *testing.T) {
 		})
 	}
 }
+
+// fakeProvider is an llm.Provider whose classification succeeds or fails.
+type fakeProvider struct {
+	name llm.ProviderType
+	err  error
+}
+
+func (f *fakeProvider) Complete(ctx context.Context, prompt string) (string, error) {
+	return "", f.err
+}
+
+func (f *fakeProvider) Stream(ctx context.Context, prompt string, onToken func(string) error) (string, error) {
+	return "", f.err
+}
+
+func (f *fakeProvider) Classify(ctx context.Context, content string) (*model.ClassificationResult, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &model.ClassificationResult{Primary: model.CategoryNews, Confidence: 0.9}, nil
+}
+
+func (f *fakeProvider) Summarize(ctx context.Context, content string, category model.ContentCategory) (string, error) {
+	return "", f.err
+}
+
+func (f *fakeProvider) Name() llm.ProviderType {
+	return f.name
+}
+
+func TestHandleClassify_ReportsFallbackProvider(t *testing.T) {
+	claude := &fakeProvider{name: llm.ProviderClaude, err: &llm.APIError{Provider: llm.ProviderClaude, Kind: llm.ErrOverloaded}}
+	openai := &fakeProvider{name: llm.ProviderOpenAI}
+	adapter, err := llm.NewAdapter(llm.ProviderClaude, claude, openai)
+	if err != nil {
+		t.Fatal(err)
+	}
+	adapter.SetFallbackOrder(llm.ProviderClaude, llm.ProviderOpenAI)
+
+	providers := map[string]llm.Provider{"claude": adapter.Route(llm.ProviderClaude)}
+	handler := HandleClassify(adapter.Route(""), providers, nil)
+
+	for _, body := range []string{`{"content":"x"}`, `{"content":"x","provider":"claude"}`} {
+		req := httptest.NewRequest("POST", "/api/classify", bytes.NewBufferString(body))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		var resp ClassifyResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		if rec.Code != 200 {
+			t.Fatalf("%s: status = %d, want 200 (%s)", body, rec.Code, resp.Error)
+		}
+		if resp.Provider != "openai" {
+			t.Errorf("%s: provider = %q, want %q", body, resp.Provider, "openai")
+		}
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"log/slog"
 	"net/http"
 
+	"github.com/rookiecj/scrum-agents/backend/internal/cache"
 	"github.com/rookiecj/scrum-agents/backend/internal/extractor"
+	"github.com/rookiecj/scrum-agents/backend/internal/history"
 	"github.com/rookiecj/scrum-agents/backend/internal/model"
 	"github.com/rookiecj/scrum-agents/backend/internal/urldetect"
 )
@@ -20,21 +22,24 @@ type DetectResponse struct {
 }
 
 type ExtractRequest struct {
-	URL string `json:"url"`
+	URL     string `json:"url"`
+	NoCache bool   `json:"no_cache,omitempty"`
 }
 
 type ExtractResponse struct {
 	LinkInfo model.LinkInfo `json:"link_info"`
 	Content  string         `json:"content"`
+	Cache    cache.Hits     `json:"cache,omitempty"`
 	Error    string         `json:"error,omitempty"`
 }
 
 // HandleDetect returns a handler that detects the type of a URL.
-func HandleDetect() http.HandlerFunc {
+// Results are recorded in hist for authenticated users when hist is non-nil.
+func HandleDetect(hist *history.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req DetectRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			slog.Warn("detect: invalid request body",
+			slog.WarnContext(r.Context(), "detect: invalid request body",
 				slog.String("handler", "detect"),
 				slog.String("error", err.Error()),
 			)
@@ -43,7 +48,7 @@ func HandleDetect() http.HandlerFunc {
 		}
 
 		if req.URL == "" {
-			slog.Warn("detect: empty url",
+			slog.WarnContext(r.Context(), "detect: empty url",
 				slog.String("handler", "detect"),
 			)
 			writeJSON(w, http.StatusBadRequest, DetectResponse{Error: "url is required"})
@@ -52,7 +57,7 @@ func HandleDetect() http.HandlerFunc {
 
 		linkType, err := urldetect.Detect(req.URL)
 		if err != nil {
-			slog.Error("detect: invalid URL",
+			slog.ErrorContext(r.Context(), "detect: invalid URL",
 				slog.String("handler", "detect"),
 				slog.String("url", req.URL),
 				slog.String("error", err.Error()),
@@ -61,37 +66,35 @@ func HandleDetect() http.HandlerFunc {
 			return
 		}
 
-		slog.Debug("detect: success",
+		slog.DebugContext(r.Context(), "detect: success",
 			slog.String("handler", "detect"),
 			slog.String("url", req.URL),
 			slog.String("link_type", string(linkType)),
 		)
-		writeJSON(w, http.StatusOK, DetectResponse{
+		resp := DetectResponse{
 			LinkInfo: model.LinkInfo{
 				URL:      req.URL,
 				LinkType: linkType,
 			},
-		})
+		}
+		recordHistory(r, hist, model.HistoryEntry{
+			Kind:     model.HistoryDetect,
+			URL:      req.URL,
+			LinkType: linkType,
+		}, resp)
+		writeJSON(w, http.StatusOK, resp)
 	}
 }
 
-// HandleExtract returns a handler that extracts content from a URL.
-func HandleExtract() http.HandlerFunc {
-	extractors := map[model.LinkType]extractor.Extractor{
-		model.LinkTypeArticle:    extractor.NewArticleExtractor(),
-		model.LinkTypeYouTube:    extractor.NewYouTubeExtractor(),
-		model.LinkTypePDF:        extractor.NewPDFExtractor(),
-		model.LinkTypeTwitter:    extractor.NewTwitterExtractor(),
-		model.LinkTypeNewsletter: extractor.NewNewsletterExtractor(),
-	}
-
-	// Fallback extractor for unsupported types
-	fallback := extractor.NewArticleExtractor()
-
+// HandleExtract returns a handler that extracts content from a URL using the
+// extractor registered for its link type.
+// A "no_cache" flag in the request bypasses cached extractions.
+// Results are recorded in hist for authenticated users when hist is non-nil.
+func HandleExtract(extractors *extractor.Registry, hist *history.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req ExtractRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			slog.Warn("extract: invalid request body",
+			slog.WarnContext(r.Context(), "extract: invalid request body",
 				slog.String("handler", "extract"),
 				slog.String("error", err.Error()),
 			)
@@ -100,7 +103,7 @@ func HandleExtract() http.HandlerFunc {
 		}
 
 		if req.URL == "" {
-			slog.Warn("extract: empty url",
+			slog.WarnContext(r.Context(), "extract: empty url",
 				slog.String("handler", "extract"),
 			)
 			writeJSON(w, http.StatusBadRequest, ExtractResponse{Error: "url is required"})
@@ -109,7 +112,7 @@ func HandleExtract() http.HandlerFunc {
 
 		linkType, err := urldetect.Detect(req.URL)
 		if err != nil {
-			slog.Error("extract: invalid URL",
+			slog.ErrorContext(r.Context(), "extract: invalid URL",
 				slog.String("handler", "extract"),
 				slog.String("url", req.URL),
 				slog.String("error", err.Error()),
@@ -118,20 +121,20 @@ func HandleExtract() http.HandlerFunc {
 			return
 		}
 
-		ext, ok := extractors[linkType]
+		// Unsupported types get the generic HTML extractor as a graceful fallback
+		ext, ok := extractors.For(linkType)
 		if !ok {
-			slog.Warn("extract: no extractor for type, using fallback",
+			slog.WarnContext(r.Context(), "extract: no extractor for type, using fallback",
 				slog.String("handler", "extract"),
 				slog.String("url", req.URL),
 				slog.String("link_type", string(linkType)),
 			)
-			// Graceful fallback: attempt generic HTML extraction
-			ext = fallback
 		}
 
-		result, err := ext.Extract(req.URL)
+		ctx, hits := cacheContext(r.Context(), req.NoCache)
+		result, err := ext.Extract(ctx, req.URL)
 		if err != nil {
-			slog.Error("extract: extraction failed",
+			slog.ErrorContext(r.Context(), "extract: extraction failed",
 				slog.String("handler", "extract"),
 				slog.String("url", req.URL),
 				slog.String("link_type", string(linkType)),
@@ -141,15 +144,25 @@ func HandleExtract() http.HandlerFunc {
 			return
 		}
 
-		slog.Debug("extract: success",
+		slog.DebugContext(r.Context(), "extract: success",
 			slog.String("handler", "extract"),
 			slog.String("url", req.URL),
 			slog.String("link_type", string(linkType)),
 		)
-		writeJSON(w, http.StatusOK, ExtractResponse{
+		resp := ExtractResponse{
 			LinkInfo: result.LinkInfo,
 			Content:  result.Content,
-		})
+			Cache:    hits(),
+		}
+		recordHistory(r, hist, model.HistoryEntry{
+			Kind:     model.HistoryExtract,
+			URL:      result.LinkInfo.URL,
+			LinkType: result.LinkInfo.LinkType,
+			Title:    result.LinkInfo.Title,
+			Author:   result.LinkInfo.Author,
+			SiteName: result.LinkInfo.SiteName,
+		}, resp)
+		writeJSON(w, http.StatusOK, resp)
 	}
 }
 
@@ -6,10 +6,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/cache"
+	"github.com/rookiecj/scrum-agents/backend/internal/extractor"
 )
 
 func TestHandleDetect(t *testing.T) {
-	handler := HandleDetect()
+	handler := HandleDetect(nil)
 
 	tests := []struct {
 		name       string
@@ -89,7 +92,7 @@ func TestHandleExtract(t *testing.T) {
 	}))
 	defer htmlServer.Close()
 
-	handler := HandleExtract()
+	handler := HandleExtract(extractor.NewRegistry(), nil)
 
 	t.Run("extract article content", func(t *testing.T) {
 		body := `{"url":"` + htmlServer.URL + `"}`
@@ -154,3 +157,42 @@ func TestHandleExtract(t *testing.T) {
 		}
 	})
 }
+
+func TestHandleExtract_Cache(t *testing.T) {
+	fetches := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>Cached</title></head><body><p>Body.</p></body></html>`))
+	}))
+	defer srv.Close()
+
+	c, _ := cache.New(cache.Options{})
+	handler := HandleExtract(cache.Extractors(extractor.NewRegistry(), c), nil)
+
+	tests := []struct {
+		name        string
+		body        string
+		wantHit     bool
+		wantFetches int
+	}{
+		{name: "first request misses", body: `{"url":"` + srv.URL + `/post"}`, wantHit: false, wantFetches: 1},
+		{name: "second request hits", body: `{"url":"` + srv.URL + `/post"}`, wantHit: true, wantFetches: 1},
+		{name: "no_cache refetches", body: `{"url":"` + srv.URL + `/post","no_cache":true}`, wantHit: false, wantFetches: 2},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/api/extract", bytes.NewBufferString(tt.body))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		var resp ExtractResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		if hit, ok := resp.Cache[cache.LayerExtract]; !ok || hit != tt.wantHit {
+			t.Errorf("%s: cache = %v, want extract hit=%v", tt.name, resp.Cache, tt.wantHit)
+		}
+		if fetches != tt.wantFetches {
+			t.Errorf("%s: fetches = %d, want %d", tt.name, fetches, tt.wantFetches)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/auth"
+	"github.com/rookiecj/scrum-agents/backend/internal/history"
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+)
+
+// HistoryListResponse is the response body for GET /api/history.
+type HistoryListResponse struct {
+	Items  []model.HistoryEntry `json:"items"`
+	Total  int                  `json:"total"`
+	Limit  int                  `json:"limit"`
+	Offset int                  `json:"offset"`
+	Error  string               `json:"error,omitempty"`
+}
+
+// HistoryEntryResponse is the response body for GET /api/history/{id}.
+type HistoryEntryResponse struct {
+	Entry *model.HistoryEntry `json:"entry,omitempty"`
+	Error string              `json:"error,omitempty"`
+}
+
+// HandleListHistory returns a handler for GET /api/history.
+// Supported query parameters: limit, offset, kind, category, link_type.
+func HandleListHistory(store *history.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := auth.UserFromContext(r.Context())
+		if user == nil {
+			writeJSON(w, http.StatusUnauthorized, HistoryListResponse{Error: "authentication required"})
+			return
+		}
+
+		filter, err := parseHistoryFilter(r)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, HistoryListResponse{Error: err.Error()})
+			return
+		}
+
+		entries, total, err := store.List(user.UserID, filter)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "history: list failed",
+				slog.String("handler", "history"),
+				slog.Int64("user_id", user.UserID),
+				slog.String("error", err.Error()),
+			)
+			writeJSON(w, http.StatusInternalServerError, HistoryListResponse{Error: "internal server error"})
+			return
+		}
+
+		limit := filter.Limit
+		if limit <= 0 {
+			limit = history.DefaultLimit
+		}
+		writeJSON(w, http.StatusOK, HistoryListResponse{
+			Items:  entries,
+			Total:  total,
+			Limit:  min(limit, history.MaxLimit),
+			Offset: filter.Offset,
+		})
+	}
+}
+
+// HandleGetHistory returns a handler for GET /api/history/{id}.
+func HandleGetHistory(store *history.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := auth.UserFromContext(r.Context())
+		if user == nil {
+			writeJSON(w, http.StatusUnauthorized, HistoryEntryResponse{Error: "authentication required"})
+			return
+		}
+
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, HistoryEntryResponse{Error: "invalid history id"})
+			return
+		}
+
+		entry, err := store.Get(user.UserID, id)
+		if err != nil {
+			if errors.Is(err, history.ErrNotFound) {
+				writeJSON(w, http.StatusNotFound, HistoryEntryResponse{Error: "history entry not found"})
+				return
+			}
+			slog.ErrorContext(r.Context(), "history: get failed",
+				slog.String("handler", "history"),
+				slog.Int64("user_id", user.UserID),
+				slog.Int64("id", id),
+				slog.String("error", err.Error()),
+			)
+			writeJSON(w, http.StatusInternalServerError, HistoryEntryResponse{Error: "internal server error"})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, HistoryEntryResponse{Entry: entry})
+	}
+}
+
+// HandleDeleteHistory returns a handler for DELETE /api/history/{id}.
+func HandleDeleteHistory(store *history.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := auth.UserFromContext(r.Context())
+		if user == nil {
+			writeJSON(w, http.StatusUnauthorized, HistoryEntryResponse{Error: "authentication required"})
+			return
+		}
+
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, HistoryEntryResponse{Error: "invalid history id"})
+			return
+		}
+
+		if err := store.Delete(user.UserID, id); err != nil {
+			if errors.Is(err, history.ErrNotFound) {
+				writeJSON(w, http.StatusNotFound, HistoryEntryResponse{Error: "history entry not found"})
+				return
+			}
+			slog.ErrorContext(r.Context(), "history: delete failed",
+				slog.String("handler", "history"),
+				slog.Int64("user_id", user.UserID),
+				slog.Int64("id", id),
+				slog.String("error", err.Error()),
+			)
+			writeJSON(w, http.StatusInternalServerError, HistoryEntryResponse{Error: "internal server error"})
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func parseHistoryFilter(r *http.Request) (history.Filter, error) {
+	q := r.URL.Query()
+	var f history.Filter
+
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return f, errors.New("invalid limit")
+		}
+		f.Limit = n
+	}
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return f, errors.New("invalid offset")
+		}
+		f.Offset = n
+	}
+	if v := q.Get("kind"); v != "" {
+		kind := model.HistoryKind(v)
+		if !slices.Contains(model.AllHistoryKinds(), kind) {
+			return f, errors.New("invalid kind: " + v)
+		}
+		f.Kind = kind
+	}
+	if v := q.Get("category"); v != "" {
+		category := model.ContentCategory(v)
+		if !slices.Contains(model.AllCategories(), category) {
+			return f, errors.New("invalid category: " + v)
+		}
+		f.Category = category
+	}
+	if v := q.Get("link_type"); v != "" {
+		linkType := model.LinkType(v)
+		if !slices.Contains(model.AllLinkTypes(), linkType) {
+			return f, errors.New("invalid link_type: " + v)
+		}
+		f.LinkType = linkType
+	}
+	return f, nil
+}
+
+// recordHistory stores a stage result for the authenticated user, if any.
+// Failures are logged and never fail the request.
+func recordHistory(r *http.Request, store *history.Store, entry model.HistoryEntry, result any) {
+	if user := auth.UserFromContext(r.Context()); user != nil {
+		addHistory(r.Context(), store, user.UserID, entry, result)
+	}
+}
+
+// addHistory stores a stage result for userID, logging failures.
+func addHistory(ctx context.Context, store *history.Store, userID int64, entry model.HistoryEntry, result any) {
+	if store == nil {
+		return
+	}
+
+	payload, err := json.Marshal(result)
+	if err != nil {
+		slog.ErrorContext(ctx, "history: marshal result failed",
+			slog.String("kind", string(entry.Kind)),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	entry.UserID = userID
+	entry.Result = payload
+	if _, err := store.Add(entry); err != nil {
+		slog.ErrorContext(ctx, "history: record failed",
+			slog.String("kind", string(entry.Kind)),
+			slog.Int64("user_id", userID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// historyEntryFor builds an entry from optional link metadata sent by the client.
+func historyEntryFor(kind model.HistoryKind, info *model.LinkInfo, category model.ContentCategory, provider string) model.HistoryEntry {
+	entry := model.HistoryEntry{
+		Kind:     kind,
+		Category: category,
+		Provider: provider,
+	}
+	if info != nil {
+		entry.URL = info.URL
+		entry.LinkType = info.LinkType
+		entry.Title = info.Title
+		entry.Author = info.Author
+		entry.SiteName = info.SiteName
+	}
+	return entry
+}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/auth"
+	"github.com/rookiecj/scrum-agents/backend/internal/history"
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+)
+
+func newTestHistory(t *testing.T) *history.Store {
+	t.Helper()
+	store, err := auth.NewStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	hist, err := history.NewStore(store.DB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hist
+}
+
+// newHistoryMux wires the history routes the same way cmd/server/main.go does.
+func newHistoryMux(hist *history.Store, jwtSvc *auth.JWTService) *http.ServeMux {
+	requireAuth := auth.Middleware(jwtSvc)
+	optionalAuth := auth.OptionalMiddleware(jwtSvc)
+
+	mux := http.NewServeMux()
+	mux.Handle("POST /api/detect", optionalAuth(HandleDetect(hist)))
+	mux.Handle("GET /api/history", requireAuth(HandleListHistory(hist)))
+	mux.Handle("GET /api/history/{id}", requireAuth(HandleGetHistory(hist)))
+	mux.Handle("DELETE /api/history/{id}", requireAuth(HandleDeleteHistory(hist)))
+	return mux
+}
+
+func doRequest(mux http.Handler, method, path, token string, body any) *httptest.ResponseRecorder {
+	var b []byte
+	if body != nil {
+		b, _ = json.Marshal(body)
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(b))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHistory_RecordAndList(t *testing.T) {
+	hist := newTestHistory(t)
+	jwtSvc := auth.NewJWTService("test-secret", time.Hour)
+	mux := newHistoryMux(hist, jwtSvc)
+	token, _ := jwtSvc.GenerateToken(1, "alice@example.com")
+
+	// Anonymous requests are not recorded.
+	doRequest(mux, "POST", "/api/detect", "", DetectRequest{URL: "https://example.com/anon"})
+	// Authenticated requests are recorded.
+	doRequest(mux, "POST", "/api/detect", token, DetectRequest{URL: "https://example.com/post"})
+	doRequest(mux, "POST", "/api/detect", token, DetectRequest{URL: "https://youtube.com/watch?v=abc"})
+
+	rec := doRequest(mux, "GET", "/api/history", token, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var resp HistoryListResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.Total != 2 || len(resp.Items) != 2 {
+		t.Fatalf("total = %d, items = %d, want 2", resp.Total, len(resp.Items))
+	}
+	if resp.Items[0].URL != "https://youtube.com/watch?v=abc" {
+		t.Errorf("first item = %q, want newest entry", resp.Items[0].URL)
+	}
+
+	rec = doRequest(mux, "GET", "/api/history?link_type=youtube", token, nil)
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.Total != 1 || resp.Items[0].LinkType != model.LinkTypeYouTube {
+		t.Errorf("link_type filter: total = %d, want 1 youtube entry", resp.Total)
+	}
+}
+
+func TestHistory_ListValidation(t *testing.T) {
+	hist := newTestHistory(t)
+	jwtSvc := auth.NewJWTService("test-secret", time.Hour)
+	mux := newHistoryMux(hist, jwtSvc)
+	token, _ := jwtSvc.GenerateToken(1, "alice@example.com")
+
+	tests := []struct {
+		name       string
+		query      string
+		token      string
+		wantStatus int
+	}{
+		{name: "unauthenticated", query: "", token: "", wantStatus: http.StatusUnauthorized},
+		{name: "invalid limit", query: "?limit=abc", token: token, wantStatus: http.StatusBadRequest},
+		{name: "invalid category", query: "?category=unknown", token: token, wantStatus: http.StatusBadRequest},
+		{name: "invalid link type", query: "?link_type=video", token: token, wantStatus: http.StatusBadRequest},
+		{name: "valid filters", query: "?category=%ED%8A%9C%ED%86%A0%EB%A6%AC%EC%96%BC&link_type=article&limit=5&offset=0", token: token, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(mux, "GET", "/api/history"+tt.query, tt.token, nil)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHistory_GetAndDelete(t *testing.T) {
+	hist := newTestHistory(t)
+	jwtSvc := auth.NewJWTService("test-secret", time.Hour)
+	mux := newHistoryMux(hist, jwtSvc)
+	alice, _ := jwtSvc.GenerateToken(1, "alice@example.com")
+	bob, _ := jwtSvc.GenerateToken(2, "bob@example.com")
+
+	entry, err := hist.Add(model.HistoryEntry{UserID: 1, Kind: model.HistorySummarize, Result: []byte(`{"result":{}}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := fmt.Sprintf("/api/history/%d", entry.ID)
+
+	if rec := doRequest(mux, "GET", path, bob, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("other user GET status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if rec := doRequest(mux, "GET", "/api/history/abc", alice, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid id status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	rec := doRequest(mux, "GET", path, alice, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var resp HistoryEntryResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.Entry == nil || resp.Entry.ID != entry.ID {
+		t.Fatalf("entry = %+v, want id %d", resp.Entry, entry.ID)
+	}
+
+	if rec := doRequest(mux, "DELETE", path, bob, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("other user DELETE status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if rec := doRequest(mux, "DELETE", path, alice, nil); rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if rec := doRequest(mux, "GET", path, alice, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("GET after delete status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/auth"
+	"github.com/rookiecj/scrum-agents/backend/internal/history"
+	"github.com/rookiecj/scrum-agents/backend/internal/jobs"
+	"github.com/rookiecj/scrum-agents/backend/internal/llm"
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+	"github.com/rookiecj/scrum-agents/backend/internal/pipeline"
+	"github.com/rookiecj/scrum-agents/backend/internal/urldetect"
+)
+
+// JobRequest is the request body for POST /api/jobs. When CallbackURL is
+// set, it receives a signed webhook once the job succeeds or fails.
+type JobRequest struct {
+	URL         string `json:"url"`
+	Provider    string `json:"provider,omitempty"`
+	NoCache     bool   `json:"no_cache,omitempty"`
+	CallbackURL string `json:"callback_url,omitempty"`
+}
+
+// JobResponse is the response body of the job endpoints.
+type JobResponse struct {
+	Job   *model.Job `json:"job,omitempty"`
+	Error string     `json:"error,omitempty"`
+}
+
+// HandleCreateJob returns a handler for POST /api/jobs, which queues a
+// process run and answers 202 with the job. notifier checks callback URLs;
+// they are refused when it is nil, as webhooks cannot be signed.
+func HandleCreateJob(store *jobs.Store, runner *jobs.Runner, providers map[string]llm.Provider, notifier *jobs.Notifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Jobs are looked up by their owner, so anonymous callers cannot
+		// create any even when the policy lets them through.
+		user := auth.UserFromContext(r.Context())
+		if user == nil {
+			writeJSON(w, http.StatusUnauthorized, JobResponse{Error: "authentication required"})
+			return
+		}
+
+		var req JobRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.WarnContext(r.Context(), "jobs: invalid request body",
+				slog.String("handler", "jobs"),
+				slog.String("error", err.Error()),
+			)
+			writeJSON(w, http.StatusBadRequest, JobResponse{Error: "invalid request body"})
+			return
+		}
+		if req.URL == "" {
+			writeJSON(w, http.StatusBadRequest, JobResponse{Error: "url is required"})
+			return
+		}
+		if _, err := urldetect.Detect(req.URL); err != nil {
+			writeJSON(w, http.StatusBadRequest, JobResponse{Error: err.Error()})
+			return
+		}
+		if req.Provider != "" {
+			if _, ok := providers[req.Provider]; !ok {
+				writeJSON(w, http.StatusBadRequest, JobResponse{Error: "provider not available: " + req.Provider})
+				return
+			}
+		}
+		if req.CallbackURL != "" {
+			if notifier == nil {
+				writeJSON(w, http.StatusBadRequest, JobResponse{Error: "webhooks are not configured on this server"})
+				return
+			}
+			if err := notifier.ValidateCallback(req.CallbackURL); err != nil {
+				writeJSON(w, http.StatusBadRequest, JobResponse{Error: err.Error()})
+				return
+			}
+		}
+
+		job, err := store.Create(model.Job{
+			UserID:      user.UserID,
+			URL:         req.URL,
+			Provider:    req.Provider,
+			NoCache:     req.NoCache,
+			CallbackURL: req.CallbackURL,
+		})
+		if err != nil {
+			slog.ErrorContext(r.Context(), "jobs: create failed",
+				slog.String("handler", "jobs"),
+				slog.Int64("user_id", user.UserID),
+				slog.String("error", err.Error()),
+			)
+			writeJSON(w, http.StatusInternalServerError, JobResponse{Error: "internal server error"})
+			return
+		}
+		runner.Notify()
+
+		slog.InfoContext(r.Context(), "jobs: queued",
+			slog.String("handler", "jobs"),
+			slog.Int64("job_id", job.ID),
+			slog.String("url", job.URL),
+		)
+		w.Header().Set("Location", "/api/jobs/"+strconv.FormatInt(job.ID, 10))
+		writeJSON(w, http.StatusAccepted, JobResponse{Job: job})
+	}
+}
+
+// HandleGetJob returns a handler for GET /api/jobs/{id}, reporting the
+// job's status, stage and, once finished, its result.
+func HandleGetJob(store *jobs.Store) http.HandlerFunc {
+	return jobHandler("get", func(r *http.Request, userID, id int64) (*model.Job, int, error) {
+		job, err := store.Get(userID, id)
+		return job, http.StatusOK, err
+	})
+}
+
+// HandleCancelJob returns a handler for POST /api/jobs/{id}/cancel, which
+// cancels a queued or running job.
+func HandleCancelJob(store *jobs.Store, runner *jobs.Runner) http.HandlerFunc {
+	return jobHandler("cancel", func(r *http.Request, userID, id int64) (*model.Job, int, error) {
+		job, err := store.Cancel(userID, id)
+		if err == nil {
+			runner.Cancel(id)
+		}
+		return job, http.StatusOK, err
+	})
+}
+
+// HandleRetryJob returns a handler for POST /api/jobs/{id}/retry, which
+// queues a failed or cancelled job again.
+func HandleRetryJob(store *jobs.Store, runner *jobs.Runner) http.HandlerFunc {
+	return jobHandler("retry", func(r *http.Request, userID, id int64) (*model.Job, int, error) {
+		job, err := store.Retry(userID, id)
+		if err == nil {
+			runner.Notify()
+		}
+		return job, http.StatusAccepted, err
+	})
+}
+
+// jobHandler serves an endpoint acting on the caller's job {id} with fn,
+// which returns the job and the status to answer with on success.
+func jobHandler(op string, fn func(r *http.Request, userID, id int64) (*model.Job, int, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := auth.UserFromContext(r.Context())
+		if user == nil {
+			writeJSON(w, http.StatusUnauthorized, JobResponse{Error: "authentication required"})
+			return
+		}
+
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, JobResponse{Error: "invalid job id"})
+			return
+		}
+
+		job, status, err := fn(r, user.UserID, id)
+		switch {
+		case err == nil:
+			writeJSON(w, status, JobResponse{Job: job})
+		case errors.Is(err, jobs.ErrNotFound):
+			writeJSON(w, http.StatusNotFound, JobResponse{Error: "job not found"})
+		case errors.Is(err, jobs.ErrConflict):
+			writeJSON(w, http.StatusConflict, JobResponse{Job: job, Error: "cannot " + op + " a job that is " + string(job.Status)})
+		default:
+			slog.ErrorContext(r.Context(), "jobs: "+op+" failed",
+				slog.String("handler", "jobs"),
+				slog.Int64("user_id", user.UserID),
+				slog.Int64("id", id),
+				slog.String("error", err.Error()),
+			)
+			writeJSON(w, http.StatusInternalServerError, JobResponse{Error: "internal server error"})
+		}
+	}
+}
+
+// ProcessJob returns the jobs.Processor running a job like HandleProcess:
+// its result is the ProcessResponse the endpoint would have returned, and
+// a successful run is kept in the owner's history.
+func ProcessJob(pipe *pipeline.Pipeline, defaultClient pipeline.Client, providers map[string]llm.Provider, hist *history.Store) jobs.Processor {
+	return func(ctx context.Context, job *model.Job, stage func(string)) (any, error) {
+		var client pipeline.Client = defaultClient
+		if job.Provider != "" {
+			p, ok := providers[job.Provider]
+			if !ok {
+				return nil, errors.New("provider not available: " + job.Provider)
+			}
+			client = p
+		}
+
+		ctx, hits := cacheContext(ctx, job.NoCache)
+		ctx, served := llm.TrackServed(ctx)
+		ctx = pipeline.WithProgress(ctx, func(s pipeline.Stage) { stage(string(s)) })
+		result, err := pipe.Run(ctx, job.URL, client)
+		if result != nil {
+			countClassification(result.Classification)
+		}
+		if err != nil {
+			return ProcessResponse{Result: result, Stage: pipeline.FailedStage(err), Error: err.Error()}, err
+		}
+
+		resp := ProcessResponse{Result: result, Provider: servedProvider(served, job.Provider), Cache: hits()}
+		addHistory(ctx, hist, job.UserID, processEntry(result, resp.Provider), resp)
+		return resp, nil
+	}
+}
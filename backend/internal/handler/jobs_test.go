@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/auth"
+	"github.com/rookiecj/scrum-agents/backend/internal/extractor"
+	"github.com/rookiecj/scrum-agents/backend/internal/history"
+	"github.com/rookiecj/scrum-agents/backend/internal/jobs"
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+	"github.com/rookiecj/scrum-agents/backend/internal/pipeline"
+)
+
+// newJobsMux wires the job routes the way cmd/server/main.go does, with a
+// runner working the queue until the test ends.
+func newJobsMux(t *testing.T, jwtSvc *auth.JWTService) (*http.ServeMux, *history.Store) {
+	t.Helper()
+	db, err := auth.NewStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	hist, err := history.NewStore(db.DB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := jobs.NewStore(db.DB())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &mockPipelineClient{
+		classification: &model.ClassificationResult{Primary: model.CategoryTutorial, Confidence: 0.9},
+		summary:        "## 요약",
+	}
+	pipe := pipeline.New(extractor.NewRegistry(), newTestSummarizer(t))
+	runner := jobs.NewRunner(store, ProcessJob(pipe, client, nil, hist), jobs.Options{})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		runner.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	requireAuth := auth.Middleware(jwtSvc)
+	mux := http.NewServeMux()
+	mux.Handle("POST /api/jobs", requireAuth(HandleCreateJob(store, runner, nil, nil)))
+	mux.Handle("GET /api/jobs/{id}", requireAuth(HandleGetJob(store)))
+	mux.Handle("POST /api/jobs/{id}/cancel", requireAuth(HandleCancelJob(store, runner)))
+	mux.Handle("POST /api/jobs/{id}/retry", requireAuth(HandleRetryJob(store, runner)))
+	return mux, hist
+}
+
+func decodeJob(t *testing.T, rec *httptest.ResponseRecorder) JobResponse {
+	t.Helper()
+	var resp JobResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return resp
+}
+
+func TestJobs_RunToCompletion(t *testing.T) {
+	jwtSvc := auth.NewJWTService("test-secret", time.Hour)
+	mux, hist := newJobsMux(t, jwtSvc)
+	alice, _ := jwtSvc.GenerateToken(1, "alice@example.com")
+	bob, _ := jwtSvc.GenerateToken(2, "bob@example.com")
+
+	htmlServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>Test Page</title></head><body><p>Hello from test server.</p></body></html>`))
+	}))
+	defer htmlServer.Close()
+
+	rec := doRequest(mux, "POST", "/api/jobs", alice, JobRequest{URL: htmlServer.URL + "/post"})
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("create: status = %d, want 202: %s", rec.Code, rec.Body)
+	}
+	created := decodeJob(t, rec).Job
+	path := fmt.Sprintf("/api/jobs/%d", created.ID)
+	if rec.Header().Get("Location") != path || created.Status != model.JobQueued {
+		t.Errorf("create: location = %q, job = %+v", rec.Header().Get("Location"), created)
+	}
+
+	var job *model.Job
+	for deadline := time.Now().Add(2 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		rec = doRequest(mux, "GET", path, alice, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("get: status = %d", rec.Code)
+		}
+		if job = decodeJob(t, rec).Job; job.Status.Done() || time.Now().After(deadline) {
+			break
+		}
+	}
+	if job.Status != model.JobSucceeded || job.Stage != string(pipeline.StageSummarize) {
+		t.Fatalf("job = %+v, want succeeded", job)
+	}
+	var result ProcessResponse
+	if err := json.Unmarshal(job.Result, &result); err != nil || result.Result == nil || result.LinkInfo.Title != "Test Page" || result.Summary == nil {
+		t.Errorf("result = %s, %v", job.Result, err)
+	}
+	if entries, total, _ := hist.List(1, history.Filter{}); total != 1 || entries[0].Kind != model.HistoryProcess {
+		t.Errorf("history = %+v, want the job's result", entries)
+	}
+
+	if rec := doRequest(mux, "GET", path, bob, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("get by another user: status = %d, want 404", rec.Code)
+	}
+	if rec := doRequest(mux, "POST", path+"/cancel", alice, nil); rec.Code != http.StatusConflict {
+		t.Errorf("cancel finished job: status = %d, want 409", rec.Code)
+	}
+	if rec := doRequest(mux, "POST", path+"/retry", alice, nil); rec.Code != http.StatusConflict {
+		t.Errorf("retry succeeded job: status = %d, want 409", rec.Code)
+	}
+}
+
+func TestJobs_CreateErrors(t *testing.T) {
+	jwtSvc := auth.NewJWTService("test-secret", time.Hour)
+	mux, _ := newJobsMux(t, jwtSvc)
+	token, _ := jwtSvc.GenerateToken(1, "alice@example.com")
+
+	tests := []struct {
+		name       string
+		token      string
+		body       any
+		wantStatus int
+	}{
+		{name: "no token", body: JobRequest{URL: "https://example.com"}, wantStatus: http.StatusUnauthorized},
+		{name: "missing url", token: token, body: JobRequest{}, wantStatus: http.StatusBadRequest},
+		{name: "invalid url", token: token, body: JobRequest{URL: "://bad"}, wantStatus: http.StatusBadRequest},
+		{name: "unknown provider", token: token, body: JobRequest{URL: "https://example.com", Provider: "nope"}, wantStatus: http.StatusBadRequest},
+		{name: "webhooks not configured", token: token, body: JobRequest{URL: "https://example.com", CallbackURL: "https://hooks.example.com"}, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := doRequest(mux, "POST", "/api/jobs", tt.token, tt.body); rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+
+	if rec := doRequest(mux, "GET", "/api/jobs/abc", token, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid id: status = %d, want 400", rec.Code)
+	}
+	if rec := doRequest(mux, "POST", "/api/jobs/999/cancel", token, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("cancel unknown job: status = %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/llm"
+)
+
+// llmErrorStatus maps an LLM failure to the HTTP status returned to the
+// client. For rate limits it also sets Retry-After when the provider gave one.
+// Errors that are not LLM API errors map to 500.
+func llmErrorStatus(w http.ResponseWriter, err error) int {
+	switch {
+	case errors.Is(err, llm.ErrRateLimited):
+		var apiErr *llm.APIError
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(apiErr.RetryAfter.Seconds()+0.5)))
+		}
+		return http.StatusTooManyRequests
+	case errors.Is(err, llm.ErrContextTooLong):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, llm.ErrOverloaded), errors.Is(err, llm.ErrUnavailable), errors.Is(err, llm.ErrQuotaExceeded):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, llm.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, llm.ErrAuthFailed), errors.Is(err, llm.ErrInvalidRequest), errors.Is(err, llm.ErrUnexpectedReply):
+		// The server's own credentials or request were rejected upstream.
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/llm"
+)
+
+func TestLLMErrorStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		wantStatus     int
+		wantRetryAfter string
+	}{
+		{
+			name:           "rate limited with retry-after",
+			err:            fmt.Errorf("LLM summarization failed: %w", &llm.APIError{Provider: llm.ProviderClaude, Kind: llm.ErrRateLimited, RetryAfter: 7 * time.Second}),
+			wantStatus:     http.StatusTooManyRequests,
+			wantRetryAfter: "7",
+		},
+		{name: "context too long", err: &llm.APIError{Kind: llm.ErrContextTooLong}, wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "overloaded", err: &llm.APIError{Kind: llm.ErrOverloaded}, wantStatus: http.StatusServiceUnavailable},
+		{name: "auth failed", err: &llm.APIError{Kind: llm.ErrAuthFailed}, wantStatus: http.StatusBadGateway},
+		{name: "timeout", err: &llm.APIError{Kind: llm.ErrTimeout}, wantStatus: http.StatusGatewayTimeout},
+		{name: "deadline", err: context.DeadlineExceeded, wantStatus: http.StatusGatewayTimeout},
+		{name: "other", err: errors.New("boom"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			if got := llmErrorStatus(rec, tt.err); got != tt.wantStatus {
+				t.Errorf("status = %d, want %d", got, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package handler
+
+import (
+	"github.com/rookiecj/scrum-agents/backend/internal/metrics"
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+)
+
+var classificationsTotal = metrics.NewCounter("classifications_total",
+	"Classifications returned to clients, by primary category.",
+	"category")
+
+// countClassification records the primary category of result, if any.
+func countClassification(result *model.ClassificationResult) {
+	if result != nil {
+		classificationsTotal.Inc(string(result.Primary))
+	}
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+)
+
+func TestHandleClassify_CountsCategory(t *testing.T) {
+	cls := &mockClassifier{result: &model.ClassificationResult{Primary: model.CategoryNews, Confidence: 0.9}}
+	handler := HandleClassify(cls, nil, nil)
+	before := classificationsTotal.Value(string(model.CategoryNews))
+
+	// Rejected requests are not counted.
+	for _, body := range []string{`{"content":"headline"}`, `{"content":"another"}`, `{"content":""}`} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/classify", bytes.NewBufferString(body)))
+	}
+
+	if got := classificationsTotal.Value(string(model.CategoryNews)) - before; got != 2 {
+		t.Errorf("news classifications = %v, want 2", got)
+	}
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/cache"
+	"github.com/rookiecj/scrum-agents/backend/internal/history"
+	"github.com/rookiecj/scrum-agents/backend/internal/llm"
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+	"github.com/rookiecj/scrum-agents/backend/internal/pipeline"
+)
+
+// ProcessRequest is the request body for the process endpoint.
+type ProcessRequest struct {
+	URL      string `json:"url"`
+	Provider string `json:"provider,omitempty"`
+	NoCache  bool   `json:"no_cache,omitempty"`
+}
+
+// ProcessResponse is the response body for the process endpoint.
+// On failure, Stage names the pipeline stage that failed and the fields of
+// the stages that completed are still populated.
+type ProcessResponse struct {
+	*pipeline.Result
+	Provider string         `json:"provider,omitempty"`
+	Cache    cache.Hits     `json:"cache,omitempty"`
+	Stage    pipeline.Stage `json:"stage,omitempty"`
+	Error    string         `json:"error,omitempty"`
+}
+
+// HandleProcess returns a handler that runs detect, extract, classify and
+// summarize for a URL in a single call.
+// It accepts an optional "provider" field in the request to select the LLM provider;
+// the response names the provider that served the summary and which stages
+// were served from the cache. A "no_cache" flag bypasses cached results.
+func HandleProcess(pipe *pipeline.Pipeline, defaultClient pipeline.Client, providers map[string]llm.Provider, hist *history.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req ProcessRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.WarnContext(r.Context(), "process: invalid request body",
+				slog.String("handler", "process"),
+				slog.String("error", err.Error()),
+			)
+			writeJSON(w, http.StatusBadRequest, ProcessResponse{Error: "invalid request body"})
+			return
+		}
+
+		if req.URL == "" {
+			slog.WarnContext(r.Context(), "process: empty url",
+				slog.String("handler", "process"),
+			)
+			writeJSON(w, http.StatusBadRequest, ProcessResponse{Error: "url is required"})
+			return
+		}
+
+		// Select LLM client based on requested provider
+		var client pipeline.Client = defaultClient
+		if req.Provider != "" {
+			if p, ok := providers[req.Provider]; ok {
+				client = p
+			} else {
+				writeJSON(w, http.StatusBadRequest, ProcessResponse{Error: "provider not available: " + req.Provider})
+				return
+			}
+		}
+
+		ctx, hits := cacheContext(r.Context(), req.NoCache)
+		ctx, served := llm.TrackServed(ctx)
+		result, err := pipe.Run(ctx, req.URL, client)
+		// The classification is kept even when summarizing failed.
+		if result != nil {
+			countClassification(result.Classification)
+		}
+		if err != nil {
+			stage := pipeline.FailedStage(err)
+			slog.ErrorContext(r.Context(), "process: pipeline failed",
+				slog.String("handler", "process"),
+				slog.String("url", req.URL),
+				slog.String("stage", string(stage)),
+				slog.String("error", err.Error()),
+			)
+			status := http.StatusBadRequest
+			if stage != pipeline.StageDetect {
+				status = llmErrorStatus(w, err)
+			}
+			writeJSON(w, status, ProcessResponse{Result: result, Stage: stage, Error: err.Error()})
+			return
+		}
+
+		slog.DebugContext(r.Context(), "process: success",
+			slog.String("handler", "process"),
+			slog.String("url", req.URL),
+			slog.String("link_type", string(result.LinkInfo.LinkType)),
+			slog.String("template_used", result.Summary.TemplateUsed),
+			slog.Float64("total_ms", result.Timings.TotalMs),
+		)
+		resp := ProcessResponse{Result: result, Provider: servedProvider(served, req.Provider), Cache: hits()}
+		recordHistory(r, hist, processEntry(result, resp.Provider), resp)
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// processEntry builds the history entry of a completed pipeline run.
+func processEntry(result *pipeline.Result, provider string) model.HistoryEntry {
+	return model.HistoryEntry{
+		Kind:     model.HistoryProcess,
+		URL:      result.LinkInfo.URL,
+		LinkType: result.LinkInfo.LinkType,
+		Title:    result.LinkInfo.Title,
+		Author:   result.LinkInfo.Author,
+		SiteName: result.LinkInfo.SiteName,
+		Category: result.Summary.Category,
+		Provider: provider,
+	}
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/extractor"
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+	"github.com/rookiecj/scrum-agents/backend/internal/pipeline"
+)
+
+// mockPipelineClient is a test double for pipeline.Client.
+type mockPipelineClient struct {
+	classification *model.ClassificationResult
+	classifyErr    error
+	summary        string
+}
+
+func (m *mockPipelineClient) Classify(ctx context.Context, content string) (*model.ClassificationResult, error) {
+	return m.classification, m.classifyErr
+}
+
+func (m *mockPipelineClient) Complete(ctx context.Context, prompt string) (string, error) {
+	return m.summary, nil
+}
+
+func TestHandleProcess(t *testing.T) {
+	pipe := pipeline.New(extractor.NewRegistry(), newTestSummarizer(t))
+
+	htmlServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>Test Page</title></head><body><p>Hello from test server.</p></body></html>`))
+	}))
+	defer htmlServer.Close()
+
+	good := &mockPipelineClient{
+		classification: &model.ClassificationResult{Primary: model.CategoryTutorial, Confidence: 0.9},
+		summary:        "## 요약",
+	}
+	failing := &mockPipelineClient{classifyErr: errors.New("API down")}
+
+	tests := []struct {
+		name       string
+		client     pipeline.Client
+		body       string
+		wantStatus int
+		wantStage  pipeline.Stage
+	}{
+		{
+			name:       "full pipeline",
+			client:     good,
+			body:       `{"url":"` + htmlServer.URL + `/post"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "classification failure names stage",
+			client:     failing,
+			body:       `{"url":"` + htmlServer.URL + `/post"}`,
+			wantStatus: http.StatusInternalServerError,
+			wantStage:  pipeline.StageClassify,
+		},
+		{
+			name:       "missing url",
+			client:     good,
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown provider",
+			client:     good,
+			body:       `{"url":"` + htmlServer.URL + `","provider":"nope"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid body",
+			client:     good,
+			body:       `not json`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := HandleProcess(pipe, tt.client, nil, nil)
+			req := httptest.NewRequest("POST", "/api/process", bytes.NewBufferString(tt.body))
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+
+			var resp ProcessResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+
+			if resp.Stage != tt.wantStage {
+				t.Errorf("stage = %q, want %q", resp.Stage, tt.wantStage)
+			}
+			if tt.wantStatus == http.StatusOK {
+				if resp.Result == nil || resp.Summary == nil || resp.Classification == nil {
+					t.Fatalf("expected link info, classification and summary, got %+v", resp.Result)
+				}
+				if resp.LinkInfo.Title != "Test Page" {
+					t.Errorf("title = %q, want %q", resp.LinkInfo.Title, "Test Page")
+				}
+			} else if resp.Error == "" {
+				t.Error("expected error in response")
+			}
+		})
+	}
+}
@@ -1,9 +1,12 @@
 package handler
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 	"os"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/llm"
 )
 
 // ProviderInfo describes a provider's availability for the frontend.
@@ -23,11 +26,21 @@ var KnownProviders = []struct {
 	{Name: "gemini", EnvVar: "GOOGLE_API_KEY"},
 }
 
+// ReachabilityCheck describes a provider whose availability is determined by
+// Ping rather than an API key, such as a local Ollama server or a declared
+// OpenAI-compatible endpoint.
+type ReachabilityCheck struct {
+	Name   string
+	EnvVar string
+	Ping   func(ctx context.Context) error
+}
+
 // HandleProviders returns a handler that lists all known LLM providers with availability status.
-// Availability is determined by checking whether the corresponding environment variable is set.
-func HandleProviders() http.HandlerFunc {
+// Availability is determined by checking whether the corresponding environment variable is set,
+// or, for each of checks, whether its server is reachable.
+func HandleProviders(checks ...ReachabilityCheck) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		providers := make([]ProviderInfo, 0, len(KnownProviders))
+		providers := make([]ProviderInfo, 0, len(KnownProviders)+len(checks))
 		for _, kp := range KnownProviders {
 			available := os.Getenv(kp.EnvVar) != ""
 			providers = append(providers, ProviderInfo{
@@ -36,8 +49,23 @@ func HandleProviders() http.HandlerFunc {
 				EnvVar:    kp.EnvVar,
 			})
 		}
+		for _, c := range checks {
+			err := c.Ping(r.Context())
+			if err != nil {
+				slog.DebugContext(r.Context(), "providers: provider unreachable",
+					slog.String("handler", "providers"),
+					slog.String("provider", c.Name),
+					slog.String("error", err.Error()),
+				)
+			}
+			providers = append(providers, ProviderInfo{
+				Name:      c.Name,
+				Available: err == nil,
+				EnvVar:    c.EnvVar,
+			})
+		}
 
-		slog.Debug("providers: listing available providers",
+		slog.DebugContext(r.Context(), "providers: listing available providers",
 			slog.String("handler", "providers"),
 			slog.Int("count", len(providers)),
 		)
@@ -45,3 +73,12 @@ func HandleProviders() http.HandlerFunc {
 		writeJSON(w, http.StatusOK, providers)
 	}
 }
+
+// servedProvider returns the provider recorded by llm.TrackServed, or the
+// requested one when the client did not report it (e.g. a plain provider).
+func servedProvider(served func() llm.ProviderType, requested string) string {
+	if p := served(); p != "" {
+		return string(p)
+	}
+	return requested
+}
@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -178,3 +180,39 @@ func searchString(s, substr string) bool {
 	}
 	return false
 }
+
+func TestHandleProviders_ReachabilityCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		ping      error
+		wantAvail bool
+	}{
+		{name: "reachable", ping: nil, wantAvail: true},
+		{name: "unreachable", ping: errors.New("connection refused"), wantAvail: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := HandleProviders(ReachabilityCheck{
+				Name:   "ollama",
+				EnvVar: "OLLAMA_BASE_URL",
+				Ping:   func(ctx context.Context) error { return tt.ping },
+			})
+			req := httptest.NewRequest("GET", "/api/providers", nil)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			var providers []ProviderInfo
+			if err := json.NewDecoder(rec.Body).Decode(&providers); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if len(providers) != 4 {
+				t.Fatalf("got %d providers, want 4", len(providers))
+			}
+			last := providers[3]
+			if last.Name != "ollama" || last.Available != tt.wantAvail {
+				t.Errorf("ollama = %+v, want available=%v", last, tt.wantAvail)
+			}
+		})
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"log/slog"
 	"net/http"
 
+	"github.com/rookiecj/scrum-agents/backend/internal/cache"
+	"github.com/rookiecj/scrum-agents/backend/internal/history"
 	"github.com/rookiecj/scrum-agents/backend/internal/llm"
 	"github.com/rookiecj/scrum-agents/backend/internal/model"
 	"github.com/rookiecj/scrum-agents/backend/internal/summarizer"
@@ -13,25 +15,42 @@ import (
 // SummarizeRequest is the request body for the summarize endpoint.
 // Accepts either a full Classification object or a Category string.
 type SummarizeRequest struct {
-	Content        string                     `json:"content"`
+	Content        string                      `json:"content"`
 	Classification *model.ClassificationResult `json:"classification,omitempty"`
-	Category       string                     `json:"category,omitempty"`
-	Provider       string                     `json:"provider,omitempty"`
+	Category       string                      `json:"category,omitempty"`
+	Provider       string                      `json:"provider,omitempty"`
+	LinkInfo       *model.LinkInfo             `json:"link_info,omitempty"`
+	Stream         bool                        `json:"stream,omitempty"`
+	NoCache        bool                        `json:"no_cache,omitempty"`
+}
+
+// SummarizeToken is the payload of a "token" event in streaming mode.
+type SummarizeToken struct {
+	Text string `json:"text"`
 }
 
 // SummarizeResponse is the response body for the summarize endpoint.
 type SummarizeResponse struct {
-	Result *summarizer.SummaryResult `json:"result,omitempty"`
-	Error  string                   `json:"error,omitempty"`
+	Result   *summarizer.SummaryResult `json:"result,omitempty"`
+	Provider string                    `json:"provider,omitempty"`
+	Cache    cache.Hits                `json:"cache,omitempty"`
+	Error    string                    `json:"error,omitempty"`
 }
 
 // HandleSummarize returns a handler that summarizes content using type-specific templates.
 // It accepts an optional "provider" field and supports both "classification" (object) and "category" (string).
-func HandleSummarize(s *summarizer.Summarizer, defaultClient summarizer.LLMClient, providers map[string]llm.Provider) http.HandlerFunc {
+// An optional "link_info" field is stored alongside the result in hist.
+// The response names the provider that served the request and whether the
+// summary came from the cache; a "no_cache" flag bypasses cached results.
+//
+// When "stream" is true the response is a text/event-stream: a "token" event
+// per generated fragment, then a "done" event carrying the SummaryResult, or
+// an "error" event if summarization fails.
+func HandleSummarize(s *summarizer.Summarizer, defaultClient summarizer.LLMClient, providers map[string]llm.Provider, hist *history.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req SummarizeRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			slog.Warn("summarize: invalid request body",
+			slog.WarnContext(r.Context(), "summarize: invalid request body",
 				slog.String("handler", "summarize"),
 				slog.String("error", err.Error()),
 			)
@@ -40,7 +59,7 @@ func HandleSummarize(s *summarizer.Summarizer, defaultClient summarizer.LLMClien
 		}
 
 		if req.Content == "" {
-			slog.Warn("summarize: empty content",
+			slog.WarnContext(r.Context(), "summarize: empty content",
 				slog.String("handler", "summarize"),
 			)
 			writeJSON(w, http.StatusBadRequest, SummarizeResponse{Error: "content is required"})
@@ -56,7 +75,7 @@ func HandleSummarize(s *summarizer.Summarizer, defaultClient summarizer.LLMClien
 			}
 		}
 		if classification == nil {
-			slog.Warn("summarize: missing classification and category",
+			slog.WarnContext(r.Context(), "summarize: missing classification and category",
 				slog.String("handler", "summarize"),
 			)
 			writeJSON(w, http.StatusBadRequest, SummarizeResponse{Error: "classification or category is required"})
@@ -74,21 +93,61 @@ func HandleSummarize(s *summarizer.Summarizer, defaultClient summarizer.LLMClien
 			}
 		}
 
-		result, err := s.Summarize(client, req.Content, classification)
+		if req.Stream {
+			streamSummary(w, r, s, client, &req, classification, hist)
+			return
+		}
+
+		ctx, hits := cacheContext(r.Context(), req.NoCache)
+		ctx, served := llm.TrackServed(ctx)
+		result, err := s.Summarize(ctx, client, req.Content, classification)
 		if err != nil {
-			slog.Error("summarize: summarization failed",
+			slog.ErrorContext(r.Context(), "summarize: summarization failed",
 				slog.String("handler", "summarize"),
 				slog.String("category", string(classification.Primary)),
 				slog.String("error", err.Error()),
 			)
-			writeJSON(w, http.StatusInternalServerError, SummarizeResponse{Error: "summarization failed: " + err.Error()})
+			writeJSON(w, llmErrorStatus(w, err), SummarizeResponse{Error: "summarization failed: " + err.Error()})
 			return
 		}
 
-		slog.Debug("summarize: success",
+		slog.DebugContext(r.Context(), "summarize: success",
 			slog.String("handler", "summarize"),
 			slog.String("template_used", result.TemplateUsed),
+			slog.Int("chunks", result.Chunks),
+		)
+		resp := SummarizeResponse{Result: result, Provider: servedProvider(served, req.Provider), Cache: hits()}
+		recordHistory(r, hist, historyEntryFor(model.HistorySummarize, req.LinkInfo, result.Category, resp.Provider), resp)
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// streamSummary runs summarization in streaming mode and writes SSE events.
+func streamSummary(w http.ResponseWriter, r *http.Request, s *summarizer.Summarizer, client summarizer.LLMClient,
+	req *SummarizeRequest, classification *model.ClassificationResult, hist *history.Store) {
+	sse := newSSEWriter(w, r)
+
+	ctx, hits := cacheContext(r.Context(), req.NoCache)
+	ctx, served := llm.TrackServed(ctx)
+	result, err := s.SummarizeStream(ctx, client, req.Content, classification, func(text string) error {
+		return sse.send("token", SummarizeToken{Text: text})
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "summarize: streaming summarization failed",
+			slog.String("handler", "summarize"),
+			slog.String("category", string(classification.Primary)),
+			slog.String("error", err.Error()),
 		)
-		writeJSON(w, http.StatusOK, SummarizeResponse{Result: result})
+		sse.send("error", SummarizeResponse{Error: "summarization failed: " + err.Error()})
+		return
 	}
+
+	slog.DebugContext(r.Context(), "summarize: stream complete",
+		slog.String("handler", "summarize"),
+		slog.String("template_used", result.TemplateUsed),
+		slog.Int("chunks", result.Chunks),
+	)
+	resp := SummarizeResponse{Result: result, Provider: servedProvider(served, req.Provider), Cache: hits()}
+	recordHistory(r, hist, historyEntryFor(model.HistorySummarize, req.LinkInfo, result.Category, resp.Provider), resp)
+	sse.send("done", resp)
 }
@@ -2,11 +2,15 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/rookiecj/scrum-agents/backend/internal/model"
 	"github.com/rookiecj/scrum-agents/backend/internal/summarizer"
@@ -17,7 +21,7 @@ type mockSummarizerLLM struct {
 	err      error
 }
 
-func (m *mockSummarizerLLM) Complete(prompt string) (string, error) {
+func (m *mockSummarizerLLM) Complete(ctx context.Context, prompt string) (string, error) {
 	return m.response, m.err
 }
 
@@ -49,7 +53,7 @@ func TestHandleSummarize(t *testing.T) {
 	s := newTestSummarizer(t)
 	client := &mockSummarizerLLM{response: "## 핵심 원리\nTCP is..."}
 
-	handler := HandleSummarize(s, client, nil)
+	handler := HandleSummarize(s, client, nil, nil)
 
 	tests := []struct {
 		name       string
@@ -136,7 +140,7 @@ func TestHandleSummarize_LLMError(t *testing.T) {
 	s := newTestSummarizer(t)
 	client := &mockSummarizerLLM{err: fmt.Errorf("API down")}
 
-	handler := HandleSummarize(s, client, nil)
+	handler := HandleSummarize(s, client, nil, nil)
 
 	body := SummarizeRequest{
 		Content: "test content",
@@ -155,3 +159,113 @@ func TestHandleSummarize_LLMError(t *testing.T) {
 		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
 	}
 }
+
+// mockStreamingLLM is a summarizer.StreamingLLMClient emitting fixed chunks,
+// each after delay.
+type mockStreamingLLM struct {
+	chunks []string
+	delay  time.Duration
+	err    error
+}
+
+func (m *mockStreamingLLM) Complete(ctx context.Context, prompt string) (string, error) {
+	return strings.Join(m.chunks, ""), m.err
+}
+
+func (m *mockStreamingLLM) Stream(ctx context.Context, prompt string, onToken func(string) error) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+	for _, c := range m.chunks {
+		time.Sleep(m.delay)
+		if err := onToken(c); err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(m.chunks, ""), nil
+}
+
+func TestHandleSummarize_Stream(t *testing.T) {
+	s := newTestSummarizer(t)
+
+	tests := []struct {
+		name       string
+		client     summarizer.LLMClient
+		wantEvents []string
+	}{
+		{
+			name:       "tokens then done",
+			client:     &mockStreamingLLM{chunks: []string{"## 핵심", " 원리"}},
+			wantEvents: []string{"token", "token", "done"},
+		},
+		{
+			name:       "provider error",
+			client:     &mockStreamingLLM{err: fmt.Errorf("API down")},
+			wantEvents: []string{"error"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := HandleSummarize(s, tt.client, nil, nil)
+			body := `{"content":"TCP works by...","category":"원리소개","stream":true}`
+			req := httptest.NewRequest("POST", "/api/summarize", strings.NewReader(body))
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+				t.Errorf("Content-Type = %q, want text/event-stream", ct)
+			}
+
+			var events []string
+			var lastData string
+			for _, line := range strings.Split(w.Body.String(), "\n") {
+				if ev, ok := strings.CutPrefix(line, "event: "); ok {
+					events = append(events, ev)
+				}
+				if data, ok := strings.CutPrefix(line, "data: "); ok {
+					lastData = data
+				}
+			}
+			if strings.Join(events, ",") != strings.Join(tt.wantEvents, ",") {
+				t.Errorf("events = %v, want %v", events, tt.wantEvents)
+			}
+
+			if tt.wantEvents[len(tt.wantEvents)-1] == "done" {
+				var resp SummarizeResponse
+				if err := json.Unmarshal([]byte(lastData), &resp); err != nil {
+					t.Fatalf("failed to decode done event: %v", err)
+				}
+				if resp.Result == nil || resp.Result.Summary != "## 핵심 원리" {
+					t.Errorf("done result = %+v", resp.Result)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleSummarize_StreamOutlastsWriteTimeout(t *testing.T) {
+	client := &mockStreamingLLM{chunks: []string{"## 핵심", " 원리", "."}, delay: 50 * time.Millisecond}
+	srv := httptest.NewUnstartedServer(HandleSummarize(newTestSummarizer(t), client, nil, nil))
+	srv.Config.WriteTimeout = 60 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	body := `{"content":"TCP works by...","category":"원리소개","stream":true}`
+	res, err := http.Post(srv.URL, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	stream, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("stream cut off after %q: %v", stream, err)
+	}
+	if !strings.Contains(string(stream), "event: done") {
+		t.Errorf("stream = %q, want a done event", stream)
+	}
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/auth"
+	"github.com/rookiecj/scrum-agents/backend/internal/usage"
+)
+
+// Bounds of the usage report period, in days.
+const (
+	defaultUsageDays = 30
+	maxUsageDays     = 366
+)
+
+// UsageResponse is the response body for GET /api/usage and
+// GET /api/admin/usage. Costs are in US dollars.
+type UsageResponse struct {
+	From       string        `json:"from,omitempty"` // First day of the period, UTC
+	To         string        `json:"to,omitempty"`   // Last day of the period, UTC
+	Total      *usage.Totals `json:"total,omitempty"`
+	ByDay      []usage.Group `json:"by_day,omitempty"`
+	ByModel    []usage.Group `json:"by_model,omitempty"`
+	ByEndpoint []usage.Group `json:"by_endpoint,omitempty"`
+	ByUser     []usage.Group `json:"by_user,omitempty"` // Admin report only
+	Error      string        `json:"error,omitempty"`
+}
+
+// HandleUsage returns a handler for GET /api/usage, reporting the caller's
+// own LLM usage. The days query parameter sets the period (default 30).
+func HandleUsage(store *usage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := auth.UserFromContext(r.Context())
+		if user == nil {
+			writeJSON(w, http.StatusUnauthorized, UsageResponse{Error: "authentication required"})
+			return
+		}
+		serveUsage(w, r, store, user.UserID, usage.ByDay, usage.ByModel, usage.ByEndpoint)
+	}
+}
+
+// HandleAdminUsage returns a handler for GET /api/admin/usage, reporting the
+// usage of all users. It must be mounted behind auth.RequireAdmin.
+func HandleAdminUsage(store *usage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		serveUsage(w, r, store, 0, usage.ByDay, usage.ByModel, usage.ByEndpoint, usage.ByUser)
+	}
+}
+
+func serveUsage(w http.ResponseWriter, r *http.Request, store *usage.Store, userID int64, dims ...usage.Dimension) {
+	days := defaultUsageDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxUsageDays {
+			writeJSON(w, http.StatusBadRequest, UsageResponse{Error: "days must be between 1 and 366"})
+			return
+		}
+		days = n
+	}
+
+	now := time.Now().UTC()
+	from := now.Truncate(24*time.Hour).AddDate(0, 0, 1-days)
+	filter := usage.Filter{UserID: userID, Since: from}
+
+	resp := UsageResponse{From: from.Format(time.DateOnly), To: now.Format(time.DateOnly)}
+	total, err := store.Total(filter)
+	if err == nil {
+		resp.Total = &total
+		for _, d := range dims {
+			var groups []usage.Group
+			if groups, err = store.Breakdown(filter, d); err != nil {
+				break
+			}
+			switch d {
+			case usage.ByDay:
+				resp.ByDay = groups
+			case usage.ByModel:
+				resp.ByModel = groups
+			case usage.ByEndpoint:
+				resp.ByEndpoint = groups
+			case usage.ByUser:
+				resp.ByUser = groups
+			}
+		}
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "usage: report failed",
+			slog.String("handler", "usage"),
+			slog.String("error", err.Error()),
+		)
+		writeJSON(w, http.StatusInternalServerError, UsageResponse{Error: "internal server error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/auth"
+	"github.com/rookiecj/scrum-agents/backend/internal/usage"
+)
+
+func TestUsage(t *testing.T) {
+	store := testStore(t)
+	alice, _ := store.CreateUser("alice@example.com", "hash")
+	bob, _ := store.CreateUser("bob@example.com", "hash")
+	usageStore, err := usage.NewStore(store.DB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().AddDate(0, 0, -40)
+	usageStore.Add(
+		usage.Record{UserID: alice.ID, Endpoint: "POST /api/summarize", Provider: "claude", Model: "c", InputTokens: 100, OutputTokens: 10, CostUSD: 0.5},
+		usage.Record{UserID: alice.ID, Endpoint: "POST /api/summarize", Provider: "claude", Model: "c", InputTokens: 100, OutputTokens: 10, CostUSD: 0.5, CreatedAt: old},
+		usage.Record{UserID: bob.ID, Endpoint: "POST /api/classify", Provider: "openai", Model: "g", InputTokens: 50, OutputTokens: 5, CostUSD: 0.1},
+	)
+
+	jwtSvc := auth.NewJWTService("test-secret", time.Hour)
+	requireAuth := auth.Middleware(jwtSvc)
+	mux := http.NewServeMux()
+	mux.Handle("GET /api/usage", requireAuth(HandleUsage(usageStore)))
+	mux.Handle("GET /api/admin/usage", requireAuth(auth.RequireAdmin([]string{"bob@example.com"})(HandleAdminUsage(usageStore))))
+	aliceToken, _ := jwtSvc.GenerateToken(alice.ID, alice.Email)
+	bobToken, _ := jwtSvc.GenerateToken(bob.ID, bob.Email)
+
+	get := func(path, token string) (int, UsageResponse) {
+		rec := doRequest(mux, "GET", path, token, nil)
+		var resp UsageResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		return rec.Code, resp
+	}
+
+	code, resp := get("/api/usage", aliceToken)
+	if code != http.StatusOK || resp.Total == nil {
+		t.Fatalf("GET /api/usage: %d %+v", code, resp)
+	}
+	if resp.Total.Calls != 1 || len(resp.ByModel) != 1 || resp.ByModel[0].Key != "claude/c" || resp.ByUser != nil {
+		t.Errorf("own usage = %+v, want alice's recent call only", resp)
+	}
+	if _, resp := get("/api/usage?days=60", aliceToken); resp.Total == nil || resp.Total.Calls != 2 {
+		t.Errorf("own usage over 60 days = %+v, want 2 calls", resp.Total)
+	}
+	for _, q := range []string{"0", "367", "x"} {
+		if code, _ := get("/api/usage?days="+q, aliceToken); code != http.StatusBadRequest {
+			t.Errorf("days=%s: status = %d, want 400", q, code)
+		}
+	}
+	if code, _ := get("/api/usage", ""); code != http.StatusUnauthorized {
+		t.Errorf("anonymous: status = %d, want 401", code)
+	}
+
+	if code, _ := get("/api/admin/usage", aliceToken); code != http.StatusForbidden {
+		t.Errorf("admin usage as non-admin: status = %d, want 403", code)
+	}
+	code, resp = get("/api/admin/usage", bobToken)
+	if code != http.StatusOK || resp.Total == nil || resp.Total.Calls != 2 || len(resp.ByUser) != 2 {
+		t.Fatalf("admin usage: %d %+v", code, resp)
+	}
+	if resp.ByUser[0].Key != "alice@example.com" {
+		t.Errorf("top spender = %q, want alice", resp.ByUser[0].Key)
+	}
+}
@@ -0,0 +1,218 @@
+// Package history persists pipeline results per user so that previously
+// processed links can be looked up instead of re-running paid LLM calls.
+package history
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+)
+
+var ErrNotFound = errors.New("history entry not found")
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// Filter narrows a history listing. Zero values mean "no filter".
+type Filter struct {
+	Kind     model.HistoryKind
+	Category model.ContentCategory
+	LinkType model.LinkType
+	Limit    int
+	Offset   int
+}
+
+// Store manages history persistence with SQLite.
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore initialises the history table on the given database.
+// The database is typically shared with auth.Store.
+func NewStore(db *sql.DB) (*Store, error) {
+	const createTable = `
+		CREATE TABLE IF NOT EXISTS history (
+			id         INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id    INTEGER NOT NULL,
+			kind       TEXT    NOT NULL,
+			url        TEXT    NOT NULL DEFAULT '',
+			link_type  TEXT    NOT NULL DEFAULT '',
+			title      TEXT    NOT NULL DEFAULT '',
+			author     TEXT    NOT NULL DEFAULT '',
+			site_name  TEXT    NOT NULL DEFAULT '',
+			category   TEXT    NOT NULL DEFAULT '',
+			provider   TEXT    NOT NULL DEFAULT '',
+			result     TEXT    NOT NULL,
+			created_at TEXT    NOT NULL DEFAULT (datetime('now'))
+		);
+		CREATE INDEX IF NOT EXISTS idx_history_user ON history (user_id, id);`
+
+	if _, err := db.Exec(createTable); err != nil {
+		return nil, fmt.Errorf("create history table: %w", err)
+	}
+	if err := addMissingColumns(db, "author", "site_name"); err != nil {
+		return nil, err
+	}
+
+	return &Store{db: db}, nil
+}
+
+// addMissingColumns adds text columns introduced after a database was
+// created, so that existing history survives upgrades.
+func addMissingColumns(db *sql.DB, columns ...string) error {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info('history')`)
+	if err != nil {
+		return fmt.Errorf("inspect history table: %w", err)
+	}
+	have := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return fmt.Errorf("inspect history table: %w", err)
+		}
+		have[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("inspect history table: %w", err)
+	}
+
+	for _, c := range columns {
+		if have[c] {
+			continue
+		}
+		if _, err := db.Exec(`ALTER TABLE history ADD COLUMN ` + c + ` TEXT NOT NULL DEFAULT ''`); err != nil {
+			return fmt.Errorf("add history column %s: %w", c, err)
+		}
+	}
+	return nil
+}
+
+// Add inserts a history entry and returns it with ID and CreatedAt set.
+func (s *Store) Add(e model.HistoryEntry) (*model.HistoryEntry, error) {
+	const q = `INSERT INTO history (user_id, kind, url, link_type, title, author, site_name, category, provider, result)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	result, err := s.db.Exec(q, e.UserID, string(e.Kind), e.URL, string(e.LinkType), e.Title, e.Author, e.SiteName,
+		string(e.Category), e.Provider, string(e.Result))
+	if err != nil {
+		return nil, fmt.Errorf("insert history entry: %w", err)
+	}
+
+	e.ID, _ = result.LastInsertId()
+	e.CreatedAt = time.Now().UTC()
+	return &e, nil
+}
+
+// List returns the user's entries, newest first, together with the total
+// number of entries matching the filter (ignoring limit and offset).
+func (s *Store) List(userID int64, f Filter) ([]model.HistoryEntry, int, error) {
+	where := []string{"user_id = ?"}
+	args := []any{userID}
+	if f.Kind != "" {
+		where = append(where, "kind = ?")
+		args = append(args, string(f.Kind))
+	}
+	if f.Category != "" {
+		where = append(where, "category = ?")
+		args = append(args, string(f.Category))
+	}
+	if f.LinkType != "" {
+		where = append(where, "link_type = ?")
+		args = append(args, string(f.LinkType))
+	}
+	cond := strings.Join(where, " AND ")
+
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM history WHERE `+cond, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count history: %w", err)
+	}
+
+	limit := f.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+	offset := max(f.Offset, 0)
+
+	q := `SELECT id, user_id, kind, url, link_type, title, author, site_name, category, provider, result, created_at
+		FROM history WHERE ` + cond + ` ORDER BY id DESC LIMIT ? OFFSET ?`
+	rows, err := s.db.Query(q, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query history: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]model.HistoryEntry, 0, limit)
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, *e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate history: %w", err)
+	}
+
+	return entries, total, nil
+}
+
+// Get returns a single entry owned by the user. Returns ErrNotFound if the
+// entry does not exist or belongs to another user.
+func (s *Store) Get(userID, id int64) (*model.HistoryEntry, error) {
+	const q = `SELECT id, user_id, kind, url, link_type, title, author, site_name, category, provider, result, created_at
+		FROM history WHERE id = ? AND user_id = ?`
+	e, err := scanEntry(s.db.QueryRow(q, id, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return e, nil
+}
+
+// Delete removes an entry owned by the user. Returns ErrNotFound if the
+// entry does not exist or belongs to another user.
+func (s *Store) Delete(userID, id int64) error {
+	result, err := s.db.Exec(`DELETE FROM history WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return fmt.Errorf("delete history entry: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanEntry(row scanner) (*model.HistoryEntry, error) {
+	var e model.HistoryEntry
+	var kind, linkType, category, result, createdAt string
+	err := row.Scan(&e.ID, &e.UserID, &kind, &e.URL, &linkType, &e.Title, &e.Author, &e.SiteName, &category, &e.Provider, &result, &createdAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scan history entry: %w", err)
+	}
+
+	e.Kind = model.HistoryKind(kind)
+	e.LinkType = model.LinkType(linkType)
+	e.Category = model.ContentCategory(category)
+	e.Result = []byte(result)
+	e.CreatedAt, _ = time.Parse("2006-01-02 15:04:05", createdAt)
+	return &e, nil
+}
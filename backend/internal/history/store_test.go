@@ -0,0 +1,216 @@
+package history
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+
+	_ "modernc.org/sqlite"
+)
+
+func tempStore(t *testing.T) *Store {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	store, err := NewStore(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func addEntry(t *testing.T, s *Store, e model.HistoryEntry) *model.HistoryEntry {
+	t.Helper()
+	if e.Result == nil {
+		e.Result = []byte(`{}`)
+	}
+	got, err := s.Add(e)
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	return got
+}
+
+func TestAdd(t *testing.T) {
+	s := tempStore(t)
+
+	e := addEntry(t, s, model.HistoryEntry{
+		UserID:   1,
+		Kind:     model.HistorySummarize,
+		URL:      "https://example.com/post",
+		LinkType: model.LinkTypeArticle,
+		Category: model.CategoryTutorial,
+		Result:   []byte(`{"summary":"ok"}`),
+	})
+
+	if e.ID == 0 {
+		t.Error("expected non-zero ID")
+	}
+	if e.CreatedAt.IsZero() {
+		t.Error("expected non-zero CreatedAt")
+	}
+}
+
+func TestNewStore_AddsMissingColumns(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// The table as created before author and site_name were added.
+	_, err = db.Exec(`
+		CREATE TABLE history (
+			id         INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id    INTEGER NOT NULL,
+			kind       TEXT    NOT NULL,
+			url        TEXT    NOT NULL DEFAULT '',
+			link_type  TEXT    NOT NULL DEFAULT '',
+			title      TEXT    NOT NULL DEFAULT '',
+			category   TEXT    NOT NULL DEFAULT '',
+			provider   TEXT    NOT NULL DEFAULT '',
+			result     TEXT    NOT NULL,
+			created_at TEXT    NOT NULL DEFAULT (datetime('now'))
+		);
+		INSERT INTO history (user_id, kind, title, result) VALUES (1, 'detect', 'old', '{}');`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewStore(db)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	old, err := s.Get(1, 1)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if old.Title != "old" || old.Author != "" {
+		t.Errorf("Get() = %+v, want the old entry with no author", old)
+	}
+	e := addEntry(t, s, model.HistoryEntry{UserID: 1, Kind: model.HistoryDetect, Author: "Kim"})
+	if got, _ := s.Get(1, e.ID); got == nil || got.Author != "Kim" {
+		t.Errorf("Get() = %+v, want author Kim", got)
+	}
+
+	// Opening the upgraded database again is a no-op.
+	if _, err := NewStore(db); err != nil {
+		t.Fatalf("NewStore() on upgraded table error = %v", err)
+	}
+}
+
+func TestGet(t *testing.T) {
+	s := tempStore(t)
+	e := addEntry(t, s, model.HistoryEntry{
+		UserID:   1,
+		Kind:     model.HistoryExtract,
+		URL:      "https://example.com/a",
+		LinkType: model.LinkTypeArticle,
+		Title:    "A",
+		Author:   "Kim",
+		SiteName: "Blog",
+		Result:   []byte(`{"content":"hello"}`),
+	})
+
+	got, err := s.Get(1, e.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Title != "A" || got.Author != "Kim" || got.SiteName != "Blog" ||
+		got.Kind != model.HistoryExtract || got.LinkType != model.LinkTypeArticle {
+		t.Errorf("Get() = %+v, want stored fields", got)
+	}
+	if string(got.Result) != `{"content":"hello"}` {
+		t.Errorf("result = %s, want stored payload", got.Result)
+	}
+	if got.CreatedAt.IsZero() {
+		t.Error("expected CreatedAt to be parsed")
+	}
+}
+
+func TestGet_OtherUser(t *testing.T) {
+	s := tempStore(t)
+	e := addEntry(t, s, model.HistoryEntry{UserID: 1, Kind: model.HistoryDetect})
+
+	if _, err := s.Get(2, e.ID); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestList_FilterAndPaginate(t *testing.T) {
+	s := tempStore(t)
+	for i := 0; i < 5; i++ {
+		addEntry(t, s, model.HistoryEntry{UserID: 1, Kind: model.HistorySummarize, LinkType: model.LinkTypeArticle, Category: model.CategoryNews})
+	}
+	addEntry(t, s, model.HistoryEntry{UserID: 1, Kind: model.HistorySummarize, LinkType: model.LinkTypeYouTube, Category: model.CategoryTutorial})
+	addEntry(t, s, model.HistoryEntry{UserID: 2, Kind: model.HistorySummarize, LinkType: model.LinkTypeArticle, Category: model.CategoryNews})
+
+	tests := []struct {
+		name      string
+		filter    Filter
+		wantLen   int
+		wantTotal int
+	}{
+		{name: "all for user", filter: Filter{}, wantLen: 6, wantTotal: 6},
+		{name: "by category", filter: Filter{Category: model.CategoryNews}, wantLen: 5, wantTotal: 5},
+		{name: "by link type", filter: Filter{LinkType: model.LinkTypeYouTube}, wantLen: 1, wantTotal: 1},
+		{name: "by kind", filter: Filter{Kind: model.HistoryDetect}, wantLen: 0, wantTotal: 0},
+		{name: "limit", filter: Filter{Limit: 2}, wantLen: 2, wantTotal: 6},
+		{name: "offset past end", filter: Filter{Limit: 10, Offset: 5}, wantLen: 1, wantTotal: 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, total, err := s.List(1, tt.filter)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if len(entries) != tt.wantLen {
+				t.Errorf("len = %d, want %d", len(entries), tt.wantLen)
+			}
+			if total != tt.wantTotal {
+				t.Errorf("total = %d, want %d", total, tt.wantTotal)
+			}
+			for _, e := range entries {
+				if e.UserID != 1 {
+					t.Errorf("entry %d belongs to user %d", e.ID, e.UserID)
+				}
+			}
+		})
+	}
+}
+
+func TestList_NewestFirst(t *testing.T) {
+	s := tempStore(t)
+	first := addEntry(t, s, model.HistoryEntry{UserID: 1, Kind: model.HistoryDetect})
+	second := addEntry(t, s, model.HistoryEntry{UserID: 1, Kind: model.HistoryDetect})
+
+	entries, _, err := s.List(1, Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries[0].ID != second.ID || entries[1].ID != first.ID {
+		t.Errorf("order = [%d %d], want [%d %d]", entries[0].ID, entries[1].ID, second.ID, first.ID)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	s := tempStore(t)
+	e := addEntry(t, s, model.HistoryEntry{UserID: 1, Kind: model.HistoryDetect})
+
+	if err := s.Delete(2, e.ID); err != ErrNotFound {
+		t.Errorf("delete by other user: expected ErrNotFound, got %v", err)
+	}
+	if err := s.Delete(1, e.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := s.Get(1, e.ID); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+}
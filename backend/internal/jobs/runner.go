@@ -0,0 +1,268 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+)
+
+// Processor runs job, calling stage as it reaches each step, and returns
+// its result. The result is stored even when err is not nil, so it can
+// carry the steps completed before a failure.
+type Processor func(ctx context.Context, job *model.Job, stage func(string)) (result any, err error)
+
+// Options configures a Runner.
+type Options struct {
+	// Workers is the number of jobs run at once. Defaults to 2.
+	Workers int
+	// PollInterval is how often idle workers look for jobs queued without
+	// a Notify, e.g. by a retry before a restart. Defaults to 5 seconds.
+	PollInterval time.Duration
+	// Timeout bounds each run of a job, which fails once it passes.
+	// Defaults to 10 minutes.
+	Timeout time.Duration
+	// MaxAttempts is the number of runs after which a job interrupted by a
+	// shutdown fails instead of returning to the queue. Defaults to 3.
+	MaxAttempts int
+	// Notifier delivers completion webhooks. Jobs with a callback URL get
+	// none when it is nil.
+	Notifier *Notifier
+	// WebhookTimeout bounds the delivery of each completion webhook,
+	// retries included. It runs after the job's own Timeout, so jobs that
+	// time out are reported too. Defaults to the longest the Notifier's
+	// attempts and backoff can take.
+	WebhookTimeout time.Duration
+}
+
+// Runner works the job queue of a Store.
+type Runner struct {
+	store   *Store
+	process Processor
+	opts    Options
+	wake    chan struct{}
+
+	mu          sync.Mutex
+	running     map[int64]active
+	interrupted bool
+}
+
+// active is a job a worker is running.
+type active struct {
+	attempt int
+	cancel  context.CancelFunc
+}
+
+// NewRunner returns a Runner running the jobs of store with process.
+func NewRunner(store *Store, process Processor, opts Options) *Runner {
+	if opts.Workers <= 0 {
+		opts.Workers = 2
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 5 * time.Second
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Minute
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 3
+	}
+	if opts.WebhookTimeout <= 0 && opts.Notifier != nil {
+		opts.WebhookTimeout = opts.Notifier.maxDuration()
+	}
+	return &Runner{
+		store:   store,
+		process: process,
+		opts:    opts,
+		wake:    make(chan struct{}, 1),
+		running: make(map[int64]active),
+	}
+}
+
+// Notify wakes an idle worker to pick up a newly queued job.
+func (r *Runner) Notify() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Cancel stops job id if a worker is running it. The job's status is
+// changed by Store.Cancel.
+func (r *Runner) Cancel(id int64) {
+	r.mu.Lock()
+	a, ok := r.running[id]
+	r.mu.Unlock()
+	if ok {
+		a.cancel()
+	}
+}
+
+// Interrupt stops the jobs in progress, for a shutdown that cannot wait
+// for them any longer. Each returns to the queue to run again after the
+// restart, or fails if it has had MaxAttempts runs. Run's context must be
+// done first, so no more jobs are claimed. A nil *Runner does nothing.
+func (r *Runner) Interrupt() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.interrupted = true
+	running := make(map[int64]active, len(r.running))
+	for id, a := range r.running {
+		running[id] = a
+	}
+	r.mu.Unlock()
+
+	for id, a := range running {
+		requeued, err := r.store.interrupt(id, a.attempt, r.opts.MaxAttempts)
+		if err != nil {
+			slog.Error("jobs: interrupt failed", slog.Int64("job_id", id), slog.String("error", err.Error()))
+		} else if requeued {
+			slog.Warn("jobs: requeued job cut off by shutdown", slog.Int64("job_id", id), slog.Int("attempt", a.attempt))
+		} else {
+			slog.Warn("jobs: failed job cut off by shutdown", slog.Int64("job_id", id), slog.Int("attempt", a.attempt))
+		}
+		a.cancel()
+	}
+}
+
+// Run returns the jobs left running by a previous server to the queue,
+// failing those that have had MaxAttempts runs, then works the queue until
+// ctx is done. It returns once the jobs in progress have finished; they are
+// not cancelled with ctx, so a shutdown drains them, unless it calls
+// Interrupt.
+func (r *Runner) Run(ctx context.Context) {
+	if requeued, failed, err := r.store.requeue(r.opts.MaxAttempts); err != nil {
+		slog.Error("jobs: requeue failed", slog.String("error", err.Error()))
+	} else if requeued > 0 || failed > 0 {
+		slog.Info("jobs: requeued interrupted jobs", slog.Int("count", requeued), slog.Int("failed", failed))
+	}
+
+	var wg sync.WaitGroup
+	for range r.opts.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (r *Runner) work(ctx context.Context) {
+	ticker := time.NewTicker(r.opts.PollInterval)
+	defer ticker.Stop()
+	for ctx.Err() == nil {
+		job, err := r.store.claim()
+		if err != nil {
+			slog.Error("jobs: claim failed", slog.String("error", err.Error()))
+		}
+		if job != nil {
+			r.run(context.WithoutCancel(ctx), job)
+			// Another job may be waiting; look again before sleeping.
+			continue
+		}
+		select {
+		case <-ctx.Done():
+		case <-r.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// run processes one claimed job, for at most the timeout, and records its
+// outcome.
+func (r *Runner) run(parent context.Context, job *model.Job) {
+	ctx, cancel := context.WithTimeout(parent, r.opts.Timeout)
+	defer cancel()
+	r.mu.Lock()
+	r.running[job.ID] = active{attempt: job.Attempts, cancel: cancel}
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		delete(r.running, job.ID)
+		r.mu.Unlock()
+	}()
+
+	log := slog.With(slog.Int64("job_id", job.ID), slog.Int("attempt", job.Attempts))
+	log.InfoContext(ctx, "jobs: started", slog.String("url", job.URL))
+	start := time.Now()
+	result, err := r.process(ctx, job, func(stage string) {
+		if err := r.store.setStage(job.ID, job.Attempts, stage); err != nil {
+			log.WarnContext(ctx, "jobs: stage update failed", slog.String("error", err.Error()))
+		}
+	})
+
+	status, errMsg := model.JobSucceeded, ""
+	if err != nil {
+		status, errMsg = model.JobFailed, err.Error()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			errMsg = fmt.Sprintf("timed out after %s", r.opts.Timeout)
+		}
+	}
+	var payload []byte
+	if result != nil {
+		if payload, err = json.Marshal(result); err != nil {
+			status, errMsg = model.JobFailed, "encode result: "+err.Error()
+			payload = nil
+		}
+	}
+	ok, err := r.store.finish(job.ID, job.Attempts, status, errMsg, payload)
+	if err != nil {
+		log.ErrorContext(ctx, "jobs: recording outcome failed", slog.String("error", err.Error()))
+		return
+	}
+	if !ok {
+		r.mu.Lock()
+		interrupted := r.interrupted
+		r.mu.Unlock()
+		if interrupted {
+			log.InfoContext(ctx, "jobs: interrupted", slog.Float64("duration_ms", msSince(start)))
+		} else {
+			log.InfoContext(ctx, "jobs: cancelled", slog.Float64("duration_ms", msSince(start)))
+		}
+		return
+	}
+	if status == model.JobFailed {
+		log.WarnContext(ctx, "jobs: failed",
+			slog.Float64("duration_ms", msSince(start)),
+			slog.String("error", errMsg),
+		)
+	} else {
+		log.InfoContext(ctx, "jobs: succeeded", slog.Float64("duration_ms", msSince(start)))
+	}
+	r.notify(parent, log, job.ID)
+}
+
+// notify delivers the completion webhook of a finished job, if it has a
+// callback URL, and records the outcome. The delivery gets a deadline of
+// its own rather than what is left of the job's.
+func (r *Runner) notify(parent context.Context, log *slog.Logger, id int64) {
+	if r.opts.Notifier == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(parent), r.opts.WebhookTimeout)
+	defer cancel()
+	job, err := r.store.get(id)
+	if err != nil || job.CallbackURL == "" {
+		return
+	}
+	outcome := "delivered"
+	if err := r.opts.Notifier.Send(ctx, job); err != nil {
+		outcome = err.Error()
+		log.WarnContext(ctx, "jobs: webhook failed", slog.String("error", outcome))
+	}
+	if err := r.store.setCallback(id, outcome); err != nil {
+		log.ErrorContext(ctx, "jobs: recording webhook failed", slog.String("error", err.Error()))
+	}
+}
+
+func msSince(t time.Time) float64 {
+	return float64(time.Since(t).Nanoseconds()) / 1e6
+}
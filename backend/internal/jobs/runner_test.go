@@ -0,0 +1,214 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+)
+
+// waitFor polls job id until cond holds or a second passes.
+func waitFor(t *testing.T, s *Store, id int64, cond func(*model.Job) bool) *model.Job {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		job, err := s.Get(1, id)
+		if err == nil && cond(job) {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %d = %+v, %v; condition not met", id, job, err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func startRunner(t *testing.T, s *Store, process Processor, opts Options) *Runner {
+	t.Helper()
+	r := NewRunner(s, process, opts)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		r.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return r
+}
+
+func TestRunner_RunsJobs(t *testing.T) {
+	s := testStore(t)
+	hook := make(chan string, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hook <- r.Header.Get(EventHeader)
+	}))
+	defer srv.Close()
+
+	r := startRunner(t, s, func(ctx context.Context, job *model.Job, stage func(string)) (any, error) {
+		stage("summarize")
+		if job.URL == "https://example.com/bad" {
+			return map[string]string{"stage": "summarize"}, errors.New("summarize failed")
+		}
+		return map[string]string{"summary": "ok"}, nil
+	}, Options{Notifier: &Notifier{Secret: "s", Allow: loopback}})
+
+	good, _ := s.Create(model.Job{UserID: 1, URL: "https://example.com/good", CallbackURL: srv.URL})
+	bad, _ := s.Create(model.Job{UserID: 1, URL: "https://example.com/bad"})
+	r.Notify()
+
+	got := waitFor(t, s, good.ID, func(j *model.Job) bool { return j.Callback != "" })
+	if got.Status != model.JobSucceeded || string(got.Result) != `{"summary":"ok"}` || got.Callback != "delivered" {
+		t.Errorf("good job = %+v", got)
+	}
+	if ev := <-hook; ev != "job.succeeded" {
+		t.Errorf("webhook event = %q", ev)
+	}
+
+	got = waitFor(t, s, bad.ID, func(j *model.Job) bool { return j.Status.Done() })
+	if got.Status != model.JobFailed || got.Error != "summarize failed" || got.Stage != "summarize" || got.Result == nil {
+		t.Errorf("bad job = %+v", got)
+	}
+}
+
+func TestRunner_Cancel(t *testing.T) {
+	s := testStore(t)
+	started := make(chan struct{})
+	r := startRunner(t, s, func(ctx context.Context, job *model.Job, stage func(string)) (any, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, Options{})
+
+	job, _ := s.Create(model.Job{UserID: 1, URL: "https://example.com/slow"})
+	r.Notify()
+	<-started
+
+	if _, err := s.Cancel(1, job.ID); err != nil {
+		t.Fatal(err)
+	}
+	r.Cancel(job.ID)
+
+	// The cancelled attempt must not record itself as failed.
+	time.Sleep(20 * time.Millisecond)
+	if got, _ := s.Get(1, job.ID); got.Status != model.JobCancelled || got.Error != "" {
+		t.Errorf("job = %+v, want cancelled", got)
+	}
+}
+
+func TestRunner_DrainsOnShutdown(t *testing.T) {
+	s := testStore(t)
+	started, release := make(chan struct{}), make(chan struct{})
+	r := NewRunner(s, func(ctx context.Context, job *model.Job, stage func(string)) (any, error) {
+		close(started)
+		<-release
+		return "done", ctx.Err()
+	}, Options{Workers: 1})
+	job, _ := s.Create(model.Job{UserID: 1, URL: "https://example.com/a"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		r.Run(ctx)
+		close(done)
+	}()
+	<-started
+	cancel()
+
+	select {
+	case <-done:
+		t.Fatal("Run returned before the job in progress finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	<-done
+	if got, _ := s.Get(1, job.ID); got.Status != model.JobSucceeded {
+		t.Errorf("job = %+v, want succeeded", got)
+	}
+}
+
+func TestRunner_RequeuesInterruptedJobs(t *testing.T) {
+	s := testStore(t)
+	job, _ := s.Create(model.Job{UserID: 1, URL: "https://example.com/a"})
+	s.claim() // left running by a previous server
+
+	startRunner(t, s, func(ctx context.Context, job *model.Job, stage func(string)) (any, error) {
+		return "ok", nil
+	}, Options{})
+	got := waitFor(t, s, job.ID, func(j *model.Job) bool { return j.Status.Done() })
+	if got.Status != model.JobSucceeded || got.Attempts != 2 {
+		t.Errorf("job = %+v, want succeeded on attempt 2", got)
+	}
+}
+
+func TestRunner_TimesOutJobs(t *testing.T) {
+	s := testStore(t)
+	hook := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hook <- r.Header.Get(EventHeader)
+	}))
+	defer srv.Close()
+
+	startRunner(t, s, func(ctx context.Context, job *model.Job, stage func(string)) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, Options{Timeout: 20 * time.Millisecond, Notifier: &Notifier{Secret: "s", Allow: loopback}})
+	job, _ := s.Create(model.Job{UserID: 1, URL: "https://example.com/a", CallbackURL: srv.URL})
+
+	got := waitFor(t, s, job.ID, func(j *model.Job) bool { return j.Status.Done() })
+	if got.Status != model.JobFailed || got.Error != "timed out after 20ms" {
+		t.Errorf("job = %+v, want failed with a timeout", got)
+	}
+	// The job's deadline has passed, but its webhook still goes out.
+	select {
+	case event := <-hook:
+		if event != "job.failed" {
+			t.Errorf("webhook event = %q, want job.failed", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no webhook for the timed out job")
+	}
+	got = waitFor(t, s, job.ID, func(j *model.Job) bool { return j.Callback != "" })
+	if got.Callback != "delivered" {
+		t.Errorf("callback = %q, want delivered", got.Callback)
+	}
+}
+
+func TestRunner_Interrupt(t *testing.T) {
+	s := testStore(t)
+	started := make(chan struct{}, 2)
+	r := NewRunner(s, func(ctx context.Context, job *model.Job, stage func(string)) (any, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, Options{Workers: 2, MaxAttempts: 2})
+	first, _ := s.Create(model.Job{UserID: 1, URL: "https://example.com/a"})
+	last, _ := s.Create(model.Job{UserID: 1, URL: "https://example.com/b"})
+	// The second job has already had one run.
+	s.db.Exec(`UPDATE jobs SET attempts = 1 WHERE id = ?`, last.ID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		r.Run(ctx)
+		close(done)
+	}()
+	<-started
+	<-started
+	cancel()
+	r.Interrupt()
+	<-done
+
+	if got, _ := s.Get(1, first.ID); got.Status != model.JobQueued || got.Attempts != 1 {
+		t.Errorf("first job = %+v, want queued again", got)
+	}
+	if got, _ := s.Get(1, last.ID); got.Status != model.JobFailed || got.Error != interruptedError {
+		t.Errorf("job on its last attempt = %+v, want failed", got)
+	}
+}
@@ -0,0 +1,238 @@
+// Package jobs runs summarizations in the background: jobs are queued in
+// SQLite so they survive restarts, worked by a pool of workers, and their
+// owners are told of completion by signed webhooks.
+package jobs
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+)
+
+var (
+	ErrNotFound = errors.New("job not found")
+	// ErrConflict is returned when a job's status does not allow the
+	// change, such as cancelling a finished job.
+	ErrConflict = errors.New("job status does not allow this")
+)
+
+// timeLayout is the format of SQLite's datetime().
+const timeLayout = "2006-01-02 15:04:05"
+
+// Store manages job persistence with SQLite.
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore initialises the jobs table on the given database.
+// The database is typically shared with auth.Store.
+func NewStore(db *sql.DB) (*Store, error) {
+	const createTable = `
+		CREATE TABLE IF NOT EXISTS jobs (
+			id           INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id      INTEGER NOT NULL,
+			url          TEXT    NOT NULL,
+			provider     TEXT    NOT NULL DEFAULT '',
+			no_cache     INTEGER NOT NULL DEFAULT 0,
+			callback_url TEXT    NOT NULL DEFAULT '',
+			status       TEXT    NOT NULL,
+			stage        TEXT    NOT NULL DEFAULT '',
+			attempts     INTEGER NOT NULL DEFAULT 0,
+			error        TEXT    NOT NULL DEFAULT '',
+			result       TEXT    NOT NULL DEFAULT '',
+			callback     TEXT    NOT NULL DEFAULT '',
+			created_at   TEXT    NOT NULL DEFAULT (datetime('now')),
+			updated_at   TEXT    NOT NULL DEFAULT (datetime('now')),
+			finished_at  TEXT
+		);
+		CREATE INDEX IF NOT EXISTS idx_jobs_status ON jobs (status, id);
+		CREATE INDEX IF NOT EXISTS idx_jobs_user ON jobs (user_id, id);`
+
+	if _, err := db.Exec(createTable); err != nil {
+		return nil, fmt.Errorf("create jobs table: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+const jobColumns = `id, user_id, url, provider, no_cache, callback_url, status, stage, attempts,
+	error, result, callback, created_at, updated_at, finished_at`
+
+// Create queues a job for j.UserID and returns it as stored.
+func (s *Store) Create(j model.Job) (*model.Job, error) {
+	const q = `INSERT INTO jobs (user_id, url, provider, no_cache, callback_url, status)
+		VALUES (?, ?, ?, ?, ?, ?)`
+	result, err := s.db.Exec(q, j.UserID, j.URL, j.Provider, j.NoCache, j.CallbackURL, string(model.JobQueued))
+	if err != nil {
+		return nil, fmt.Errorf("insert job: %w", err)
+	}
+	id, _ := result.LastInsertId()
+	return s.Get(j.UserID, id)
+}
+
+// Get returns a job owned by the user. Returns ErrNotFound if the job does
+// not exist or belongs to another user.
+func (s *Store) Get(userID, id int64) (*model.Job, error) {
+	return s.scanOne(s.db.QueryRow(`SELECT `+jobColumns+` FROM jobs WHERE id = ? AND user_id = ?`, id, userID))
+}
+
+// get returns a job of any user.
+func (s *Store) get(id int64) (*model.Job, error) {
+	return s.scanOne(s.db.QueryRow(`SELECT `+jobColumns+` FROM jobs WHERE id = ?`, id))
+}
+
+// Cancel cancels a queued or running job owned by the user. Returns
+// ErrConflict if the job has already finished.
+func (s *Store) Cancel(userID, id int64) (*model.Job, error) {
+	const q = `UPDATE jobs SET status = ?, updated_at = datetime('now'), finished_at = datetime('now')
+		WHERE id = ? AND user_id = ? AND status IN (?, ?)`
+	return s.transition(userID, id, q, string(model.JobCancelled), id, userID, string(model.JobQueued), string(model.JobRunning))
+}
+
+// Retry queues a failed or cancelled job owned by the user again, clearing
+// its previous outcome. Returns ErrConflict if the job is queued, running
+// or succeeded.
+func (s *Store) Retry(userID, id int64) (*model.Job, error) {
+	const q = `UPDATE jobs SET status = ?, stage = '', error = '', result = '', callback = '',
+		updated_at = datetime('now'), finished_at = NULL
+		WHERE id = ? AND user_id = ? AND status IN (?, ?)`
+	return s.transition(userID, id, q, string(model.JobQueued), id, userID, string(model.JobFailed), string(model.JobCancelled))
+}
+
+// transition runs the status update q and returns the updated job, telling
+// a missing job from one whose status did not match.
+func (s *Store) transition(userID, id int64, q string, args ...any) (*model.Job, error) {
+	result, err := s.db.Exec(q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("update job: %w", err)
+	}
+	j, err := s.Get(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return j, ErrConflict
+	}
+	return j, nil
+}
+
+// claim marks the oldest queued job as running and returns it, or nil if
+// the queue is empty.
+func (s *Store) claim() (*model.Job, error) {
+	const q = `UPDATE jobs SET status = ?, attempts = attempts + 1, updated_at = datetime('now')
+		WHERE id = (SELECT id FROM jobs WHERE status = ? ORDER BY id LIMIT 1) AND status = ?
+		RETURNING ` + jobColumns
+	j, err := s.scanOne(s.db.QueryRow(q, string(model.JobRunning), string(model.JobQueued), string(model.JobQueued)))
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	return j, err
+}
+
+// setStage records the stage attempt of a running job has reached.
+func (s *Store) setStage(id int64, attempt int, stage string) error {
+	const q = `UPDATE jobs SET stage = ?, updated_at = datetime('now') WHERE id = ? AND attempts = ? AND status = ?`
+	if _, err := s.db.Exec(q, stage, id, attempt, string(model.JobRunning)); err != nil {
+		return fmt.Errorf("update job stage: %w", err)
+	}
+	return nil
+}
+
+// finish records the outcome of attempt of a running job. It reports false
+// if that attempt was no longer running, e.g. because the job was cancelled
+// and retried meanwhile.
+func (s *Store) finish(id int64, attempt int, status model.JobStatus, errMsg string, result []byte) (bool, error) {
+	const q = `UPDATE jobs SET status = ?, error = ?, result = ?, updated_at = datetime('now'), finished_at = datetime('now')
+		WHERE id = ? AND attempts = ? AND status = ?`
+	res, err := s.db.Exec(q, string(status), errMsg, string(result), id, attempt, string(model.JobRunning))
+	if err != nil {
+		return false, fmt.Errorf("finish job: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// setCallback records the outcome of the completion webhook.
+func (s *Store) setCallback(id int64, outcome string) error {
+	if _, err := s.db.Exec(`UPDATE jobs SET callback = ? WHERE id = ?`, outcome, id); err != nil {
+		return fmt.Errorf("update job callback: %w", err)
+	}
+	return nil
+}
+
+// requeue returns jobs left running by a stopped server to the queue, or
+// fails them if they have had maxAttempts runs, and reports how many of
+// each there were.
+func (s *Store) requeue(maxAttempts int) (requeued, failed int, err error) {
+	const fail = `UPDATE jobs SET status = ?, error = ?, updated_at = datetime('now'), finished_at = datetime('now')
+		WHERE status = ? AND attempts >= ?`
+	res, err := s.db.Exec(fail, string(model.JobFailed), interruptedError, string(model.JobRunning), maxAttempts)
+	if err != nil {
+		return 0, 0, fmt.Errorf("fail interrupted jobs: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	failed = int(n)
+
+	const q = `UPDATE jobs SET status = ?, updated_at = datetime('now') WHERE status = ?`
+	res, err = s.db.Exec(q, string(model.JobQueued), string(model.JobRunning))
+	if err != nil {
+		return 0, failed, fmt.Errorf("requeue jobs: %w", err)
+	}
+	n, _ = res.RowsAffected()
+	return int(n), failed, nil
+}
+
+// interruptedError is the error of jobs interrupted on their last attempt.
+const interruptedError = "interrupted by a shutdown on the last attempt"
+
+// interrupt returns attempt of a running job to the queue, or fails the
+// job if it has had maxAttempts runs. It reports whether the job was
+// requeued; the worker's finish then finds the attempt no longer running.
+func (s *Store) interrupt(id int64, attempt, maxAttempts int) (bool, error) {
+	const q = `UPDATE jobs SET
+			status = CASE WHEN attempts < ? THEN ? ELSE ? END,
+			error = CASE WHEN attempts < ? THEN '' ELSE ? END,
+			finished_at = CASE WHEN attempts < ? THEN NULL ELSE datetime('now') END,
+			updated_at = datetime('now')
+		WHERE id = ? AND attempts = ? AND status = ?
+		RETURNING status`
+	var status string
+	err := s.db.QueryRow(q, maxAttempts, string(model.JobQueued), string(model.JobFailed),
+		maxAttempts, interruptedError, maxAttempts, id, attempt, string(model.JobRunning)).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Finished or cancelled in the meantime.
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("interrupt job: %w", err)
+	}
+	return status == string(model.JobQueued), nil
+}
+
+func (s *Store) scanOne(row *sql.Row) (*model.Job, error) {
+	var j model.Job
+	var status, result, createdAt, updatedAt string
+	var finishedAt sql.NullString
+	err := row.Scan(&j.ID, &j.UserID, &j.URL, &j.Provider, &j.NoCache, &j.CallbackURL, &status, &j.Stage,
+		&j.Attempts, &j.Error, &result, &j.Callback, &createdAt, &updatedAt, &finishedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("scan job: %w", err)
+	}
+
+	j.Status = model.JobStatus(status)
+	if result != "" {
+		j.Result = []byte(result)
+	}
+	j.CreatedAt, _ = time.Parse(timeLayout, createdAt)
+	j.UpdatedAt, _ = time.Parse(timeLayout, updatedAt)
+	if finishedAt.Valid {
+		t, _ := time.Parse(timeLayout, finishedAt.String)
+		j.FinishedAt = &t
+	}
+	return &j, nil
+}
@@ -0,0 +1,142 @@
+package jobs
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+
+	_ "modernc.org/sqlite"
+)
+
+func testStore(t *testing.T) *Store {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db")+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	s, err := NewStore(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestStore_Lifecycle(t *testing.T) {
+	s := testStore(t)
+	job, err := s.Create(model.Job{UserID: 1, URL: "https://example.com/a", Provider: "openai", NoCache: true})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if job.ID == 0 || job.Status != model.JobQueued || !job.NoCache || job.CreatedAt.IsZero() {
+		t.Errorf("Create() = %+v", job)
+	}
+	if _, err := s.Get(2, job.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() by another user = %v, want ErrNotFound", err)
+	}
+
+	claimed, err := s.claim()
+	if err != nil || claimed == nil || claimed.ID != job.ID || claimed.Status != model.JobRunning || claimed.Attempts != 1 {
+		t.Fatalf("claim() = %+v, %v", claimed, err)
+	}
+	if next, err := s.claim(); next != nil || err != nil {
+		t.Errorf("claim() on an empty queue = %+v, %v", next, err)
+	}
+
+	if err := s.setStage(job.ID, 1, "classify"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := s.finish(job.ID, 1, model.JobFailed, "classify failed", []byte(`{"stage":"classify"}`)); !ok || err != nil {
+		t.Fatalf("finish() = %v, %v", ok, err)
+	}
+	got, _ := s.Get(1, job.ID)
+	if got.Status != model.JobFailed || got.Stage != "classify" || got.Error != "classify failed" ||
+		string(got.Result) != `{"stage":"classify"}` || got.FinishedAt == nil {
+		t.Errorf("Get() after finish = %+v", got)
+	}
+
+	if _, err := s.Cancel(1, job.ID); !errors.Is(err, ErrConflict) {
+		t.Errorf("Cancel() of a failed job = %v, want ErrConflict", err)
+	}
+	retried, err := s.Retry(1, job.ID)
+	if err != nil || retried.Status != model.JobQueued || retried.Error != "" || retried.Result != nil || retried.FinishedAt != nil {
+		t.Fatalf("Retry() = %+v, %v", retried, err)
+	}
+	if _, err := s.Retry(1, job.ID); !errors.Is(err, ErrConflict) {
+		t.Errorf("Retry() of a queued job = %v, want ErrConflict", err)
+	}
+	if _, err := s.Retry(2, job.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Retry() by another user = %v, want ErrNotFound", err)
+	}
+}
+
+func TestStore_CancelStopsStaleAttempt(t *testing.T) {
+	s := testStore(t)
+	job, _ := s.Create(model.Job{UserID: 1, URL: "https://example.com/a"})
+	s.claim()
+
+	if cancelled, err := s.Cancel(1, job.ID); err != nil || cancelled.Status != model.JobCancelled {
+		t.Fatalf("Cancel() = %+v, %v", cancelled, err)
+	}
+	s.Retry(1, job.ID)
+	s.claim() // attempt 2
+
+	// The first attempt finishing late must not overwrite the second.
+	if ok, _ := s.finish(job.ID, 1, model.JobSucceeded, "", nil); ok {
+		t.Error("finish() of a stale attempt succeeded")
+	}
+	if ok, _ := s.finish(job.ID, 2, model.JobSucceeded, "", nil); !ok {
+		t.Error("finish() of the current attempt failed")
+	}
+}
+
+func TestStore_Requeue(t *testing.T) {
+	s := testStore(t)
+	job, _ := s.Create(model.Job{UserID: 1, URL: "https://example.com/a"})
+	s.claim()
+
+	if requeued, failed, err := s.requeue(3); requeued != 1 || failed != 0 || err != nil {
+		t.Fatalf("requeue() = %d, %d, %v, want 1, 0", requeued, failed, err)
+	}
+	got, _ := s.Get(1, job.ID)
+	if got.Status != model.JobQueued || got.Attempts != 1 {
+		t.Errorf("after requeue = %+v", got)
+	}
+
+	// A job on its last attempt fails instead.
+	s.claim()
+	if requeued, failed, _ := s.requeue(2); requeued != 0 || failed != 1 {
+		t.Errorf("requeue() on the last attempt = %d, %d, want 0, 1", requeued, failed)
+	}
+	if got, _ := s.Get(1, job.ID); got.Status != model.JobFailed || got.Error != interruptedError {
+		t.Errorf("after requeue on the last attempt = %+v", got)
+	}
+}
+
+func TestStore_Interrupt(t *testing.T) {
+	s := testStore(t)
+	job, _ := s.Create(model.Job{UserID: 1, URL: "https://example.com/a"})
+	s.claim()
+
+	if ok, err := s.interrupt(job.ID, 1, 2); !ok || err != nil {
+		t.Fatalf("interrupt() = %v, %v, want requeued", ok, err)
+	}
+	if ok, _ := s.interrupt(job.ID, 1, 2); ok {
+		t.Error("interrupt() of a queued job requeued it")
+	}
+	if got, _ := s.Get(1, job.ID); got.Status != model.JobQueued {
+		t.Errorf("after interrupt = %+v, want queued", got)
+	}
+
+	s.claim()
+	if ok, _ := s.interrupt(job.ID, 2, 2); ok {
+		t.Error("interrupt() of the last attempt requeued the job")
+	}
+	got, _ := s.Get(1, job.ID)
+	if got.Status != model.JobFailed || got.Error != interruptedError || got.FinishedAt == nil {
+		t.Errorf("after interrupt of the last attempt = %+v, want failed", got)
+	}
+}
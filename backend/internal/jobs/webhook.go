@@ -0,0 +1,261 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+)
+
+// Headers of completion webhooks.
+const (
+	EventHeader     = "X-Webhook-Event"
+	TimestampHeader = "X-Webhook-Timestamp"
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+// Event is the body of a completion webhook.
+type Event struct {
+	Type string     `json:"type"` // "job.succeeded" or "job.failed"
+	Job  *model.Job `json:"job"`
+}
+
+// ParseAllow parses a list of IP addresses and CIDR networks, such as
+// "10.0.0.5" or "10.1.0.0/16", that webhooks may be sent to although they
+// are internal.
+func ParseAllow(list []string) ([]netip.Prefix, error) {
+	var allow []netip.Prefix
+	for _, s := range list {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if ip, err := netip.ParseAddr(s); err == nil {
+			allow = append(allow, netip.PrefixFrom(ip.Unmap(), ip.Unmap().BitLen()))
+			continue
+		}
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address or network %q", s)
+		}
+		allow = append(allow, p.Masked())
+	}
+	return allow, nil
+}
+
+// internalNets are ranges checkAddr refuses besides those the netip.Addr
+// predicates cover: "this network" and carrier-grade NAT, where some
+// clouds serve instance metadata.
+var internalNets = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// checkAddr refuses loopback, private, link-local, unspecified and
+// multicast addresses, which would let a callback URL reach the server's
+// own network or a cloud metadata service, unless allow contains them.
+func checkAddr(ip netip.Addr, allow []netip.Prefix) error {
+	ip = ip.Unmap()
+	for _, p := range allow {
+		if p.Contains(ip) {
+			return nil
+		}
+	}
+	internal := ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast()
+	for _, p := range internalNets {
+		internal = internal || p.Contains(ip)
+	}
+	if internal {
+		return fmt.Errorf("callback address %s is not allowed", ip)
+	}
+	return nil
+}
+
+// NewClient returns a client for webhooks that gives up after timeout and
+// refuses to connect to internal addresses outside allow. Addresses are
+// checked as the connection is made, after DNS resolution, so neither a
+// hostname resolving to one nor a redirect can reach them. Proxies from
+// the environment are not used, as they would hide the address.
+func NewClient(timeout time.Duration, allow []netip.Prefix) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addr, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("callback address %s: %w", address, err)
+			}
+			return checkAddr(addr.Addr(), allow)
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+// Sign returns the signature of a webhook body sent at timestamp (Unix
+// seconds): "sha256=" and the hex HMAC-SHA256, keyed with secret, of the
+// timestamp, a dot and the body.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature of a webhook received at now, rejecting
+// timestamps further than tolerance from now so a captured request cannot
+// be replayed later.
+func Verify(secret string, h http.Header, body []byte, now time.Time, tolerance time.Duration) error {
+	ts, err := strconv.ParseInt(h.Get(TimestampHeader), 10, 64)
+	if err != nil {
+		return errors.New("missing or invalid webhook timestamp")
+	}
+	if d := now.Sub(time.Unix(ts, 0)); d > tolerance || d < -tolerance {
+		return errors.New("webhook timestamp outside tolerance")
+	}
+	if !hmac.Equal([]byte(h.Get(SignatureHeader)), []byte(Sign(secret, ts, body))) {
+		return errors.New("invalid webhook signature")
+	}
+	return nil
+}
+
+// Notifier delivers completion webhooks signed with Secret.
+type Notifier struct {
+	Secret string
+	// Timeout bounds each delivery attempt. Defaults to 10 seconds.
+	Timeout time.Duration
+	// Allow lists the internal addresses webhooks may be sent to. Others,
+	// such as loopback and private addresses, are refused.
+	Allow []netip.Prefix
+	// Attempts is the number of deliveries tried. Defaults to 3.
+	Attempts int
+	// Backoff is the wait before the second attempt, doubled after each
+	// failure. Defaults to 1 second.
+	Backoff time.Duration
+
+	once   sync.Once
+	client *http.Client
+}
+
+// ValidateCallback checks that rawURL is an absolute http or https URL
+// whose host, if it is an IP address or localhost, is allowed. Hostnames
+// are checked again when a webhook is sent.
+func (n *Notifier) ValidateCallback(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Hostname() == "" {
+		return errors.New("callback_url must be an absolute http or https URL")
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		host = "127.0.0.1"
+	}
+	if ip, err := netip.ParseAddr(host); err == nil {
+		if err := checkAddr(ip, n.Allow); err != nil {
+			return fmt.Errorf("callback_url: %w", err)
+		}
+	}
+	return nil
+}
+
+// Send posts the event for j to its callback URL, retrying failures and
+// responses other than 2xx.
+func (n *Notifier) Send(ctx context.Context, j *model.Job) error {
+	body, err := json.Marshal(Event{Type: "job." + string(j.Status), Job: j})
+	if err != nil {
+		return fmt.Errorf("encode webhook: %w", err)
+	}
+	attempts, _, backoff := n.settings()
+
+	for attempt := 1; ; attempt++ {
+		err = n.post(ctx, j, body)
+		if err == nil || attempt == attempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+			backoff *= 2
+		}
+	}
+}
+
+// settings returns the attempts, timeout and backoff of n with their
+// defaults applied.
+func (n *Notifier) settings() (attempts int, timeout, backoff time.Duration) {
+	attempts, timeout, backoff = n.Attempts, n.Timeout, n.Backoff
+	if attempts <= 0 {
+		attempts = 3
+	}
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	return attempts, timeout, backoff
+}
+
+// maxDuration is the longest Send can take: every attempt timing out, and
+// the backoff between them.
+func (n *Notifier) maxDuration() time.Duration {
+	attempts, timeout, backoff := n.settings()
+	total := time.Duration(attempts) * timeout
+	for range attempts - 1 {
+		total += backoff
+		backoff *= 2
+	}
+	return total
+}
+
+func (n *Notifier) post(ctx context.Context, j *model.Job, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", j.CallbackURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, "job."+string(j.Status))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(ts, 10))
+	req.Header.Set(SignatureHeader, Sign(n.Secret, ts, body))
+
+	n.once.Do(func() {
+		_, timeout, _ := n.settings()
+		n.client = NewClient(timeout, n.Allow)
+	})
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("callback returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package jobs
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+)
+
+// loopback lets notifiers reach the test servers.
+var loopback = []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")}
+
+func TestNotifier_SendSigned(t *testing.T) {
+	const secret = "whsec"
+	var got atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := Verify(secret, r.Header, body, time.Now(), time.Minute); err != nil {
+			t.Errorf("Verify() = %v", err)
+		}
+		got.Store(r.Header.Get(EventHeader) + " " + string(body))
+	}))
+	defer srv.Close()
+
+	n := &Notifier{Secret: secret, Allow: loopback}
+	job := &model.Job{ID: 3, URL: "https://example.com", CallbackURL: srv.URL, Status: model.JobSucceeded}
+	if err := n.Send(context.Background(), job); err != nil {
+		t.Fatalf("Send() = %v", err)
+	}
+	if s, _ := got.Load().(string); !strings.HasPrefix(s, `job.succeeded {"type":"job.succeeded","job":{"id":3`) {
+		t.Errorf("webhook = %q", s)
+	}
+}
+
+func TestNotifier_Retries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	n := &Notifier{Secret: "s", Allow: loopback, Backoff: time.Millisecond}
+	job := &model.Job{ID: 1, CallbackURL: srv.URL, Status: model.JobFailed}
+	if err := n.Send(context.Background(), job); err != nil || calls.Load() != 3 {
+		t.Errorf("Send() = %v after %d calls, want success on the third", err, calls.Load())
+	}
+
+	calls.Store(-10)
+	n.Attempts = 2
+	if err := n.Send(context.Background(), job); err == nil || !strings.Contains(err.Error(), "502") {
+		t.Errorf("Send() = %v, want status error after 2 attempts", err)
+	}
+}
+
+func TestNotifier_MaxDuration(t *testing.T) {
+	n := &Notifier{Timeout: 10 * time.Second, Backoff: time.Second}
+	// Three attempts of 10s, with 1s and 2s between them.
+	if got := n.maxDuration(); got != 33*time.Second {
+		t.Errorf("maxDuration() = %v, want 33s", got)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"type":"job.failed"}`)
+	now := time.Unix(1_700_000_000, 0)
+	h := http.Header{}
+	h.Set(TimestampHeader, "1700000000")
+	h.Set(SignatureHeader, Sign("secret", now.Unix(), body))
+
+	if err := Verify("secret", h, body, now, time.Minute); err != nil {
+		t.Errorf("Verify() = %v", err)
+	}
+	if err := Verify("other", h, body, now, time.Minute); err == nil {
+		t.Error("Verify() with the wrong secret = nil")
+	}
+	if err := Verify("secret", h, []byte(`{}`), now, time.Minute); err == nil {
+		t.Error("Verify() of a modified body = nil")
+	}
+	if err := Verify("secret", h, body, now.Add(time.Hour), time.Minute); err == nil {
+		t.Error("Verify() of a replayed webhook = nil")
+	}
+}
+
+func TestNotifier_RefusesInternalAddresses(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+
+	n := &Notifier{Secret: "s", Attempts: 1}
+	// localhost is only known to be loopback once it has been resolved.
+	for _, callback := range []string{srv.URL + "/cb", "http://localhost:" + u.Port() + "/cb"} {
+		job := &model.Job{ID: 1, CallbackURL: callback, Status: model.JobSucceeded}
+		if err := n.Send(context.Background(), job); err == nil || !strings.Contains(err.Error(), "not allowed") {
+			t.Errorf("Send(%s) = %v, want refused", callback, err)
+		}
+	}
+	if calls.Load() != 0 {
+		t.Errorf("server called %d times, want 0", calls.Load())
+	}
+}
+
+func TestValidateCallback(t *testing.T) {
+	n := &Notifier{}
+	for _, u := range []string{"https://hooks.example.com/jobs", "http://93.184.215.14:9000/cb"} {
+		if err := n.ValidateCallback(u); err != nil {
+			t.Errorf("ValidateCallback(%q) = %v", u, err)
+		}
+	}
+	for _, u := range []string{
+		"ftp://example.com", "/relative", "https://", "::",
+		"http://127.0.0.1/cb", "http://localhost:9000/cb", "http://[::1]/cb", "http://10.0.0.8/cb",
+		"http://192.168.1.1/cb", "http://169.254.169.254/latest/meta-data", "http://0.0.0.0/cb",
+		"http://[::ffff:127.0.0.1]/cb",
+	} {
+		if err := n.ValidateCallback(u); err == nil {
+			t.Errorf("ValidateCallback(%q) = nil, want error", u)
+		}
+	}
+
+	allow, err := ParseAllow([]string{"127.0.0.1", "10.1.0.0/16"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.Allow = allow
+	for _, u := range []string{"http://localhost:9000/cb", "http://10.1.2.3/cb"} {
+		if err := n.ValidateCallback(u); err != nil {
+			t.Errorf("ValidateCallback(%q) with allow = %v", u, err)
+		}
+	}
+	if err := n.ValidateCallback("http://10.2.0.1/cb"); err == nil {
+		t.Error("ValidateCallback() outside allow = nil, want error")
+	}
+	if _, err := ParseAllow([]string{"10.0.0.0/33"}); err == nil {
+		t.Error("ParseAllow(10.0.0.0/33) = nil error")
+	}
+}
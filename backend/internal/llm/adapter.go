@@ -1,15 +1,19 @@
 package llm
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/rookiecj/scrum-agents/backend/internal/model"
 )
 
 // Adapter manages multiple LLM providers and routes requests to the selected one.
+// When a fallback order is set, a request that fails on the selected provider
+// moves on to the next registered provider in that order.
 type Adapter struct {
 	providers      map[ProviderType]Provider
 	defaultProvider ProviderType
+	fallback        []ProviderType
 }
 
 // NewAdapter creates an Adapter with the given providers and default.
@@ -47,31 +51,52 @@ func (a *Adapter) GetProvider(providerType ProviderType) (Provider, error) {
 	return p, nil
 }
 
-// Complete sends a prompt using the specified or default provider.
-func (a *Adapter) Complete(prompt string, providerType ProviderType) (string, error) {
-	p, err := a.GetProvider(providerType)
-	if err != nil {
-		return "", err
+// SetFallbackOrder sets the order in which providers are tried after the
+// selected one fails, e.g. claude, openai, gemini. Types that are not
+// registered are skipped, so the order may name unconfigured providers.
+func (a *Adapter) SetFallbackOrder(order ...ProviderType) {
+	a.fallback = order
+}
+
+// FallbackOrder returns the configured fallback order.
+func (a *Adapter) FallbackOrder() []ProviderType {
+	return a.fallback
+}
+
+// chain returns the registered providers to try for a request: the preferred
+// (or default) provider first, then the fallback order without duplicates.
+func (a *Adapter) chain(preferred ProviderType) []Provider {
+	if preferred == "" {
+		preferred = a.defaultProvider
+	}
+
+	var chain []Provider
+	seen := make(map[ProviderType]bool)
+	for _, t := range append([]ProviderType{preferred}, a.fallback...) {
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		if p, ok := a.providers[t]; ok {
+			chain = append(chain, p)
+		}
 	}
-	return p.Complete(prompt)
+	return chain
+}
+
+// Complete sends a prompt using the specified or default provider.
+func (a *Adapter) Complete(ctx context.Context, prompt string, providerType ProviderType) (string, error) {
+	return a.Route(providerType).Complete(ctx, prompt)
 }
 
 // Classify classifies content using the specified or default provider.
-func (a *Adapter) Classify(content string, providerType ProviderType) (*model.ClassificationResult, error) {
-	p, err := a.GetProvider(providerType)
-	if err != nil {
-		return nil, err
-	}
-	return p.Classify(content)
+func (a *Adapter) Classify(ctx context.Context, content string, providerType ProviderType) (*model.ClassificationResult, error) {
+	return a.Route(providerType).Classify(ctx, content)
 }
 
 // Summarize generates a summary using the specified or default provider.
-func (a *Adapter) Summarize(content string, category model.ContentCategory, providerType ProviderType) (string, error) {
-	p, err := a.GetProvider(providerType)
-	if err != nil {
-		return "", err
-	}
-	return p.Summarize(content, category)
+func (a *Adapter) Summarize(ctx context.Context, content string, category model.ContentCategory, providerType ProviderType) (string, error) {
+	return a.Route(providerType).Summarize(ctx, content, category)
 }
 
 // AvailableProviders returns the list of registered provider types.
@@ -1,6 +1,7 @@
 package llm
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -18,15 +19,22 @@ type mockProvider struct {
 	summarizeErr error
 }
 
-func (m *mockProvider) Complete(prompt string) (string, error) {
+func (m *mockProvider) Complete(ctx context.Context, prompt string) (string, error) {
 	return m.completeRes, m.completeErr
 }
 
-func (m *mockProvider) Classify(content string) (*model.ClassificationResult, error) {
+func (m *mockProvider) Stream(ctx context.Context, prompt string, onToken func(string) error) (string, error) {
+	if m.completeErr != nil {
+		return "", m.completeErr
+	}
+	return m.completeRes, onToken(m.completeRes)
+}
+
+func (m *mockProvider) Classify(ctx context.Context, content string) (*model.ClassificationResult, error) {
 	return m.classifyRes, m.classifyErr
 }
 
-func (m *mockProvider) Summarize(content string, category model.ContentCategory) (string, error) {
+func (m *mockProvider) Summarize(ctx context.Context, content string, category model.ContentCategory) (string, error) {
 	return m.summarizeRes, m.summarizeErr
 }
 
@@ -142,7 +150,7 @@ func TestAdapter_Complete(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := adapter.Complete("prompt", tt.provider)
+			got, err := adapter.Complete(context.Background(), "prompt", tt.provider)
 			if tt.wantErr {
 				if err == nil {
 					t.Error("expected error")
@@ -167,7 +175,7 @@ func TestAdapter_Classify(t *testing.T) {
 	claude := &mockProvider{name: ProviderClaude, classifyRes: result}
 	adapter, _ := NewAdapter(ProviderClaude, claude)
 
-	got, err := adapter.Classify("content", "")
+	got, err := adapter.Classify(context.Background(), "content", "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -180,7 +188,7 @@ func TestAdapter_Summarize(t *testing.T) {
 	claude := &mockProvider{name: ProviderClaude, summarizeRes: "summary text"}
 	adapter, _ := NewAdapter(ProviderClaude, claude)
 
-	got, err := adapter.Summarize("content", model.CategoryPrinciple, "")
+	got, err := adapter.Summarize(context.Background(), "content", model.CategoryPrinciple, "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -198,17 +206,17 @@ func TestAdapter_ErrorPropagation(t *testing.T) {
 	}
 	adapter, _ := NewAdapter(ProviderClaude, claude)
 
-	_, err := adapter.Complete("prompt", "")
+	_, err := adapter.Complete(context.Background(), "prompt", "")
 	if err == nil {
 		t.Error("expected error from Complete")
 	}
 
-	_, err = adapter.Classify("content", "")
+	_, err = adapter.Classify(context.Background(), "content", "")
 	if err == nil {
 		t.Error("expected error from Classify")
 	}
 
-	_, err = adapter.Summarize("content", model.CategoryNews, "")
+	_, err = adapter.Summarize(context.Background(), "content", model.CategoryNews, "")
 	if err == nil {
 		t.Error("expected error from Summarize")
 	}
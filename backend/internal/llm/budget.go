@@ -0,0 +1,95 @@
+package llm
+
+import (
+	"strings"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/tokens"
+)
+
+// Token estimators for the tokenizers behind each provider. The figures are
+// measured on mixed Korean and English articles and rounded up.
+var (
+	claudeEstimator = tokens.Estimator{CharsPerToken: 3.5, TokensPerWideRune: 1.2}
+	// o200k, used by gpt-4o and later, encodes Hangul far more compactly
+	// than cl100k, used by earlier models.
+	o200kEstimator  = tokens.Estimator{CharsPerToken: 4, TokensPerWideRune: 0.8}
+	cl100kEstimator = tokens.Estimator{CharsPerToken: 4, TokensPerWideRune: 1.3}
+	geminiEstimator = tokens.Estimator{CharsPerToken: 4, TokensPerWideRune: 0.8}
+	ollamaEstimator = tokens.Estimator{CharsPerToken: 3.8, TokensPerWideRune: 1.2}
+)
+
+// estimatorFor returns the token estimator for model served by provider.
+// Named OpenAI-compatible providers may serve any model, so they get
+// tokens.DefaultEstimator.
+func estimatorFor(provider ProviderType, model string) tokens.Estimator {
+	switch provider {
+	case ProviderClaude:
+		return claudeEstimator
+	case ProviderOpenAI:
+		if strings.HasPrefix(model, "gpt-3.5") || model == "gpt-4" || strings.HasPrefix(model, "gpt-4-") {
+			return cl100kEstimator
+		}
+		return o200kEstimator
+	case ProviderGemini:
+		return geminiEstimator
+	case ProviderOllama:
+		return ollamaEstimator
+	}
+	return tokens.DefaultEstimator
+}
+
+// Budget returns the token budget of a model with this configuration,
+// counted with est. Without a ContextWindow the window of tokens.Default is
+// assumed, and without an OutputReserve, MaxTokens is reserved.
+func (c Config) Budget(est tokens.Estimator) tokens.Budget {
+	b := tokens.Budget{
+		ContextWindow: c.ContextWindow,
+		OutputReserve: c.OutputReserve,
+		Estimator:     est,
+	}
+	if b.ContextWindow <= 0 {
+		b.ContextWindow = tokens.Default.ContextWindow
+	}
+	if b.OutputReserve <= 0 {
+		b.OutputReserve = c.MaxTokens
+	}
+	if b.OutputReserve <= 0 {
+		b.OutputReserve = tokens.Default.OutputReserve
+	}
+	return b
+}
+
+// Budget returns the token budget of the configured model.
+func (p *ClaudeProvider) Budget() tokens.Budget {
+	return p.config.Budget(estimatorFor(ProviderClaude, p.config.Model))
+}
+
+// Budget returns the token budget of the configured model.
+func (p *OpenAIProvider) Budget() tokens.Budget {
+	return p.config.Budget(estimatorFor(p.Name(), p.config.Model))
+}
+
+// Budget returns the token budget of the configured model.
+func (p *GeminiProvider) Budget() tokens.Budget {
+	return p.config.Budget(estimatorFor(ProviderGemini, p.config.Model))
+}
+
+// Budget returns the token budget of the configured model.
+func (p *OllamaProvider) Budget() tokens.Budget {
+	return p.config.Budget(estimatorFor(ProviderOllama, p.config.Model))
+}
+
+// Budget returns the smallest budget among the providers the route may
+// fall back to, so that a prompt sized for it suits whichever serves it.
+func (r *Route) Budget() tokens.Budget {
+	var out tokens.Budget
+	for i, p := range r.adapter.chain(r.preferred) {
+		if b := tokens.Of(p); i == 0 || b.Input() < out.Input() {
+			out = b
+		}
+	}
+	if out.ContextWindow == 0 {
+		return tokens.Default
+	}
+	return out
+}
@@ -0,0 +1,115 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/tokens"
+)
+
+func TestEstimatorFor(t *testing.T) {
+	tests := []struct {
+		provider ProviderType
+		model    string
+		want     tokens.Estimator
+	}{
+		{ProviderClaude, "claude-sonnet-4-6", claudeEstimator},
+		{ProviderOpenAI, "gpt-4o", o200kEstimator},
+		{ProviderOpenAI, "gpt-4o-mini", o200kEstimator},
+		{ProviderOpenAI, "gpt-4.1", o200kEstimator},
+		{ProviderOpenAI, "gpt-4", cl100kEstimator},
+		{ProviderOpenAI, "gpt-4-turbo", cl100kEstimator},
+		{ProviderOpenAI, "gpt-3.5-turbo", cl100kEstimator},
+		{ProviderGemini, "gemini-2.0-flash", geminiEstimator},
+		{ProviderOllama, "llama3.1", ollamaEstimator},
+		{"vllm", "qwen2.5-7b", tokens.DefaultEstimator},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.provider)+"/"+tt.model, func(t *testing.T) {
+			if got := estimatorFor(tt.provider, tt.model); got != tt.want {
+				t.Errorf("estimatorFor(%q, %q) = %+v, want %+v", tt.provider, tt.model, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_Budget(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     Config
+		wantWindow int
+		wantInput  int
+	}{
+		{"claude defaults", DefaultClaudeConfig("k"), 200000, 200000 - 4096},
+		{"explicit reserve", Config{ContextWindow: 32768, OutputReserve: 2048, MaxTokens: 4096}, 32768, 32768 - 2048},
+		{"unknown window", Config{MaxTokens: 1024}, tokens.Default.ContextWindow, tokens.Default.ContextWindow - 1024},
+		{"nothing set", Config{}, tokens.Default.ContextWindow, tokens.Default.Input()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := tt.config.Budget(tokens.DefaultEstimator)
+			if b.ContextWindow != tt.wantWindow || b.Input() != tt.wantInput {
+				t.Errorf("Budget() = %+v (input %d), want window %d and input %d", b, b.Input(), tt.wantWindow, tt.wantInput)
+			}
+		})
+	}
+}
+
+func TestProviders_Budget(t *testing.T) {
+	compatible := NewOpenAICompatibleProvider("vllm", Config{Model: "qwen2.5-7b", MaxTokens: 1024})
+	tests := []struct {
+		name       string
+		provider   tokens.Budgeted
+		wantWindow int
+		wantEst    tokens.Estimator
+	}{
+		{"claude", NewClaudeProvider(DefaultClaudeConfig("k")), 200000, claudeEstimator},
+		{"openai", NewOpenAIProvider(DefaultOpenAIConfig("k")), 128000, o200kEstimator},
+		{"gemini", NewGeminiProvider(DefaultGeminiConfig("k")), 1048576, geminiEstimator},
+		{"ollama", NewOllamaProvider(DefaultOllamaConfig("", "")), 16384, ollamaEstimator},
+		{"compatible", compatible, tokens.Default.ContextWindow, tokens.DefaultEstimator},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := tt.provider.Budget()
+			if b.ContextWindow != tt.wantWindow || b.Estimator != tt.wantEst {
+				t.Errorf("Budget() = %+v, want window %d with %+v", b, tt.wantWindow, tt.wantEst)
+			}
+		})
+	}
+}
+
+// budgetedMock is a mockProvider with a token budget.
+type budgetedMock struct {
+	mockProvider
+	budget tokens.Budget
+}
+
+func (m *budgetedMock) Budget() tokens.Budget { return m.budget }
+
+func TestRoute_Budget(t *testing.T) {
+	large := &budgetedMock{mockProvider{name: ProviderClaude}, tokens.Budget{ContextWindow: 200000, OutputReserve: 4096}}
+	small := &budgetedMock{mockProvider{name: ProviderOllama}, tokens.Budget{ContextWindow: 8192, OutputReserve: 1024}}
+	plain := &mockProvider{name: ProviderOpenAI}
+
+	adapter, err := NewAdapter(ProviderClaude, large, small, plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := adapter.Route(ProviderClaude).Budget(); got != large.budget {
+		t.Errorf("without fallback, Budget() = %+v, want the preferred provider's", got)
+	}
+
+	adapter.SetFallbackOrder(ProviderClaude, ProviderOllama)
+	if got := adapter.Route(ProviderClaude).Budget(); got != small.budget {
+		t.Errorf("with fallback, Budget() = %+v, want the smallest in the chain", got)
+	}
+
+	if got := adapter.Route(ProviderOpenAI).Budget(); got != tokens.Default {
+		t.Errorf("provider without budget: Budget() = %+v, want tokens.Default", got)
+	}
+
+	if got := adapter.Route("missing").Budget(); got.Input() > small.budget.Input() {
+		t.Errorf("unregistered provider: Budget() = %+v, want the fallback chain's", got)
+	}
+}
@@ -2,10 +2,12 @@ package llm
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/rookiecj/scrum-agents/backend/internal/classifier"
 	"github.com/rookiecj/scrum-agents/backend/internal/model"
@@ -15,6 +17,7 @@ import (
 type ClaudeProvider struct {
 	config     Config
 	client     *http.Client
+	stream     *http.Client
 	baseURL    string
 	classifier *classifier.LLMClassifier
 }
@@ -24,6 +27,7 @@ func NewClaudeProvider(config Config) *ClaudeProvider {
 	p := &ClaudeProvider{
 		config:  config,
 		client:  &http.Client{Timeout: config.Timeout},
+		stream:  newStreamClient(config.Timeout),
 		baseURL: "https://api.anthropic.com/v1",
 	}
 	p.classifier = classifier.NewLLMClassifier(p)
@@ -35,10 +39,16 @@ func (p *ClaudeProvider) Name() ProviderType {
 	return ProviderClaude
 }
 
+// Model returns the configured model name.
+func (p *ClaudeProvider) Model() string {
+	return p.config.Model
+}
+
 type claudeRequest struct {
-	Model     string           `json:"model"`
-	MaxTokens int              `json:"max_tokens"`
-	Messages  []claudeMessage  `json:"messages"`
+	Model     string          `json:"model"`
+	MaxTokens int             `json:"max_tokens"`
+	Messages  []claudeMessage `json:"messages"`
+	Stream    bool            `json:"stream,omitempty"`
 }
 
 type claudeMessage struct {
@@ -47,40 +57,74 @@ type claudeMessage struct {
 }
 
 type claudeResponse struct {
+	Model   string `json:"model"`
 	Content []struct {
 		Text string `json:"text"`
 	} `json:"content"`
+	Usage claudeUsage `json:"usage"`
 	Error *struct {
 		Message string `json:"message"`
 	} `json:"error,omitempty"`
 }
 
-// Complete sends a prompt to Claude and returns the response.
-func (p *ClaudeProvider) Complete(prompt string) (string, error) {
+// claudeUsage is the token usage of a Messages API call.
+type claudeUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// claudeStreamEvent is the payload of a Messages API streaming event.
+// message_start carries the input token count, message_delta the output
+// token count so far.
+type claudeStreamEvent struct {
+	Type    string `json:"type"`
+	Message struct {
+		Model string      `json:"model"`
+		Usage claudeUsage `json:"usage"`
+	} `json:"message"`
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+	Usage claudeUsage `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// newRequest builds a Messages API request for the prompt.
+func (p *ClaudeProvider) newRequest(ctx context.Context, prompt string, stream bool) (*http.Request, error) {
 	reqBody := claudeRequest{
 		Model:     p.config.Model,
 		MaxTokens: p.config.MaxTokens,
 		Messages: []claudeMessage{
 			{Role: "user", Content: prompt},
 		},
+		Stream: stream,
 	}
 
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("marshaling request: %w", err)
+		return nil, fmt.Errorf("marshaling request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", p.baseURL+"/messages", bytes.NewReader(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/messages", bytes.NewReader(jsonBody))
 	if err != nil {
-		return "", fmt.Errorf("creating request: %w", err)
+		return nil, fmt.Errorf("creating request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("x-api-key", p.config.APIKey)
 	req.Header.Set("anthropic-version", "2023-06-01")
+	return req, nil
+}
 
-	resp, err := p.client.Do(req)
+// Complete sends a prompt to Claude and returns the response.
+func (p *ClaudeProvider) Complete(ctx context.Context, prompt string) (string, error) {
+	resp, err := doWithRetry(ctx, p.client, ProviderClaude, p.config.MaxRetries, func() (*http.Request, error) {
+		return p.newRequest(ctx, prompt, false)
+	})
 	if err != nil {
-		return "", fmt.Errorf("calling Claude API: %w", err)
+		return "", err
 	}
 	defer resp.Body.Close()
 
@@ -97,6 +141,12 @@ func (p *ClaudeProvider) Complete(prompt string) (string, error) {
 	if result.Error != nil {
 		return "", fmt.Errorf("Claude API error: %s", result.Error.Message)
 	}
+	recordUsage(ctx, Usage{
+		Provider:     ProviderClaude,
+		Model:        modelOr(result.Model, p.config.Model),
+		InputTokens:  result.Usage.InputTokens,
+		OutputTokens: result.Usage.OutputTokens,
+	})
 
 	if len(result.Content) == 0 {
 		return "", fmt.Errorf("empty response from Claude")
@@ -105,16 +155,73 @@ func (p *ClaudeProvider) Complete(prompt string) (string, error) {
 	return result.Content[0].Text, nil
 }
 
+// Stream sends a prompt to Claude with streaming enabled, calling onToken for
+// every text delta. It returns the full response text.
+func (p *ClaudeProvider) Stream(ctx context.Context, prompt string, onToken func(string) error) (string, error) {
+	ctx, watch, stop := withIdleTimeout(ctx, p.config.Timeout)
+	defer stop()
+	resp, err := doWithRetry(ctx, p.stream, ProviderClaude, p.config.MaxRetries, func() (*http.Request, error) {
+		req, err := p.newRequest(ctx, prompt, true)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "text/event-stream")
+		return req, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var sb strings.Builder
+	usage := Usage{Provider: ProviderClaude, Model: p.config.Model}
+	defer func() { recordUsage(ctx, usage) }()
+	err = readSSE(watch(resp.Body), func(_, data string) error {
+		var ev claudeStreamEvent
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			return fmt.Errorf("parsing stream event: %w", err)
+		}
+		switch ev.Type {
+		case "error":
+			if ev.Error != nil {
+				return fmt.Errorf("Claude API error: %s", ev.Error.Message)
+			}
+			return fmt.Errorf("Claude API error: stream failed")
+		case "message_start":
+			usage.Model = modelOr(ev.Message.Model, usage.Model)
+			usage.InputTokens = ev.Message.Usage.InputTokens
+			usage.OutputTokens = ev.Message.Usage.OutputTokens
+		case "message_delta":
+			usage.OutputTokens = ev.Usage.OutputTokens
+		case "content_block_delta":
+			if ev.Delta.Text == "" {
+				return nil
+			}
+			sb.WriteString(ev.Delta.Text)
+			return onToken(ev.Delta.Text)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("reading stream: %w", err)
+	}
+
+	if sb.Len() == 0 {
+		return "", fmt.Errorf("empty response from Claude")
+	}
+	return sb.String(), nil
+}
+
 // Classify classifies content using Claude.
-func (p *ClaudeProvider) Classify(content string) (*model.ClassificationResult, error) {
-	return p.classifier.Classify(content)
+func (p *ClaudeProvider) Classify(ctx context.Context, content string) (*model.ClassificationResult, error) {
+	return p.classifier.Classify(ctx, content)
 }
 
 // Summarize generates a summary using Claude with a pre-built prompt.
 // The prompt should be constructed by the summarizer package using the appropriate template.
-func (p *ClaudeProvider) Summarize(content string, category model.ContentCategory) (string, error) {
+func (p *ClaudeProvider) Summarize(ctx context.Context, content string, category model.ContentCategory) (string, error) {
 	// This method is kept for backward compatibility.
 	// For type-specific summarization, use the summarizer package directly.
 	prompt := fmt.Sprintf("Summarize the following %s content concisely:\n\n%s", string(category), content)
-	return p.Complete(prompt)
+	return p.Complete(ctx, prompt)
 }
@@ -1,7 +1,10 @@
 package llm
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -67,10 +70,11 @@ func TestClaudeProvider_Complete(t *testing.T) {
 			p := &ClaudeProvider{
 				config:  Config{APIKey: "test-key", Model: "claude-sonnet-4-6", MaxTokens: 1024},
 				client:  server.Client(),
+				stream:  server.Client(),
 				baseURL: server.URL,
 			}
 
-			got, err := p.Complete("test prompt")
+			got, err := p.Complete(context.Background(), "test prompt")
 			if tt.wantErr {
 				if err == nil {
 					t.Error("expected error")
@@ -87,6 +91,31 @@ func TestClaudeProvider_Complete(t *testing.T) {
 	}
 }
 
+func TestClaudeProvider_Complete_Canceled(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	p := &ClaudeProvider{
+		config:  Config{APIKey: "test-key", Model: "claude-sonnet-4-6", MaxTokens: 1024},
+		client:  server.Client(),
+		stream:  server.Client(),
+		baseURL: server.URL,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := p.Complete(ctx, "test prompt"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Complete() error = %v, want context.Canceled", err)
+	}
+	if called {
+		t.Error("expected no request to be sent with a canceled context")
+	}
+}
+
 func TestClaudeProvider_Name(t *testing.T) {
 	p := NewClaudeProvider(Config{APIKey: "test", Timeout: 5 * time.Second})
 	if p.Name() != ProviderClaude {
@@ -97,3 +126,133 @@ func TestClaudeProvider_Name(t *testing.T) {
 func TestClaudeProvider_ImplementsProvider(t *testing.T) {
 	var _ Provider = &ClaudeProvider{}
 }
+
+func TestClaudeProvider_StreamOutlastsTimeout(t *testing.T) {
+	// Events keep arriving, so a stream may run well past Config.Timeout.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for range 6 {
+			fmt.Fprint(w, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"가\"}}\n\n")
+			w.(http.Flusher).Flush()
+			time.Sleep(40 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	p := NewClaudeProvider(Config{APIKey: "test-key", Model: "claude-sonnet-4-6", MaxTokens: 1024, Timeout: 100 * time.Millisecond})
+	p.baseURL = server.URL
+
+	start := time.Now()
+	got, err := p.Stream(context.Background(), "test prompt", func(string) error { return nil })
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if got != "가가가가가가" {
+		t.Errorf("Stream() = %q, want six deltas", got)
+	}
+	if elapsed := time.Since(start); elapsed < 2*p.config.Timeout {
+		t.Errorf("stream took %v, want it to outlast the %v timeout", elapsed, p.config.Timeout)
+	}
+}
+
+func TestClaudeProvider_StreamStalls(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"가\"}}\n\n")
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	p := NewClaudeProvider(Config{APIKey: "test-key", Model: "claude-sonnet-4-6", MaxTokens: 1024, Timeout: 50 * time.Millisecond})
+	p.baseURL = server.URL
+
+	_, err := p.Stream(context.Background(), "test prompt", func(string) error { return nil })
+	if !errors.Is(err, errStreamIdle) {
+		t.Errorf("Stream() error = %v, want errStreamIdle", err)
+	}
+}
+
+func TestClaudeProvider_Stream(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		want       string
+		wantTokens []string
+		wantErr    bool
+	}{
+		{
+			name:       "text deltas",
+			statusCode: 200,
+			body: "event: message_start\ndata: {\"type\":\"message_start\"}\n\n" +
+				"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"안녕\"}}\n\n" +
+				"event: ping\ndata: {\"type\":\"ping\"}\n\n" +
+				"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"하세요\"}}\n\n" +
+				"event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n",
+			want:       "안녕하세요",
+			wantTokens: []string{"안녕", "하세요"},
+		},
+		{
+			name:       "error event",
+			statusCode: 200,
+			body:       "event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n",
+			wantErr:    true,
+		},
+		{
+			name:       "non-200 status",
+			statusCode: 401,
+			body:       `{"error":{"message":"invalid x-api-key"}}`,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req claudeRequest
+				json.NewDecoder(r.Body).Decode(&req)
+				if !req.Stream {
+					t.Error("expected stream=true in request")
+				}
+				w.Header().Set("Content-Type", "text/event-stream")
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			p := &ClaudeProvider{
+				config:  Config{APIKey: "test-key", Model: "claude-sonnet-4-6", MaxTokens: 1024},
+				client:  server.Client(),
+				stream:  server.Client(),
+				baseURL: server.URL,
+			}
+
+			var tokens []string
+			got, err := p.Stream(context.Background(), "test prompt", func(tok string) error {
+				tokens = append(tokens, tok)
+				return nil
+			})
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Stream() = %q, want %q", got, tt.want)
+			}
+			if len(tokens) != len(tt.wantTokens) {
+				t.Errorf("tokens = %v, want %v", tokens, tt.wantTokens)
+			}
+		})
+	}
+}
@@ -4,42 +4,83 @@ import "time"
 
 // Config holds configuration for an LLM provider.
 type Config struct {
-	APIKey     string        `json:"api_key"`
-	Model      string        `json:"model"`
-	MaxTokens  int           `json:"max_tokens"`
+	APIKey    string `json:"api_key"`
+	Model     string `json:"model"`
+	MaxTokens int    `json:"max_tokens"`
+	// Timeout bounds a Complete call. A stream has no overall limit, so long
+	// responses are not cut off; Timeout instead bounds the wait for its
+	// response headers and the gap between events.
 	Timeout    time.Duration `json:"timeout"`
 	MaxRetries int           `json:"max_retries"`
+	// ContextWindow is the model's context size in tokens, prompt and
+	// response together. Zero means unknown; see Budget.
+	ContextWindow int `json:"context_window,omitempty"`
+	// OutputReserve is the part of the context window kept for the
+	// response. Zero reserves MaxTokens.
+	OutputReserve int `json:"output_reserve,omitempty"`
+	// BaseURL overrides the API endpoint for providers that support it.
+	BaseURL string `json:"base_url,omitempty"`
+	// Headers are extra HTTP headers sent with every request, e.g. for an
+	// internal gateway. Only OpenAI-compatible providers use them.
+	Headers map[string]string `json:"headers,omitempty"`
 }
 
 // DefaultClaudeConfig returns default configuration for Claude.
 func DefaultClaudeConfig(apiKey string) Config {
 	return Config{
-		APIKey:     apiKey,
-		Model:      "claude-sonnet-4-6",
-		MaxTokens:  4096,
-		Timeout:    30 * time.Second,
-		MaxRetries: 3,
+		APIKey:        apiKey,
+		Model:         "claude-sonnet-4-6",
+		MaxTokens:     4096,
+		Timeout:       30 * time.Second,
+		MaxRetries:    3,
+		ContextWindow: 200000,
 	}
 }
 
 // DefaultOpenAIConfig returns default configuration for OpenAI.
 func DefaultOpenAIConfig(apiKey string) Config {
 	return Config{
-		APIKey:     apiKey,
-		Model:      "gpt-4o",
-		MaxTokens:  4096,
-		Timeout:    30 * time.Second,
-		MaxRetries: 3,
+		APIKey:        apiKey,
+		Model:         "gpt-4o",
+		MaxTokens:     4096,
+		Timeout:       30 * time.Second,
+		MaxRetries:    3,
+		ContextWindow: 128000,
 	}
 }
 
 // DefaultGeminiConfig returns default configuration for Google Gemini.
 func DefaultGeminiConfig(apiKey string) Config {
 	return Config{
-		APIKey:     apiKey,
-		Model:      "gemini-2.0-flash",
-		MaxTokens:  4096,
-		Timeout:    30 * time.Second,
-		MaxRetries: 3,
+		APIKey:        apiKey,
+		Model:         "gemini-2.0-flash",
+		MaxTokens:     4096,
+		Timeout:       30 * time.Second,
+		MaxRetries:    3,
+		ContextWindow: 1048576,
+	}
+}
+
+// DefaultOllamaBaseURL is the address of a local Ollama server.
+const DefaultOllamaBaseURL = "http://localhost:11434"
+
+// DefaultOllamaConfig returns default configuration for a local Ollama server.
+// Local models are slower than hosted APIs, so the timeout is longer. The
+// context window is sent to Ollama with every request, since its own
+// default is small enough to silently cut prompts.
+func DefaultOllamaConfig(baseURL, model string) Config {
+	if baseURL == "" {
+		baseURL = DefaultOllamaBaseURL
+	}
+	if model == "" {
+		model = "llama3.1"
+	}
+	return Config{
+		Model:         model,
+		MaxTokens:     4096,
+		Timeout:       120 * time.Second,
+		MaxRetries:    1,
+		BaseURL:       baseURL,
+		ContextWindow: 16384,
 	}
 }
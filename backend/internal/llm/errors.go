@@ -0,0 +1,147 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sentinel errors describing why an LLM call failed. An *APIError wraps one
+// of these, so callers can test for them with errors.Is.
+var (
+	ErrRateLimited     = errors.New("rate limited")
+	ErrOverloaded      = errors.New("provider overloaded")
+	ErrUnavailable     = errors.New("provider unavailable")
+	ErrTimeout         = errors.New("provider timed out")
+	ErrAuthFailed      = errors.New("authentication failed")
+	ErrQuotaExceeded   = errors.New("quota exceeded")
+	ErrContextTooLong  = errors.New("context too long")
+	ErrInvalidRequest  = errors.New("invalid request")
+	ErrUnexpectedReply = errors.New("unexpected response")
+)
+
+// APIError is an error returned by a provider's API.
+type APIError struct {
+	Provider   ProviderType
+	StatusCode int
+	Message    string
+	// Kind is one of the sentinel errors above.
+	Kind error
+	// RetryAfter is the delay requested by the provider, if any.
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = e.Kind.Error()
+	}
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("%s API error (%d): %s", e.Provider, e.StatusCode, msg)
+	}
+	return fmt.Sprintf("%s API error: %s", e.Provider, msg)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Kind
+}
+
+// IsRetryable reports whether err is a transient failure worth retrying:
+// rate limits, overload, 5xx responses and timeouts.
+func IsRetryable(err error) bool {
+	return errors.Is(err, ErrRateLimited) ||
+		errors.Is(err, ErrOverloaded) ||
+		errors.Is(err, ErrUnavailable) ||
+		errors.Is(err, ErrTimeout)
+}
+
+// vendorError is the error envelope shared closely enough by the hosted
+// providers: Anthropic sets type, OpenAI sets type and code, Gemini sets
+// a numeric code and status.
+type vendorError struct {
+	Error *struct {
+		Message string          `json:"message"`
+		Type    string          `json:"type"`
+		Code    json.RawMessage `json:"code"`
+		Status  string          `json:"status"`
+	} `json:"error"`
+}
+
+// newAPIError classifies a non-200 response from provider.
+func newAPIError(provider ProviderType, resp *http.Response, body []byte) *APIError {
+	e := &APIError{
+		Provider:   provider,
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+
+	var typ string
+	var ve vendorError
+	var plain struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &ve) == nil && ve.Error != nil {
+		e.Message = ve.Error.Message
+		typ = strings.ToLower(ve.Error.Type + " " + ve.Error.Status + " " + strings.Trim(string(ve.Error.Code), `"`))
+	} else if json.Unmarshal(body, &plain) == nil {
+		// Ollama reports errors as a bare string.
+		e.Message = plain.Error
+	}
+	if e.Message == "" {
+		e.Message = http.StatusText(resp.StatusCode)
+	}
+	msg := strings.ToLower(e.Message)
+
+	switch {
+	case isContextTooLong(typ, msg):
+		e.Kind = ErrContextTooLong
+	case strings.Contains(typ, "insufficient_quota"):
+		e.Kind = ErrQuotaExceeded
+	case resp.StatusCode == http.StatusTooManyRequests:
+		e.Kind = ErrRateLimited
+	case resp.StatusCode == 529 || strings.Contains(typ, "overloaded"):
+		// Anthropic signals overload with a non-standard 529.
+		e.Kind = ErrOverloaded
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		e.Kind = ErrAuthFailed
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusGatewayTimeout:
+		e.Kind = ErrTimeout
+	case resp.StatusCode >= 500:
+		e.Kind = ErrUnavailable
+	case resp.StatusCode >= 400:
+		e.Kind = ErrInvalidRequest
+	default:
+		e.Kind = ErrUnexpectedReply
+	}
+	return e
+}
+
+// isContextTooLong recognises each vendor's way of rejecting an oversized prompt.
+func isContextTooLong(typ, msg string) bool {
+	return strings.Contains(typ, "context_length_exceeded") ||
+		strings.Contains(typ, "request_too_large") ||
+		strings.Contains(msg, "prompt is too long") ||
+		strings.Contains(msg, "maximum context length") ||
+		strings.Contains(msg, "exceeds the maximum number of tokens")
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as
+// an HTTP date. It returns 0 if the header is missing or invalid.
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,120 @@
+package llm
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewAPIError(t *testing.T) {
+	tests := []struct {
+		name      string
+		provider  ProviderType
+		status    int
+		body      string
+		wantKind  error
+		wantRetry bool
+	}{
+		{
+			name:      "claude rate limit",
+			provider:  ProviderClaude,
+			status:    429,
+			body:      `{"type":"error","error":{"type":"rate_limit_error","message":"slow down"}}`,
+			wantKind:  ErrRateLimited,
+			wantRetry: true,
+		},
+		{
+			name:      "claude overloaded",
+			provider:  ProviderClaude,
+			status:    529,
+			body:      `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+			wantKind:  ErrOverloaded,
+			wantRetry: true,
+		},
+		{
+			name:     "claude prompt too long",
+			provider: ProviderClaude,
+			status:   400,
+			body:     `{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long: 210000 tokens > 200000 maximum"}}`,
+			wantKind: ErrContextTooLong,
+		},
+		{
+			name:     "claude auth",
+			provider: ProviderClaude,
+			status:   401,
+			body:     `{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`,
+			wantKind: ErrAuthFailed,
+		},
+		{
+			name:     "openai context length",
+			provider: ProviderOpenAI,
+			status:   400,
+			body:     `{"error":{"message":"This model's maximum context length is 128000 tokens.","type":"invalid_request_error","code":"context_length_exceeded"}}`,
+			wantKind: ErrContextTooLong,
+		},
+		{
+			name:     "openai insufficient quota",
+			provider: ProviderOpenAI,
+			status:   429,
+			body:     `{"error":{"message":"You exceeded your current quota","type":"insufficient_quota","code":"insufficient_quota"}}`,
+			wantKind: ErrQuotaExceeded,
+		},
+		{
+			name:      "gemini resource exhausted",
+			provider:  ProviderGemini,
+			status:    429,
+			body:      `{"error":{"code":429,"message":"Resource has been exhausted","status":"RESOURCE_EXHAUSTED"}}`,
+			wantKind:  ErrRateLimited,
+			wantRetry: true,
+		},
+		{
+			name:     "gemini permission denied",
+			provider: ProviderGemini,
+			status:   403,
+			body:     `{"error":{"code":403,"message":"API key not valid","status":"PERMISSION_DENIED"}}`,
+			wantKind: ErrAuthFailed,
+		},
+		{
+			name:      "server error without body",
+			provider:  ProviderOpenAI,
+			status:    502,
+			body:      `<html>Bad Gateway</html>`,
+			wantKind:  ErrUnavailable,
+			wantRetry: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			err := newAPIError(tt.provider, resp, []byte(tt.body))
+
+			if !errors.Is(err, tt.wantKind) {
+				t.Errorf("kind = %v, want %v", err.Kind, tt.wantKind)
+			}
+			if got := IsRetryable(err); got != tt.wantRetry {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.wantRetry)
+			}
+			if err.Message == "" {
+				t.Error("expected non-empty message")
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if got := parseRetryAfter("3"); got != 3*time.Second {
+		t.Errorf("seconds: got %v, want 3s", got)
+	}
+	if got := parseRetryAfter(""); got != 0 {
+		t.Errorf("empty: got %v, want 0", got)
+	}
+	if got := parseRetryAfter("soon"); got != 0 {
+		t.Errorf("invalid: got %v, want 0", got)
+	}
+	date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(date); got <= 0 || got > time.Minute {
+		t.Errorf("http date: got %v, want within 1m", got)
+	}
+}
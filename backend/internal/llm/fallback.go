@@ -0,0 +1,208 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+	"github.com/rookiecj/scrum-agents/backend/internal/tracing"
+)
+
+// FallbackError is returned when every provider in a fallback chain failed.
+// It wraps each provider's error in the order they were tried.
+type FallbackError struct {
+	Errs []error
+}
+
+func (e *FallbackError) Error() string {
+	msgs := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		msgs[i] = err.Error()
+	}
+	return "all providers failed: " + strings.Join(msgs, "; ")
+}
+
+func (e *FallbackError) Unwrap() []error {
+	return e.Errs
+}
+
+// Route is a Provider that sends each call to the preferred provider of an
+// Adapter and, on failure, to the next provider in the adapter's fallback
+// order. Routes are cheap and safe to share between requests.
+type Route struct {
+	adapter   *Adapter
+	preferred ProviderType
+}
+
+// Route returns a Route starting at preferred, or at the default provider if
+// preferred is empty.
+func (a *Adapter) Route(preferred ProviderType) *Route {
+	return &Route{adapter: a, preferred: preferred}
+}
+
+// Name returns the preferred provider type.
+func (r *Route) Name() ProviderType {
+	if r.preferred == "" {
+		return r.adapter.defaultProvider
+	}
+	return r.preferred
+}
+
+// Model returns the model of the preferred provider, or "" if it is not
+// registered or does not report one.
+func (r *Route) Model() string {
+	p, err := r.adapter.GetProvider(r.preferred)
+	if err != nil {
+		return ""
+	}
+	if m, ok := p.(interface{ Model() string }); ok {
+		return m.Model()
+	}
+	return ""
+}
+
+// Complete sends a prompt through the fallback chain.
+func (r *Route) Complete(ctx context.Context, prompt string) (string, error) {
+	var out string
+	err := r.try(ctx, "complete", func(ctx context.Context, p Provider) (err error) {
+		out, err = p.Complete(ctx, prompt)
+		return err
+	})
+	return out, err
+}
+
+// Stream streams a prompt through the fallback chain. Once a provider has
+// emitted a token the response is committed to it and its error, if any, is
+// returned without falling back.
+func (r *Route) Stream(ctx context.Context, prompt string, onToken func(string) error) (string, error) {
+	var out string
+	committed := false
+	err := r.try(ctx, "stream", func(ctx context.Context, p Provider) (err error) {
+		out, err = p.Stream(ctx, prompt, func(text string) error {
+			committed = true
+			return onToken(text)
+		})
+		if err != nil && committed {
+			return &committedError{err}
+		}
+		return err
+	})
+	if ce, ok := err.(*committedError); ok {
+		return "", ce.err
+	}
+	return out, err
+}
+
+// committedError stops the fallback loop after a partially streamed response.
+type committedError struct{ err error }
+
+func (e *committedError) Error() string { return e.err.Error() }
+
+func (e *committedError) Unwrap() error { return e.err }
+
+// Classify classifies content through the fallback chain.
+func (r *Route) Classify(ctx context.Context, content string) (*model.ClassificationResult, error) {
+	var out *model.ClassificationResult
+	err := r.try(ctx, "classify", func(ctx context.Context, p Provider) (err error) {
+		out, err = p.Classify(ctx, content)
+		return err
+	})
+	return out, err
+}
+
+// Summarize generates a summary through the fallback chain.
+func (r *Route) Summarize(ctx context.Context, content string, category model.ContentCategory) (string, error) {
+	var out string
+	err := r.try(ctx, "summarize", func(ctx context.Context, p Provider) (err error) {
+		out, err = p.Summarize(ctx, content, category)
+		return err
+	})
+	return out, err
+}
+
+// try calls fn with each provider in the chain until one succeeds, and
+// records the provider that served the call in ctx (see TrackServed).
+// Every attempt is counted and timed under op in the metrics, and traced
+// in a span of its own passed to fn in ctx.
+func (r *Route) try(ctx context.Context, op string, fn func(context.Context, Provider) error) error {
+	chain := r.adapter.chain(r.preferred)
+	if len(chain) == 0 {
+		return fmt.Errorf("provider %q not available", r.Name())
+	}
+
+	var errs []error
+	for i, p := range chain {
+		start := time.Now()
+		callCtx, span := tracing.Start(ctx, "llm."+op,
+			slog.String("llm.provider", string(p.Name())),
+			slog.Int("llm.attempt", i+1),
+		)
+		if m, ok := p.(interface{ Model() string }); ok && m.Model() != "" {
+			span.SetAttrs(slog.String("llm.model", m.Model()))
+		}
+		err := fn(callCtx, p)
+		span.RecordError(err)
+		span.End()
+		observeCall(p.Name(), op, start, err)
+		if err == nil {
+			if i > 0 {
+				slog.InfoContext(ctx, "llm: request served by fallback provider",
+					slog.String("preferred", string(r.Name())),
+					slog.String("provider", string(p.Name())),
+				)
+			}
+			RecordServed(ctx, p.Name())
+			return nil
+		}
+		if _, ok := err.(*committedError); ok || ctx.Err() != nil {
+			return err
+		}
+		errs = append(errs, err)
+		if i < len(chain)-1 {
+			slog.WarnContext(ctx, "llm: provider failed, falling back",
+				slog.String("provider", string(p.Name())),
+				slog.String("next", string(chain[i+1].Name())),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return &FallbackError{Errs: errs}
+}
+
+// servedKey is the context key for the provider recorder.
+type servedKey struct{}
+
+type servedRecorder struct {
+	mu       sync.Mutex
+	provider ProviderType
+}
+
+// TrackServed returns a context that records which provider served the
+// calls made through a Route, and a function reporting it. The function
+// returns "" if no call has succeeded yet.
+func TrackServed(ctx context.Context) (context.Context, func() ProviderType) {
+	rec := &servedRecorder{}
+	return context.WithValue(ctx, servedKey{}, rec), func() ProviderType {
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		return rec.provider
+	}
+}
+
+// RecordServed records p as the provider that served a call, for contexts
+// created by TrackServed. It is a no-op for other contexts.
+func RecordServed(ctx context.Context, p ProviderType) {
+	if rec, ok := ctx.Value(servedKey{}).(*servedRecorder); ok {
+		rec.mu.Lock()
+		rec.provider = p
+		rec.mu.Unlock()
+	}
+}
@@ -0,0 +1,126 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+)
+
+func TestRoute_FallsBackInOrder(t *testing.T) {
+	claude := &mockProvider{name: ProviderClaude, completeErr: &APIError{Provider: ProviderClaude, Kind: ErrOverloaded}}
+	openai := &mockProvider{name: ProviderOpenAI, completeErr: errors.New("openai down")}
+	gemini := &mockProvider{name: ProviderGemini, completeRes: "gemini response"}
+	adapter, _ := NewAdapter(ProviderClaude, claude, openai, gemini)
+	adapter.SetFallbackOrder(ProviderClaude, ProviderOpenAI, ProviderGemini)
+
+	ctx, served := TrackServed(context.Background())
+	got, err := adapter.Route("").Complete(ctx, "prompt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "gemini response" {
+		t.Errorf("Complete() = %q, want %q", got, "gemini response")
+	}
+	if served() != ProviderGemini {
+		t.Errorf("served = %q, want %q", served(), ProviderGemini)
+	}
+}
+
+func TestRoute_PreferredFirst(t *testing.T) {
+	claude := &mockProvider{name: ProviderClaude, completeRes: "claude response"}
+	openai := &mockProvider{name: ProviderOpenAI, completeRes: "openai response"}
+	adapter, _ := NewAdapter(ProviderClaude, claude, openai)
+	adapter.SetFallbackOrder(ProviderClaude, ProviderOpenAI)
+
+	ctx, served := TrackServed(context.Background())
+	got, _ := adapter.Route(ProviderOpenAI).Complete(ctx, "prompt")
+	if got != "openai response" || served() != ProviderOpenAI {
+		t.Errorf("got %q from %q, want openai", got, served())
+	}
+}
+
+func TestRoute_SkipsUnconfiguredProviders(t *testing.T) {
+	openai := &mockProvider{name: ProviderOpenAI, classifyRes: &model.ClassificationResult{Primary: model.CategoryNews}}
+	adapter, _ := NewAdapter(ProviderOpenAI, openai)
+	adapter.SetFallbackOrder(ProviderClaude, ProviderOpenAI)
+
+	ctx, served := TrackServed(context.Background())
+	got, err := adapter.Route(ProviderClaude).Classify(ctx, "content")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Primary != model.CategoryNews || served() != ProviderOpenAI {
+		t.Errorf("got %+v from %q, want openai classification", got, served())
+	}
+}
+
+func TestRoute_AllFail(t *testing.T) {
+	claude := &mockProvider{name: ProviderClaude, summarizeErr: &APIError{Provider: ProviderClaude, Kind: ErrRateLimited}}
+	openai := &mockProvider{name: ProviderOpenAI, summarizeErr: &APIError{Provider: ProviderOpenAI, Kind: ErrAuthFailed}}
+	adapter, _ := NewAdapter(ProviderClaude, claude, openai)
+	adapter.SetFallbackOrder(ProviderClaude, ProviderOpenAI)
+
+	_, err := adapter.Route("").Summarize(context.Background(), "content", model.CategoryNews)
+	var fe *FallbackError
+	if !errors.As(err, &fe) || len(fe.Errs) != 2 {
+		t.Fatalf("error = %v, want FallbackError with 2 errors", err)
+	}
+	if !errors.Is(err, ErrRateLimited) || !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("expected both provider errors to be wrapped, got %v", err)
+	}
+}
+
+func TestRoute_NoFallbackWithoutOrder(t *testing.T) {
+	claude := &mockProvider{name: ProviderClaude, completeErr: errors.New("API down")}
+	openai := &mockProvider{name: ProviderOpenAI, completeRes: "openai response"}
+	adapter, _ := NewAdapter(ProviderClaude, claude, openai)
+
+	if _, err := adapter.Route("").Complete(context.Background(), "prompt"); err == nil {
+		t.Error("expected error without a fallback order")
+	}
+}
+
+func TestRoute_StreamDoesNotFallBackAfterFirstToken(t *testing.T) {
+	partial := &partialStreamProvider{mockProvider{name: ProviderClaude}}
+	openai := &mockProvider{name: ProviderOpenAI, completeRes: "openai response"}
+	adapter, _ := NewAdapter(ProviderClaude, partial, openai)
+	adapter.SetFallbackOrder(ProviderClaude, ProviderOpenAI)
+
+	var tokens []string
+	_, err := adapter.Route("").Stream(context.Background(), "prompt", func(s string) error {
+		tokens = append(tokens, s)
+		return nil
+	})
+	if err == nil {
+		t.Fatal("expected error from partially streamed provider")
+	}
+	if len(tokens) != 1 {
+		t.Errorf("tokens = %v, want only the first provider's token", tokens)
+	}
+}
+
+func TestRoute_StreamFallsBackBeforeFirstToken(t *testing.T) {
+	claude := &mockProvider{name: ProviderClaude, completeErr: errors.New("API down")}
+	openai := &mockProvider{name: ProviderOpenAI, completeRes: "openai response"}
+	adapter, _ := NewAdapter(ProviderClaude, claude, openai)
+	adapter.SetFallbackOrder(ProviderClaude, ProviderOpenAI)
+
+	got, err := adapter.Route("").Stream(context.Background(), "prompt", func(string) error { return nil })
+	if err != nil || got != "openai response" {
+		t.Errorf("Stream() = %q, %v; want openai response", got, err)
+	}
+}
+
+// partialStreamProvider emits one token and then fails.
+type partialStreamProvider struct {
+	mockProvider
+}
+
+func (p *partialStreamProvider) Stream(ctx context.Context, prompt string, onToken func(string) error) (string, error) {
+	if err := onToken("partial"); err != nil {
+		return "", err
+	}
+	return "", errors.New("connection reset")
+}
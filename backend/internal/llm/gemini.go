@@ -2,10 +2,12 @@ package llm
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/rookiecj/scrum-agents/backend/internal/classifier"
 	"github.com/rookiecj/scrum-agents/backend/internal/model"
@@ -15,6 +17,7 @@ import (
 type GeminiProvider struct {
 	config     Config
 	client     *http.Client
+	stream     *http.Client
 	baseURL    string
 	classifier *classifier.LLMClassifier
 }
@@ -24,6 +27,7 @@ func NewGeminiProvider(config Config) *GeminiProvider {
 	p := &GeminiProvider{
 		config:  config,
 		client:  &http.Client{Timeout: config.Timeout},
+		stream:  newStreamClient(config.Timeout),
 		baseURL: "https://generativelanguage.googleapis.com/v1beta",
 	}
 	p.classifier = classifier.NewLLMClassifier(p)
@@ -35,6 +39,11 @@ func (p *GeminiProvider) Name() ProviderType {
 	return ProviderGemini
 }
 
+// Model returns the configured model name.
+func (p *GeminiProvider) Model() string {
+	return p.config.Model
+}
+
 type geminiRequest struct {
 	Contents []geminiContent `json:"contents"`
 }
@@ -55,14 +64,21 @@ type geminiResponse struct {
 			} `json:"parts"`
 		} `json:"content"`
 	} `json:"candidates"`
-	Error *struct {
+	// UsageMetadata is cumulative: in a stream, the last chunk has the totals.
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+	} `json:"usageMetadata"`
+	ModelVersion string `json:"modelVersion"`
+	Error        *struct {
 		Message string `json:"message"`
 		Code    int    `json:"code"`
 	} `json:"error,omitempty"`
 }
 
-// Complete sends a prompt to Gemini and returns the response.
-func (p *GeminiProvider) Complete(prompt string) (string, error) {
+// newRequest builds a request for the given Gemini model method
+// (e.g. "generateContent" or "streamGenerateContent?alt=sse").
+func (p *GeminiProvider) newRequest(ctx context.Context, prompt, method string) (*http.Request, error) {
 	reqBody := geminiRequest{
 		Contents: []geminiContent{
 			{
@@ -75,20 +91,26 @@ func (p *GeminiProvider) Complete(prompt string) (string, error) {
 
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("marshaling request: %w", err)
+		return nil, fmt.Errorf("marshaling request: %w", err)
 	}
 
-	url := fmt.Sprintf("%s/models/%s:generateContent", p.baseURL, p.config.Model)
-	req, err := http.NewRequest("POST", url, bytes.NewReader(jsonBody))
+	url := fmt.Sprintf("%s/models/%s:%s", p.baseURL, p.config.Model, method)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonBody))
 	if err != nil {
-		return "", fmt.Errorf("creating request: %w", err)
+		return nil, fmt.Errorf("creating request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("x-goog-api-key", p.config.APIKey)
+	return req, nil
+}
 
-	resp, err := p.client.Do(req)
+// Complete sends a prompt to Gemini and returns the response.
+func (p *GeminiProvider) Complete(ctx context.Context, prompt string) (string, error) {
+	resp, err := doWithRetry(ctx, p.client, ProviderGemini, p.config.MaxRetries, func() (*http.Request, error) {
+		return p.newRequest(ctx, prompt, "generateContent")
+	})
 	if err != nil {
-		return "", fmt.Errorf("calling Gemini API: %w", err)
+		return "", err
 	}
 	defer resp.Body.Close()
 
@@ -105,6 +127,7 @@ func (p *GeminiProvider) Complete(prompt string) (string, error) {
 	if result.Error != nil {
 		return "", fmt.Errorf("Gemini API error: %s", result.Error.Message)
 	}
+	recordUsage(ctx, result.usage(p.config.Model))
 
 	if len(result.Candidates) == 0 {
 		return "", fmt.Errorf("empty response from Gemini")
@@ -117,13 +140,78 @@ func (p *GeminiProvider) Complete(prompt string) (string, error) {
 	return result.Candidates[0].Content.Parts[0].Text, nil
 }
 
+// Stream sends a prompt to Gemini's streamGenerateContent endpoint in SSE
+// mode, calling onToken for every text part. It returns the full response text.
+func (p *GeminiProvider) Stream(ctx context.Context, prompt string, onToken func(string) error) (string, error) {
+	ctx, watch, stop := withIdleTimeout(ctx, p.config.Timeout)
+	defer stop()
+	resp, err := doWithRetry(ctx, p.stream, ProviderGemini, p.config.MaxRetries, func() (*http.Request, error) {
+		req, err := p.newRequest(ctx, prompt, "streamGenerateContent?alt=sse")
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "text/event-stream")
+		return req, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var sb strings.Builder
+	usage := Usage{Provider: ProviderGemini, Model: p.config.Model}
+	defer func() { recordUsage(ctx, usage) }()
+	err = readSSE(watch(resp.Body), func(_, data string) error {
+		var chunk geminiResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("parsing stream chunk: %w", err)
+		}
+		if chunk.Error != nil {
+			return fmt.Errorf("Gemini API error: %s", chunk.Error.Message)
+		}
+		if u := chunk.usage(p.config.Model); u.InputTokens != 0 || u.OutputTokens != 0 {
+			usage = u
+		}
+		for _, c := range chunk.Candidates {
+			for _, part := range c.Content.Parts {
+				if part.Text == "" {
+					continue
+				}
+				sb.WriteString(part.Text)
+				if err := onToken(part.Text); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("reading stream: %w", err)
+	}
+
+	if sb.Len() == 0 {
+		return "", fmt.Errorf("empty response from Gemini")
+	}
+	return sb.String(), nil
+}
+
+// usage returns the token usage reported in r.
+func (r *geminiResponse) usage(configuredModel string) Usage {
+	return Usage{
+		Provider:     ProviderGemini,
+		Model:        modelOr(r.ModelVersion, configuredModel),
+		InputTokens:  r.UsageMetadata.PromptTokenCount,
+		OutputTokens: r.UsageMetadata.CandidatesTokenCount,
+	}
+}
+
 // Classify classifies content using Gemini.
-func (p *GeminiProvider) Classify(content string) (*model.ClassificationResult, error) {
-	return p.classifier.Classify(content)
+func (p *GeminiProvider) Classify(ctx context.Context, content string) (*model.ClassificationResult, error) {
+	return p.classifier.Classify(ctx, content)
 }
 
 // Summarize generates a summary using Gemini with a pre-built prompt.
-func (p *GeminiProvider) Summarize(content string, category model.ContentCategory) (string, error) {
+func (p *GeminiProvider) Summarize(ctx context.Context, content string, category model.ContentCategory) (string, error) {
 	prompt := fmt.Sprintf("Summarize the following %s content concisely:\n\n%s", string(category), content)
-	return p.Complete(prompt)
+	return p.Complete(ctx, prompt)
 }
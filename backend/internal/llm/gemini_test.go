@@ -1,6 +1,7 @@
 package llm
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -115,10 +116,11 @@ func TestGeminiProvider_Complete(t *testing.T) {
 			p := &GeminiProvider{
 				config:  Config{APIKey: "test-key", Model: "gemini-2.0-flash", MaxTokens: 4096},
 				client:  server.Client(),
+				stream:  server.Client(),
 				baseURL: server.URL,
 			}
 
-			got, err := p.Complete("test prompt")
+			got, err := p.Complete(context.Background(), "test prompt")
 			if tt.wantErr {
 				if err == nil {
 					t.Error("expected error")
@@ -180,10 +182,11 @@ func TestGeminiProvider_RequestFormat(t *testing.T) {
 	p := &GeminiProvider{
 		config:  Config{APIKey: "test-key", Model: "gemini-2.0-flash", MaxTokens: 4096},
 		client:  server.Client(),
+		stream:  server.Client(),
 		baseURL: server.URL,
 	}
 
-	_, err := p.Complete("hello world")
+	_, err := p.Complete(context.Background(), "hello world")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -199,3 +202,64 @@ func TestGeminiProvider_RequestFormat(t *testing.T) {
 		t.Errorf("prompt = %q, want %q", receivedBody.Contents[0].Parts[0].Text, "hello world")
 	}
 }
+
+func TestGeminiProvider_Stream(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:       "text parts",
+			statusCode: 200,
+			body: "data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"Hello\"}]}}]}\n\n" +
+				"data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\" from Gemini\"}]}}]}\n\n",
+			want: "Hello from Gemini",
+		},
+		{
+			name:       "non-200 status",
+			statusCode: 400,
+			body:       `{"error":{"message":"API key not valid","code":400}}`,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/models/gemini-2.0-flash:streamGenerateContent" {
+					t.Errorf("path = %q", r.URL.Path)
+				}
+				if r.URL.Query().Get("alt") != "sse" {
+					t.Error("expected alt=sse query parameter")
+				}
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			p := &GeminiProvider{
+				config:  Config{APIKey: "test-key", Model: "gemini-2.0-flash", MaxTokens: 1024},
+				client:  server.Client(),
+				stream:  server.Client(),
+				baseURL: server.URL,
+			}
+
+			got, err := p.Stream(context.Background(), "test prompt", func(string) error { return nil })
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Stream() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
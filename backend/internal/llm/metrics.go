@@ -0,0 +1,62 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/metrics"
+)
+
+var (
+	callsTotal = metrics.NewCounter("llm_calls_total",
+		"LLM provider calls, by provider, operation and outcome.",
+		"provider", "op", "outcome")
+	callDuration = metrics.NewHistogram("llm_call_duration_seconds",
+		"Time taken by LLM provider calls, by provider and operation.",
+		metrics.SlowBuckets, "provider", "op")
+	errorsTotal = metrics.NewCounter("llm_errors_total",
+		"Failed LLM provider calls, by provider and kind of error.",
+		"provider", "kind")
+)
+
+// observeCall records a call to provider for op that started at start and
+// returned err.
+func observeCall(provider ProviderType, op string, start time.Time, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+		errorsTotal.Inc(string(provider), errorKind(err))
+	}
+	callsTotal.Inc(string(provider), op, outcome)
+	callDuration.Observe(time.Since(start).Seconds(), string(provider), op)
+}
+
+// errorKinds names the sentinel errors in metric labels.
+var errorKinds = []struct {
+	err  error
+	kind string
+}{
+	{ErrRateLimited, "rate_limited"},
+	{ErrOverloaded, "overloaded"},
+	{ErrUnavailable, "unavailable"},
+	{ErrTimeout, "timeout"},
+	{ErrAuthFailed, "auth_failed"},
+	{ErrQuotaExceeded, "quota_exceeded"},
+	{ErrContextTooLong, "context_too_long"},
+	{ErrInvalidRequest, "invalid_request"},
+	{ErrUnexpectedReply, "unexpected_reply"},
+	{context.DeadlineExceeded, "timeout"},
+	{context.Canceled, "canceled"},
+}
+
+// errorKind returns a short label describing err, or "other" if it wraps
+// none of the known errors.
+func errorKind(err error) string {
+	for _, k := range errorKinds {
+		if errors.Is(err, k.err) {
+			return k.kind
+		}
+	}
+	return "other"
+}
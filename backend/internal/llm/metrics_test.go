@@ -0,0 +1,93 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+	"github.com/rookiecj/scrum-agents/backend/internal/tracing"
+)
+
+func TestRoute_RecordsMetrics(t *testing.T) {
+	failing := &mockProvider{name: "metrics-a", completeErr: &APIError{Provider: "metrics-a", Kind: ErrOverloaded}}
+	serving := &mockProvider{name: "metrics-b", completeRes: "ok"}
+	adapter, _ := NewAdapter("metrics-a", failing, serving)
+	adapter.SetFallbackOrder("metrics-a", "metrics-b")
+
+	if _, err := adapter.Route("").Complete(context.Background(), "prompt"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := callsTotal.Value("metrics-a", "complete", "error"); got != 1 {
+		t.Errorf("failed calls = %v, want 1", got)
+	}
+	if got := errorsTotal.Value("metrics-a", "overloaded"); got != 1 {
+		t.Errorf("overloaded errors = %v, want 1", got)
+	}
+	if got := callsTotal.Value("metrics-b", "complete", "success"); got != 1 {
+		t.Errorf("successful calls = %v, want 1", got)
+	}
+	if got := callDuration.Count("metrics-b", "complete"); got != 1 {
+		t.Errorf("timed calls = %d, want 1", got)
+	}
+}
+
+func TestErrorKind(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{&APIError{Kind: ErrRateLimited}, "rate_limited"},
+		{fmt.Errorf("calling: %w", &APIError{Kind: ErrContextTooLong}), "context_too_long"},
+		{&committedError{&APIError{Kind: ErrUnavailable}}, "unavailable"},
+		{context.DeadlineExceeded, "timeout"},
+		{context.Canceled, "canceled"},
+		{errors.New("boom"), "other"},
+	}
+	for _, tt := range tests {
+		if got := errorKind(tt.err); got != tt.want {
+			t.Errorf("errorKind(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+// spanRecorder keeps the spans exported during a test.
+type spanRecorder struct{ spans []tracing.Record }
+
+func (s *spanRecorder) ExportSpan(r tracing.Record)    { s.spans = append(s.spans, r) }
+func (s *spanRecorder) Shutdown(context.Context) error { return nil }
+
+func TestRoute_TracesAttempts(t *testing.T) {
+	rec := &spanRecorder{}
+	prev := tracing.SetExporter(rec)
+	defer tracing.SetExporter(prev)
+
+	failing := &mockProvider{name: ProviderClaude, classifyErr: &APIError{Provider: ProviderClaude, Kind: ErrRateLimited}}
+	serving := &mockProvider{name: ProviderOpenAI, classifyRes: &model.ClassificationResult{Primary: model.CategoryNews}}
+	adapter, _ := NewAdapter(ProviderClaude, failing, serving)
+	adapter.SetFallbackOrder(ProviderClaude, ProviderOpenAI)
+
+	ctx, parent := tracing.Start(context.Background(), "request")
+	if _, err := adapter.Route("").Classify(ctx, "content"); err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+
+	if len(rec.spans) != 3 {
+		t.Fatalf("exported %d spans, want 3", len(rec.spans))
+	}
+	for i, want := range []struct {
+		provider string
+		failed   bool
+	}{{"claude", true}, {"openai", false}} {
+		s := rec.spans[i]
+		if s.Name != "llm.classify" || s.ParentID != parent.SpanID() {
+			t.Errorf("span %d = %q with parent %s", i, s.Name, s.ParentID)
+		}
+		if s.Attrs[0].Value.String() != want.provider || (s.Error != "") != want.failed {
+			t.Errorf("span %d: provider %v, error %q", i, s.Attrs[0].Value, s.Error)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/classifier"
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+)
+
+// OllamaProvider implements the Provider interface for a local Ollama server,
+// so content never leaves the machine. Complete uses /api/generate and Stream
+// uses /api/chat, whose newline-delimited JSON carries incremental message
+// content.
+type OllamaProvider struct {
+	config     Config
+	client     *http.Client
+	stream     *http.Client
+	baseURL    string
+	classifier *classifier.LLMClassifier
+}
+
+// NewOllamaProvider creates a new Ollama provider. config.BaseURL selects the
+// server; it defaults to DefaultOllamaBaseURL.
+func NewOllamaProvider(config Config) *OllamaProvider {
+	baseURL := strings.TrimSuffix(config.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultOllamaBaseURL
+	}
+	p := &OllamaProvider{
+		config:  config,
+		client:  &http.Client{Timeout: config.Timeout},
+		stream:  newStreamClient(config.Timeout),
+		baseURL: baseURL,
+	}
+	p.classifier = classifier.NewLLMClassifier(p)
+	return p
+}
+
+// Name returns the provider type.
+func (p *OllamaProvider) Name() ProviderType {
+	return ProviderOllama
+}
+
+// Model returns the configured model name.
+func (p *OllamaProvider) Model() string {
+	return p.config.Model
+}
+
+type ollamaOptions struct {
+	NumPredict int `json:"num_predict,omitempty"`
+	NumCtx     int `json:"num_ctx,omitempty"`
+}
+
+type ollamaGenerateRequest struct {
+	Model   string        `json:"model"`
+	Prompt  string        `json:"prompt"`
+	Stream  bool          `json:"stream"`
+	Options ollamaOptions `json:"options"`
+}
+
+type ollamaGenerateResponse struct {
+	Response string `json:"response"`
+	Error    string `json:"error,omitempty"`
+	ollamaCounts
+}
+
+// ollamaCounts are the token counts of a finished generation.
+type ollamaCounts struct {
+	Model           string `json:"model"`
+	PromptEvalCount int    `json:"prompt_eval_count"`
+	EvalCount       int    `json:"eval_count"`
+}
+
+func (c ollamaCounts) usage(configuredModel string) Usage {
+	return Usage{
+		Provider:     ProviderOllama,
+		Model:        modelOr(c.Model, configuredModel),
+		InputTokens:  c.PromptEvalCount,
+		OutputTokens: c.EvalCount,
+	}
+}
+
+type ollamaChatRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Options  ollamaOptions   `json:"options"`
+}
+
+type ollamaMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ollamaChatChunk is one line of a streamed /api/chat response.
+type ollamaChatChunk struct {
+	Message ollamaMessage `json:"message"`
+	Done    bool          `json:"done"`
+	Error   string        `json:"error,omitempty"`
+	ollamaCounts
+}
+
+// options returns the generation options sent with every request.
+func (p *OllamaProvider) options() ollamaOptions {
+	return ollamaOptions{NumPredict: p.config.MaxTokens, NumCtx: p.config.ContextWindow}
+}
+
+// newRequest builds a POST request to the given API path.
+func (p *OllamaProvider) newRequest(ctx context.Context, path string, body any) (*http.Request, error) {
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+path, bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// Complete sends a prompt to Ollama's generate endpoint and returns the response.
+func (p *OllamaProvider) Complete(ctx context.Context, prompt string) (string, error) {
+	reqBody := ollamaGenerateRequest{
+		Model:   p.config.Model,
+		Prompt:  prompt,
+		Options: p.options(),
+	}
+	resp, err := doWithRetry(ctx, p.client, ProviderOllama, p.config.MaxRetries, func() (*http.Request, error) {
+		return p.newRequest(ctx, "/api/generate", reqBody)
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading response: %w", err)
+	}
+
+	var result ollamaGenerateResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("parsing response: %w", err)
+	}
+
+	if result.Error != "" {
+		return "", fmt.Errorf("Ollama API error: %s", result.Error)
+	}
+	recordUsage(ctx, result.usage(p.config.Model))
+
+	if result.Response == "" {
+		return "", fmt.Errorf("empty response from Ollama")
+	}
+
+	return result.Response, nil
+}
+
+// Stream sends a prompt to Ollama's chat endpoint with streaming enabled,
+// calling onToken for every content fragment. It returns the full response text.
+func (p *OllamaProvider) Stream(ctx context.Context, prompt string, onToken func(string) error) (string, error) {
+	reqBody := ollamaChatRequest{
+		Model:    p.config.Model,
+		Messages: []ollamaMessage{{Role: "user", Content: prompt}},
+		Stream:   true,
+		Options:  p.options(),
+	}
+	ctx, watch, stop := withIdleTimeout(ctx, p.config.Timeout)
+	defer stop()
+	resp, err := doWithRetry(ctx, p.stream, ProviderOllama, p.config.MaxRetries, func() (*http.Request, error) {
+		return p.newRequest(ctx, "/api/chat", reqBody)
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var sb strings.Builder
+	scanner := bufio.NewScanner(watch(resp.Body))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxSSELineSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var chunk ollamaChatChunk
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return "", fmt.Errorf("parsing stream chunk: %w", err)
+		}
+		if chunk.Error != "" {
+			return "", fmt.Errorf("Ollama API error: %s", chunk.Error)
+		}
+		if text := chunk.Message.Content; text != "" {
+			sb.WriteString(text)
+			if err := onToken(text); err != nil {
+				return "", fmt.Errorf("reading stream: %w", err)
+			}
+		}
+		if chunk.Done {
+			recordUsage(ctx, chunk.usage(p.config.Model))
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("reading stream: %w", err)
+	}
+
+	if sb.Len() == 0 {
+		return "", fmt.Errorf("empty response from Ollama")
+	}
+	return sb.String(), nil
+}
+
+// Ping reports whether the Ollama server is reachable by listing its local
+// models. It is used instead of an API key to decide availability.
+func (p *OllamaProvider) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", p.baseURL+"/api/tags", nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("calling Ollama API: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Ollama API error: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Classify classifies content using Ollama.
+func (p *OllamaProvider) Classify(ctx context.Context, content string) (*model.ClassificationResult, error) {
+	return p.classifier.Classify(ctx, content)
+}
+
+// Summarize generates a summary using Ollama with a pre-built prompt.
+// The prompt should be constructed by the summarizer package using the appropriate template.
+func (p *OllamaProvider) Summarize(ctx context.Context, content string, category model.ContentCategory) (string, error) {
+	prompt := fmt.Sprintf("Summarize the following %s content concisely:\n\n%s", string(category), content)
+	return p.Complete(ctx, prompt)
+}
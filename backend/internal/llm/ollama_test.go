@@ -0,0 +1,156 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestOllama(server *httptest.Server) *OllamaProvider {
+	return NewOllamaProvider(Config{Model: "llama3.1", MaxTokens: 256, ContextWindow: 8192, BaseURL: server.URL})
+}
+
+func TestOllamaProvider_Complete(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		want       string
+		wantErr    error
+	}{
+		{
+			name:       "successful response",
+			statusCode: 200,
+			body:       `{"model":"llama3.1","response":"Hello from Ollama","done":true}`,
+			want:       "Hello from Ollama",
+		},
+		{
+			name:       "model not found",
+			statusCode: 404,
+			body:       `{"error":"model \"llama3.1\" not found, try pulling it first"}`,
+			wantErr:    ErrInvalidRequest,
+		},
+		{
+			name:       "empty response",
+			statusCode: 200,
+			body:       `{"response":"","done":true}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/generate" {
+					t.Errorf("path = %q, want /api/generate", r.URL.Path)
+				}
+				var req ollamaGenerateRequest
+				json.NewDecoder(r.Body).Decode(&req)
+				if req.Model != "llama3.1" || req.Stream {
+					t.Errorf("request = %+v, want model llama3.1 without streaming", req)
+				}
+				if req.Options.NumPredict != 256 || req.Options.NumCtx != 8192 {
+					t.Errorf("options = %+v, want num_predict 256 and num_ctx 8192", req.Options)
+				}
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			got, err := newTestOllama(server).Complete(context.Background(), "test prompt")
+			if tt.want == "" {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Complete() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOllamaProvider_Stream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("path = %q, want /api/chat", r.URL.Path)
+		}
+		var req ollamaChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream || len(req.Messages) != 1 || req.Messages[0].Content != "test prompt" {
+			t.Errorf("request = %+v, want streamed single user message", req)
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Write([]byte(`{"message":{"role":"assistant","content":"안녕"},"done":false}` + "\n" +
+			`{"message":{"role":"assistant","content":"하세요"},"done":false}` + "\n" +
+			`{"message":{"role":"assistant","content":""},"done":true}` + "\n"))
+	}))
+	defer server.Close()
+
+	var tokens []string
+	got, err := newTestOllama(server).Stream(context.Background(), "test prompt", func(tok string) error {
+		tokens = append(tokens, tok)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "안녕하세요" || len(tokens) != 2 {
+		t.Errorf("Stream() = %q with tokens %v, want %q in 2 tokens", got, tokens, "안녕하세요")
+	}
+}
+
+func TestOllamaProvider_StreamError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":"out of memory"}` + "\n"))
+	}))
+	defer server.Close()
+
+	_, err := newTestOllama(server).Stream(context.Background(), "test prompt", func(string) error { return nil })
+	if err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestOllamaProvider_Ping(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tags" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"models":[]}`))
+	}))
+
+	p := newTestOllama(server)
+	if err := p.Ping(context.Background()); err != nil {
+		t.Errorf("Ping() error = %v", err)
+	}
+
+	server.Close()
+	if err := p.Ping(context.Background()); err == nil {
+		t.Error("expected error for unreachable server")
+	}
+}
+
+func TestOllamaProvider_Defaults(t *testing.T) {
+	p := NewOllamaProvider(DefaultOllamaConfig("", ""))
+	if p.Name() != ProviderOllama {
+		t.Errorf("Name() = %q, want %q", p.Name(), ProviderOllama)
+	}
+	if p.baseURL != DefaultOllamaBaseURL || p.Model() != "llama3.1" {
+		t.Errorf("baseURL = %q, model = %q; want defaults", p.baseURL, p.Model())
+	}
+}
+
+func TestOllamaProvider_ImplementsProvider(t *testing.T) {
+	var _ Provider = &OllamaProvider{}
+}
@@ -2,29 +2,54 @@ package llm
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/rookiecj/scrum-agents/backend/internal/classifier"
 	"github.com/rookiecj/scrum-agents/backend/internal/model"
 )
 
-// OpenAIProvider implements the Provider interface for OpenAI's API.
+// DefaultOpenAIBaseURL is the endpoint of OpenAI's hosted API.
+const DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+
+// OpenAIProvider implements the Provider interface for OpenAI's API and for
+// any server exposing an OpenAI-compatible chat completions endpoint, such as
+// vLLM, LM Studio or LiteLLM.
 type OpenAIProvider struct {
+	name       ProviderType
 	config     Config
 	client     *http.Client
+	stream     *http.Client
 	baseURL    string
 	classifier *classifier.LLMClassifier
 }
 
-// NewOpenAIProvider creates a new OpenAI provider.
+// NewOpenAIProvider creates a new OpenAI provider. config.BaseURL overrides
+// the default endpoint.
 func NewOpenAIProvider(config Config) *OpenAIProvider {
+	return NewOpenAICompatibleProvider(ProviderOpenAI, config)
+}
+
+// NewOpenAICompatibleProvider creates a provider registered under name that
+// talks to the OpenAI-compatible API at config.BaseURL, sending config.Headers
+// with every request. The Authorization header is omitted when config.APIKey
+// is empty, as many self-hosted servers need no key.
+func NewOpenAICompatibleProvider(name ProviderType, config Config) *OpenAIProvider {
+	baseURL := strings.TrimSuffix(config.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultOpenAIBaseURL
+	}
 	p := &OpenAIProvider{
+		name:    name,
 		config:  config,
 		client:  &http.Client{Timeout: config.Timeout},
-		baseURL: "https://api.openai.com/v1",
+		stream:  newStreamClient(config.Timeout),
+		baseURL: baseURL,
 	}
 	p.classifier = classifier.NewLLMClassifier(p)
 	return p
@@ -32,13 +57,34 @@ func NewOpenAIProvider(config Config) *OpenAIProvider {
 
 // Name returns the provider type.
 func (p *OpenAIProvider) Name() ProviderType {
-	return ProviderOpenAI
+	if p.name == "" {
+		return ProviderOpenAI
+	}
+	return p.name
+}
+
+// Model returns the configured model name.
+func (p *OpenAIProvider) Model() string {
+	return p.config.Model
 }
 
 type openaiRequest struct {
 	Model     string          `json:"model"`
 	Messages  []openaiMessage `json:"messages"`
 	MaxTokens int             `json:"max_tokens"`
+	Stream    bool            `json:"stream,omitempty"`
+	// StreamOptions asks for a final chunk with the token usage.
+	StreamOptions *openaiStreamOptions `json:"stream_options,omitempty"`
+}
+
+type openaiStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// openaiUsage is the token usage of a chat completion.
+type openaiUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
 }
 
 type openaiMessage struct {
@@ -47,41 +93,72 @@ type openaiMessage struct {
 }
 
 type openaiResponse struct {
+	Model   string `json:"model"`
 	Choices []struct {
 		Message struct {
 			Content string `json:"content"`
 		} `json:"message"`
 	} `json:"choices"`
+	Usage *openaiUsage `json:"usage"`
 	Error *struct {
 		Message string `json:"message"`
 	} `json:"error,omitempty"`
 }
 
-// Complete sends a prompt to OpenAI and returns the response.
-func (p *OpenAIProvider) Complete(prompt string) (string, error) {
+// openaiStreamChunk is the payload of a chat completion stream chunk.
+type openaiStreamChunk struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Usage *openaiUsage `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// newRequest builds a chat completion request for the prompt.
+func (p *OpenAIProvider) newRequest(ctx context.Context, prompt string, stream bool) (*http.Request, error) {
 	reqBody := openaiRequest{
 		Model: p.config.Model,
 		Messages: []openaiMessage{
 			{Role: "user", Content: prompt},
 		},
 		MaxTokens: p.config.MaxTokens,
+		Stream:    stream,
+	}
+	if stream {
+		reqBody.StreamOptions = &openaiStreamOptions{IncludeUsage: true}
 	}
 
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("marshaling request: %w", err)
+		return nil, fmt.Errorf("marshaling request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", p.baseURL+"/chat/completions", bytes.NewReader(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/chat/completions", bytes.NewReader(jsonBody))
 	if err != nil {
-		return "", fmt.Errorf("creating request: %w", err)
+		return nil, fmt.Errorf("creating request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+p.config.APIKey)
+	if p.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.config.APIKey)
+	}
+	for k, v := range p.config.Headers {
+		req.Header.Set(k, v)
+	}
+	return req, nil
+}
 
-	resp, err := p.client.Do(req)
+// Complete sends a prompt to OpenAI and returns the response.
+func (p *OpenAIProvider) Complete(ctx context.Context, prompt string) (string, error) {
+	resp, err := doWithRetry(ctx, p.client, p.Name(), p.config.MaxRetries, func() (*http.Request, error) {
+		return p.newRequest(ctx, prompt, false)
+	})
 	if err != nil {
-		return "", fmt.Errorf("calling OpenAI API: %w", err)
+		return "", err
 	}
 	defer resp.Body.Close()
 
@@ -98,6 +175,14 @@ func (p *OpenAIProvider) Complete(prompt string) (string, error) {
 	if result.Error != nil {
 		return "", fmt.Errorf("OpenAI API error: %s", result.Error.Message)
 	}
+	if result.Usage != nil {
+		recordUsage(ctx, Usage{
+			Provider:     p.Name(),
+			Model:        modelOr(result.Model, p.config.Model),
+			InputTokens:  result.Usage.PromptTokens,
+			OutputTokens: result.Usage.CompletionTokens,
+		})
+	}
 
 	if len(result.Choices) == 0 {
 		return "", fmt.Errorf("empty response from OpenAI")
@@ -106,16 +191,100 @@ func (p *OpenAIProvider) Complete(prompt string) (string, error) {
 	return result.Choices[0].Message.Content, nil
 }
 
+// Stream sends a prompt to OpenAI with streaming enabled, calling onToken for
+// every content delta. It returns the full response text.
+func (p *OpenAIProvider) Stream(ctx context.Context, prompt string, onToken func(string) error) (string, error) {
+	ctx, watch, stop := withIdleTimeout(ctx, p.config.Timeout)
+	defer stop()
+	resp, err := doWithRetry(ctx, p.stream, p.Name(), p.config.MaxRetries, func() (*http.Request, error) {
+		req, err := p.newRequest(ctx, prompt, true)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "text/event-stream")
+		return req, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var sb strings.Builder
+	usage := Usage{Provider: p.Name(), Model: p.config.Model}
+	defer func() { recordUsage(ctx, usage) }()
+	err = readSSE(watch(resp.Body), func(_, data string) error {
+		if data == "[DONE]" {
+			return nil
+		}
+		var chunk openaiStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("parsing stream chunk: %w", err)
+		}
+		if chunk.Error != nil {
+			return fmt.Errorf("OpenAI API error: %s", chunk.Error.Message)
+		}
+		usage.Model = modelOr(chunk.Model, usage.Model)
+		if chunk.Usage != nil {
+			usage.InputTokens = chunk.Usage.PromptTokens
+			usage.OutputTokens = chunk.Usage.CompletionTokens
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			return nil
+		}
+		text := chunk.Choices[0].Delta.Content
+		sb.WriteString(text)
+		return onToken(text)
+	})
+	if err != nil {
+		return "", fmt.Errorf("reading stream: %w", err)
+	}
+
+	if sb.Len() == 0 {
+		return "", fmt.Errorf("empty response from OpenAI")
+	}
+	return sb.String(), nil
+}
+
+// Ping reports whether the API is reachable by listing its models. It is
+// used to report availability of self-hosted OpenAI-compatible servers.
+func (p *OpenAIProvider) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", p.baseURL+"/models", nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	if p.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.config.APIKey)
+	}
+	for k, v := range p.config.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("calling %s API: %w", p.Name(), err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s API error: unexpected status %d", p.Name(), resp.StatusCode)
+	}
+	return nil
+}
+
 // Classify classifies content using OpenAI.
-func (p *OpenAIProvider) Classify(content string) (*model.ClassificationResult, error) {
-	return p.classifier.Classify(content)
+func (p *OpenAIProvider) Classify(ctx context.Context, content string) (*model.ClassificationResult, error) {
+	return p.classifier.Classify(ctx, content)
 }
 
 // Summarize generates a summary using OpenAI with a pre-built prompt.
 // The prompt should be constructed by the summarizer package using the appropriate template.
-func (p *OpenAIProvider) Summarize(content string, category model.ContentCategory) (string, error) {
+func (p *OpenAIProvider) Summarize(ctx context.Context, content string, category model.ContentCategory) (string, error) {
 	// This method is kept for backward compatibility.
 	// For type-specific summarization, use the summarizer package directly.
 	prompt := fmt.Sprintf("Summarize the following %s content concisely:\n\n%s", string(category), content)
-	return p.Complete(prompt)
+	return p.Complete(ctx, prompt)
 }
@@ -1,6 +1,7 @@
 package llm
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -70,10 +71,11 @@ func TestOpenAIProvider_Complete(t *testing.T) {
 			p := &OpenAIProvider{
 				config:  Config{APIKey: "test-key", Model: "gpt-4o", MaxTokens: 1024},
 				client:  server.Client(),
+				stream:  server.Client(),
 				baseURL: server.URL,
 			}
 
-			got, err := p.Complete("test prompt")
+			got, err := p.Complete(context.Background(), "test prompt")
 			if tt.wantErr {
 				if err == nil {
 					t.Error("expected error")
@@ -100,3 +102,147 @@ func TestOpenAIProvider_Name(t *testing.T) {
 func TestOpenAIProvider_ImplementsProvider(t *testing.T) {
 	var _ Provider = &OpenAIProvider{}
 }
+
+func TestOpenAIProvider_Stream(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:       "content deltas",
+			statusCode: 200,
+			body: "data: {\"choices\":[{\"delta\":{\"role\":\"assistant\"}}]}\n\n" +
+				"data: {\"choices\":[{\"delta\":{\"content\":\"Hello\"}}]}\n\n" +
+				"data: {\"choices\":[{\"delta\":{\"content\":\" world\"}}]}\n\n" +
+				"data: [DONE]\n\n",
+			want: "Hello world",
+		},
+		{
+			name:       "non-200 status",
+			statusCode: 429,
+			body:       `{"error":{"message":"Rate limit reached"}}`,
+			wantErr:    true,
+		},
+		{
+			name:       "empty stream",
+			statusCode: 200,
+			body:       "data: [DONE]\n\n",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req openaiRequest
+				json.NewDecoder(r.Body).Decode(&req)
+				if !req.Stream {
+					t.Error("expected stream=true in request")
+				}
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			p := &OpenAIProvider{
+				config:  Config{APIKey: "test-key", Model: "gpt-4o", MaxTokens: 1024},
+				client:  server.Client(),
+				stream:  server.Client(),
+				baseURL: server.URL,
+			}
+
+			var tokens int
+			got, err := p.Stream(context.Background(), "test prompt", func(string) error {
+				tokens++
+				return nil
+			})
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Stream() = %q, want %q", got, tt.want)
+			}
+			if tokens != 2 {
+				t.Errorf("tokens = %d, want 2", tokens)
+			}
+		})
+	}
+}
+
+func TestOpenAICompatibleProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("path = %q, want /v1/chat/completions", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Errorf("Authorization = %q, want none for keyless server", got)
+		}
+		if got := r.Header.Get("X-Team"); got != "infra" {
+			t.Errorf("X-Team = %q, want %q", got, "infra")
+		}
+		var req openaiRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "qwen2.5-7b" {
+			t.Errorf("model = %q, want %q", req.Model, "qwen2.5-7b")
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"content": "self-hosted"}}},
+		})
+	}))
+	defer server.Close()
+
+	p := NewOpenAICompatibleProvider("vllm", Config{
+		Model:     "qwen2.5-7b",
+		MaxTokens: 256,
+		Timeout:   5 * time.Second,
+		BaseURL:   server.URL + "/v1/",
+		Headers:   map[string]string{"X-Team": "infra"},
+	})
+
+	if p.Name() != "vllm" {
+		t.Errorf("Name() = %q, want %q", p.Name(), "vllm")
+	}
+	got, err := p.Complete(context.Background(), "test prompt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "self-hosted" {
+		t.Errorf("Complete() = %q, want %q", got, "self-hosted")
+	}
+}
+
+func TestOpenAIProvider_DefaultBaseURL(t *testing.T) {
+	p := NewOpenAIProvider(DefaultOpenAIConfig("k"))
+	if p.baseURL != DefaultOpenAIBaseURL {
+		t.Errorf("baseURL = %q, want %q", p.baseURL, DefaultOpenAIBaseURL)
+	}
+}
+
+func TestOpenAIProvider_Ping(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" || r.Header.Get("Authorization") != "Bearer k" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"data":[]}`))
+	}))
+	defer server.Close()
+
+	ok := NewOpenAICompatibleProvider("gateway", Config{APIKey: "k", BaseURL: server.URL + "/v1"})
+	if err := ok.Ping(context.Background()); err != nil {
+		t.Errorf("Ping() error = %v", err)
+	}
+	bad := NewOpenAICompatibleProvider("gateway", Config{APIKey: "wrong", BaseURL: server.URL + "/v1"})
+	if err := bad.Ping(context.Background()); err == nil {
+		t.Error("expected error for rejected key")
+	}
+}
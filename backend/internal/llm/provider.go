@@ -1,6 +1,7 @@
 package llm
 
 import (
+	"context"
 	"github.com/rookiecj/scrum-agents/backend/internal/model"
 )
 
@@ -11,18 +12,24 @@ const (
 	ProviderClaude ProviderType = "claude"
 	ProviderOpenAI ProviderType = "openai"
 	ProviderGemini ProviderType = "gemini"
+	ProviderOllama ProviderType = "ollama"
 )
 
 // Provider defines the interface for LLM providers.
 type Provider interface {
 	// Complete sends a prompt and returns the response text.
-	Complete(prompt string) (string, error)
+	Complete(ctx context.Context, prompt string) (string, error)
+
+	// Stream sends a prompt and calls onToken with each text fragment as it
+	// arrives. It returns the full response text once the stream completes.
+	// An error returned by onToken aborts the stream.
+	Stream(ctx context.Context, prompt string, onToken func(string) error) (string, error)
 
 	// Classify classifies content into a category.
-	Classify(content string) (*model.ClassificationResult, error)
+	Classify(ctx context.Context, content string) (*model.ClassificationResult, error)
 
 	// Summarize generates a summary of the content.
-	Summarize(content string, category model.ContentCategory) (string, error)
+	Summarize(ctx context.Context, content string, category model.ContentCategory) (string, error)
 
 	// Name returns the provider name.
 	Name() ProviderType
@@ -0,0 +1,103 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/tracing"
+)
+
+// Backoff bounds for retries. Variables so tests can shorten them.
+var (
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 10 * time.Second
+	// maxRetryAfter caps how long a provider's Retry-After is honored;
+	// longer requests fail immediately instead of holding the caller.
+	maxRetryAfter = 30 * time.Second
+)
+
+// doWithRetry sends the request built by newReq, retrying retryable failures
+// up to maxRetries times with exponential backoff and jitter. A fresh request
+// is built for each attempt so the body can be replayed.
+//
+// On success it returns the 200 response, which the caller must close.
+// Any other response is consumed and returned as an *APIError.
+func doWithRetry(ctx context.Context, client *http.Client, provider ProviderType, maxRetries int,
+	newReq func() (*http.Request, error)) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := newReq()
+		if err != nil {
+			return nil, err
+		}
+		tracing.Inject(ctx, req.Header)
+
+		resp, err := send(client, provider, req)
+		if err == nil {
+			return resp, nil
+		}
+		if attempt >= maxRetries || !IsRetryable(err) {
+			return nil, err
+		}
+
+		delay := backoff(attempt)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+			if apiErr.RetryAfter > maxRetryAfter {
+				return nil, err
+			}
+			delay = apiErr.RetryAfter
+		}
+
+		slog.WarnContext(ctx, "llm: retrying request",
+			slog.String("provider", string(provider)),
+			slog.Int("attempt", attempt+1),
+			slog.Duration("delay", delay),
+			slog.String("error", err.Error()),
+		)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// send performs a single attempt and converts failures into typed errors.
+func send(client *http.Client, provider ProviderType, req *http.Request) (*http.Response, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		if req.Context().Err() != nil {
+			return nil, fmt.Errorf("calling %s API: %w", provider, req.Context().Err())
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return nil, &APIError{Provider: provider, Message: err.Error(), Kind: ErrTimeout}
+		}
+		return nil, fmt.Errorf("calling %s API: %w", provider, err)
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return nil, newAPIError(provider, resp, body)
+}
+
+// backoff returns the delay before retry number attempt+1: exponential from
+// retryBaseDelay, capped at retryMaxDelay, with jitter.
+func backoff(attempt int) time.Duration {
+	d := retryBaseDelay << attempt
+	if d <= 0 || d > retryMaxDelay {
+		d = retryMaxDelay
+	}
+	return d/2 + rand.N(d/2+1)
+}
@@ -0,0 +1,164 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// shortenBackoff makes retries fast for the duration of the test.
+func shortenBackoff(t *testing.T) {
+	t.Helper()
+	base, max := retryBaseDelay, retryMaxDelay
+	retryBaseDelay, retryMaxDelay = time.Millisecond, 5*time.Millisecond
+	t.Cleanup(func() { retryBaseDelay, retryMaxDelay = base, max })
+}
+
+// flakyServer fails the first `failures` requests with status and then
+// returns a successful Claude response.
+func flakyServer(t *testing.T, failures int32, status int, header http.Header) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			w.Write([]byte(`{"type":"error","error":{"type":"error","message":"try again"}}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"content": []map[string]string{{"text": "ok"}},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func newRetryTestProvider(srv *httptest.Server, maxRetries int) *ClaudeProvider {
+	return &ClaudeProvider{
+		config:  Config{APIKey: "k", Model: "m", MaxTokens: 10, MaxRetries: maxRetries},
+		client:  srv.Client(),
+		stream:  srv.Client(),
+		baseURL: srv.URL,
+	}
+}
+
+func TestRetry_RecoversFromTransientErrors(t *testing.T) {
+	shortenBackoff(t)
+
+	for _, status := range []int{429, 500, 503, 529} {
+		srv, calls := flakyServer(t, 2, status, nil)
+		p := newRetryTestProvider(srv, 3)
+
+		got, err := p.Complete(context.Background(), "hi")
+		if err != nil {
+			t.Fatalf("status %d: unexpected error: %v", status, err)
+		}
+		if got != "ok" {
+			t.Errorf("status %d: got %q, want %q", status, got, "ok")
+		}
+		if calls.Load() != 3 {
+			t.Errorf("status %d: calls = %d, want 3", status, calls.Load())
+		}
+	}
+}
+
+func TestRetry_GivesUpAfterMaxRetries(t *testing.T) {
+	shortenBackoff(t)
+	srv, calls := flakyServer(t, 10, 529, nil)
+	p := newRetryTestProvider(srv, 2)
+
+	_, err := p.Complete(context.Background(), "hi")
+	if !errors.Is(err, ErrOverloaded) {
+		t.Fatalf("error = %v, want ErrOverloaded", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("calls = %d, want 3 (1 + 2 retries)", calls.Load())
+	}
+}
+
+func TestRetry_DoesNotRetryPermanentErrors(t *testing.T) {
+	shortenBackoff(t)
+	srv, calls := flakyServer(t, 10, 401, nil)
+	p := newRetryTestProvider(srv, 3)
+
+	_, err := p.Complete(context.Background(), "hi")
+	if !errors.Is(err, ErrAuthFailed) {
+		t.Fatalf("error = %v, want ErrAuthFailed", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("calls = %d, want 1", calls.Load())
+	}
+}
+
+func TestRetry_HonorsRetryAfter(t *testing.T) {
+	shortenBackoff(t)
+	srv, calls := flakyServer(t, 1, 429, http.Header{"Retry-After": {"1"}})
+	p := newRetryTestProvider(srv, 1)
+
+	start := time.Now()
+	if _, err := p.Complete(context.Background(), "hi"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("elapsed = %v, want at least the 1s Retry-After", elapsed)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want 2", calls.Load())
+	}
+}
+
+func TestRetry_StopsWhenContextCanceled(t *testing.T) {
+	srv, calls := flakyServer(t, 10, 503, http.Header{"Retry-After": {"5"}})
+	p := newRetryTestProvider(srv, 3)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := p.Complete(ctx, "hi")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error = %v, want context.DeadlineExceeded", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("calls = %d, want 1", calls.Load())
+	}
+}
+
+func TestRetry_StreamRetriesBeforeFirstByte(t *testing.T) {
+	shortenBackoff(t)
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(529)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"hi\"}}\n\n"))
+	}))
+	defer srv.Close()
+	p := newRetryTestProvider(srv, 1)
+
+	got, err := p.Stream(context.Background(), "hi", func(string) error { return nil })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "hi" || calls.Load() != 2 {
+		t.Errorf("got %q after %d calls, want %q after 2", got, calls.Load(), "hi")
+	}
+}
+
+func TestBackoff_Bounds(t *testing.T) {
+	for attempt := 0; attempt < 40; attempt++ {
+		d := backoff(attempt)
+		if d <= 0 || d > retryMaxDelay {
+			t.Errorf("backoff(%d) = %v, want within (0, %v]", attempt, d, retryMaxDelay)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// maxSSELineSize bounds a single server-sent event line.
+const maxSSELineSize = 1024 * 1024
+
+// readSSE parses a text/event-stream body and calls fn for every event with
+// its event name (empty if unnamed) and data payload. Multi-line data fields
+// are joined with newlines. Reading stops at the first error returned by fn.
+func readSSE(r io.Reader, fn func(event, data string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxSSELineSize)
+
+	var event string
+	var data []string
+	dispatch := func() error {
+		if len(data) == 0 {
+			event = ""
+			return nil
+		}
+		err := fn(event, strings.Join(data, "\n"))
+		event, data = "", nil
+		return err
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if err := dispatch(); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, ":"):
+			// comment / keep-alive
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return dispatch()
+}
+
+// errStreamIdle is returned when a stream sends nothing for longer than its
+// idle timeout.
+var errStreamIdle = errors.New("stream stalled")
+
+// newStreamClient returns the HTTP client for streaming calls. The client
+// used for Complete has an overall Timeout, which also covers reading the
+// body and so cuts off long streams. This one has none: timeout bounds the
+// wait for response headers, and withIdleTimeout the gap between events.
+func newStreamClient(timeout time.Duration) *http.Client {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.ResponseHeaderTimeout = timeout
+	return &http.Client{Transport: t}
+}
+
+// withIdleTimeout returns a context for a streaming request and a function
+// wrapping its response body. The context is canceled once the body yields
+// no data for timeout, and reads then fail with errStreamIdle. A zero
+// timeout disables the check. stop must be called when the stream is done.
+func withIdleTimeout(ctx context.Context, timeout time.Duration) (_ context.Context, watch func(io.Reader) io.Reader, stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	if timeout <= 0 {
+		return ctx, func(r io.Reader) io.Reader { return r }, cancel
+	}
+
+	r := &idleReader{timeout: timeout}
+	watch = func(body io.Reader) io.Reader {
+		r.r = body
+		r.timer = time.AfterFunc(timeout, func() {
+			r.stalled.Store(true)
+			cancel()
+		})
+		return r
+	}
+	stop = func() {
+		if r.timer != nil {
+			r.timer.Stop()
+		}
+		cancel()
+	}
+	return ctx, watch, stop
+}
+
+// idleReader restarts its timer whenever data arrives.
+type idleReader struct {
+	r       io.Reader
+	timer   *time.Timer
+	timeout time.Duration
+	stalled atomic.Bool
+}
+
+func (r *idleReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.timer.Reset(r.timeout)
+	}
+	if err != nil && r.stalled.Load() {
+		return n, fmt.Errorf("%w: no data for %v", errStreamIdle, r.timeout)
+	}
+	return n, err
+}
@@ -0,0 +1,56 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestReadSSE(t *testing.T) {
+	body := ": keep-alive\n" +
+		"event: message_start\n" +
+		"data: {\"a\":1}\n\n" +
+		"data: line1\n" +
+		"data: line2\n\n" +
+		"\n" +
+		"data: [DONE]"
+
+	type ev struct{ event, data string }
+	var got []ev
+	err := readSSE(strings.NewReader(body), func(event, data string) error {
+		got = append(got, ev{event, data})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("readSSE() error = %v", err)
+	}
+
+	want := []ev{
+		{"message_start", `{"a":1}`},
+		{"", "line1\nline2"},
+		{"", "[DONE]"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d events, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestReadSSE_CallbackError(t *testing.T) {
+	body := "data: one\n\ndata: two\n\n"
+	calls := 0
+	err := readSSE(strings.NewReader(body), func(_, _ string) error {
+		calls++
+		return fmt.Errorf("stop")
+	})
+	if err == nil {
+		t.Error("expected error")
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
@@ -0,0 +1,55 @@
+package llm
+
+import (
+	"context"
+	"sync"
+)
+
+// Usage is the token usage of one LLM call, as reported by the provider.
+type Usage struct {
+	Provider     ProviderType
+	Model        string
+	InputTokens  int
+	OutputTokens int
+}
+
+type usageKey struct{}
+
+type usageRecorder struct {
+	mu    sync.Mutex
+	calls []Usage
+}
+
+// TrackUsage returns a context that collects the usage of every LLM call
+// made with it, and a function returning the calls so far.
+func TrackUsage(ctx context.Context) (context.Context, func() []Usage) {
+	rec := &usageRecorder{}
+	return context.WithValue(ctx, usageKey{}, rec), func() []Usage {
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		return append([]Usage(nil), rec.calls...)
+	}
+}
+
+// recordUsage adds u to the usage tracked in ctx, if any. Calls that report
+// no tokens are skipped.
+func recordUsage(ctx context.Context, u Usage) {
+	if u.InputTokens == 0 && u.OutputTokens == 0 {
+		return
+	}
+	rec, ok := ctx.Value(usageKey{}).(*usageRecorder)
+	if !ok {
+		return
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.calls = append(rec.calls, u)
+}
+
+// modelOr returns the model a response reported, or the configured one.
+func modelOr(reported, configured string) string {
+	if reported != "" {
+		return reported
+	}
+	return configured
+}
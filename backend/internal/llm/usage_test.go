@@ -0,0 +1,110 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestTrackUsage(t *testing.T) {
+	ctx, calls := TrackUsage(context.Background())
+	recordUsage(ctx, Usage{Provider: ProviderClaude, Model: "m", InputTokens: 10, OutputTokens: 2})
+	recordUsage(ctx, Usage{Provider: ProviderClaude, Model: "m"}) // No tokens: skipped
+	recordUsage(context.Background(), Usage{InputTokens: 1})      // Untracked context: ignored
+
+	if got := calls(); len(got) != 1 || got[0].InputTokens != 10 || got[0].OutputTokens != 2 {
+		t.Errorf("calls() = %+v, want one call of 10/2 tokens", got)
+	}
+}
+
+func TestProviders_RecordUsage(t *testing.T) {
+	claudeSSE := "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"model\":\"claude-x\",\"usage\":{\"input_tokens\":12,\"output_tokens\":1}}}\n\n" +
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"hi\"}}\n\n" +
+		"event: message_delta\ndata: {\"type\":\"message_delta\",\"usage\":{\"output_tokens\":5}}\n\n"
+	openaiSSE := "data: {\"model\":\"gpt-x\",\"choices\":[{\"delta\":{\"content\":\"hi\"}}]}\n\n" +
+		"data: {\"model\":\"gpt-x\",\"choices\":[],\"usage\":{\"prompt_tokens\":12,\"completion_tokens\":5}}\n\n" +
+		"data: [DONE]\n\n"
+	geminiSSE := "data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"h\"}]}}],\"usageMetadata\":{\"promptTokenCount\":12,\"candidatesTokenCount\":1},\"modelVersion\":\"gemini-x\"}\n\n" +
+		"data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"i\"}]}}],\"usageMetadata\":{\"promptTokenCount\":12,\"candidatesTokenCount\":5},\"modelVersion\":\"gemini-x\"}\n\n"
+	ollamaChat := `{"message":{"role":"assistant","content":"hi"},"done":false}` + "\n" +
+		`{"model":"llama-x","message":{"role":"assistant","content":""},"done":true,"prompt_eval_count":12,"eval_count":5}` + "\n"
+
+	tests := []struct {
+		name     string
+		complete string // Body of a non-streaming response
+		stream   string
+		provider func(url string) Provider
+		want     Usage
+	}{
+		{
+			name:     "claude",
+			complete: `{"model":"claude-x","content":[{"text":"hi"}],"usage":{"input_tokens":12,"output_tokens":5}}`,
+			stream:   claudeSSE,
+			provider: func(url string) Provider {
+				p := NewClaudeProvider(Config{APIKey: "k", Model: "claude-default"})
+				p.baseURL = url
+				return p
+			},
+			want: Usage{Provider: ProviderClaude, Model: "claude-x", InputTokens: 12, OutputTokens: 5},
+		},
+		{
+			name:     "openai",
+			complete: `{"model":"gpt-x","choices":[{"message":{"content":"hi"}}],"usage":{"prompt_tokens":12,"completion_tokens":5}}`,
+			stream:   openaiSSE,
+			provider: func(url string) Provider {
+				return NewOpenAIProvider(Config{APIKey: "k", Model: "gpt-default", BaseURL: url})
+			},
+			want: Usage{Provider: ProviderOpenAI, Model: "gpt-x", InputTokens: 12, OutputTokens: 5},
+		},
+		{
+			name:     "gemini",
+			complete: `{"candidates":[{"content":{"parts":[{"text":"hi"}]}}],"usageMetadata":{"promptTokenCount":12,"candidatesTokenCount":5},"modelVersion":"gemini-x"}`,
+			stream:   geminiSSE,
+			provider: func(url string) Provider {
+				p := NewGeminiProvider(Config{APIKey: "k", Model: "gemini-default"})
+				p.baseURL = url
+				return p
+			},
+			want: Usage{Provider: ProviderGemini, Model: "gemini-x", InputTokens: 12, OutputTokens: 5},
+		},
+		{
+			name:     "ollama",
+			complete: `{"model":"llama-x","response":"hi","prompt_eval_count":12,"eval_count":5}`,
+			stream:   ollamaChat,
+			provider: func(url string) Provider {
+				return NewOllamaProvider(Config{Model: "llama-default", BaseURL: url})
+			},
+			want: Usage{Provider: ProviderOllama, Model: "llama-x", InputTokens: 12, OutputTokens: 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req map[string]any
+				json.NewDecoder(r.Body).Decode(&req)
+				if req["stream"] == true || r.URL.Query().Get("alt") == "sse" {
+					w.Write([]byte(tt.stream))
+					return
+				}
+				w.Write([]byte(tt.complete))
+			}))
+			defer server.Close()
+			p := tt.provider(server.URL)
+
+			ctx, calls := TrackUsage(context.Background())
+			if _, err := p.Complete(ctx, "prompt"); err != nil {
+				t.Fatalf("Complete() error = %v", err)
+			}
+			if _, err := p.Stream(ctx, "prompt", func(string) error { return nil }); err != nil {
+				t.Fatalf("Stream() error = %v", err)
+			}
+			if got := calls(); !slices.Equal(got, []Usage{tt.want, tt.want}) {
+				t.Errorf("usage = %+v, want %+v for both calls", got, tt.want)
+			}
+		})
+	}
+}
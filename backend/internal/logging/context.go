@@ -0,0 +1,78 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+type contextKey struct{}
+
+// requestAttrs collects attributes that describe the request being served,
+// such as the authenticated user. Middleware creates one per request.
+type requestAttrs struct {
+	mu    sync.Mutex
+	attrs []slog.Attr
+}
+
+// AddAttrs attaches attrs to the request that ctx belongs to. They are
+// logged with the request's "http request" line and with every record
+// logged through a *Context function with ctx or a context derived from it.
+// AddAttrs does nothing for contexts outside Middleware.
+func AddAttrs(ctx context.Context, attrs ...slog.Attr) {
+	ra, _ := ctx.Value(contextKey{}).(*requestAttrs)
+	if ra == nil {
+		return
+	}
+	ra.mu.Lock()
+	ra.attrs = append(ra.attrs, attrs...)
+	ra.mu.Unlock()
+}
+
+// Attrs returns the attributes attached to ctx's request, led by its
+// request ID (see WithRequestID).
+func Attrs(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	var attrs []slog.Attr
+	if id := RequestID(ctx); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	if ra, _ := ctx.Value(contextKey{}).(*requestAttrs); ra != nil {
+		attrs = append(attrs, ra.list()...)
+	}
+	return attrs
+}
+
+func (ra *requestAttrs) list() []slog.Attr {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	return append([]slog.Attr(nil), ra.attrs...)
+}
+
+// ContextHandler returns a handler that adds the attributes attached to a
+// record's context (see AddAttrs) before passing it to h.
+func ContextHandler(h slog.Handler) slog.Handler {
+	return contextHandler{h}
+}
+
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if attrs := Attrs(ctx); len(attrs) > 0 {
+		r = r.Clone()
+		r.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,46 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAddAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	orig := slog.Default()
+	slog.SetDefault(slog.New(ContextHandler(slog.NewJSONHandler(&buf, nil))))
+	defer slog.SetDefault(orig)
+
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		AddAttrs(r.Context(), slog.Int64("user_id", 42))
+		slog.InfoContext(r.Context(), "inside")
+	})
+	Middleware(inner).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/x", nil))
+
+	dec := json.NewDecoder(&buf)
+	for _, msg := range []string{"inside", "http request"} {
+		var entry map[string]any
+		if err := dec.Decode(&entry); err != nil {
+			t.Fatalf("decoding %q log line: %v", msg, err)
+		}
+		if entry["msg"] != msg {
+			t.Errorf("msg = %v, want %q", entry["msg"], msg)
+		}
+		if entry["user_id"] != float64(42) {
+			t.Errorf("%s: user_id = %v, want 42", msg, entry["user_id"])
+		}
+	}
+}
+
+func TestAddAttrs_OutsideRequest(t *testing.T) {
+	ctx := context.Background()
+	AddAttrs(ctx, slog.String("k", "v"))
+	if got := Attrs(ctx); got != nil {
+		t.Errorf("Attrs() = %v, want nil outside Middleware", got)
+	}
+}
@@ -11,7 +11,9 @@ import (
 	"strings"
 )
 
-// Init initializes the default slog logger with a JSON handler.
+// Init initializes the default slog logger with a JSON handler. Records
+// logged with a request's context carry the request's attributes (see
+// AddAttrs).
 // The log level is determined by the LOG_LEVEL environment variable.
 // Supported values: "debug", "info", "warn", "error" (case-insensitive).
 // If LOG_LEVEL is empty or unrecognized, defaults to Info.
@@ -20,7 +22,7 @@ func Init() {
 	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		Level: level,
 	})
-	slog.SetDefault(slog.New(handler))
+	slog.SetDefault(slog.New(ContextHandler(handler)))
 }
 
 // ParseLevel converts a string log level name to a slog.Level.
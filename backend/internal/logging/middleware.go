@@ -1,6 +1,7 @@
 package logging
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 	"time"
@@ -18,26 +19,44 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap returns the wrapped writer so http.ResponseController can reach
+// optional interfaces such as http.Flusher for streaming responses.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // Middleware returns an HTTP middleware that logs each request at Info level.
-// Logged attributes: method, path, status, duration_ms.
+// Logged attributes: request_id, method, path, status, duration_ms, and any
+// attributes attached to the request with AddAttrs.
+//
+// The request ID is taken from the X-Request-ID header, or generated, and
+// echoed in the response. It is stored in the request's context, so every
+// record logged with that context carries it too.
 func Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
+		id := requestIDFrom(r)
+		w.Header().Set(RequestIDHeader, id)
+
 		rw := &responseWriter{
 			ResponseWriter: w,
 			statusCode:     http.StatusOK,
 		}
 
-		next.ServeHTTP(rw, r)
+		ra := &requestAttrs{}
+		ctx := context.WithValue(WithRequestID(r.Context(), id), contextKey{}, ra)
+		next.ServeHTTP(rw, r.WithContext(ctx))
 
 		duration := time.Since(start)
 
-		slog.Info("http request",
+		attrs := append([]slog.Attr{
+			slog.String("request_id", id),
 			slog.String("method", r.Method),
 			slog.String("path", r.URL.Path),
 			slog.Int("status", rw.statusCode),
 			slog.Float64("duration_ms", float64(duration.Nanoseconds())/1e6),
-		)
+		}, ra.list()...)
+		slog.LogAttrs(context.Background(), slog.LevelInfo, "http request", attrs...)
 	})
 }
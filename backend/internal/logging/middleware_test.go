@@ -116,3 +116,22 @@ func TestMiddleware_DefaultStatusCode(t *testing.T) {
 		t.Errorf("status = %v, want 200", logEntry["status"])
 	}
 }
+
+func TestMiddleware_SupportsFlush(t *testing.T) {
+	// Streaming handlers flush through the wrapped writer.
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("data: hello\n\n"))
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("Flush() error = %v", err)
+		}
+	})
+
+	req := httptest.NewRequest("GET", "/stream", nil)
+	rec := httptest.NewRecorder()
+
+	Middleware(inner).ServeHTTP(rec, req)
+
+	if !rec.Flushed {
+		t.Error("expected response to be flushed")
+	}
+}
@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware_RequestID(t *testing.T) {
+	var buf bytes.Buffer
+	orig := slog.Default()
+	slog.SetDefault(slog.New(ContextHandler(slog.NewJSONHandler(&buf, nil))))
+	defer slog.SetDefault(orig)
+
+	var seen string
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestID(r.Context())
+		slog.ErrorContext(r.Context(), "inside")
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		want   string // "" means a generated ID
+	}{
+		{name: "accepted", header: "client-abc.123", want: "client-abc.123"},
+		{name: "generated", header: ""},
+		{name: "invalid characters", header: "bad id\n"},
+		{name: "too long", header: strings.Repeat("x", maxRequestIDLen+1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest("GET", "/x", nil)
+			if tt.header != "" {
+				req.Header.Set(RequestIDHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			Middleware(inner).ServeHTTP(rec, req)
+
+			id := rec.Header().Get(RequestIDHeader)
+			if tt.want != "" && id != tt.want {
+				t.Errorf("response ID = %q, want %q", id, tt.want)
+			}
+			if tt.want == "" && (len(id) != 32 || id == tt.header) {
+				t.Errorf("response ID = %q, want a generated ID", id)
+			}
+			if seen != id {
+				t.Errorf("context ID = %q, want %q", seen, id)
+			}
+
+			dec := json.NewDecoder(&buf)
+			for _, msg := range []string{"inside", "http request"} {
+				var entry map[string]any
+				if err := dec.Decode(&entry); err != nil {
+					t.Fatalf("decoding %q log line: %v", msg, err)
+				}
+				if entry["request_id"] != id {
+					t.Errorf("%s: request_id = %v, want %q", msg, entry["request_id"], id)
+				}
+			}
+		})
+	}
+}
+
+func TestWithRequestID_OutsideRequest(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(ContextHandler(slog.NewJSONHandler(&buf, nil)))
+	logger.InfoContext(WithRequestID(context.Background(), "job-7"), "background work")
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["request_id"] != "job-7" {
+		t.Errorf("request_id = %v, want job-7", entry["request_id"])
+	}
+}
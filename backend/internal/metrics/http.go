@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	httpRequests = NewCounter("http_requests_total",
+		"HTTP requests served, by method, route and status code.",
+		"method", "route", "status")
+	httpDuration = NewHistogram("http_request_duration_seconds",
+		"Time taken to serve HTTP requests, by method and route.",
+		DefBuckets, "method", "route")
+)
+
+// statusWriter wraps http.ResponseWriter to capture the status code.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sw *statusWriter) WriteHeader(code int) {
+	sw.status = code
+	sw.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap returns the wrapped writer so http.ResponseController can reach
+// optional interfaces such as http.Flusher for streaming responses.
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
+
+// Middleware returns an HTTP middleware that counts and times requests by
+// route. next must be the *http.ServeMux, or pass the request it receives
+// on to one unchanged, so that the matched pattern can be read back from
+// the request; requests no route matched are labelled "unmatched".
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+
+		route := routeOf(r.Pattern)
+		httpRequests.Inc(r.Method, route, strconv.Itoa(sw.status))
+		httpDuration.Observe(time.Since(start).Seconds(), r.Method, route)
+	})
+}
+
+// routeOf strips the method from a ServeMux pattern such as
+// "POST /api/extract", leaving the path template.
+func routeOf(pattern string) string {
+	if pattern == "" {
+		return "unmatched"
+	}
+	if i := strings.IndexByte(pattern, ' '); i >= 0 {
+		return strings.TrimLeft(pattern[i:], " \t")
+	}
+	return pattern
+}
+
+// Handler returns a handler serving the Default registry.
+func Handler() http.Handler {
+	return Default.Handler()
+}
+
+// Handler returns a handler serving the registry in the text format.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteTo(w)
+	})
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	handler := Middleware(mux)
+
+	before := httpRequests.Value("GET", "/api/items/{id}", "200")
+	for _, path := range []string{"/api/items/1", "/api/items/2", "/api/items/missing", "/nowhere"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	if got := httpRequests.Value("GET", "/api/items/{id}", "200") - before; got != 2 {
+		t.Errorf("200 requests = %v, want 2", got)
+	}
+	if got := httpRequests.Value("GET", "/api/items/{id}", "404"); got < 1 {
+		t.Errorf("404 requests = %v, want at least 1", got)
+	}
+	if got := httpRequests.Value("GET", "unmatched", "404"); got < 1 {
+		t.Errorf("unmatched requests = %v, want at least 1", got)
+	}
+	if got := httpDuration.Count("GET", "/api/items/{id}"); got < 3 {
+		t.Errorf("observations = %d, want at least 3", got)
+	}
+}
+
+func TestMiddleware_Flush(t *testing.T) {
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("Flush: %v", err)
+		}
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/stream", nil))
+}
+
+func TestRouteOf(t *testing.T) {
+	tests := map[string]string{
+		"":                      "unmatched",
+		"POST /api/extract":     "/api/extract",
+		"/health":               "/health",
+		"GET /api/history/{id}": "/api/history/{id}",
+	}
+	for pattern, want := range tests {
+		if got := routeOf(pattern); got != want {
+			t.Errorf("routeOf(%q) = %q, want %q", pattern, got, want)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("hits_total", "Hits.").Inc()
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), "hits_total 1\n") {
+		t.Errorf("body:\n%s", rec.Body.String())
+	}
+}
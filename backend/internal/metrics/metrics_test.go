@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func render(t *testing.T, r *Registry) string {
+	t.Helper()
+	var sb strings.Builder
+	if _, err := r.WriteTo(&sb); err != nil {
+		t.Fatal(err)
+	}
+	return sb.String()
+}
+
+func TestCounter(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounter("jobs_total", "Jobs run.", "kind", "outcome")
+	c.Inc("a", "ok")
+	c.Inc("a", "ok")
+	c.Add(2.5, "b", "error")
+
+	if got := c.Value("a", "ok"); got != 2 {
+		t.Errorf("Value(a, ok) = %v, want 2", got)
+	}
+	if got := c.Value("c", "ok"); got != 0 {
+		t.Errorf("Value(c, ok) = %v, want 0", got)
+	}
+
+	want := `# HELP jobs_total Jobs run.
+# TYPE jobs_total counter
+jobs_total{kind="a",outcome="ok"} 2
+jobs_total{kind="b",outcome="error"} 2.5
+`
+	if got := render(t, r); got != want {
+		t.Errorf("output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCounter_NoLabels(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("up_total", "Starts.").Inc()
+	if got := render(t, r); !strings.Contains(got, "\nup_total 1\n") {
+		t.Errorf("output:\n%s", got)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogram("latency_seconds", "Latency.", []float64{0.1, 1}, "route")
+	h.Observe(0.05, "/a")
+	h.Observe(0.1, "/a") // bounds are inclusive
+	h.Observe(0.5, "/a")
+	h.Observe(3, "/a")
+
+	if got := h.Count("/a"); got != 4 {
+		t.Errorf("Count = %d, want 4", got)
+	}
+
+	want := `# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="/a",le="0.1"} 2
+latency_seconds_bucket{route="/a",le="1"} 3
+latency_seconds_bucket{route="/a",le="+Inf"} 4
+latency_seconds_sum{route="/a"} 3.65
+latency_seconds_count{route="/a"} 4
+`
+	if got := render(t, r); got != want {
+		t.Errorf("output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRegistry_SortedAndEscaped(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("z_total", "Last.").Inc()
+	r.NewCounter("a_total", "First\nline.", "v").Inc("say \"hi\"\\\n")
+
+	got := render(t, r)
+	if strings.Index(got, "a_total") > strings.Index(got, "z_total") {
+		t.Errorf("metrics not sorted:\n%s", got)
+	}
+	if !strings.Contains(got, `# HELP a_total First\nline.`) {
+		t.Errorf("help not escaped:\n%s", got)
+	}
+	if !strings.Contains(got, `a_total{v="say \"hi\"\\\n"} 1`) {
+		t.Errorf("label not escaped:\n%s", got)
+	}
+}
+
+func TestRegistry_Misuse(t *testing.T) {
+	mustPanic := func(name string, fn func()) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s: expected panic", name)
+			}
+		}()
+		fn()
+	}
+
+	r := NewRegistry()
+	c := r.NewCounter("x_total", "X.", "a")
+	mustPanic("duplicate", func() { r.NewCounter("x_total", "X.") })
+	mustPanic("label count", func() { c.Inc() })
+	mustPanic("negative", func() { c.Add(-1, "a") })
+	mustPanic("unsorted buckets", func() { r.NewHistogram("h", "H.", []float64{2, 1}) })
+}
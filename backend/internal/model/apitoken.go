@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// APIToken is a personal access token a user created for scripts and
+// integrations. The token itself is only returned once, at creation.
+type APIToken struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"-"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // Leading characters of the token, to tell tokens apart
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
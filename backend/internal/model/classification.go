@@ -30,4 +30,6 @@ type ClassificationResult struct {
 	Confidence float64         `json:"confidence"`
 	Secondary  ContentCategory `json:"secondary,omitempty"`
 	SecondConf float64         `json:"secondary_confidence,omitempty"`
+	// Truncated is set when only the beginning of the content was classified.
+	Truncated bool `json:"truncated,omitempty"`
 }
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// HistoryKind identifies which pipeline stage produced a history entry.
+type HistoryKind string
+
+const (
+	HistoryDetect    HistoryKind = "detect"
+	HistoryExtract   HistoryKind = "extract"
+	HistoryClassify  HistoryKind = "classify"
+	HistorySummarize HistoryKind = "summarize"
+	HistoryProcess   HistoryKind = "process"
+)
+
+// AllHistoryKinds returns all valid history kinds.
+func AllHistoryKinds() []HistoryKind {
+	return []HistoryKind{
+		HistoryDetect,
+		HistoryExtract,
+		HistoryClassify,
+		HistorySummarize,
+		HistoryProcess,
+	}
+}
+
+// HistoryEntry is a stored result of a pipeline stage, owned by a user.
+// Result holds the stage-specific response payload as raw JSON.
+type HistoryEntry struct {
+	ID        int64           `json:"id"`
+	UserID    int64           `json:"user_id"`
+	Kind      HistoryKind     `json:"kind"`
+	URL       string          `json:"url,omitempty"`
+	LinkType  LinkType        `json:"link_type,omitempty"`
+	Title     string          `json:"title,omitempty"`
+	Author    string          `json:"author,omitempty"`
+	SiteName  string          `json:"site_name,omitempty"`
+	Category  ContentCategory `json:"category,omitempty"`
+	Provider  string          `json:"provider,omitempty"`
+	Result    json.RawMessage `json:"result"`
+	CreatedAt time.Time       `json:"created_at"`
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// JobStatus is the state of an asynchronous job.
+type JobStatus string
+
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+	JobCancelled JobStatus = "cancelled"
+)
+
+// Done reports whether s is final until the job is retried.
+func (s JobStatus) Done() bool {
+	return s == JobSucceeded || s == JobFailed || s == JobCancelled
+}
+
+// Job is a summarization run in the background for a user. Stage is the
+// pipeline stage in progress, or the one that failed. Result holds the
+// process response, including the stages completed before a failure, as
+// raw JSON.
+type Job struct {
+	ID          int64           `json:"id"`
+	UserID      int64           `json:"-"`
+	URL         string          `json:"url"`
+	Provider    string          `json:"provider,omitempty"`
+	NoCache     bool            `json:"no_cache,omitempty"`
+	CallbackURL string          `json:"callback_url,omitempty"`
+	Status      JobStatus       `json:"status"`
+	Stage       string          `json:"stage,omitempty"`
+	Attempts    int             `json:"attempts"`
+	Error       string          `json:"error,omitempty"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	// Callback reports the delivery of the completion webhook: "delivered",
+	// or the error of the last attempt.
+	Callback   string     `json:"callback,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
@@ -12,13 +12,34 @@ const (
 	LinkTypeUnknown    LinkType = "unknown"
 )
 
+// AllLinkTypes returns all valid link types.
+func AllLinkTypes() []LinkType {
+	return []LinkType{
+		LinkTypeArticle,
+		LinkTypeYouTube,
+		LinkTypePDF,
+		LinkTypeTwitter,
+		LinkTypeNewsletter,
+		LinkTypeUnknown,
+	}
+}
+
 // LinkInfo holds the result of URL type detection and metadata extraction.
+// Dates are RFC 3339, or YYYY-MM-DD when the source gives no time of day.
 type LinkInfo struct {
 	URL      string   `json:"url"`
 	LinkType LinkType `json:"link_type"`
 	Title    string   `json:"title,omitempty"`
 	Author   string   `json:"author,omitempty"`
-	Date     string   `json:"date,omitempty"`
+	Date     string   `json:"date,omitempty"`     // Publication date
+	Modified string   `json:"modified,omitempty"` // Last update
+	SiteName string   `json:"site_name,omitempty"`
+	// CanonicalURL is the URL the page names as its own, which may differ
+	// from URL after redirects or tracking parameters.
+	CanonicalURL string `json:"canonical_url,omitempty"`
+	Language     string `json:"language,omitempty"` // BCP 47 tag, e.g. "ko-KR"
+	Image        string `json:"image,omitempty"`    // Lead image URL
+	Description  string `json:"description,omitempty"`
 }
 
 // ExtractedContent holds the content extracted from a URL.
@@ -0,0 +1,123 @@
+package pipeline
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+)
+
+// Defaults of BatchOptions.
+const (
+	DefaultBatchWorkers = 4
+	DefaultBatchPerHost = 2
+)
+
+// DefaultBatchMaxURLs is the default limit on the URLs of one batch
+// request. RunBatch itself takes any number.
+const DefaultBatchMaxURLs = 100
+
+// DefaultBatchTimeout is the default time a batch request may take, which
+// is longer than the server's write timeout allows most requests.
+const DefaultBatchTimeout = 30 * time.Minute
+
+// BatchOptions bounds the concurrency of RunBatch.
+type BatchOptions struct {
+	// Workers is the number of URLs processed at once. Defaults to
+	// DefaultBatchWorkers.
+	Workers int
+	// PerHost is the number of extractions running at once against one
+	// host, so a list of links to one site does not hammer it. Defaults to
+	// DefaultBatchPerHost.
+	PerHost int
+	// Context, if set, returns the context the i-th URL is processed with,
+	// e.g. to track its cache hits separately.
+	Context func(ctx context.Context, i int) context.Context
+}
+
+// BatchResult is the outcome of one URL of a batch, as returned by Run.
+type BatchResult struct {
+	Result *Result
+	Err    error
+}
+
+// RunBatch processes urls through every stage like Run, several at a time,
+// and returns their outcomes in the order of urls. URLs not started when
+// ctx is done fail with its error.
+func (p *Pipeline) RunBatch(ctx context.Context, urls []string, client Client, opts BatchOptions) []BatchResult {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = DefaultBatchWorkers
+	}
+	workers = min(workers, len(urls))
+	perHost := opts.PerHost
+	if perHost <= 0 {
+		perHost = DefaultBatchPerHost
+	}
+	hosts := &hostLimiter{limit: perHost, slots: make(map[string]chan struct{})}
+
+	out := make([]BatchResult, len(urls))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if err := ctx.Err(); err != nil {
+					out[i] = BatchResult{Result: &Result{LinkInfo: model.LinkInfo{URL: urls[i]}}, Err: err}
+					continue
+				}
+				itemCtx := ctx
+				if opts.Context != nil {
+					itemCtx = opts.Context(ctx, i)
+				}
+				res, err := p.run(itemCtx, urls[i], client, hosts)
+				out[i] = BatchResult{Result: res, Err: err}
+			}
+		}()
+	}
+	for i := range urls {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return out
+}
+
+// hostLimiter bounds the extractions running at once per host. A nil
+// *hostLimiter imposes no limit.
+type hostLimiter struct {
+	limit int
+	mu    sync.Mutex
+	slots map[string]chan struct{}
+}
+
+// acquire waits for a slot for the host of rawURL, or for ctx to be done.
+// The returned function releases the slot.
+func (h *hostLimiter) acquire(ctx context.Context, rawURL string) (func(), error) {
+	if h == nil {
+		return func() {}, nil
+	}
+	host := rawURL
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		host = strings.ToLower(u.Hostname())
+	}
+	h.mu.Lock()
+	slot, ok := h.slots[host]
+	if !ok {
+		slot = make(chan struct{}, h.limit)
+		h.slots[host] = slot
+	}
+	h.mu.Unlock()
+
+	select {
+	case slot <- struct{}{}:
+		return func() { <-slot }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,130 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+)
+
+func TestRunBatch_ResultsInOrder(t *testing.T) {
+	p := newTestPipeline(t)
+	srv := newArticleServer(t)
+	client := &mockClient{
+		classification: &model.ClassificationResult{Primary: model.CategoryNews, Confidence: 0.9},
+		summary:        "## 요약",
+	}
+	urls := []string{srv.URL + "/a", "://bad", srv.URL + "/missing", srv.URL + "/b"}
+
+	var mu sync.Mutex
+	seen := map[int]bool{}
+	got := p.RunBatch(context.Background(), urls, client, BatchOptions{
+		Workers: 3,
+		Context: func(ctx context.Context, i int) context.Context {
+			mu.Lock()
+			seen[i] = true
+			mu.Unlock()
+			return ctx
+		},
+	})
+
+	if len(got) != len(urls) {
+		t.Fatalf("len(results) = %d, want %d", len(got), len(urls))
+	}
+	wantStages := []Stage{"", StageDetect, StageExtract, ""}
+	for i, r := range got {
+		if stage := FailedStage(r.Err); stage != wantStages[i] || (stage == "") != (r.Err == nil) {
+			t.Errorf("results[%d]: err = %v, want stage %q", i, r.Err, wantStages[i])
+		}
+		if r.Result == nil || r.Result.LinkInfo.URL != urls[i] {
+			t.Errorf("results[%d] = %+v, want result for %q", i, r.Result, urls[i])
+		}
+		if !seen[i] {
+			t.Errorf("Context not called for item %d", i)
+		}
+	}
+}
+
+func TestRunBatch_LimitsPerHost(t *testing.T) {
+	p := newTestPipeline(t)
+	var running, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			old := peak.Load()
+			if n <= old || peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head><title>T</title></head><body><p>Body.</p></body></html>`)
+	}))
+	defer srv.Close()
+
+	urls := make([]string, 8)
+	for i := range urls {
+		urls[i] = srv.URL + "/" + strconv.Itoa(i)
+	}
+	client := &mockClient{
+		classification: &model.ClassificationResult{Primary: model.CategoryNews, Confidence: 0.9},
+		summary:        "ok",
+	}
+	results := p.RunBatch(context.Background(), urls, client, BatchOptions{Workers: 6, PerHost: 2})
+	for i, r := range results {
+		if r.Err != nil {
+			t.Errorf("results[%d].Err = %v", i, r.Err)
+		}
+	}
+	if got := peak.Load(); got > 2 {
+		t.Errorf("peak concurrent requests to one host = %d, want at most 2", got)
+	}
+}
+
+func TestRunBatch_Cancelled(t *testing.T) {
+	p := newTestPipeline(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := p.RunBatch(ctx, []string{"https://example.com/a", "https://example.com/b"}, &mockClient{}, BatchOptions{})
+	for i, r := range results {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("results[%d].Err = %v, want context.Canceled", i, r.Err)
+		}
+	}
+}
+
+func TestHostLimiter_WaitsForSlot(t *testing.T) {
+	h := &hostLimiter{limit: 1, slots: make(map[string]chan struct{})}
+	release, err := h.acquire(context.Background(), "https://Example.com/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := h.acquire(ctx, "https://example.com/b"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("acquire() on a busy host = %v, want deadline exceeded", err)
+	}
+	if r, err := h.acquire(context.Background(), "https://other.example/"); err != nil {
+		t.Errorf("acquire() on another host = %v", err)
+	} else {
+		r()
+	}
+
+	release()
+	if r, err := h.acquire(context.Background(), "https://example.com/c"); err != nil {
+		t.Errorf("acquire() after release = %v", err)
+	} else {
+		r()
+	}
+}
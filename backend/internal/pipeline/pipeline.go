@@ -0,0 +1,166 @@
+// Package pipeline runs the full URL-to-summary flow server-side:
+// detect → extract → classify → summarize.
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/classifier"
+	"github.com/rookiecj/scrum-agents/backend/internal/extractor"
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+	"github.com/rookiecj/scrum-agents/backend/internal/summarizer"
+	"github.com/rookiecj/scrum-agents/backend/internal/urldetect"
+)
+
+// Stage identifies a step of the pipeline.
+type Stage string
+
+const (
+	StageDetect    Stage = "detect"
+	StageExtract   Stage = "extract"
+	StageClassify  Stage = "classify"
+	StageSummarize Stage = "summarize"
+)
+
+// StageError reports which stage of the pipeline failed.
+type StageError struct {
+	Stage Stage
+	Err   error
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("%s failed: %v", e.Stage, e.Err)
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// FailedStage returns the stage recorded in err, or "" if err is not a StageError.
+func FailedStage(err error) Stage {
+	var se *StageError
+	if errors.As(err, &se) {
+		return se.Stage
+	}
+	return ""
+}
+
+type progressKey struct{}
+
+// WithProgress returns a context in which Run calls fn as it starts each
+// stage, e.g. to report the progress of a background job.
+func WithProgress(ctx context.Context, fn func(Stage)) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// reportStage calls the function set by WithProgress, if any.
+func reportStage(ctx context.Context, stage Stage) {
+	if fn, ok := ctx.Value(progressKey{}).(func(Stage)); ok {
+		fn(stage)
+	}
+}
+
+// Client is the LLM client used for classification and summarization.
+// Every llm.Provider satisfies it.
+type Client interface {
+	classifier.Classifier
+	summarizer.LLMClient
+}
+
+// Timings holds per-stage durations in milliseconds.
+type Timings struct {
+	DetectMs    float64 `json:"detect_ms"`
+	ExtractMs   float64 `json:"extract_ms"`
+	ClassifyMs  float64 `json:"classify_ms"`
+	SummarizeMs float64 `json:"summarize_ms"`
+	TotalMs     float64 `json:"total_ms"`
+}
+
+// Result holds the output of every stage that completed.
+type Result struct {
+	LinkInfo       model.LinkInfo              `json:"link_info"`
+	Content        string                      `json:"content,omitempty"`
+	Classification *model.ClassificationResult `json:"classification,omitempty"`
+	Summary        *summarizer.SummaryResult   `json:"summary,omitempty"`
+	Timings        Timings                     `json:"timings"`
+}
+
+// Pipeline wires the extractor registry and summarizer together.
+type Pipeline struct {
+	extractors *extractor.Registry
+	summarizer *summarizer.Summarizer
+}
+
+// New creates a Pipeline from the shared extractor registry and summarizer.
+func New(extractors *extractor.Registry, sum *summarizer.Summarizer) *Pipeline {
+	return &Pipeline{
+		extractors: extractors,
+		summarizer: sum,
+	}
+}
+
+// Run processes rawURL through every stage using client for the LLM calls.
+// On failure it returns the partial result gathered so far together with a
+// *StageError naming the stage that failed.
+func (p *Pipeline) Run(ctx context.Context, rawURL string, client Client) (*Result, error) {
+	return p.run(ctx, rawURL, client, nil)
+}
+
+// run is Run, waiting for a slot of hosts, if not nil, before extracting.
+func (p *Pipeline) run(ctx context.Context, rawURL string, client Client, hosts *hostLimiter) (*Result, error) {
+	res := &Result{LinkInfo: model.LinkInfo{URL: rawURL}}
+	start := time.Now()
+	defer func() { res.Timings.TotalMs = msSince(start) }()
+
+	reportStage(ctx, StageDetect)
+	t := time.Now()
+	linkType, err := urldetect.Detect(rawURL)
+	res.Timings.DetectMs = msSince(t)
+	if err != nil {
+		return res, &StageError{Stage: StageDetect, Err: err}
+	}
+	res.LinkInfo.LinkType = linkType
+
+	reportStage(ctx, StageExtract)
+	release, err := hosts.acquire(ctx, rawURL)
+	if err != nil {
+		return res, &StageError{Stage: StageExtract, Err: err}
+	}
+	t = time.Now()
+	ext, _ := p.extractors.For(linkType)
+	extracted, err := ext.Extract(ctx, rawURL)
+	res.Timings.ExtractMs = msSince(t)
+	release()
+	if err != nil {
+		return res, &StageError{Stage: StageExtract, Err: err}
+	}
+	res.LinkInfo = extracted.LinkInfo
+	res.Content = extracted.Content
+
+	reportStage(ctx, StageClassify)
+	t = time.Now()
+	classification, err := client.Classify(ctx, extracted.Content)
+	res.Timings.ClassifyMs = msSince(t)
+	if err != nil {
+		return res, &StageError{Stage: StageClassify, Err: err}
+	}
+	res.Classification = classification
+
+	reportStage(ctx, StageSummarize)
+	t = time.Now()
+	summary, err := p.summarizer.Summarize(ctx, client, extracted.Content, classification)
+	res.Timings.SummarizeMs = msSince(t)
+	if err != nil {
+		return res, &StageError{Stage: StageSummarize, Err: err}
+	}
+	res.Summary = summary
+
+	return res, nil
+}
+
+func msSince(t time.Time) float64 {
+	return float64(time.Since(t).Nanoseconds()) / 1e6
+}
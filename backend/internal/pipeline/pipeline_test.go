@@ -0,0 +1,181 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/extractor"
+	"github.com/rookiecj/scrum-agents/backend/internal/model"
+	"github.com/rookiecj/scrum-agents/backend/internal/summarizer"
+)
+
+// mockClient is a test double for Client.
+type mockClient struct {
+	classification *model.ClassificationResult
+	classifyErr    error
+	summary        string
+	completeErr    error
+}
+
+func (m *mockClient) Classify(ctx context.Context, content string) (*model.ClassificationResult, error) {
+	return m.classification, m.classifyErr
+}
+
+func (m *mockClient) Complete(ctx context.Context, prompt string) (string, error) {
+	return m.summary, m.completeErr
+}
+
+func newTestPipeline(t *testing.T) *Pipeline {
+	t.Helper()
+	reg, err := summarizer.LoadTemplates("../../prompts")
+	if err != nil {
+		t.Skipf("prompts directory not found: %v", err)
+	}
+	return New(extractor.NewRegistry(), summarizer.NewSummarizer(reg, 0.6))
+}
+
+func newArticleServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head><title>Go Generics</title></head><body><p>Go 1.18 introduced generics.</p></body></html>`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRun_Success(t *testing.T) {
+	p := newTestPipeline(t)
+	srv := newArticleServer(t)
+	client := &mockClient{
+		classification: &model.ClassificationResult{Primary: model.CategoryTechIntro, Confidence: 0.9},
+		summary:        "## 요약\nGo generics",
+	}
+
+	res, err := p.Run(context.Background(), srv.URL+"/post", client)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if res.LinkInfo.LinkType != model.LinkTypeArticle {
+		t.Errorf("link_type = %q, want %q", res.LinkInfo.LinkType, model.LinkTypeArticle)
+	}
+	if res.LinkInfo.Title != "Go Generics" {
+		t.Errorf("title = %q, want %q", res.LinkInfo.Title, "Go Generics")
+	}
+	if res.Classification == nil || res.Classification.Primary != model.CategoryTechIntro {
+		t.Errorf("classification = %+v, want %q", res.Classification, model.CategoryTechIntro)
+	}
+	if res.Summary == nil || res.Summary.Summary != "## 요약\nGo generics" {
+		t.Errorf("summary = %+v", res.Summary)
+	}
+	if res.Timings.TotalMs <= 0 {
+		t.Error("expected total timing to be recorded")
+	}
+}
+
+func TestRun_StageErrors(t *testing.T) {
+	p := newTestPipeline(t)
+	srv := newArticleServer(t)
+	ok := &model.ClassificationResult{Primary: model.CategoryNews, Confidence: 0.9}
+
+	tests := []struct {
+		name      string
+		url       string
+		client    *mockClient
+		wantStage Stage
+	}{
+		{
+			name:      "invalid url",
+			url:       "://bad",
+			client:    &mockClient{},
+			wantStage: StageDetect,
+		},
+		{
+			name:      "extraction fails",
+			url:       srv.URL + "/missing",
+			client:    &mockClient{},
+			wantStage: StageExtract,
+		},
+		{
+			name:      "classification fails",
+			url:       srv.URL + "/post",
+			client:    &mockClient{classifyErr: errors.New("API down")},
+			wantStage: StageClassify,
+		},
+		{
+			name:      "summarization fails",
+			url:       srv.URL + "/post",
+			client:    &mockClient{classification: ok, completeErr: errors.New("rate limited")},
+			wantStage: StageSummarize,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := p.Run(context.Background(), tt.url, tt.client)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if got := FailedStage(err); got != tt.wantStage {
+				t.Errorf("stage = %q, want %q", got, tt.wantStage)
+			}
+			if res == nil {
+				t.Fatal("expected partial result")
+			}
+		})
+	}
+}
+
+func TestRun_PartialResultKeepsCompletedStages(t *testing.T) {
+	p := newTestPipeline(t)
+	srv := newArticleServer(t)
+	client := &mockClient{
+		classification: &model.ClassificationResult{Primary: model.CategoryNews, Confidence: 0.9},
+		completeErr:    errors.New("rate limited"),
+	}
+
+	res, err := p.Run(context.Background(), srv.URL+"/post", client)
+	if FailedStage(err) != StageSummarize {
+		t.Fatalf("stage = %q, want %q", FailedStage(err), StageSummarize)
+	}
+	if res.Content == "" {
+		t.Error("expected extracted content in partial result")
+	}
+	if res.Classification == nil {
+		t.Error("expected classification in partial result")
+	}
+	if res.Summary != nil {
+		t.Error("expected no summary in partial result")
+	}
+}
+
+func TestFailedStage_NonStageError(t *testing.T) {
+	if got := FailedStage(errors.New("plain")); got != "" {
+		t.Errorf("FailedStage() = %q, want empty", got)
+	}
+}
+
+func TestRun_ReportsProgress(t *testing.T) {
+	p := newTestPipeline(t)
+	srv := newArticleServer(t)
+	client := &mockClient{classifyErr: errors.New("API down")}
+
+	var stages []Stage
+	ctx := WithProgress(context.Background(), func(s Stage) { stages = append(stages, s) })
+	if _, err := p.Run(ctx, srv.URL+"/post", client); err == nil {
+		t.Fatal("expected error")
+	}
+	want := []Stage{StageDetect, StageExtract, StageClassify}
+	if fmt.Sprint(stages) != fmt.Sprint(want) {
+		t.Errorf("stages = %v, want %v", stages, want)
+	}
+}
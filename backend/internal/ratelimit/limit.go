@@ -0,0 +1,186 @@
+// Package ratelimit limits how often callers may use expensive routes:
+// token buckets per route and caller, and daily quotas per user kept in
+// SQLite.
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket: Burst requests at once, refilled at Rate
+// requests per second. The zero Limit allows everything.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Every returns a Limit of n requests per period, all of which may be
+// spent at once.
+func Every(n int, per time.Duration) Limit {
+	if n <= 0 || per <= 0 {
+		return Limit{}
+	}
+	return Limit{Rate: float64(n) / per.Seconds(), Burst: n}
+}
+
+// Enabled reports whether l limits anything.
+func (l Limit) Enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// String formats l the way ParseLimit reads it.
+func (l Limit) String() string {
+	if !l.Enabled() {
+		return "off"
+	}
+	per := time.Duration(float64(l.Burst) / l.Rate * float64(time.Second)).Round(time.Second)
+	return fmt.Sprintf("%d/%s", l.Burst, per)
+}
+
+// MarshalText formats l like String, so limits read back from config files.
+func (l Limit) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText parses text like ParseLimit.
+func (l *Limit) UnmarshalText(text []byte) error {
+	parsed, err := ParseLimit(string(text))
+	if err != nil {
+		return err
+	}
+	*l = parsed
+	return nil
+}
+
+// ParseLimit parses "N/PERIOD", where PERIOD is s, m, h, d or a Go
+// duration such as 10m: "30/m" allows 30 requests per minute. "off" and
+// "0" disable the limit.
+func ParseLimit(s string) (Limit, error) {
+	s = strings.TrimSpace(s)
+	if s == "off" || s == "0" {
+		return Limit{}, nil
+	}
+	count, period, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("limit %q: want N/PERIOD, e.g. 30/m", s)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(count))
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("limit %q: invalid count", s)
+	}
+	per, err := parsePeriod(strings.TrimSpace(period))
+	if err != nil {
+		return Limit{}, fmt.Errorf("limit %q: %w", s, err)
+	}
+	return Every(n, per), nil
+}
+
+func parsePeriod(s string) (time.Duration, error) {
+	switch s {
+	case "s":
+		return time.Second, nil
+	case "m":
+		return time.Minute, nil
+	case "h":
+		return time.Hour, nil
+	case "d":
+		return 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid period %q", s)
+	}
+	return d, nil
+}
+
+// ParseRouteLimits parses per-route limits of the form
+// "POST /api/summarize=10/m, POST /api/classify=off".
+func ParseRouteLimits(s string) (map[string]Limit, error) {
+	limits := make(map[string]Limit)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		pattern, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("route %q: want PATTERN=LIMIT", entry)
+		}
+		l, err := ParseLimit(value)
+		if err != nil {
+			return nil, fmt.Errorf("route %q: %w", entry, err)
+		}
+		limits[strings.Join(strings.Fields(pattern), " ")] = l
+	}
+	return limits, nil
+}
+
+// buckets holds a token bucket per key.
+type buckets struct {
+	mu    sync.Mutex
+	m     map[string]*bucket
+	swept time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	full   time.Time // When the bucket will have refilled completely
+}
+
+// result describes the state of a bucket after a take.
+type result struct {
+	ok        bool
+	remaining int
+	// retry is how long until the next request is allowed; reset is how
+	// long until the bucket is full again.
+	retry, reset time.Duration
+}
+
+// take spends one token from the bucket of key, limited by l.
+func (b *buckets) take(key string, l Limit, now time.Time) result {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.m == nil {
+		b.m = make(map[string]*bucket)
+	}
+	// Full buckets are the same as missing ones, so drop them now and then.
+	if now.Sub(b.swept) > time.Minute {
+		for k, bk := range b.m {
+			if !now.Before(bk.full) {
+				delete(b.m, k)
+			}
+		}
+		b.swept = now
+	}
+
+	bk, ok := b.m[key]
+	if !ok {
+		bk = &bucket{tokens: float64(l.Burst), last: now}
+		b.m[key] = bk
+	}
+	burst := float64(l.Burst)
+	bk.tokens = math.Min(burst, bk.tokens+now.Sub(bk.last).Seconds()*l.Rate)
+	bk.last = now
+
+	res := result{ok: bk.tokens >= 1}
+	if res.ok {
+		bk.tokens--
+	} else {
+		res.retry = seconds((1 - bk.tokens) / l.Rate)
+	}
+	res.remaining = int(bk.tokens)
+	res.reset = seconds((burst - bk.tokens) / l.Rate)
+	bk.full = now.Add(res.reset)
+	return res
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
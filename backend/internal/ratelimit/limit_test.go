@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Limit
+	}{
+		{"30/m", Every(30, time.Minute)},
+		{" 10 / 1h ", Every(10, time.Hour)},
+		{"5/90s", Every(5, 90*time.Second)},
+		{"100/d", Every(100, 24*time.Hour)},
+		{"off", Limit{}},
+		{"0", Limit{}},
+	}
+	for _, tt := range tests {
+		got, err := ParseLimit(tt.in)
+		if err != nil {
+			t.Errorf("ParseLimit(%q) error = %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLimit(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"30", "x/m", "-1/m", "10/fortnight", "10/-1m"} {
+		if _, err := ParseLimit(in); err == nil {
+			t.Errorf("ParseLimit(%q) error = nil, want error", in)
+		}
+	}
+}
+
+func TestLimit_String(t *testing.T) {
+	if got := Every(30, time.Minute).String(); got != "30/1m0s" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := (Limit{}).String(); got != "off" {
+		t.Errorf("String() = %q, want off", got)
+	}
+}
+
+func TestLimit_Text(t *testing.T) {
+	for _, l := range []Limit{Every(30, time.Minute), Every(100, 24*time.Hour), {}} {
+		text, err := l.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var back Limit
+		if err := back.UnmarshalText(text); err != nil || back != l {
+			t.Errorf("round trip of %+v via %q = %+v, %v", l, text, back, err)
+		}
+	}
+	var l Limit
+	if err := l.UnmarshalText([]byte("fast")); err == nil {
+		t.Error("UnmarshalText(fast) error = nil, want error")
+	}
+}
+
+func TestParseRouteLimits(t *testing.T) {
+	got, err := ParseRouteLimits("POST  /api/summarize=10/m, POST /api/classify=off")
+	if err != nil {
+		t.Fatalf("ParseRouteLimits() error = %v", err)
+	}
+	if got["POST /api/summarize"] != Every(10, time.Minute) || got["POST /api/classify"].Enabled() || len(got) != 2 {
+		t.Errorf("ParseRouteLimits() = %+v", got)
+	}
+	for _, in := range []string{"POST /api/summarize", "POST /api/summarize=fast"} {
+		if _, err := ParseRouteLimits(in); err == nil {
+			t.Errorf("ParseRouteLimits(%q) error = nil, want error", in)
+		}
+	}
+}
+
+func TestBuckets_Take(t *testing.T) {
+	var b buckets
+	l := Every(2, time.Minute) // A token every 30s
+	now := time.Now()
+
+	for i, want := range []int{1, 0} {
+		res := b.take("k", l, now)
+		if !res.ok || res.remaining != want {
+			t.Fatalf("take %d = %+v, want ok with %d remaining", i, res, want)
+		}
+	}
+	res := b.take("k", l, now)
+	if res.ok || res.retry != 30*time.Second || res.reset != time.Minute {
+		t.Fatalf("take on empty bucket = %+v, want retry 30s, reset 1m", res)
+	}
+	if res := b.take("other", l, now); !res.ok {
+		t.Error("other key limited by k's bucket")
+	}
+
+	if res := b.take("k", l, now.Add(30*time.Second)); !res.ok {
+		t.Errorf("take after refill = %+v, want ok", res)
+	}
+
+	// Full buckets are dropped on the next sweep.
+	b.take("x", l, now.Add(5*time.Minute))
+	if _, ok := b.m["k"]; ok {
+		t.Error("full bucket not swept")
+	}
+}
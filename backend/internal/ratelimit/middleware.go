@@ -0,0 +1,203 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/auth"
+)
+
+// Rule is the rate limit of one route.
+type Rule struct {
+	// User limits each signed-in user, or each personal access token, and
+	// Anonymous each client address without a token.
+	User      Limit
+	Anonymous Limit
+	// Quota makes requests count towards the caller's daily quota.
+	Quota bool
+}
+
+// Quota is the number of requests a caller may make per UTC day across
+// all routes with Rule.Quota. Zero means no quota.
+type Quota struct {
+	User      int
+	Anonymous int
+}
+
+// Options configures a Limiter.
+type Options struct {
+	// Routes maps ServeMux patterns to their rule. Routes not listed are
+	// not limited.
+	Routes map[string]Rule
+	Daily  Quota
+	// DB keeps the daily quota counts. It is required when Daily is set.
+	DB *sql.DB
+	// TrustProxy identifies anonymous clients by X-Forwarded-For.
+	TrustProxy bool
+	// AnonymousMaxBody caps the request bodies of callers without a token
+	// on limited routes, in bytes. Zero means no cap.
+	AnonymousMaxBody int64
+}
+
+// Limiter applies rate limits and quotas to the routes it wraps. A nil
+// *Limiter is valid and limits nothing.
+type Limiter struct {
+	opts    Options
+	buckets buckets
+	quotas  *Quotas
+	now     func() time.Time
+}
+
+// New creates a Limiter.
+func New(opts Options) (*Limiter, error) {
+	l := &Limiter{opts: opts, now: time.Now}
+	if opts.Daily.User > 0 || opts.Daily.Anonymous > 0 {
+		if opts.DB == nil {
+			return nil, errors.New("ratelimit: daily quota needs a database")
+		}
+		q, err := NewQuotas(opts.DB)
+		if err != nil {
+			return nil, err
+		}
+		l.quotas = q
+	}
+	return l, nil
+}
+
+// Wrap returns h limited by the rule of pattern. It must run after the
+// auth middleware, which identifies the caller.
+//
+// Responses carry X-RateLimit-Limit, X-RateLimit-Remaining and
+// X-RateLimit-Reset (seconds) for the route's bucket and
+// X-RateLimit-Quota-Limit, -Remaining and -Reset for the daily quota.
+// Rejected requests get 429 Too Many Requests with Retry-After, and bodies
+// over the anonymous cap 413 Request Entity Too Large.
+func (l *Limiter) Wrap(pattern string, h http.Handler) http.Handler {
+	if l == nil {
+		return h
+	}
+	rule, ok := l.opts.Routes[pattern]
+	if !ok {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		now := l.now()
+		bucketKey, subject, signedIn := l.caller(r)
+
+		limit, quota := rule.Anonymous, l.opts.Daily.Anonymous
+		if signedIn {
+			limit, quota = rule.User, l.opts.Daily.User
+		} else if max := l.opts.AnonymousMaxBody; max > 0 {
+			// Checked first so oversized requests spend no tokens.
+			if r.ContentLength > max {
+				http.Error(w, `{"error":"request body too large; sign in to send more"}`, http.StatusRequestEntityTooLarge)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, max)
+		}
+
+		if limit.Enabled() {
+			res := l.buckets.take(pattern+" "+bucketKey, limit, now)
+			hdr := w.Header()
+			hdr.Set("X-RateLimit-Limit", strconv.Itoa(limit.Burst))
+			hdr.Set("X-RateLimit-Remaining", strconv.Itoa(res.remaining))
+			hdr.Set("X-RateLimit-Reset", ceilSeconds(res.reset))
+			if !res.ok {
+				slog.WarnContext(r.Context(), "ratelimit: limit reached",
+					slog.String("path", r.URL.Path),
+					slog.String("caller", bucketKey),
+				)
+				hdr.Set("Retry-After", ceilSeconds(res.retry))
+				http.Error(w, `{"error":"rate limit exceeded; retry later"}`, http.StatusTooManyRequests)
+				return
+			}
+		}
+
+		if rule.Quota && quota > 0 && l.quotas != nil {
+			if !l.takeQuota(w, r, subject, quota, 1, now) {
+				http.Error(w, `{"error":"daily quota exceeded"}`, http.StatusTooManyRequests)
+				return
+			}
+			req := r
+			r = r.WithContext(context.WithValue(r.Context(), chargeKey{}, charger(func(n int) bool {
+				return l.takeQuota(w, req, subject, quota, n, l.now())
+			})))
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// takeQuota counts n requests against the daily quota of subject and sets
+// the quota headers. It reports false, counting nothing, if fewer than n
+// remain, after setting Retry-After. A broken counter lets the request
+// through rather than take the service down.
+func (l *Limiter) takeQuota(w http.ResponseWriter, r *http.Request, subject string, quota, n int, now time.Time) bool {
+	used, ok, err := l.quotas.TakeN(subject, n, quota, now)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "ratelimit: quota check failed",
+			slog.String("path", r.URL.Path),
+			slog.String("error", err.Error()),
+		)
+		return true
+	}
+	reset := ceilSeconds(untilMidnight(now))
+	hdr := w.Header()
+	hdr.Set("X-RateLimit-Quota-Limit", strconv.Itoa(quota))
+	hdr.Set("X-RateLimit-Quota-Remaining", strconv.Itoa(quota-used))
+	hdr.Set("X-RateLimit-Quota-Reset", reset)
+	if !ok {
+		slog.WarnContext(r.Context(), "ratelimit: daily quota reached",
+			slog.String("path", r.URL.Path),
+			slog.String("caller", subject),
+			slog.Int("requested", n),
+		)
+		hdr.Set("Retry-After", reset)
+	}
+	return ok
+}
+
+type chargeKey struct{}
+
+// charger counts n more requests against the quota of the current caller.
+type charger func(n int) bool
+
+// Charge counts n more requests against the daily quota of the caller of
+// ctx, for handlers whose requests do the work of several, such as
+// batches; Wrap has already counted the request itself. It reports false,
+// counting nothing, if fewer than n remain, after setting the quota
+// headers and Retry-After. The handler then answers 429 itself. Requests
+// on routes without a quota are always allowed.
+func Charge(ctx context.Context, n int) bool {
+	c, ok := ctx.Value(chargeKey{}).(charger)
+	if !ok || n <= 0 {
+		return true
+	}
+	return c(n)
+}
+
+// caller identifies who made r: the key of its token buckets, the subject
+// of its daily quota and whether it is signed in. Personal access tokens
+// get buckets of their own but share their user's quota.
+func (l *Limiter) caller(r *http.Request) (bucketKey, subject string, signedIn bool) {
+	claims := auth.UserFromContext(r.Context())
+	if claims == nil {
+		ip := "ip:" + auth.ClientIP(r, l.opts.TrustProxy)
+		return ip, ip, false
+	}
+	user := "user:" + strconv.FormatInt(claims.UserID, 10)
+	if claims.IsAPIToken() {
+		return "token:" + strconv.FormatInt(claims.TokenID, 10), user, true
+	}
+	return user, user, true
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
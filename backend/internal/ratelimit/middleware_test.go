@@ -0,0 +1,209 @@
+package ratelimit
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/auth"
+)
+
+const route = "POST /api/summarize"
+
+func newTestMux(t *testing.T, opts Options) (http.Handler, *auth.JWTService) {
+	t.Helper()
+	l, err := New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtSvc := auth.NewJWTService("test-secret", time.Hour)
+	mux := http.NewServeMux()
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	mux.Handle(route, auth.OptionalMiddleware(jwtSvc)(l.Wrap(route, ok)))
+	mux.Handle("POST /api/detect", auth.OptionalMiddleware(jwtSvc)(l.Wrap("POST /api/detect", ok)))
+	return mux, jwtSvc
+}
+
+func send(h http.Handler, path, token, addr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", path, nil)
+	req.RemoteAddr = addr + ":1234"
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestLimiter_Buckets(t *testing.T) {
+	mux, jwtSvc := newTestMux(t, Options{
+		Routes: map[string]Rule{route: {User: Every(3, time.Minute), Anonymous: Every(1, time.Minute)}},
+	})
+	alice, _ := jwtSvc.GenerateToken(1, "alice@example.com")
+
+	rec := send(mux, "/api/summarize", "", "10.0.0.1")
+	if rec.Code != http.StatusOK || rec.Header().Get("X-RateLimit-Limit") != "1" || rec.Header().Get("X-RateLimit-Remaining") != "0" {
+		t.Fatalf("first anonymous request: %d %v", rec.Code, rec.Header())
+	}
+	rec = send(mux, "/api/summarize", "", "10.0.0.1")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "60" {
+		t.Fatalf("second anonymous request: %d, Retry-After %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if rec := send(mux, "/api/summarize", "", "10.0.0.2"); rec.Code != http.StatusOK {
+		t.Errorf("other address: status = %d, want 200", rec.Code)
+	}
+
+	// Signed-in users get their own, larger bucket, whatever their address.
+	for i := 0; i < 3; i++ {
+		if rec := send(mux, "/api/summarize", alice, "10.0.0.1"); rec.Code != http.StatusOK {
+			t.Fatalf("user request %d: status = %d, want 200", i, rec.Code)
+		}
+	}
+	if rec := send(mux, "/api/summarize", alice, "10.0.0.3"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("user over limit: status = %d, want 429", rec.Code)
+	}
+
+	// Routes without a rule are not limited.
+	rec = send(mux, "/api/detect", "", "10.0.0.1")
+	if rec.Code != http.StatusOK || rec.Header().Get("X-RateLimit-Limit") != "" {
+		t.Errorf("unlimited route: %d %v", rec.Code, rec.Header())
+	}
+}
+
+func TestLimiter_DailyQuota(t *testing.T) {
+	mux, jwtSvc := newTestMux(t, Options{
+		Routes: map[string]Rule{route: {Quota: true}},
+		Daily:  Quota{User: 2, Anonymous: 1},
+		DB:     testDB(t),
+	})
+	alice, _ := jwtSvc.GenerateToken(1, "alice@example.com")
+
+	for i, want := range []string{"1", "0"} {
+		rec := send(mux, "/api/summarize", alice, "10.0.0.1")
+		if rec.Code != http.StatusOK || rec.Header().Get("X-RateLimit-Quota-Remaining") != want {
+			t.Fatalf("request %d: %d, remaining %q, want %s", i, rec.Code, rec.Header().Get("X-RateLimit-Quota-Remaining"), want)
+		}
+	}
+	rec := send(mux, "/api/summarize", alice, "10.0.0.1")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("over quota: %d, Retry-After %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	if rec := send(mux, "/api/summarize", "", "10.0.0.1"); rec.Code != http.StatusOK {
+		t.Errorf("anonymous: status = %d, want 200", rec.Code)
+	}
+	if rec := send(mux, "/api/summarize", "", "10.0.0.1"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("anonymous over quota: status = %d, want 429", rec.Code)
+	}
+}
+
+func TestCharge(t *testing.T) {
+	l, err := New(Options{
+		Routes: map[string]Rule{route: {Quota: true}},
+		Daily:  Quota{User: 4, Anonymous: 4},
+		DB:     testDB(t),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The handler charges one more request per item after the first.
+	h := l.Wrap(route, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		items, _ := strconv.Atoi(r.URL.Query().Get("items"))
+		if !Charge(r.Context(), items-1) {
+			http.Error(w, "quota", http.StatusTooManyRequests)
+		}
+	}))
+	do := func(items int) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/summarize?items="+strconv.Itoa(items), nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := do(3); rec.Code != http.StatusOK || rec.Header().Get("X-RateLimit-Quota-Remaining") != "1" {
+		t.Fatalf("3 items: %d, remaining %q, want 200 with 1 left", rec.Code, rec.Header().Get("X-RateLimit-Quota-Remaining"))
+	}
+	rec := do(2)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("2 items with 1 left: %d, Retry-After %q, want 429", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if got := rec.Header().Get("X-RateLimit-Quota-Remaining"); got != "0" {
+		t.Errorf("remaining after refused charge = %q, want 0: the request itself counts", got)
+	}
+
+	// Outside a limited route, Charge allows anything.
+	if !Charge(context.Background(), 100) {
+		t.Error("Charge() without a quota = false, want true")
+	}
+}
+
+func TestLimiter_AnonymousMaxBody(t *testing.T) {
+	l, err := New(Options{
+		Routes:           map[string]Rule{route: {Anonymous: Every(10, time.Minute)}},
+		AnonymousMaxBody: 16,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtSvc := auth.NewJWTService("test-secret", time.Hour)
+	alice, _ := jwtSvc.GenerateToken(1, "alice@example.com")
+	h := auth.OptionalMiddleware(jwtSvc)(l.Wrap(route, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		}
+	})))
+
+	do := func(token, body string, chunked bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/summarize", strings.NewReader(body))
+		req.RemoteAddr = "10.0.0.1:1234"
+		if chunked {
+			req.ContentLength = -1
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	big := strings.Repeat("x", 17)
+	if rec := do("", "{}", false); rec.Code != http.StatusOK {
+		t.Errorf("small body: status = %d, want 200", rec.Code)
+	}
+	rec := do("", big, false)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("large body: status = %d, want 413", rec.Code)
+	}
+	if rec.Header().Get("X-RateLimit-Limit") != "" {
+		t.Error("large body spent a token")
+	}
+	if rec := do("", big, true); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("large body without length: status = %d, want 413", rec.Code)
+	}
+	if rec := do(alice, big, false); rec.Code != http.StatusOK {
+		t.Errorf("signed-in large body: status = %d, want 200", rec.Code)
+	}
+}
+
+func TestNew_QuotaNeedsDB(t *testing.T) {
+	if _, err := New(Options{Daily: Quota{User: 1}}); err == nil {
+		t.Error("New() error = nil, want error without DB")
+	}
+}
+
+func TestLimiter_Nil(t *testing.T) {
+	var l *Limiter
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	rec := httptest.NewRecorder()
+	l.Wrap(route, h).ServeHTTP(rec, httptest.NewRequest("POST", "/api/summarize", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", rec.Code)
+	}
+}
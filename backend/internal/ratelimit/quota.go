@@ -0,0 +1,104 @@
+package ratelimit
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Quotas counts requests per subject and UTC day in SQLite, so daily
+// quotas hold across restarts.
+type Quotas struct {
+	db *sql.DB
+
+	mu      sync.Mutex
+	current string // Day whose older rows have been purged
+}
+
+// NewQuotas returns Quotas stored in db, creating the table if needed.
+func NewQuotas(db *sql.DB) (*Quotas, error) {
+	const createTable = `
+		CREATE TABLE IF NOT EXISTS quota_usage (
+			subject TEXT    NOT NULL,
+			day     TEXT    NOT NULL,
+			count   INTEGER NOT NULL,
+			PRIMARY KEY (subject, day)
+		);`
+	if _, err := db.Exec(createTable); err != nil {
+		return nil, fmt.Errorf("create quota table: %w", err)
+	}
+	return &Quotas{db: db}, nil
+}
+
+// Take counts one request of subject against limit requests per day. It
+// returns the requests counted today, including this one, and false
+// without counting if the limit was already reached.
+func (q *Quotas) Take(subject string, limit int, now time.Time) (int, bool, error) {
+	return q.TakeN(subject, 1, limit, now)
+}
+
+// TakeN counts n requests of subject at once, like Take. It returns false
+// without counting any if fewer than n remain.
+func (q *Quotas) TakeN(subject string, n, limit int, now time.Time) (int, bool, error) {
+	day := now.UTC().Format(time.DateOnly)
+	if err := q.purgeBefore(day); err != nil {
+		return 0, false, err
+	}
+	refused := func() (int, bool, error) {
+		used, err := q.Used(subject, now)
+		return min(used, limit), false, err
+	}
+	if n > limit {
+		return refused()
+	}
+
+	var used int
+	err := q.db.QueryRow(`INSERT INTO quota_usage (subject, day, count) VALUES (?, ?, ?)
+		ON CONFLICT (subject, day) DO UPDATE SET count = count + excluded.count
+		WHERE count + excluded.count <= ?
+		RETURNING count`, subject, day, n, limit).Scan(&used)
+	if errors.Is(err, sql.ErrNoRows) {
+		return refused()
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("count quota usage: %w", err)
+	}
+	return used, true, nil
+}
+
+// Used returns how many requests subject made on the day of now.
+func (q *Quotas) Used(subject string, now time.Time) (int, error) {
+	var used int
+	err := q.db.QueryRow(`SELECT count FROM quota_usage WHERE subject = ? AND day = ?`,
+		subject, now.UTC().Format(time.DateOnly)).Scan(&used)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("query quota usage: %w", err)
+	}
+	return used, nil
+}
+
+// purgeBefore deletes the counts of days before day, once per day.
+func (q *Quotas) purgeBefore(day string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.current == day {
+		return nil
+	}
+	if _, err := q.db.Exec(`DELETE FROM quota_usage WHERE day < ?`, day); err != nil {
+		return fmt.Errorf("purge quota usage: %w", err)
+	}
+	q.current = day
+	return nil
+}
+
+// untilMidnight returns how long until the next UTC day starts.
+func untilMidnight(now time.Time) time.Duration {
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	return next.Sub(now)
+}
@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestQuotas_Take(t *testing.T) {
+	q, err := NewQuotas(testDB(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2025, 3, 1, 23, 0, 0, 0, time.UTC)
+
+	for want := 1; want <= 2; want++ {
+		used, ok, err := q.Take("user:1", 2, day)
+		if err != nil || !ok || used != want {
+			t.Fatalf("Take() = %d, %v, %v; want %d, true", used, ok, err, want)
+		}
+	}
+	if used, ok, _ := q.Take("user:1", 2, day); ok || used != 2 {
+		t.Errorf("Take() over quota = %d, %v; want 2, false", used, ok)
+	}
+	if _, ok, _ := q.Take("user:2", 2, day); !ok {
+		t.Error("user:2 limited by user:1's quota")
+	}
+
+	// A new day starts from zero and purges the old counts.
+	next := day.Add(2 * time.Hour)
+	if used, ok, _ := q.Take("user:1", 2, next); !ok || used != 1 {
+		t.Errorf("Take() next day = %d, %v; want 1, true", used, ok)
+	}
+	if used, _ := q.Used("user:2", day); used != 0 {
+		t.Errorf("Used(previous day) = %d after purge, want 0", used)
+	}
+}
+
+func TestQuotas_TakeN(t *testing.T) {
+	q, err := NewQuotas(testDB(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	if used, ok, err := q.TakeN("user:1", 3, 5, day); err != nil || !ok || used != 3 {
+		t.Fatalf("TakeN(3) = %d, %v, %v; want 3, true", used, ok, err)
+	}
+	// Only two remain: three are refused and none counted.
+	if used, ok, _ := q.TakeN("user:1", 3, 5, day); ok || used != 3 {
+		t.Errorf("TakeN(3) over quota = %d, %v; want 3, false", used, ok)
+	}
+	if used, ok, _ := q.TakeN("user:1", 2, 5, day); !ok || used != 5 {
+		t.Errorf("TakeN(2) = %d, %v; want 5, true", used, ok)
+	}
+	if used, ok, _ := q.TakeN("user:2", 6, 5, day); ok || used != 0 {
+		t.Errorf("TakeN(6) above the limit = %d, %v; want 0, false", used, ok)
+	}
+}
+
+func TestUntilMidnight(t *testing.T) {
+	now := time.Date(2025, 3, 1, 22, 30, 0, 0, time.UTC)
+	if got := untilMidnight(now); got != 90*time.Minute {
+		t.Errorf("untilMidnight() = %v, want 1h30m", got)
+	}
+}
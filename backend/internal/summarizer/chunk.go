@@ -0,0 +1,104 @@
+package summarizer
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/tokens"
+)
+
+// DefaultChunkOverlap is the number of tokens each chunk repeats from the
+// one before.
+const DefaultChunkOverlap = 100
+
+// maxParallelChunks bounds the concurrent LLM calls of the map step.
+const maxParallelChunks = 4
+
+// splitChunks splits content into chunks of at most size tokens as counted
+// by b. Each chunk after the first repeats about overlap tokens of the
+// previous one, so that text cut at a boundary is seen whole by at least one
+// chunk. Chunks end at a paragraph, line, sentence or word boundary where
+// there is one in their second half, and never inside a UTF-8 sequence.
+func splitChunks(content string, size, overlap int, b tokens.Budget) []string {
+	if b.Count(content) <= size {
+		return []string{content}
+	}
+	overlap = min(overlap, size/2)
+
+	var chunks []string
+	start := 0
+	for start < len(content) {
+		end := start + b.Cut(content[start:], size)
+		if end == len(content) {
+			chunks = appendChunk(chunks, content[start:])
+			break
+		}
+		if end <= start {
+			_, n := utf8.DecodeRuneInString(content[start:])
+			end = start + n
+		}
+		chunks = appendChunk(chunks, content[start:end])
+
+		next := overlapStart(content, end-tailLen(content[start:end], overlap, b), end)
+		if next <= start {
+			next = end
+		}
+		start = next
+	}
+	return chunks
+}
+
+func appendChunk(chunks []string, chunk string) []string {
+	if chunk = strings.TrimSpace(chunk); chunk == "" {
+		return chunks
+	}
+	return append(chunks, chunk)
+}
+
+// tailLen returns the length of the longest suffix of s within n tokens.
+func tailLen(s string, n int, b tokens.Budget) int {
+	lo, hi := 0, len(s)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if b.Count(s[mid:]) <= n {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return len(s) - lo
+}
+
+// overlapStart returns where the chunk after one ending at end should
+// start: just after the first word boundary at or after from, or the first
+// rune boundary if there is none before end.
+func overlapStart(s string, from, end int) int {
+	if i := strings.IndexAny(s[from:end], " \n"); i >= 0 {
+		return from + i + 1
+	}
+	for from < end && !utf8.RuneStart(s[from]) {
+		from++
+	}
+	return from
+}
+
+// groupNotes joins consecutive notes into groups of at most size tokens as
+// counted by b. A note longer than size forms a group of its own.
+func groupNotes(notes []string, size int, b tokens.Budget) []string {
+	var groups []string
+	var cur strings.Builder
+	for _, n := range notes {
+		if cur.Len() > 0 && b.Count(cur.String())+b.Count(n) > size {
+			groups = append(groups, cur.String())
+			cur.Reset()
+		}
+		if cur.Len() > 0 {
+			cur.WriteString("\n\n")
+		}
+		cur.WriteString(n)
+	}
+	if cur.Len() > 0 {
+		groups = append(groups, cur.String())
+	}
+	return groups
+}
@@ -0,0 +1,105 @@
+package summarizer
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/tokens"
+)
+
+// runeBudget counts one token per rune, which keeps sizes in tests readable.
+var runeBudget = tokens.Budget{Estimator: tokens.Estimator{CharsPerToken: 1, TokensPerWideRune: 1}}
+
+func TestSplitChunks(t *testing.T) {
+	sentence := "The quick brown fox jumps over the lazy dog. "
+	korean := "다람쥐 헌 쳇바퀴에 타고파. "
+
+	tests := []struct {
+		name       string
+		content    string
+		size       int
+		overlap    int
+		wantChunks int // 0 means more than one
+	}{
+		{name: "short content is one chunk", content: "short", size: 100, overlap: 10, wantChunks: 1},
+		{name: "exact size is one chunk", content: strings.Repeat("a", 100), size: 100, overlap: 10, wantChunks: 1},
+		{name: "sentences", content: strings.Repeat(sentence, 50), size: 200, overlap: 40},
+		{name: "korean", content: strings.Repeat(korean, 80), size: 200, overlap: 40},
+		{name: "no spaces", content: strings.Repeat("가", 500), size: 100, overlap: 20},
+		{name: "paragraphs", content: strings.Repeat("A paragraph of text.\n\n", 40), size: 150, overlap: 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := splitChunks(tt.content, tt.size, tt.overlap, runeBudget)
+			if tt.wantChunks > 0 && len(chunks) != tt.wantChunks {
+				t.Fatalf("got %d chunks, want %d", len(chunks), tt.wantChunks)
+			}
+			if tt.wantChunks == 0 && len(chunks) < 2 {
+				t.Fatalf("got %d chunks, want several", len(chunks))
+			}
+			for i, c := range chunks {
+				if n := runeBudget.Count(c); n > tt.size {
+					t.Errorf("chunk %d is %d tokens, limit %d", i, n, tt.size)
+				}
+				if !utf8.ValidString(c) {
+					t.Errorf("chunk %d is not valid UTF-8: %q", i, c)
+				}
+				if c == "" {
+					t.Errorf("chunk %d is empty", i)
+				}
+			}
+			// Every chunk's text appears in the content, and together
+			// they cover it from start to end.
+			if !strings.HasPrefix(tt.content, chunks[0]) {
+				t.Errorf("first chunk is not a prefix of the content")
+			}
+			if !strings.HasSuffix(strings.TrimSpace(tt.content), chunks[len(chunks)-1]) {
+				t.Errorf("last chunk is not a suffix of the content")
+			}
+		})
+	}
+}
+
+func TestSplitChunks_Overlap(t *testing.T) {
+	var words []string
+	for i := 0; i < 200; i++ {
+		words = append(words, "w"+strings.Repeat("x", i%7))
+	}
+	content := strings.Join(words, " ")
+
+	chunks := splitChunks(content, 120, 30, runeBudget)
+	for i := 1; i < len(chunks); i++ {
+		prev, cur := chunks[i-1], chunks[i]
+		// The start of each chunk repeats the end of the previous one.
+		head := strings.Fields(cur)[0]
+		if !strings.Contains(prev[len(prev)/2:], head) {
+			t.Errorf("chunk %d does not overlap chunk %d: starts with %q", i, i-1, head)
+		}
+	}
+}
+
+func TestSplitChunks_BreaksAtSentence(t *testing.T) {
+	content := strings.Repeat("Sentence number one is here. ", 10)
+	chunks := splitChunks(content, 100, 20, runeBudget)
+	for i, c := range chunks[:len(chunks)-1] {
+		if !strings.HasSuffix(c, ".") {
+			t.Errorf("chunk %d does not end at a sentence: %q", i, c)
+		}
+	}
+}
+
+func TestGroupNotes(t *testing.T) {
+	notes := []string{"aaaa", "bbbb", "cccc", strings.Repeat("d", 20), "eeee"}
+	groups := groupNotes(notes, 10, runeBudget)
+	want := []string{"aaaa\n\nbbbb", "cccc", strings.Repeat("d", 20), "eeee"}
+	if len(groups) != len(want) {
+		t.Fatalf("got %d groups %q, want %q", len(groups), groups, want)
+	}
+	for i := range want {
+		if groups[i] != want[i] {
+			t.Errorf("group %d = %q, want %q", i, groups[i], want[i])
+		}
+	}
+}
@@ -1,29 +1,46 @@
 package summarizer
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
 
 	"github.com/rookiecj/scrum-agents/backend/internal/model"
+	"github.com/rookiecj/scrum-agents/backend/internal/tokens"
 )
 
 // LLMClient is the interface for making LLM API calls.
 type LLMClient interface {
-	Complete(prompt string) (string, error)
+	Complete(ctx context.Context, prompt string) (string, error)
+}
+
+// StreamingLLMClient is an LLMClient that can also stream its completion.
+type StreamingLLMClient interface {
+	LLMClient
+	Stream(ctx context.Context, prompt string, onToken func(string) error) (string, error)
 }
 
 // SummaryResult holds the summarization result with metadata.
 type SummaryResult struct {
-	Summary        string                `json:"summary"`
-	Category       model.ContentCategory `json:"category"`
-	Style          string                `json:"style"`
-	LowConfidence  bool                  `json:"low_confidence,omitempty"`
-	TemplateUsed   string                `json:"template_used"`
+	Summary       string                `json:"summary"`
+	Category      model.ContentCategory `json:"category"`
+	Style         string                `json:"style"`
+	LowConfidence bool                  `json:"low_confidence,omitempty"`
+	TemplateUsed  string                `json:"template_used"`
+	Chunks        int                   `json:"chunks"`
+	// Truncated is set when content had to be cut to fit the model's
+	// budget, which only happens if chunk notes cannot be condensed enough.
+	Truncated bool `json:"truncated,omitempty"`
 }
 
 // Summarizer generates category-optimized summaries using prompt templates.
 type Summarizer struct {
 	registry            *TemplateRegistry
 	confidenceThreshold float64
+	maxChunkTokens      int
+	chunkOverlap        int
 }
 
 // NewSummarizer creates a Summarizer with the given template registry.
@@ -35,23 +52,38 @@ func NewSummarizer(registry *TemplateRegistry, confidenceThreshold float64) *Sum
 	return &Summarizer{
 		registry:            registry,
 		confidenceThreshold: confidenceThreshold,
+		chunkOverlap:        DefaultChunkOverlap,
 	}
 }
 
-// Summarize generates a summary using the appropriate template based on classification.
-func (s *Summarizer) Summarize(client LLMClient, content string, classification *model.ClassificationResult) (*SummaryResult, error) {
-	var tmpl *PromptTemplate
-	var lowConfidence bool
+// SetChunking sets how content is split: into chunks of at most maxTokens,
+// each repeating overlap tokens of the one before. By default chunks are as
+// large as the client's budget allows. Values <= 0 keep the defaults.
+func (s *Summarizer) SetChunking(maxTokens, overlap int) {
+	if maxTokens > 0 {
+		s.maxChunkTokens = maxTokens
+	}
+	if overlap > 0 {
+		s.chunkOverlap = overlap
+	}
+}
 
+// selectTemplate picks the category template, or the generic template when
+// the classification confidence is below the threshold.
+func (s *Summarizer) selectTemplate(classification *model.ClassificationResult) (tmpl *PromptTemplate, lowConfidence bool) {
 	if classification.Confidence < s.confidenceThreshold {
-		tmpl = s.registry.GetGeneric()
-		lowConfidence = true
-	} else {
-		tmpl = s.registry.Get(classification.Primary)
+		return s.registry.GetGeneric(), true
 	}
+	return s.registry.Get(classification.Primary), false
+}
+
+// Summarize generates a summary using the appropriate template based on classification.
+func (s *Summarizer) Summarize(ctx context.Context, client LLMClient, content string, classification *model.ClassificationResult) (*SummaryResult, error) {
+	tmpl, lowConfidence := s.selectTemplate(classification)
 
-	prompt := tmpl.BuildPrompt(content)
-	summary, err := client.Complete(prompt)
+	summary, chunks, truncated, err := s.summarize(ctx, client, tmpl, content, func(prompt string) (string, error) {
+		return client.Complete(ctx, prompt)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("LLM summarization failed: %w", err)
 	}
@@ -62,15 +94,50 @@ func (s *Summarizer) Summarize(client LLMClient, content string, classification
 		Style:         tmpl.Style,
 		LowConfidence: lowConfidence,
 		TemplateUsed:  tmpl.Category,
+		Chunks:        chunks,
+		Truncated:     truncated,
+	}, nil
+}
+
+// SummarizeStream works like Summarize but calls onToken with each fragment
+// of the summary as it is generated. Clients that cannot stream are called
+// with Complete and the whole summary is delivered as a single fragment.
+// For content that is split into chunks, only the final merge is streamed.
+func (s *Summarizer) SummarizeStream(ctx context.Context, client LLMClient, content string, classification *model.ClassificationResult, onToken func(string) error) (*SummaryResult, error) {
+	tmpl, lowConfidence := s.selectTemplate(classification)
+
+	summary, chunks, truncated, err := s.summarize(ctx, client, tmpl, content, func(prompt string) (string, error) {
+		if sc, ok := client.(StreamingLLMClient); ok {
+			return sc.Stream(ctx, prompt, onToken)
+		}
+		summary, err := client.Complete(ctx, prompt)
+		if err == nil {
+			err = onToken(summary)
+		}
+		return summary, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("LLM summarization failed: %w", err)
+	}
+
+	return &SummaryResult{
+		Summary:       summary,
+		Category:      classification.Primary,
+		Style:         tmpl.Style,
+		LowConfidence: lowConfidence,
+		TemplateUsed:  tmpl.Category,
+		Chunks:        chunks,
+		Truncated:     truncated,
 	}, nil
 }
 
 // SummarizeWithCategory generates a summary using the template for the given category directly.
-func (s *Summarizer) SummarizeWithCategory(client LLMClient, content string, category model.ContentCategory) (*SummaryResult, error) {
+func (s *Summarizer) SummarizeWithCategory(ctx context.Context, client LLMClient, content string, category model.ContentCategory) (*SummaryResult, error) {
 	tmpl := s.registry.Get(category)
 
-	prompt := tmpl.BuildPrompt(content)
-	summary, err := client.Complete(prompt)
+	summary, chunks, truncated, err := s.summarize(ctx, client, tmpl, content, func(prompt string) (string, error) {
+		return client.Complete(ctx, prompt)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("LLM summarization failed: %w", err)
 	}
@@ -80,9 +147,104 @@ func (s *Summarizer) SummarizeWithCategory(client LLMClient, content string, cat
 		Category:     category,
 		Style:        tmpl.Style,
 		TemplateUsed: tmpl.Category,
+		Chunks:       chunks,
+		Truncated:    truncated,
 	}, nil
 }
 
+// minChunkTokens keeps chunks useful when a budget leaves little room.
+const minChunkTokens = 256
+
+// summarize summarizes content with tmpl and reports how many chunks it was
+// split into and whether anything was truncated. Content that fits the
+// client's budget (see tokens.Of) is summarized in a single call. Longer
+// content is split into overlapping chunks that are noted in parallel
+// (map); while the notes are still too long for one prompt they are
+// condensed in groups, and the final call merges them into the summary
+// (reduce). Only the final call goes through final.
+func (s *Summarizer) summarize(ctx context.Context, client LLMClient, tmpl *PromptTemplate, content string, final func(prompt string) (string, error)) (string, int, bool, error) {
+	b := tokens.Of(client)
+	room := max(b.Input()-tmpl.overhead(b), minChunkTokens)
+	if s.maxChunkTokens > 0 {
+		room = min(room, s.maxChunkTokens)
+	}
+
+	if b.Count(content) <= room {
+		prompt, truncated := tmpl.BuildPrompt(content, b)
+		summary, err := final(prompt)
+		return summary, 1, truncated, err
+	}
+
+	chunks := splitChunks(content, room, s.chunkOverlap, b)
+	notes, err := mapChunks(ctx, client, tmpl, chunks)
+	if err != nil {
+		return "", len(chunks), false, err
+	}
+	for b.Count(strings.Join(notes, "\n\n")) > room {
+		groups := groupNotes(notes, room, b)
+		if len(groups) >= len(notes) {
+			break
+		}
+		if notes, err = mapChunks(ctx, client, tmpl, groups); err != nil {
+			return "", len(chunks), false, err
+		}
+	}
+
+	prompt, truncated := tmpl.BuildMergePrompt(notes, b)
+	summary, err := final(prompt)
+	return summary, len(chunks), truncated, err
+}
+
+// mapChunks asks for notes on every chunk, at most maxParallelChunks at a
+// time, and returns them in chunk order. The first failure cancels the
+// calls still running.
+func mapChunks(ctx context.Context, client LLMClient, tmpl *PromptTemplate, chunks []string) ([]string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	notes := make([]string, len(chunks))
+	errs := make([]error, len(chunks))
+	sem := make(chan struct{}, maxParallelChunks)
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			notes[i], errs[i] = client.Complete(ctx, tmpl.BuildChunkPrompt(chunk, i+1, len(chunks)))
+			if errs[i] != nil {
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Report the failure that caused the cancellation, not the calls it cut short.
+	var first error
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		err = fmt.Errorf("chunk %d/%d: %w", i+1, len(chunks), err)
+		if first == nil {
+			first = err
+		}
+		if !errors.Is(err, context.Canceled) {
+			return nil, err
+		}
+	}
+	if first != nil {
+		return nil, first
+	}
+	return notes, nil
+}
+
 // Registry returns the template registry for inspection.
 func (s *Summarizer) Registry() *TemplateRegistry {
 	return s.registry
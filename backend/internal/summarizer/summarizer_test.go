@@ -1,10 +1,14 @@
 package summarizer
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/rookiecj/scrum-agents/backend/internal/model"
+	"github.com/rookiecj/scrum-agents/backend/internal/tokens"
 )
 
 type mockLLMClient struct {
@@ -13,7 +17,7 @@ type mockLLMClient struct {
 	lastPrompt string
 }
 
-func (m *mockLLMClient) Complete(prompt string) (string, error) {
+func (m *mockLLMClient) Complete(ctx context.Context, prompt string) (string, error) {
 	m.lastPrompt = prompt
 	return m.response, m.err
 }
@@ -143,7 +147,7 @@ func TestSummarizer_Summarize(t *testing.T) {
 			client := &mockLLMClient{response: tt.llmResponse, err: tt.llmErr}
 			s := NewSummarizer(reg, 0.6)
 
-			result, err := s.Summarize(client, "test content", tt.classification)
+			result, err := s.Summarize(context.Background(), client, "test content", tt.classification)
 
 			if tt.wantErr {
 				if err == nil {
@@ -199,7 +203,7 @@ func TestSummarizer_SummarizeWithCategory(t *testing.T) {
 			client := &mockLLMClient{response: "summary text"}
 			s := NewSummarizer(reg, 0.6)
 
-			result, err := s.SummarizeWithCategory(client, "test content", tt.category)
+			result, err := s.SummarizeWithCategory(context.Background(), client, "test content", tt.category)
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
@@ -227,7 +231,7 @@ func TestSummarizer_SummarizeWithCategory_LLMError(t *testing.T) {
 	client := &mockLLMClient{err: fmt.Errorf("API error")}
 	s := NewSummarizer(reg, 0.6)
 
-	_, err = s.SummarizeWithCategory(client, "test", model.CategoryPrinciple)
+	_, err = s.SummarizeWithCategory(context.Background(), client, "test", model.CategoryPrinciple)
 	if err == nil {
 		t.Error("expected error, got nil")
 	}
@@ -248,7 +252,7 @@ func TestSummarizer_PromptContainsTemplateInstruction(t *testing.T) {
 		Confidence: 0.92,
 	}
 
-	_, err = s.Summarize(client, "TCP works by...", classification)
+	_, err = s.Summarize(context.Background(), client, "TCP works by...", classification)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -271,7 +275,7 @@ func TestNewSummarizer_DefaultThreshold(t *testing.T) {
 	// Should default to 0.6
 	client := &mockLLMClient{response: "summary"}
 
-	result, err := s.Summarize(client, "content", &model.ClassificationResult{
+	result, err := s.Summarize(context.Background(), client, "content", &model.ClassificationResult{
 		Primary:    model.CategoryPrinciple,
 		Confidence: 0.59,
 	})
@@ -283,6 +287,246 @@ func TestNewSummarizer_DefaultThreshold(t *testing.T) {
 	}
 }
 
+// mockStreamingClient is a StreamingLLMClient that emits fixed chunks.
+type mockStreamingClient struct {
+	mockLLMClient
+	chunks []string
+}
+
+func (m *mockStreamingClient) Stream(ctx context.Context, prompt string, onToken func(string) error) (string, error) {
+	m.lastPrompt = prompt
+	if m.err != nil {
+		return "", m.err
+	}
+	var full string
+	for _, c := range m.chunks {
+		if err := onToken(c); err != nil {
+			return "", err
+		}
+		full += c
+	}
+	return full, nil
+}
+
+func TestSummarizer_SummarizeStream(t *testing.T) {
+	dir := findPromptsDir(t)
+	reg, err := LoadTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadTemplates() error: %v", err)
+	}
+	s := NewSummarizer(reg, 0.6)
+	classification := &model.ClassificationResult{Primary: model.CategoryTutorial, Confidence: 0.9}
+
+	t.Run("streaming client", func(t *testing.T) {
+		client := &mockStreamingClient{chunks: []string{"## 목표", "\n", "Docker 입문"}}
+		var got []string
+		result, err := s.SummarizeStream(context.Background(), client, "content", classification, func(tok string) error {
+			got = append(got, tok)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != 3 {
+			t.Errorf("got %d tokens, want 3", len(got))
+		}
+		if result.Summary != "## 목표\nDocker 입문" {
+			t.Errorf("summary = %q", result.Summary)
+		}
+		if result.Category != model.CategoryTutorial {
+			t.Errorf("category = %q, want %q", result.Category, model.CategoryTutorial)
+		}
+	})
+
+	t.Run("non-streaming client delivers one chunk", func(t *testing.T) {
+		client := &mockLLMClient{response: "whole summary"}
+		var got []string
+		result, err := s.SummarizeStream(context.Background(), client, "content", classification, func(tok string) error {
+			got = append(got, tok)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != 1 || got[0] != "whole summary" || result.Summary != "whole summary" {
+			t.Errorf("tokens = %v, summary = %q", got, result.Summary)
+		}
+	})
+
+	t.Run("callback error aborts", func(t *testing.T) {
+		client := &mockStreamingClient{chunks: []string{"a", "b"}}
+		_, err := s.SummarizeStream(context.Background(), client, "content", classification, func(string) error {
+			return fmt.Errorf("client gone")
+		})
+		if err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+
+	t.Run("low confidence uses generic template", func(t *testing.T) {
+		client := &mockStreamingClient{chunks: []string{"x"}}
+		result, err := s.SummarizeStream(context.Background(), client, "content", &model.ClassificationResult{Primary: model.CategoryTutorial, Confidence: 0.2}, func(string) error { return nil })
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !result.LowConfidence {
+			t.Error("expected low confidence")
+		}
+	})
+}
+
+// recordingClient is a concurrency-safe LLMClient that records every prompt.
+type recordingClient struct {
+	mu      sync.Mutex
+	prompts []string
+	failOn  string // prompts containing this fail
+}
+
+func (c *recordingClient) Complete(ctx context.Context, prompt string) (string, error) {
+	c.mu.Lock()
+	c.prompts = append(c.prompts, prompt)
+	n := len(c.prompts)
+	c.mu.Unlock()
+	if c.failOn != "" && strings.Contains(prompt, c.failOn) {
+		return "", fmt.Errorf("rate limited")
+	}
+	if strings.Contains(prompt, "### 부분") {
+		return "## 최종 요약", nil
+	}
+	return fmt.Sprintf("- note %d: %s", n, strings.Repeat("요점 ", 20)), nil
+}
+
+// budgetedClient is a recordingClient that reports a token budget.
+type budgetedClient struct {
+	recordingClient
+	budget tokens.Budget
+}
+
+func (c *budgetedClient) Budget() tokens.Budget { return c.budget }
+
+func TestSummarizer_Summarize_Chunked(t *testing.T) {
+	dir := findPromptsDir(t)
+	reg, err := LoadTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadTemplates() error: %v", err)
+	}
+	classification := &model.ClassificationResult{Primary: model.CategoryTutorial, Confidence: 0.9}
+	content := strings.Repeat("이 문장은 긴 영상 자막의 일부입니다. ", 100)
+
+	t.Run("short content is one call", func(t *testing.T) {
+		s := NewSummarizer(reg, 0.6)
+		client := &recordingClient{}
+		result, err := s.Summarize(context.Background(), client, "short content", classification)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Chunks != 1 || len(client.prompts) != 1 {
+			t.Errorf("chunks = %d, calls = %d, want 1 and 1", result.Chunks, len(client.prompts))
+		}
+	})
+
+	t.Run("long content is mapped and merged", func(t *testing.T) {
+		s := NewSummarizer(reg, 0.6)
+		s.SetChunking(1000, 100)
+		client := &recordingClient{}
+		result, err := s.Summarize(context.Background(), client, content, classification)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Chunks < 2 {
+			t.Fatalf("chunks = %d, want several", result.Chunks)
+		}
+		if len(client.prompts) != result.Chunks+1 {
+			t.Errorf("calls = %d, want %d chunk calls and one merge", len(client.prompts), result.Chunks)
+		}
+		if result.Summary != "## 최종 요약" {
+			t.Errorf("summary = %q, want the merge result", result.Summary)
+		}
+		merge := client.prompts[len(client.prompts)-1]
+		for i := 1; i <= result.Chunks; i++ {
+			if !strings.Contains(merge, fmt.Sprintf("### 부분 %d/%d", i, result.Chunks)) {
+				t.Errorf("merge prompt is missing part %d", i)
+			}
+		}
+		if !strings.Contains(merge, reg.Get(model.CategoryTutorial).Instruction) {
+			t.Error("merge prompt should carry the category instruction")
+		}
+	})
+
+	t.Run("long notes are condensed before merging", func(t *testing.T) {
+		s := NewSummarizer(reg, 0.6)
+		s.SetChunking(200, 20)
+		client := &recordingClient{}
+		result, err := s.Summarize(context.Background(), client, content, classification)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(client.prompts) <= result.Chunks+1 {
+			t.Errorf("calls = %d for %d chunks, want extra condensing calls", len(client.prompts), result.Chunks)
+		}
+		merge := client.prompts[len(client.prompts)-1]
+		if strings.Count(merge, "### 부분") >= result.Chunks {
+			t.Error("merge prompt should combine condensed notes, not every chunk's notes")
+		}
+	})
+
+	t.Run("client budget sizes chunks", func(t *testing.T) {
+		s := NewSummarizer(reg, 0.6)
+		large := &budgetedClient{budget: tokens.Budget{ContextWindow: 200000, OutputReserve: 4096}}
+		result, err := s.Summarize(context.Background(), large, content, classification)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Chunks != 1 || result.Truncated {
+			t.Errorf("large window: chunks = %d, truncated = %v, want 1 chunk", result.Chunks, result.Truncated)
+		}
+
+		small := &budgetedClient{budget: tokens.Budget{ContextWindow: 2048, OutputReserve: 1024}}
+		result, err = s.Summarize(context.Background(), small, content, classification)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Chunks < 2 {
+			t.Errorf("small window: chunks = %d, want several", result.Chunks)
+		}
+		for _, p := range small.prompts {
+			if n := small.budget.Count(p); n > small.budget.Input() {
+				t.Errorf("prompt of %d tokens exceeds the budget of %d", n, small.budget.Input())
+			}
+		}
+	})
+
+	t.Run("chunk failure fails the summary", func(t *testing.T) {
+		s := NewSummarizer(reg, 0.6)
+		s.SetChunking(1000, 100)
+		client := &recordingClient{failOn: "2번째"}
+		_, err := s.Summarize(context.Background(), client, content, classification)
+		if err == nil || !strings.Contains(err.Error(), "rate limited") {
+			t.Errorf("error = %v, want the chunk failure", err)
+		}
+	})
+
+	t.Run("stream only streams the merge", func(t *testing.T) {
+		s := NewSummarizer(reg, 0.6)
+		s.SetChunking(1000, 100)
+		client := &mockStreamingClient{mockLLMClient: mockLLMClient{response: "- note"}, chunks: []string{"## 최종", " 요약"}}
+		var got []string
+		result, err := s.SummarizeStream(context.Background(), client, content, classification, func(tok string) error {
+			got = append(got, tok)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Summary != "## 최종 요약" || len(got) != 2 {
+			t.Errorf("summary = %q, tokens = %q", result.Summary, got)
+		}
+		if !strings.Contains(client.lastPrompt, "### 부분") {
+			t.Error("streamed prompt should be the merge prompt")
+		}
+	})
+}
+
 func containsStr(s, sub string) bool {
 	for i := 0; i <= len(s)-len(sub); i++ {
 		if s[i:i+len(sub)] == sub {
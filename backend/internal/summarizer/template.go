@@ -1,6 +1,8 @@
 package summarizer
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -8,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/rookiecj/scrum-agents/backend/internal/model"
+	"github.com/rookiecj/scrum-agents/backend/internal/tokens"
 )
 
 // PromptTemplate defines a category-specific summarization prompt.
@@ -102,19 +105,108 @@ func (r *TemplateRegistry) Categories() []model.ContentCategory {
 	return cats
 }
 
+// Version returns a short hash of the loaded templates. It changes whenever a
+// template does, so results built from older prompts can be told apart.
+func (r *TemplateRegistry) Version() string {
+	h := sha256.New()
+	for _, cat := range model.AllCategories() {
+		data, _ := json.Marshal(r.templates[cat])
+		h.Write(data)
+	}
+	data, _ := json.Marshal(r.generic)
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// truncatedNote marks content that was cut to fit the prompt.
+const truncatedNote = "\n\n... (내용이 잘렸습니다)"
+
 // BuildPrompt constructs the full LLM prompt from a template and content.
-func (t *PromptTemplate) BuildPrompt(content string) string {
+// Content is cut on a sentence boundary to fit the input budget b, and the
+// second result reports whether it was.
+func (t *PromptTemplate) BuildPrompt(content string, b tokens.Budget) (string, bool) {
+	room := b.Input() - b.Count(t.buildPrompt("")) - b.Count(truncatedNote)
+	content, truncated := b.Fit(content, room)
+	if truncated {
+		content += truncatedNote
+	}
+	return t.buildPrompt(content), truncated
+}
+
+func (t *PromptTemplate) buildPrompt(content string) string {
 	var sb strings.Builder
 	sb.WriteString("당신은 전문 콘텐츠 요약기입니다.\n\n")
 	sb.WriteString(fmt.Sprintf("요약 스타일: %s\n\n", t.Style))
 	sb.WriteString(t.Instruction)
 	sb.WriteString("\n\n---\n\n")
-	sb.WriteString(truncateContent(content, 6000))
+	sb.WriteString(content)
 	sb.WriteString("\n\n---\n\n")
 	sb.WriteString("위 글을 한국어로 요약하세요. 마크다운 형식으로 작성하세요.")
 	return sb.String()
 }
 
+// BuildChunkPrompt constructs the prompt for one part of content that is too
+// long for a single prompt. It asks for notes on the part rather than a
+// finished summary; BuildMergePrompt combines the notes of all parts.
+func (t *PromptTemplate) BuildChunkPrompt(chunk string, index, total int) string {
+	var sb strings.Builder
+	sb.WriteString("당신은 전문 콘텐츠 요약기입니다.\n\n")
+	sb.WriteString(fmt.Sprintf("아래는 긴 글을 %d개로 나눈 부분 중 %d번째입니다. ", total, index))
+	sb.WriteString("인접한 부분과 내용이 일부 겹칠 수 있습니다.\n")
+	if len(t.Sections) > 0 {
+		sb.WriteString(fmt.Sprintf("최종 요약은 다음 섹션으로 작성됩니다: %s\n", strings.Join(t.Sections, ", ")))
+	}
+	sb.WriteString("\n---\n\n")
+	sb.WriteString(chunk)
+	sb.WriteString("\n\n---\n\n")
+	sb.WriteString("이 부분의 핵심 내용을 한국어 글머리표로 정리하세요. ")
+	sb.WriteString("최종 요약에 필요한 사실, 수치, 주장, 예시를 빠뜨리지 마세요.")
+	return sb.String()
+}
+
+// BuildMergePrompt constructs the prompt that turns the notes made by
+// BuildChunkPrompt, in content order, into the final summary. If the notes
+// do not fit the input budget b, each is cut to an equal share of it and the
+// second result is true.
+func (t *PromptTemplate) BuildMergePrompt(notes []string, b tokens.Budget) (string, bool) {
+	prompt := t.buildMergePrompt(notes)
+	if b.Count(prompt) <= b.Input() || len(notes) == 0 {
+		return prompt, false
+	}
+	share := (b.Input() - b.Count(t.buildMergePrompt(make([]string, len(notes))))) / len(notes)
+	cut := make([]string, len(notes))
+	for i, n := range notes {
+		cut[i], _ = b.Fit(n, share)
+	}
+	return t.buildMergePrompt(cut), true
+}
+
+func (t *PromptTemplate) buildMergePrompt(notes []string) string {
+	var sb strings.Builder
+	sb.WriteString("당신은 전문 콘텐츠 요약기입니다.\n\n")
+	sb.WriteString(fmt.Sprintf("요약 스타일: %s\n\n", t.Style))
+	sb.WriteString(t.Instruction)
+	sb.WriteString("\n\n아래는 긴 글을 여러 부분으로 나누어 순서대로 정리한 내용입니다.\n\n---\n\n")
+	for i, n := range notes {
+		sb.WriteString(fmt.Sprintf("### 부분 %d/%d\n\n", i+1, len(notes)))
+		sb.WriteString(n)
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString("---\n\n")
+	sb.WriteString("부분 사이에 겹치는 내용은 한 번만 다루고, 글 전체를 한국어로 요약하세요. 마크다운 형식으로 작성하세요.")
+	return sb.String()
+}
+
+// promptSlack covers the part of chunk prompts that varies, such as the
+// part numbers.
+const promptSlack = 32
+
+// overhead returns the tokens a summary or chunk prompt spends besides the
+// content.
+func (t *PromptTemplate) overhead(b tokens.Budget) int {
+	return max(b.Count(t.buildPrompt("")), b.Count(t.BuildChunkPrompt("", 0, 0))) + promptSlack
+}
+
 func loadTemplateFile(path string) (*PromptTemplate, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -128,10 +220,3 @@ func loadTemplateFile(path string) (*PromptTemplate, error) {
 
 	return &tmpl, nil
 }
-
-func truncateContent(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	return s[:maxLen] + "\n\n... (내용이 잘렸습니다)"
-}
@@ -5,8 +5,10 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/rookiecj/scrum-agents/backend/internal/model"
+	"github.com/rookiecj/scrum-agents/backend/internal/tokens"
 )
 
 func TestLoadTemplates(t *testing.T) {
@@ -53,6 +55,22 @@ func TestTemplateRegistry_Validate(t *testing.T) {
 	}
 }
 
+func TestTemplateRegistry_Version(t *testing.T) {
+	reg, err := LoadTemplates(findPromptsDir(t))
+	if err != nil {
+		t.Fatalf("LoadTemplates() error: %v", err)
+	}
+	before := reg.Version()
+	if before == "" || reg.Version() != before {
+		t.Fatalf("Version() = %q, want a stable hash", before)
+	}
+
+	reg.Get(model.CategoryNews).Instruction += " 한 문장 더."
+	if reg.Version() == before {
+		t.Error("Version() did not change after a template edit")
+	}
+}
+
 func TestTemplateRegistry_Validate_MissingTemplate(t *testing.T) {
 	reg := &TemplateRegistry{
 		templates: make(map[model.ContentCategory]*PromptTemplate),
@@ -180,7 +198,10 @@ func TestPromptTemplate_BuildPrompt(t *testing.T) {
 	}
 
 	content := "This is a test article about how TCP works."
-	prompt := tmpl.BuildPrompt(content)
+	prompt, truncated := tmpl.BuildPrompt(content, tokens.Default)
+	if truncated {
+		t.Error("short content should not be truncated")
+	}
 
 	// Check prompt contains key elements
 	checks := []string{
@@ -205,37 +226,77 @@ func TestPromptTemplate_BuildPrompt_Truncation(t *testing.T) {
 		Sections:    []string{"요약"},
 		Instruction: "요약하세요.",
 	}
+	budget := tokens.Budget{ContextWindow: 1000, OutputReserve: 500, Estimator: tokens.DefaultEstimator}
 
-	longContent := strings.Repeat("a", 7000)
-	prompt := tmpl.BuildPrompt(longContent)
-
-	if !strings.Contains(prompt, "내용이 잘렸습니다") {
-		t.Error("long content should be truncated")
-	}
-}
-
-func TestTruncateContent(t *testing.T) {
 	tests := []struct {
-		name   string
-		input  string
-		maxLen int
-		want   string
+		name    string
+		content string
+		want    string // content must end with this before the note
 	}{
-		{"short", "hello", 10, "hello"},
-		{"exact", "hello", 5, "hello"},
-		{"truncated", "hello world", 5, "hello\n\n... (내용이 잘렸습니다)"},
+		{"sentences", strings.Repeat("문장이 여기서 끝납니다. ", 200), "끝납니다."},
+		{"no boundaries", strings.Repeat("가", 2000), "가"},
+		{"latin", strings.Repeat("The end of a sentence. ", 500), "sentence."},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := truncateContent(tt.input, tt.maxLen)
-			if got != tt.want {
-				t.Errorf("truncateContent() = %q, want %q", got, tt.want)
+			prompt, truncated := tmpl.BuildPrompt(tt.content, budget)
+			if !truncated {
+				t.Fatal("long content should be truncated")
+			}
+			if !utf8.ValidString(prompt) {
+				t.Error("prompt is not valid UTF-8")
+			}
+			if n := budget.Count(prompt); n > budget.Input() {
+				t.Errorf("prompt is %d tokens, budget %d", n, budget.Input())
+			}
+			if !strings.Contains(prompt, tt.want+truncatedNote) {
+				t.Errorf("content should be cut after %q", tt.want)
 			}
 		})
 	}
 }
 
+func TestPromptTemplate_BuildPrompt_UsesBudget(t *testing.T) {
+	tmpl := &PromptTemplate{Category: "generic", Style: "일반 요약", Instruction: "요약하세요."}
+	content := strings.Repeat("한국어 문장입니다. ", 2000) // ~20k tokens
+
+	if _, truncated := tmpl.BuildPrompt(content, tokens.Default); !truncated {
+		t.Error("content should not fit the default budget")
+	}
+	large := tokens.Budget{ContextWindow: 200000, OutputReserve: 4096, Estimator: tokens.DefaultEstimator}
+	if _, truncated := tmpl.BuildPrompt(content, large); truncated {
+		t.Error("content should fit a large context window whole")
+	}
+}
+
+func TestPromptTemplate_BuildMergePrompt(t *testing.T) {
+	tmpl := &PromptTemplate{Category: "generic", Style: "일반 요약", Instruction: "요약하세요."}
+	notes := []string{"- 첫째", "- 둘째", "- 셋째"}
+
+	prompt, truncated := tmpl.BuildMergePrompt(notes, tokens.Default)
+	if truncated {
+		t.Error("short notes should not be truncated")
+	}
+	for _, want := range []string{"### 부분 1/3", "- 첫째", "### 부분 3/3", "- 셋째", "요약하세요."} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("merge prompt should contain %q", want)
+		}
+	}
+
+	long := []string{strings.Repeat("긴 메모입니다. ", 1000), strings.Repeat("또 다른 메모. ", 1000)}
+	prompt, truncated = tmpl.BuildMergePrompt(long, tokens.Default)
+	if !truncated {
+		t.Error("long notes should be truncated")
+	}
+	if n := tokens.Default.Count(prompt); n > tokens.Default.Input() {
+		t.Errorf("merge prompt is %d tokens, budget %d", n, tokens.Default.Input())
+	}
+	if !strings.Contains(prompt, "### 부분 2/2") {
+		t.Error("every part should keep its share")
+	}
+}
+
 func TestLoadTemplateFile_InvalidJSON(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "bad.json")
@@ -0,0 +1,156 @@
+// Package tokens estimates how much text fits in a model's context window
+// and cuts text to fit.
+//
+// Counts are estimates: providers do not expose their tokenizers, and an
+// exact count is not needed to keep a prompt within bounds. Estimators err
+// on the high side, so text that fits by estimate fits in practice.
+package tokens
+
+import (
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// Estimator estimates token counts from character counts. Latin-script text
+// tokenizes at several characters per token, while Hangul, CJK and other
+// scripts encoded in three or more UTF-8 bytes take a token or more per
+// character.
+type Estimator struct {
+	// CharsPerToken is the average number of narrow characters (those
+	// encoded in one or two UTF-8 bytes) per token.
+	CharsPerToken float64
+	// TokensPerWideRune is the average number of tokens per wide character.
+	TokensPerWideRune float64
+}
+
+// Count returns the estimated number of tokens in s. The zero Estimator
+// counts like DefaultEstimator.
+func (e Estimator) Count(s string) int {
+	e = e.orDefault()
+	var narrow, wide int
+	for _, r := range s {
+		if r < 0x800 {
+			narrow++
+		} else {
+			wide++
+		}
+	}
+	n := float64(narrow)/e.CharsPerToken + float64(wide)*e.TokensPerWideRune
+	return int(math.Ceil(n))
+}
+
+func (e Estimator) orDefault() Estimator {
+	if e.CharsPerToken <= 0 || e.TokensPerWideRune <= 0 {
+		return DefaultEstimator
+	}
+	return e
+}
+
+// DefaultEstimator is used for models without a specific estimator. It
+// overestimates for every tokenizer in common use.
+var DefaultEstimator = Estimator{CharsPerToken: 3.5, TokensPerWideRune: 1.2}
+
+// Budget is the number of tokens a model accepts per request.
+type Budget struct {
+	// ContextWindow is the total number of tokens in the model's context.
+	ContextWindow int
+	// OutputReserve is the part of the window kept free for the response.
+	OutputReserve int
+	// Estimator counts tokens for the model.
+	Estimator Estimator
+}
+
+// Default is the budget assumed for clients that do not report one. It is
+// small enough for any model in use.
+var Default = Budget{
+	ContextWindow: 8192,
+	OutputReserve: 4096,
+	Estimator:     DefaultEstimator,
+}
+
+// Input returns the number of tokens available for the prompt.
+func (b Budget) Input() int {
+	return max(b.ContextWindow-b.OutputReserve, 0)
+}
+
+// Count returns the estimated number of tokens in s.
+func (b Budget) Count(s string) int {
+	return b.Estimator.Count(s)
+}
+
+// Fit returns s cut to at most n tokens, and whether it was cut. The cut is
+// made at a sentence, line or word boundary where one is close to the
+// limit, and never inside a UTF-8 sequence.
+func (b Budget) Fit(s string, n int) (string, bool) {
+	cut := b.Cut(s, n)
+	if cut == len(s) {
+		return s, false
+	}
+	return strings.TrimSpace(s[:cut]), true
+}
+
+// Cut returns the length of the prefix of s that Fit keeps for n tokens.
+func (b Budget) Cut(s string, n int) int {
+	// Counts grow with the prefix, so the longest prefix that fits can be
+	// found by bisection over byte offsets, up to the most bytes n tokens
+	// can span.
+	lo, hi := 0, min(len(s), b.maxBytes(n))
+	if hi == len(s) && b.Count(s) <= n {
+		return len(s)
+	}
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if b.Count(s[:mid]) <= n {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	return Boundary(s, lo)
+}
+
+// maxBytes returns an upper bound on the bytes of text n tokens can span.
+func (b Budget) maxBytes(n int) int {
+	e := b.Estimator.orDefault()
+	perToken := max(2*e.CharsPerToken, 4/e.TokensPerWideRune)
+	return int(float64(n)*perToken) + utf8.UTFMax
+}
+
+// Budgeted is implemented by LLM clients that know their budget.
+type Budgeted interface {
+	Budget() Budget
+}
+
+// Of returns the budget reported by client, or Default if it reports none.
+func Of(client any) Budget {
+	if b, ok := client.(Budgeted); ok {
+		return b.Budget()
+	}
+	return Default
+}
+
+// breaks are the places text may be cut, strongest first.
+var breaks = []string{"\n\n", "\n", ". ", "? ", "! ", "。", " "}
+
+// Boundary returns the offset at or before limit where s[:limit] is best
+// cut: at the last paragraph, line, sentence or word break in its second
+// half, or else at the last rune boundary. Whitespace after the cut is left
+// to the remainder.
+func Boundary(s string, limit int) int {
+	if limit >= len(s) {
+		return len(s)
+	}
+	// A break whose trailing whitespace lies just past limit still counts.
+	window := s[:limit+1]
+	for _, sep := range breaks {
+		i := strings.LastIndex(window, sep)
+		if cut := i + len(strings.TrimRight(sep, " \n")); i >= limit/2 && cut <= limit {
+			return cut
+		}
+	}
+	for limit > 0 && !utf8.RuneStart(s[limit]) {
+		limit--
+	}
+	return limit
+}
@@ -0,0 +1,107 @@
+package tokens
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEstimator_Count(t *testing.T) {
+	e := Estimator{CharsPerToken: 4, TokensPerWideRune: 1}
+	tests := []struct {
+		name string
+		in   string
+		want int
+	}{
+		{"empty", "", 0},
+		{"latin", "abcdefgh", 2},
+		{"rounds up", "abcde", 2},
+		{"accented latin is narrow", "café", 1},
+		{"hangul", "안녕하세요", 5},
+		{"mixed", "Go 언어", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := e.Count(tt.in); got != tt.want {
+				t.Errorf("Count(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEstimator_ZeroValueUsesDefault(t *testing.T) {
+	s := "요약할 내용 and some English"
+	if got, want := (Estimator{}).Count(s), DefaultEstimator.Count(s); got != want {
+		t.Errorf("zero Estimator counted %d, want %d", got, want)
+	}
+}
+
+func TestBudget_Input(t *testing.T) {
+	if got := (Budget{ContextWindow: 8000, OutputReserve: 2000}).Input(); got != 6000 {
+		t.Errorf("Input() = %d, want 6000", got)
+	}
+	if got := (Budget{ContextWindow: 1000, OutputReserve: 2000}).Input(); got != 0 {
+		t.Errorf("Input() = %d, want 0 for an overcommitted budget", got)
+	}
+}
+
+func TestBudget_Fit(t *testing.T) {
+	b := Budget{Estimator: Estimator{CharsPerToken: 1, TokensPerWideRune: 1}}
+	tests := []struct {
+		name          string
+		in            string
+		n             int
+		want          string
+		wantTruncated bool
+	}{
+		{"fits", "short text", 100, "short text", false},
+		{"sentence", "First sentence. Second sentence. Third", 36, "First sentence. Second sentence.", true},
+		{"paragraph", "Para one is here.\n\nPara two. More", 30, "Para one is here.", true},
+		{"korean sentence", "첫 문장입니다. 두 번째 문장입니다. 세 번째", 20, "첫 문장입니다. 두 번째 문장입니다.", true},
+		{"word", "one two three four five", 15, "one two three", true},
+		{"no boundary", "가나다라마바사아자차", 4, "가나다라", true},
+		{"zero", "text", 0, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, truncated := b.Fit(tt.in, tt.n)
+			if got != tt.want || truncated != tt.wantTruncated {
+				t.Errorf("Fit(%q, %d) = %q, %v; want %q, %v", tt.in, tt.n, got, truncated, tt.want, tt.wantTruncated)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("Fit returned invalid UTF-8: %q", got)
+			}
+			if b.Count(got) > tt.n {
+				t.Errorf("Fit returned %d tokens, limit %d", b.Count(got), tt.n)
+			}
+		})
+	}
+}
+
+func TestBudget_Fit_Long(t *testing.T) {
+	s := strings.Repeat("긴 한국어 문장과 English words 가 섞여 있습니다. ", 5000)
+	got, truncated := Default.Fit(s, 1000)
+	if !truncated {
+		t.Fatal("expected truncation")
+	}
+	if n := Default.Count(got); n > 1000 || n < 900 {
+		t.Errorf("Fit kept %d tokens, want close to 1000", n)
+	}
+	if !strings.HasSuffix(got, "있습니다.") {
+		t.Errorf("Fit should cut at a sentence boundary, got ...%q", got[len(got)-20:])
+	}
+}
+
+type budgeted struct{ b Budget }
+
+func (c budgeted) Budget() Budget { return c.b }
+
+func TestOf(t *testing.T) {
+	if got := Of(struct{}{}); got != Default {
+		t.Errorf("Of(plain) = %+v, want Default", got)
+	}
+	want := Budget{ContextWindow: 100000, OutputReserve: 1000}
+	if got := Of(budgeted{want}); got != want {
+		t.Errorf("Of(budgeted) = %+v, want %+v", got, want)
+	}
+}
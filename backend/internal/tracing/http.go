@@ -0,0 +1,111 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/logging"
+)
+
+// TraceparentHeader is the W3C Trace Context header.
+const TraceparentHeader = "traceparent"
+
+// statusWriter wraps http.ResponseWriter to capture the status code.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sw *statusWriter) WriteHeader(code int) {
+	sw.status = code
+	sw.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap returns the wrapped writer so http.ResponseController can reach
+// optional interfaces such as http.Flusher for streaming responses.
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
+
+// Middleware returns an HTTP middleware that wraps each request in a server
+// span, continuing the trace of an incoming traceparent header. The span is
+// named after the route once next has served the request, so next must be
+// the *http.ServeMux or reach it with the request unchanged. The trace ID is
+// attached to the request's log records (see logging.AddAttrs), so it must
+// run inside logging.Middleware.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !Enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		if traceID, spanID, ok := parseTraceparent(r.Header.Get(TraceparentHeader)); ok {
+			ctx = context.WithValue(ctx, remoteKey{}, remoteParent{traceID, spanID})
+		}
+		ctx, span := start(ctx, r.Method, KindServer, []slog.Attr{
+			slog.String("http.method", r.Method),
+			slog.String("http.target", r.URL.Path),
+		})
+		defer span.End()
+		if id := logging.RequestID(ctx); id != "" {
+			span.SetAttrs(slog.String("request_id", id))
+		}
+		logging.AddAttrs(ctx, slog.String("trace_id", span.TraceID().String()))
+
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		r = r.WithContext(ctx)
+		next.ServeHTTP(sw, r)
+
+		if r.Pattern != "" {
+			span.SetName(r.Pattern)
+			span.SetAttrs(slog.String("http.route", routeOf(r.Pattern)))
+		}
+		span.SetAttrs(slog.Int("http.status_code", sw.status))
+		if sw.status >= http.StatusInternalServerError {
+			span.RecordError(errorStatus(sw.status))
+		}
+	})
+}
+
+type errorStatus int
+
+func (e errorStatus) Error() string { return http.StatusText(int(e)) }
+
+// routeOf strips the method from a ServeMux pattern.
+func routeOf(pattern string) string {
+	if i := strings.IndexByte(pattern, ' '); i >= 0 {
+		return strings.TrimLeft(pattern[i:], " \t")
+	}
+	return pattern
+}
+
+// Inject sets the traceparent header of an outgoing request to the span in
+// ctx, so the receiving service can continue the trace. It does nothing
+// when ctx carries no span.
+func Inject(ctx context.Context, h http.Header) {
+	if s := FromContext(ctx); s != nil {
+		h.Set(TraceparentHeader, "00-"+s.TraceID().String()+"-"+s.SpanID().String()+"-01")
+	}
+}
+
+// parseTraceparent parses a version 00 traceparent header.
+func parseTraceparent(v string) (TraceID, SpanID, bool) {
+	var traceID TraceID
+	var spanID SpanID
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) != 4 || parts[0] != "00" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return traceID, spanID, false
+	}
+	if _, err := hex.Decode(traceID[:], []byte(parts[1])); err != nil {
+		return traceID, spanID, false
+	}
+	if _, err := hex.Decode(spanID[:], []byte(parts[2])); err != nil {
+		return traceID, spanID, false
+	}
+	return traceID, spanID, traceID.IsValid() && spanID.IsValid()
+}
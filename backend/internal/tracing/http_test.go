@@ -0,0 +1,108 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/logging"
+)
+
+func TestMiddleware(t *testing.T) {
+	m := capture(t)
+
+	var buf bytes.Buffer
+	orig := slog.Default()
+	slog.SetDefault(slog.New(logging.ContextHandler(slog.NewJSONHandler(&buf, nil))))
+	defer slog.SetDefault(orig)
+
+	var outgoing http.Header
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, span := Start(r.Context(), "work")
+		span.End()
+		outgoing = http.Header{}
+		Inject(r.Context(), outgoing)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	handler := logging.Middleware(Middleware(mux))
+
+	req := httptest.NewRequest("POST", "/api/items/7", nil)
+	req.Header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set(logging.RequestIDHeader, "req-1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := m.records()
+	if len(spans) != 2 {
+		t.Fatalf("exported %d spans, want 2", len(spans))
+	}
+	work, server := spans[0], spans[1]
+	if server.Name != "POST /api/items/{id}" || server.Kind != KindServer {
+		t.Errorf("server span = %q (%s)", server.Name, server.Kind)
+	}
+	if server.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || server.ParentID.String() != "00f067aa0ba902b7" {
+		t.Errorf("server span did not continue the trace: %s / %s", server.TraceID, server.ParentID)
+	}
+	if work.ParentID != server.SpanID {
+		t.Errorf("work span parent = %s, want %s", work.ParentID, server.SpanID)
+	}
+	if server.Error == "" {
+		t.Error("5xx response not recorded as an error")
+	}
+	attrs := map[string]string{}
+	for _, a := range server.Attrs {
+		attrs[a.Key] = a.Value.String()
+	}
+	if attrs["http.route"] != "/api/items/{id}" || attrs["http.status_code"] != "502" || attrs["request_id"] != "req-1" {
+		t.Errorf("server attrs = %v", attrs)
+	}
+
+	want := "00-4bf92f3577b34da6a3ce929d0e0e4736-" + server.SpanID.String() + "-01"
+	if got := outgoing.Get(TraceparentHeader); got != want {
+		t.Errorf("injected traceparent = %q, want %q", got, want)
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("decoding log line: %v", err)
+	}
+	if entry["trace_id"] != server.TraceID.String() {
+		t.Errorf("logged trace_id = %v, want %s", entry["trace_id"], server.TraceID)
+	}
+}
+
+func TestMiddleware_Disabled(t *testing.T) {
+	prev := SetExporter(nil)
+	defer SetExporter(prev)
+
+	called := false
+	Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = FromContext(r.Context()) == nil
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if !called {
+		t.Error("handler not called without a span")
+	}
+}
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		header string
+		ok     bool
+	}{
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true},
+		{"", false},
+		{"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false},
+		{"00-00000000000000000000000000000000-00f067aa0ba902b7-01", false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e47zz-00f067aa0ba902b7-01", false},
+		{"00-4bf92f-00f067aa0ba902b7-01", false},
+	}
+	for _, tt := range tests {
+		if _, _, ok := parseTraceparent(tt.header); ok != tt.ok {
+			t.Errorf("parseTraceparent(%q) ok = %v, want %v", tt.header, ok, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogExporter writes each span as a "trace: span" record to a logger, so
+// traces can be read from the JSON logs without a collector.
+type LogExporter struct {
+	logger *slog.Logger
+}
+
+// NewLogExporter returns an exporter logging to logger, or to the default
+// logger if logger is nil.
+func NewLogExporter(logger *slog.Logger) *LogExporter {
+	return &LogExporter{logger: logger}
+}
+
+// ExportSpan logs s at Info level, or at Warn level if it failed.
+func (e *LogExporter) ExportSpan(s Record) {
+	logger := e.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	attrs := []slog.Attr{
+		slog.String("trace_id", s.TraceID.String()),
+		slog.String("span_id", s.SpanID.String()),
+	}
+	if s.ParentID.IsValid() {
+		attrs = append(attrs, slog.String("parent_span_id", s.ParentID.String()))
+	}
+	attrs = append(attrs,
+		slog.String("span", s.Name),
+		slog.String("kind", s.Kind.String()),
+		slog.Time("start", s.Start),
+		slog.Float64("duration_ms", float64(s.End.Sub(s.Start).Nanoseconds())/1e6),
+	)
+	level := slog.LevelInfo
+	if s.Error != "" {
+		level = slog.LevelWarn
+		attrs = append(attrs, slog.String("error", s.Error))
+	}
+	if len(s.Attrs) > 0 {
+		attrs = append(attrs, slog.Attr{Key: "attrs", Value: slog.GroupValue(s.Attrs...)})
+	}
+	// The span carries its own IDs; a background context keeps the request
+	// attributes of the caller from being added twice.
+	logger.LogAttrs(context.Background(), level, "trace: span", attrs...)
+}
+
+// Shutdown does nothing; records are written as spans end.
+func (e *LogExporter) Shutdown(context.Context) error {
+	return nil
+}
@@ -0,0 +1,50 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestLogExporter(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewLogExporter(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	start := time.Now()
+	e.ExportSpan(Record{
+		TraceID:  TraceID{1},
+		SpanID:   SpanID{2},
+		ParentID: SpanID{3},
+		Name:     "llm.complete",
+		Kind:     KindInternal,
+		Start:    start,
+		End:      start.Add(1500 * time.Millisecond),
+		Attrs:    []slog.Attr{slog.String("llm.provider", "claude")},
+		Error:    "rate limited",
+	})
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"msg":            "trace: span",
+		"level":          "WARN",
+		"trace_id":       "01000000000000000000000000000000",
+		"span_id":        "0200000000000000",
+		"parent_span_id": "0300000000000000",
+		"span":           "llm.complete",
+		"duration_ms":    float64(1500),
+		"error":          "rate limited",
+	}
+	for k, v := range want {
+		if entry[k] != v {
+			t.Errorf("%s = %v, want %v", k, entry[k], v)
+		}
+	}
+	if attrs, _ := entry["attrs"].(map[string]any); attrs["llm.provider"] != "claude" {
+		t.Errorf("attrs = %v", entry["attrs"])
+	}
+}
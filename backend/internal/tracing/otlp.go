@@ -0,0 +1,285 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultOTLPEndpoint is the OTLP/HTTP endpoint of a local collector.
+const DefaultOTLPEndpoint = "http://localhost:4318"
+
+// OTLPOptions configures an OTLPExporter.
+type OTLPOptions struct {
+	// Endpoint is the collector's base URL; "/v1/traces" is appended
+	// unless it is already there. Defaults to DefaultOTLPEndpoint.
+	Endpoint string
+	// Headers are sent with every export, e.g. for authentication.
+	Headers map[string]string
+	// ServiceName is reported as the service.name resource attribute.
+	ServiceName string
+	// BatchSize is the number of spans sent per export. Defaults to 256.
+	BatchSize int
+	// Interval is the longest a span waits before being sent. Defaults to
+	// 5 seconds.
+	Interval time.Duration
+	// Client sends the exports. Defaults to a client with a 10 second
+	// timeout.
+	Client *http.Client
+}
+
+// OTLPExporter sends spans in batches to an OpenTelemetry collector over
+// OTLP/HTTP with JSON encoding. Spans that arrive while the queue is full
+// are dropped rather than slowing requests down.
+type OTLPExporter struct {
+	opts  OTLPOptions
+	url   string
+	queue chan Record
+	stop  chan struct{}
+	done  chan struct{}
+	once  sync.Once
+}
+
+// NewOTLPExporter starts an exporter sending to opts.Endpoint.
+func NewOTLPExporter(opts OTLPOptions) *OTLPExporter {
+	if opts.Endpoint == "" {
+		opts.Endpoint = DefaultOTLPEndpoint
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 256
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = 5 * time.Second
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	url := strings.TrimSuffix(opts.Endpoint, "/")
+	if !strings.HasSuffix(url, "/v1/traces") {
+		url += "/v1/traces"
+	}
+	e := &OTLPExporter{
+		opts:  opts,
+		url:   url,
+		queue: make(chan Record, 8*opts.BatchSize),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+// ExportSpan queues s for the next batch.
+func (e *OTLPExporter) ExportSpan(s Record) {
+	select {
+	case e.queue <- s:
+	case <-e.stop:
+	default:
+		slog.Warn("tracing: export queue full, dropping span", slog.String("span", s.Name))
+	}
+}
+
+// Shutdown sends the queued spans and stops the exporter, giving up when
+// ctx is done.
+func (e *OTLPExporter) Shutdown(ctx context.Context) error {
+	e.once.Do(func() { close(e.stop) })
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *OTLPExporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(e.opts.Interval)
+	defer ticker.Stop()
+
+	var batch []Record
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.send(batch); err != nil {
+			slog.Warn("tracing: export failed",
+				slog.Int("spans", len(batch)),
+				slog.String("error", err.Error()),
+			)
+		}
+		batch = nil
+	}
+	for {
+		select {
+		case s := <-e.queue:
+			if batch = append(batch, s); len(batch) >= e.opts.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-e.stop:
+			for {
+				select {
+				case s := <-e.queue:
+					if batch = append(batch, s); len(batch) >= e.opts.BatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (e *OTLPExporter) send(batch []Record) error {
+	body, err := json.Marshal(encodeOTLP(e.opts.ServiceName, batch))
+	if err != nil {
+		return fmt.Errorf("encode spans: %w", err)
+	}
+	req, err := http.NewRequest("POST", e.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.opts.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := e.opts.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("collector returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// The types below are the parts of the OTLP JSON encoding we produce. IDs
+// are hex and timestamps are decimal strings of Unix nanoseconds.
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              Kind           `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            *otlpStatus    `json:"status,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"` // 2 is STATUS_CODE_ERROR
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+// scopeName is the instrumentation scope reported with every span.
+const scopeName = "github.com/rookiecj/scrum-agents/backend"
+
+func encodeOTLP(service string, batch []Record) otlpRequest {
+	if service == "" {
+		service = "scrum-agents"
+	}
+	spans := make([]otlpSpan, len(batch))
+	for i, s := range batch {
+		span := otlpSpan{
+			TraceID:           s.TraceID.String(),
+			SpanID:            s.SpanID.String(),
+			Name:              s.Name,
+			Kind:              s.Kind,
+			StartTimeUnixNano: strconv.FormatInt(s.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.End.UnixNano(), 10),
+			Attributes:        otlpAttributes("", s.Attrs),
+		}
+		if s.ParentID.IsValid() {
+			span.ParentSpanID = s.ParentID.String()
+		}
+		if s.Error != "" {
+			span.Status = &otlpStatus{Code: 2, Message: s.Error}
+		}
+		spans[i] = span
+	}
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: otlpAttributes("", []slog.Attr{slog.String("service.name", service)})},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: scopeName}, Spans: spans}},
+	}}}
+}
+
+// otlpAttributes converts attrs, flattening groups into dotted keys.
+func otlpAttributes(prefix string, attrs []slog.Attr) []otlpKeyValue {
+	var out []otlpKeyValue
+	for _, a := range attrs {
+		key := prefix + a.Key
+		v := a.Value.Resolve()
+		var ov otlpValue
+		switch v.Kind() {
+		case slog.KindGroup:
+			out = append(out, otlpAttributes(key+".", v.Group())...)
+			continue
+		case slog.KindBool:
+			b := v.Bool()
+			ov.BoolValue = &b
+		case slog.KindInt64:
+			n := strconv.FormatInt(v.Int64(), 10)
+			ov.IntValue = &n
+		case slog.KindUint64:
+			n := strconv.FormatUint(v.Uint64(), 10)
+			ov.IntValue = &n
+		case slog.KindFloat64:
+			f := v.Float64()
+			ov.DoubleValue = &f
+		default:
+			str := v.String()
+			ov.StringValue = &str
+		}
+		out = append(out, otlpKeyValue{Key: key, Value: ov})
+	}
+	return out
+}
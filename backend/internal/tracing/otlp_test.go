@@ -0,0 +1,110 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOTLPExporter(t *testing.T) {
+	var mu sync.Mutex
+	var got []otlpRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Authorization") != "Bearer k" {
+			t.Errorf("request to %s with auth %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		var req otlpRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decoding export: %v", err)
+		}
+		mu.Lock()
+		got = append(got, req)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	e := NewOTLPExporter(OTLPOptions{
+		Endpoint:    server.URL,
+		Headers:     map[string]string{"Authorization": "Bearer k"},
+		ServiceName: "test-svc",
+		BatchSize:   2,
+		Interval:    time.Hour,
+	})
+	start := time.Unix(0, 1000)
+	for i := range 3 {
+		e.ExportSpan(Record{TraceID: TraceID{1}, SpanID: SpanID{byte(i + 1)}, Name: "op", Kind: KindServer, Start: start, End: start.Add(time.Microsecond)})
+	}
+	if err := e.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	total := 0
+	for _, req := range got {
+		rs := req.ResourceSpans[0]
+		if v := rs.Resource.Attributes[0].Value.StringValue; v == nil || *v != "test-svc" {
+			t.Errorf("service.name = %v", v)
+		}
+		total += len(rs.ScopeSpans[0].Spans)
+	}
+	if total != 3 {
+		t.Errorf("exported %d spans in %d requests, want 3", total, len(got))
+	}
+}
+
+func TestEncodeOTLP(t *testing.T) {
+	start := time.Unix(1, 0)
+	req := encodeOTLP("", []Record{{
+		TraceID:  TraceID{0xab},
+		SpanID:   SpanID{0xcd},
+		ParentID: SpanID{0xef},
+		Name:     "extract",
+		Kind:     KindInternal,
+		Start:    start,
+		End:      start.Add(time.Second),
+		Attrs: []slog.Attr{
+			slog.String("link_type", "pdf"),
+			slog.Int("bytes", 42),
+			slog.Bool("cached", false),
+			slog.Float64("ratio", 0.5),
+			slog.Group("http", slog.Int("status_code", 200)),
+		},
+		Error: "timeout",
+	}})
+
+	span := req.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	if span.TraceID != "ab000000000000000000000000000000" || span.ParentSpanID != "ef00000000000000" {
+		t.Errorf("ids = %s / %s", span.TraceID, span.ParentSpanID)
+	}
+	if span.StartTimeUnixNano != "1000000000" || span.EndTimeUnixNano != "2000000000" {
+		t.Errorf("times = %s - %s", span.StartTimeUnixNano, span.EndTimeUnixNano)
+	}
+	if span.Status == nil || span.Status.Code != 2 || span.Status.Message != "timeout" {
+		t.Errorf("status = %+v", span.Status)
+	}
+	keys := map[string]otlpValue{}
+	for _, kv := range span.Attributes {
+		keys[kv.Key] = kv.Value
+	}
+	if v := keys["bytes"].IntValue; v == nil || *v != "42" {
+		t.Errorf("bytes = %v", v)
+	}
+	if v := keys["http.status_code"].IntValue; v == nil || *v != "200" {
+		t.Errorf("group not flattened: %v", keys)
+	}
+	if v := keys["cached"].BoolValue; v == nil || *v {
+		t.Errorf("cached = %v", v)
+	}
+	if v := keys["ratio"].DoubleValue; v == nil || *v != 0.5 {
+		t.Errorf("ratio = %v", v)
+	}
+	if v := req.ResourceSpans[0].Resource.Attributes[0].Value.StringValue; *v != "scrum-agents" {
+		t.Errorf("default service name = %q", *v)
+	}
+}
@@ -0,0 +1,238 @@
+// Package tracing records OpenTelemetry-style spans for requests, content
+// extraction and LLM calls, and exports them as JSON log records or over
+// OTLP/HTTP. Tracing is off until an exporter is installed with
+// SetExporter; until then Start returns a nil *Span, whose methods do
+// nothing.
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TraceID identifies a trace, W3C Trace Context style.
+type TraceID [16]byte
+
+// String returns the ID in lowercase hex.
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+
+// IsValid reports whether t is not all zeros.
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+
+// SpanID identifies a span within a trace.
+type SpanID [8]byte
+
+// String returns the ID in lowercase hex.
+func (s SpanID) String() string { return hex.EncodeToString(s[:]) }
+
+// IsValid reports whether s is not all zeros.
+func (s SpanID) IsValid() bool { return s != SpanID{} }
+
+// Kind is the role of a span, with the values OTLP uses.
+type Kind int
+
+const (
+	KindInternal Kind = 1
+	KindServer   Kind = 2
+	KindClient   Kind = 3
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindServer:
+		return "server"
+	case KindClient:
+		return "client"
+	default:
+		return "internal"
+	}
+}
+
+// Record is a finished span, as handed to an Exporter.
+type Record struct {
+	TraceID  TraceID
+	SpanID   SpanID
+	ParentID SpanID // zero for a root span
+	Name     string
+	Kind     Kind
+	Start    time.Time
+	End      time.Time
+	Attrs    []slog.Attr
+	// Error is the message of the error recorded on the span, if any.
+	Error string
+}
+
+// Exporter receives finished spans. ExportSpan is called once per span, from
+// the goroutine ending it, and must not block for long.
+type Exporter interface {
+	ExportSpan(Record)
+	// Shutdown flushes buffered spans and releases resources.
+	Shutdown(ctx context.Context) error
+}
+
+type exporterBox struct{ Exporter }
+
+var exporter atomic.Pointer[exporterBox]
+
+// SetExporter installs e as the destination of every span ended from now
+// on; nil turns tracing off. It returns the previous exporter.
+func SetExporter(e Exporter) Exporter {
+	var old *exporterBox
+	if e == nil {
+		old = exporter.Swap(nil)
+	} else {
+		old = exporter.Swap(&exporterBox{e})
+	}
+	if old == nil {
+		return nil
+	}
+	return old.Exporter
+}
+
+// Enabled reports whether an exporter is installed.
+func Enabled() bool {
+	return exporter.Load() != nil
+}
+
+// Span is an operation being timed. A nil *Span is valid and records
+// nothing. Its methods are safe for concurrent use.
+type Span struct {
+	mu    sync.Mutex
+	rec   Record
+	ended bool
+}
+
+type spanKey struct{}
+
+// remoteKey is the context key for a parent span received from another
+// service (see Middleware).
+type remoteKey struct{}
+
+type remoteParent struct {
+	traceID TraceID
+	spanID  SpanID
+}
+
+// Start starts a span named name as a child of the span in ctx, or as the
+// root of a new trace, and returns a context carrying it. It returns ctx
+// unchanged and a nil span when tracing is off.
+func Start(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, *Span) {
+	return start(ctx, name, KindInternal, attrs)
+}
+
+func start(ctx context.Context, name string, kind Kind, attrs []slog.Attr) (context.Context, *Span) {
+	if !Enabled() {
+		return ctx, nil
+	}
+	s := &Span{rec: Record{
+		SpanID: newSpanID(),
+		Name:   name,
+		Kind:   kind,
+		Start:  time.Now(),
+		Attrs:  append([]slog.Attr(nil), attrs...),
+	}}
+	if parent := FromContext(ctx); parent != nil {
+		s.rec.TraceID, s.rec.ParentID = parent.rec.TraceID, parent.rec.SpanID
+	} else if remote, ok := ctx.Value(remoteKey{}).(remoteParent); ok {
+		s.rec.TraceID, s.rec.ParentID = remote.traceID, remote.spanID
+	} else {
+		s.rec.TraceID = newTraceID()
+	}
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// FromContext returns the span carried by ctx, or nil.
+func FromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// TraceID returns the ID of the span's trace, or the zero ID for a nil span.
+func (s *Span) TraceID() TraceID {
+	if s == nil {
+		return TraceID{}
+	}
+	return s.rec.TraceID
+}
+
+// SpanID returns the span's ID, or the zero ID for a nil span.
+func (s *Span) SpanID() SpanID {
+	if s == nil {
+		return SpanID{}
+	}
+	return s.rec.SpanID
+}
+
+// SetName renames the span.
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.rec.Name = name
+	s.mu.Unlock()
+}
+
+// SetAttrs adds attributes to the span.
+func (s *Span) SetAttrs(attrs ...slog.Attr) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.rec.Attrs = append(s.rec.Attrs, attrs...)
+	s.mu.Unlock()
+}
+
+// RecordError marks the span as failed with err. A nil err is ignored.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	s.rec.Error = err.Error()
+	s.mu.Unlock()
+}
+
+// End finishes the span and hands it to the exporter. Calls after the first
+// do nothing.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.rec.End = time.Now()
+	rec := s.rec
+	s.mu.Unlock()
+
+	if box := exporter.Load(); box != nil {
+		box.ExportSpan(rec)
+	}
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		binary.BigEndian.PutUint64(id[:8], rand.Uint64())
+		binary.BigEndian.PutUint64(id[8:], rand.Uint64())
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		binary.BigEndian.PutUint64(id[:], rand.Uint64())
+	}
+	return id
+}
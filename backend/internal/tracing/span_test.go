@@ -0,0 +1,91 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+)
+
+// memExporter keeps spans in memory.
+type memExporter struct {
+	mu    sync.Mutex
+	spans []Record
+}
+
+func (m *memExporter) ExportSpan(s Record) {
+	m.mu.Lock()
+	m.spans = append(m.spans, s)
+	m.mu.Unlock()
+}
+
+func (m *memExporter) Shutdown(context.Context) error { return nil }
+
+func (m *memExporter) records() []Record {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Record(nil), m.spans...)
+}
+
+// capture installs a memExporter for the duration of the test.
+func capture(t *testing.T) *memExporter {
+	t.Helper()
+	m := &memExporter{}
+	prev := SetExporter(m)
+	t.Cleanup(func() { SetExporter(prev) })
+	return m
+}
+
+func TestStart_Disabled(t *testing.T) {
+	prev := SetExporter(nil)
+	defer SetExporter(prev)
+
+	ctx := context.Background()
+	got, span := Start(ctx, "noop")
+	if span != nil || got != ctx {
+		t.Fatalf("Start() with tracing off = %v, %v; want ctx unchanged and nil span", got, span)
+	}
+	// A nil span is safe to use.
+	span.SetAttrs(slog.Int("n", 1))
+	span.RecordError(errors.New("boom"))
+	span.End()
+	if span.TraceID().IsValid() {
+		t.Error("nil span has a trace ID")
+	}
+}
+
+func TestStart_ParentChild(t *testing.T) {
+	m := capture(t)
+
+	ctx, root := Start(context.Background(), "root", slog.String("k", "v"))
+	_, child := Start(ctx, "child")
+	child.RecordError(errors.New("boom"))
+	child.End()
+	root.End()
+	root.End() // exported once
+
+	spans := m.records()
+	if len(spans) != 2 {
+		t.Fatalf("exported %d spans, want 2", len(spans))
+	}
+	c, r := spans[0], spans[1]
+	if c.Name != "child" || r.Name != "root" {
+		t.Fatalf("names = %q, %q", c.Name, r.Name)
+	}
+	if c.TraceID != r.TraceID || !r.TraceID.IsValid() {
+		t.Errorf("trace IDs differ: %s, %s", c.TraceID, r.TraceID)
+	}
+	if c.ParentID != r.SpanID || r.ParentID.IsValid() {
+		t.Errorf("child parent = %s, root = %s (parent %s)", c.ParentID, r.SpanID, r.ParentID)
+	}
+	if c.Error != "boom" || r.Error != "" {
+		t.Errorf("errors = %q, %q", c.Error, r.Error)
+	}
+	if len(r.Attrs) != 1 || r.Attrs[0].Key != "k" {
+		t.Errorf("root attrs = %v", r.Attrs)
+	}
+	if r.End.Before(r.Start) {
+		t.Error("span ends before it starts")
+	}
+}
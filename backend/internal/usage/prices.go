@@ -0,0 +1,81 @@
+// Package usage records the tokens every LLM call spends, prices them and
+// reports the totals per user, endpoint, provider and day.
+package usage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Price is what a model costs, in US dollars per million tokens.
+type Price struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+// Prices maps model names to their price. A name also prices the models
+// it prefixes, such as dated snapshots: "gpt-4o" prices "gpt-4o-2024-08-06".
+type Prices map[string]Price
+
+// DefaultPrices are the list prices of the default models of the built-in
+// providers. Self-hosted models cost nothing unless priced.
+var DefaultPrices = Prices{
+	"claude-sonnet-4":  {Input: 3, Output: 15},
+	"claude-opus-4":    {Input: 15, Output: 75},
+	"claude-haiku-4":   {Input: 1, Output: 5},
+	"gpt-4o":           {Input: 2.5, Output: 10},
+	"gpt-4o-mini":      {Input: 0.15, Output: 0.6},
+	"gemini-2.0-flash": {Input: 0.1, Output: 0.4},
+	"gemini-2.5-flash": {Input: 0.3, Output: 2.5},
+	"gemini-2.5-pro":   {Input: 1.25, Output: 10},
+}
+
+// Lookup returns the price of model: an exact match, or else the longest
+// name that prefixes it.
+func (p Prices) Lookup(model string) (Price, bool) {
+	if price, ok := p[model]; ok {
+		return price, true
+	}
+	best := ""
+	for name := range p {
+		if strings.HasPrefix(model, name) && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return Price{}, false
+	}
+	return p[best], true
+}
+
+// Cost returns the cost of a call to model in US dollars; unpriced models
+// cost nothing.
+func (p Prices) Cost(model string, inputTokens, outputTokens int) float64 {
+	price, _ := p.Lookup(model)
+	return (float64(inputTokens)*price.Input + float64(outputTokens)*price.Output) / 1e6
+}
+
+// Merge returns the prices of p overridden by other.
+func (p Prices) Merge(other Prices) Prices {
+	out := make(Prices, len(p)+len(other))
+	for k, v := range p {
+		out[k] = v
+	}
+	for k, v := range other {
+		out[k] = v
+	}
+	return out
+}
+
+// Validate reports prices that cannot be right.
+func (p Prices) Validate() error {
+	for name, price := range p {
+		if name == "" {
+			return fmt.Errorf("price with empty model name")
+		}
+		if price.Input < 0 || price.Output < 0 {
+			return fmt.Errorf("model %q: prices must not be negative", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package usage
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPrices_Lookup(t *testing.T) {
+	tests := []struct {
+		model string
+		want  Price
+		ok    bool
+	}{
+		{"gpt-4o", Price{2.5, 10}, true},
+		{"gpt-4o-2024-08-06", Price{2.5, 10}, true},
+		{"gpt-4o-mini-2024-07-18", Price{0.15, 0.6}, true}, // Longest prefix wins
+		{"claude-sonnet-4-6", Price{3, 15}, true},
+		{"llama3.2", Price{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := DefaultPrices.Lookup(tt.model)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Lookup(%q) = %+v, %v; want %+v, %v", tt.model, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPrices_Cost(t *testing.T) {
+	p := Prices{"m": {Input: 3, Output: 15}}
+	if got := p.Cost("m", 1000, 500); math.Abs(got-0.0105) > 1e-12 {
+		t.Errorf("Cost() = %v, want 0.0105", got)
+	}
+	if got := p.Cost("unpriced", 1000, 500); got != 0 {
+		t.Errorf("Cost(unpriced) = %v, want 0", got)
+	}
+}
+
+func TestPrices_MergeAndValidate(t *testing.T) {
+	merged := DefaultPrices.Merge(Prices{"gpt-4o": {Input: 1, Output: 2}, "local": {}})
+	if merged["gpt-4o"] != (Price{1, 2}) || merged["claude-sonnet-4"] != DefaultPrices["claude-sonnet-4"] {
+		t.Errorf("Merge() = %+v", merged)
+	}
+	if DefaultPrices["gpt-4o"] != (Price{2.5, 10}) {
+		t.Error("Merge() modified the receiver")
+	}
+
+	if err := (Prices{"m": {Input: -1}}).Validate(); err == nil {
+		t.Error("Validate(negative) error = nil")
+	}
+	if err := (Prices{"": {}}).Validate(); err == nil {
+		t.Error("Validate(empty name) error = nil")
+	}
+	if err := DefaultPrices.Validate(); err != nil {
+		t.Errorf("Validate(defaults) error = %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package usage
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/auth"
+	"github.com/rookiecj/scrum-agents/backend/internal/llm"
+	"github.com/rookiecj/scrum-agents/backend/internal/logging"
+	"github.com/rookiecj/scrum-agents/backend/internal/metrics"
+)
+
+var (
+	tokensTotal = metrics.NewCounter("llm_tokens_total",
+		"Tokens used by LLM calls, by provider, model and direction (input or output).",
+		"provider", "model", "direction")
+	costTotal = metrics.NewCounter("llm_cost_usd_total",
+		"Estimated cost of LLM calls in US dollars, by provider and model.",
+		"provider", "model")
+)
+
+// Recorder stores the usage of the LLM calls made while serving the
+// routes it wraps. A nil *Recorder is valid and records nothing.
+type Recorder struct {
+	store  *Store
+	prices Prices
+}
+
+// NewRecorder returns a Recorder that prices calls with prices and keeps
+// them in store.
+func NewRecorder(store *Store, prices Prices) *Recorder {
+	return &Recorder{store: store, prices: prices}
+}
+
+// Wrap returns h with the usage of its LLM calls recorded under endpoint,
+// for the user of the request. It must run after the auth middleware.
+func (rec *Recorder) Wrap(endpoint string, h http.Handler) http.Handler {
+	if rec == nil {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var userID int64
+		if claims := auth.UserFromContext(r.Context()); claims != nil {
+			userID = claims.UserID
+		}
+		rec.Track(r.Context(), endpoint, userID, func(ctx context.Context) {
+			h.ServeHTTP(w, r.WithContext(ctx))
+		})
+	})
+}
+
+// Track runs fn and records the usage of its LLM calls under endpoint for
+// userID, for work done outside a request such as background jobs.
+func (rec *Recorder) Track(ctx context.Context, endpoint string, userID int64, fn func(context.Context)) {
+	if rec == nil {
+		fn(ctx)
+		return
+	}
+	ctx, calls := llm.TrackUsage(ctx)
+	fn(ctx)
+
+	used := calls()
+	if len(used) == 0 {
+		return
+	}
+
+	records := make([]Record, len(used))
+	var total Totals
+	for i, u := range used {
+		records[i] = Record{
+			UserID:       userID,
+			Endpoint:     endpoint,
+			Provider:     string(u.Provider),
+			Model:        u.Model,
+			InputTokens:  u.InputTokens,
+			OutputTokens: u.OutputTokens,
+			CostUSD:      rec.prices.Cost(u.Model, u.InputTokens, u.OutputTokens),
+		}
+		tokensTotal.Add(float64(u.InputTokens), string(u.Provider), u.Model, "input")
+		tokensTotal.Add(float64(u.OutputTokens), string(u.Provider), u.Model, "output")
+		costTotal.Add(records[i].CostUSD, string(u.Provider), u.Model)
+		total.InputTokens += int64(u.InputTokens)
+		total.OutputTokens += int64(u.OutputTokens)
+		total.CostUSD += records[i].CostUSD
+	}
+	logging.AddAttrs(ctx,
+		slog.Int("llm_calls", len(used)),
+		slog.Int64("input_tokens", total.InputTokens),
+		slog.Int64("output_tokens", total.OutputTokens),
+		slog.Float64("cost_usd", total.CostUSD),
+	)
+	if err := rec.store.Add(records...); err != nil {
+		slog.ErrorContext(ctx, "usage: recording failed",
+			slog.String("endpoint", endpoint),
+			slog.String("error", err.Error()),
+		)
+	}
+}
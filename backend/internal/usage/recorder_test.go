@@ -0,0 +1,84 @@
+package usage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rookiecj/scrum-agents/backend/internal/auth"
+	"github.com/rookiecj/scrum-agents/backend/internal/llm"
+)
+
+func TestRecorder_Wrap(t *testing.T) {
+	s := testStore(t)
+	rec := NewRecorder(s, Prices{"gpt-x": {Input: 1, Output: 2}})
+
+	// A real provider against a stub server, so usage is reported the way
+	// it is in production.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"model":"gpt-x","choices":[{"message":{"content":"ok"}}],"usage":{"prompt_tokens":1000,"completion_tokens":500}}`))
+	}))
+	defer server.Close()
+	provider := llm.NewOpenAIProvider(llm.Config{APIKey: "k", Model: "gpt-x", BaseURL: server.URL})
+
+	inputBefore := tokensTotal.Value("openai", "gpt-x", "input")
+	h := rec.Wrap("POST /api/classify", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provider.Complete(r.Context(), "one")
+		provider.Complete(r.Context(), "two")
+	}))
+	jwtSvc := auth.NewJWTService("test-secret", time.Hour)
+	token, _ := jwtSvc.GenerateToken(1, "alice@example.com")
+	req := httptest.NewRequest("POST", "/api/classify", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	auth.Middleware(jwtSvc)(h).ServeHTTP(httptest.NewRecorder(), req)
+
+	total, err := s.Total(Filter{UserID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Totals{Calls: 2, InputTokens: 2000, OutputTokens: 1000, CostUSD: 0.004}
+	if total != want {
+		t.Errorf("Total() = %+v, want %+v", total, want)
+	}
+	if got := tokensTotal.Value("openai", "gpt-x", "input") - inputBefore; got != 2000 {
+		t.Errorf("llm_tokens_total input = %v, want 2000", got)
+	}
+	groups, _ := s.Breakdown(Filter{}, ByEndpoint)
+	if len(groups) != 1 || groups[0].Key != "POST /api/classify" {
+		t.Errorf("endpoints = %+v", groups)
+	}
+}
+
+func TestRecorder_Track(t *testing.T) {
+	s := testStore(t)
+	rec := NewRecorder(s, Prices{"gpt-x": {Input: 1, Output: 2}})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"model":"gpt-x","choices":[{"message":{"content":"ok"}}],"usage":{"prompt_tokens":100,"completion_tokens":50}}`))
+	}))
+	defer server.Close()
+	provider := llm.NewOpenAIProvider(llm.Config{APIKey: "k", Model: "gpt-x", BaseURL: server.URL})
+
+	rec.Track(context.Background(), "POST /api/jobs", 7, func(ctx context.Context) {
+		provider.Complete(ctx, "job")
+	})
+
+	total, err := s.Total(Filter{UserID: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total.Calls != 1 || total.InputTokens != 100 {
+		t.Errorf("Total() = %+v, want one call with 100 input tokens", total)
+	}
+}
+
+func TestRecorder_Nil(t *testing.T) {
+	var rec *Recorder
+	called := false
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true })
+	rec.Wrap("POST /api/classify", h).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", nil))
+	if !called {
+		t.Error("handler not called")
+	}
+}
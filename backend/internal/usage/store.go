@@ -0,0 +1,192 @@
+package usage
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const timeLayout = "2006-01-02 15:04:05"
+
+// Record is one priced LLM call.
+type Record struct {
+	UserID       int64 // Zero for anonymous callers
+	Endpoint     string
+	Provider     string
+	Model        string
+	InputTokens  int
+	OutputTokens int
+	CostUSD      float64
+	CreatedAt    time.Time
+}
+
+// Totals sums the usage of a set of calls.
+type Totals struct {
+	Calls        int     `json:"calls"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+// Group is the usage of the calls sharing a key.
+type Group struct {
+	Key string `json:"key"`
+	Totals
+}
+
+// Dimension is what usage is grouped by.
+type Dimension string
+
+const (
+	ByDay      Dimension = "day"
+	ByEndpoint Dimension = "endpoint"
+	ByModel    Dimension = "model" // Provider and model, as "provider/model"
+	ByUser     Dimension = "user"  // User email, or "anonymous"
+)
+
+// groupExprs are the SQL expressions of the dimensions.
+var groupExprs = map[Dimension]string{
+	ByDay:      "substr(u.created_at, 1, 10)",
+	ByEndpoint: "u.endpoint",
+	ByModel:    "u.provider || '/' || u.model",
+	ByUser:     "COALESCE(usr.email, 'anonymous')",
+}
+
+// Filter selects calls. Zero fields select everything.
+type Filter struct {
+	UserID int64
+	Since  time.Time
+	Until  time.Time
+}
+
+// Store keeps usage records in SQLite.
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore returns a Store backed by db, creating the table if needed. db
+// must also hold the users table, which reports are joined with.
+func NewStore(db *sql.DB) (*Store, error) {
+	const createTable = `
+		CREATE TABLE IF NOT EXISTS llm_usage (
+			id            INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id       INTEGER NOT NULL DEFAULT 0,
+			endpoint      TEXT    NOT NULL,
+			provider      TEXT    NOT NULL,
+			model         TEXT    NOT NULL,
+			input_tokens  INTEGER NOT NULL,
+			output_tokens INTEGER NOT NULL,
+			cost_usd      REAL    NOT NULL,
+			created_at    TEXT    NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_llm_usage_user_created ON llm_usage (user_id, created_at);
+		CREATE INDEX IF NOT EXISTS idx_llm_usage_created ON llm_usage (created_at);`
+	if _, err := db.Exec(createTable); err != nil {
+		return nil, fmt.Errorf("create usage table: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Add stores records.
+func (s *Store) Add(records ...Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin usage insert: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, r := range records {
+		created := r.CreatedAt
+		if created.IsZero() {
+			created = time.Now()
+		}
+		_, err := tx.Exec(`INSERT INTO llm_usage
+			(user_id, endpoint, provider, model, input_tokens, output_tokens, cost_usd, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			r.UserID, r.Endpoint, r.Provider, r.Model, r.InputTokens, r.OutputTokens, r.CostUSD,
+			created.UTC().Format(timeLayout))
+		if err != nil {
+			return fmt.Errorf("insert usage: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit usage: %w", err)
+	}
+	return nil
+}
+
+// Total returns the usage of the calls matching f.
+func (s *Store) Total(f Filter) (Totals, error) {
+	where, args := f.where()
+	var t Totals
+	err := s.db.QueryRow(`SELECT COUNT(*), COALESCE(SUM(u.input_tokens), 0),
+		COALESCE(SUM(u.output_tokens), 0), COALESCE(SUM(u.cost_usd), 0)
+		FROM llm_usage u`+where, args...).
+		Scan(&t.Calls, &t.InputTokens, &t.OutputTokens, &t.CostUSD)
+	if err != nil {
+		return Totals{}, fmt.Errorf("query usage: %w", err)
+	}
+	return t, nil
+}
+
+// Breakdown returns the usage of the calls matching f grouped by d. Days
+// are sorted in order, other groups by cost, highest first.
+func (s *Store) Breakdown(f Filter, d Dimension) ([]Group, error) {
+	expr, ok := groupExprs[d]
+	if !ok {
+		return nil, fmt.Errorf("unknown usage dimension %q", d)
+	}
+	order := "cost DESC, key"
+	if d == ByDay {
+		order = "key"
+	}
+	where, args := f.where()
+	rows, err := s.db.Query(`SELECT `+expr+` AS key, COUNT(*), SUM(u.input_tokens),
+		SUM(u.output_tokens), SUM(u.cost_usd) AS cost
+		FROM llm_usage u LEFT JOIN users usr ON usr.id = u.user_id`+where+`
+		GROUP BY key ORDER BY `+order, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query usage: %w", err)
+	}
+	defer rows.Close()
+
+	groups := []Group{}
+	for rows.Next() {
+		var g Group
+		if err := rows.Scan(&g.Key, &g.Calls, &g.InputTokens, &g.OutputTokens, &g.CostUSD); err != nil {
+			return nil, fmt.Errorf("scan usage: %w", err)
+		}
+		groups = append(groups, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query usage: %w", err)
+	}
+	return groups, nil
+}
+
+func (f Filter) where() (string, []any) {
+	var (
+		conds []string
+		args  []any
+	)
+	if f.UserID != 0 {
+		conds = append(conds, "u.user_id = ?")
+		args = append(args, f.UserID)
+	}
+	if !f.Since.IsZero() {
+		conds = append(conds, "u.created_at >= ?")
+		args = append(args, f.Since.UTC().Format(timeLayout))
+	}
+	if !f.Until.IsZero() {
+		conds = append(conds, "u.created_at < ?")
+		args = append(args, f.Until.UTC().Format(timeLayout))
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
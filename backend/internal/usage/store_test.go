@@ -0,0 +1,85 @@
+package usage
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// testStore returns a Store in a fresh database with alice (ID 1) as the
+// only user.
+func testStore(t *testing.T) *Store {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec(`CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT);
+		INSERT INTO users (id, email) VALUES (1, 'alice@example.com')`); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewStore(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestStore_Report(t *testing.T) {
+	s := testStore(t)
+	day1 := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	err := s.Add(
+		Record{UserID: 1, Endpoint: "POST /api/summarize", Provider: "claude", Model: "c", InputTokens: 100, OutputTokens: 10, CostUSD: 0.5, CreatedAt: day1},
+		Record{UserID: 1, Endpoint: "POST /api/classify", Provider: "openai", Model: "g", InputTokens: 50, OutputTokens: 5, CostUSD: 0.1, CreatedAt: day2},
+		Record{Endpoint: "POST /api/summarize", Provider: "claude", Model: "c", InputTokens: 10, OutputTokens: 1, CostUSD: 0.05, CreatedAt: day2},
+	)
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	total, err := s.Total(Filter{UserID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total.Calls != 2 || total.InputTokens != 150 || total.OutputTokens != 15 {
+		t.Errorf("Total(alice) = %+v", total)
+	}
+	if total, _ := s.Total(Filter{Since: day2.Truncate(24 * time.Hour)}); total.Calls != 2 {
+		t.Errorf("Total(since day 2) calls = %d, want 2", total.Calls)
+	}
+	if total, _ := s.Total(Filter{Until: day2.Truncate(24 * time.Hour)}); total.Calls != 1 {
+		t.Errorf("Total(until day 2) calls = %d, want 1", total.Calls)
+	}
+
+	tests := []struct {
+		dim  Dimension
+		keys []string
+	}{
+		{ByDay, []string{"2025-03-01", "2025-03-02"}},
+		{ByModel, []string{"claude/c", "openai/g"}},
+		{ByEndpoint, []string{"POST /api/summarize", "POST /api/classify"}},
+		{ByUser, []string{"alice@example.com", "anonymous"}},
+	}
+	for _, tt := range tests {
+		groups, err := s.Breakdown(Filter{}, tt.dim)
+		if err != nil {
+			t.Fatalf("Breakdown(%s) error = %v", tt.dim, err)
+		}
+		var keys []string
+		for _, g := range groups {
+			keys = append(keys, g.Key)
+		}
+		if len(keys) != len(tt.keys) || keys[0] != tt.keys[0] || keys[1] != tt.keys[1] {
+			t.Errorf("Breakdown(%s) keys = %v, want %v", tt.dim, keys, tt.keys)
+		}
+	}
+
+	if _, err := s.Breakdown(Filter{}, "provider; DROP TABLE llm_usage"); err == nil {
+		t.Error("Breakdown(unknown dimension) error = nil")
+	}
+}
@@ -7,13 +7,44 @@ import { logger } from './utils/logger'
 import type { SummarizeResponse, SummarizeStep, ProviderName } from './types/api'
 
 /**
- * Helper that performs a fetch with auth token and logs failures.
+ * Helper that performs a fetch with auth token and logs failures. When the
+ * access token has expired, it is renewed once with the refresh token and
+ * the request retried.
  */
-function createAuthFetch(authToken: string, onUnauthorized: () => void) {
-  return async function fetchWithAuth(url: string, init: RequestInit): Promise<Response> {
+function createAuthFetch(
+  authToken: string,
+  refreshToken: string | null,
+  onRefreshed: (token: string, refreshToken: string) => void,
+  onUnauthorized: () => void,
+) {
+  let current = authToken
+  let refresh = refreshToken
+
+  const send = (url: string, init: RequestInit) => {
     const headers = new Headers(init.headers)
-    headers.set('Authorization', `Bearer ${authToken}`)
-    const res = await fetch(url, { ...init, headers })
+    headers.set('Authorization', `Bearer ${current}`)
+    return fetch(url, { ...init, headers })
+  }
+
+  return async function fetchWithAuth(url: string, init: RequestInit): Promise<Response> {
+    let res = await send(url, init)
+    if (res.status === 401 && refresh) {
+      const refreshRes = await fetch('/api/token/refresh', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ refresh_token: refresh }),
+      })
+      if (refreshRes.ok) {
+        const data = await refreshRes.json()
+        current = data.token
+        refresh = data.refresh_token
+        onRefreshed(data.token, data.refresh_token)
+        logger.debug('Access token refreshed')
+        res = await send(url, init)
+      } else {
+        refresh = null
+      }
+    }
     if (!res.ok) {
       logger.error('API call failed', { url, status: res.status, statusText: res.statusText })
       if (res.status === 401) {
@@ -25,31 +56,65 @@ function createAuthFetch(authToken: string, onUnauthorized: () => void) {
 }
 
 const AUTH_TOKEN_KEY = 'auth_token'
+const REFRESH_TOKEN_KEY = 'refresh_token'
 
 export function App() {
   const [token, setToken] = useState<string | null>(() => localStorage.getItem(AUTH_TOKEN_KEY))
   const [step, setStep] = useState<SummarizeStep>('done')
   const [result, setResult] = useState<SummarizeResponse | null>(null)
 
-  const handleLogin = useCallback((newToken: string) => {
+  const storeTokens = useCallback((newToken: string, refreshToken?: string) => {
     localStorage.setItem(AUTH_TOKEN_KEY, newToken)
+    if (refreshToken) {
+      localStorage.setItem(REFRESH_TOKEN_KEY, refreshToken)
+    }
     setToken(newToken)
-    logger.info('User logged in')
   }, [])
 
-  const handleLogout = useCallback(() => {
+  const handleLogin = useCallback((newToken: string, refreshToken?: string) => {
+    storeTokens(newToken, refreshToken)
+    logger.info('User logged in')
+  }, [storeTokens])
+
+  const clearSession = useCallback(() => {
     localStorage.removeItem(AUTH_TOKEN_KEY)
+    localStorage.removeItem(REFRESH_TOKEN_KEY)
     setToken(null)
     setResult(null)
     setStep('done')
-    logger.info('User logged out')
   }, [])
 
+  const handleSessionExpired = useCallback(() => {
+    clearSession()
+    logger.info('Session expired')
+  }, [clearSession])
+
+  const handleLogout = useCallback(async () => {
+    const refreshToken = localStorage.getItem(REFRESH_TOKEN_KEY)
+    try {
+      // Revoke the session server-side; the local session ends either way.
+      await fetch('/api/logout', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json', Authorization: `Bearer ${token}` },
+        body: JSON.stringify({ refresh_token: refreshToken ?? undefined }),
+      })
+    } catch {
+      logger.warn('Logout request failed')
+    }
+    clearSession()
+    logger.info('User logged out')
+  }, [token, clearSession])
+
   if (!token) {
     return <LoginForm onLogin={handleLogin} />
   }
 
-  const fetchWithAuth = createAuthFetch(token, handleLogout)
+  const fetchWithAuth = createAuthFetch(
+    token,
+    localStorage.getItem(REFRESH_TOKEN_KEY),
+    storeTokens,
+    handleSessionExpired,
+  )
 
   const handleSubmit = async (url: string, provider: ProviderName) => {
     setResult(null)
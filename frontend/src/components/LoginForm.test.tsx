@@ -32,7 +32,7 @@ describe('LoginForm', () => {
   it('calls onLogin with token on successful login', async () => {
     mockFetch.mockResolvedValueOnce({
       ok: true,
-      json: async () => ({ token: 'test-jwt-token' }),
+      json: async () => ({ token: 'test-jwt-token', refresh_token: 'test-refresh-token' }),
     })
 
     const onLogin = vi.fn()
@@ -47,7 +47,7 @@ describe('LoginForm', () => {
     fireEvent.click(screen.getByRole('button', { name: 'Log In' }))
 
     await waitFor(() => {
-      expect(onLogin).toHaveBeenCalledWith('test-jwt-token')
+      expect(onLogin).toHaveBeenCalledWith('test-jwt-token', 'test-refresh-token')
     })
   })
 
@@ -81,7 +81,7 @@ describe('LoginForm', () => {
     // Second call: login success
     mockFetch.mockResolvedValueOnce({
       ok: true,
-      json: async () => ({ token: 'new-user-token' }),
+      json: async () => ({ token: 'new-user-token', refresh_token: 'new-refresh-token' }),
     })
 
     const onLogin = vi.fn()
@@ -99,7 +99,7 @@ describe('LoginForm', () => {
     fireEvent.click(screen.getByRole('button', { name: 'Sign Up' }))
 
     await waitFor(() => {
-      expect(onLogin).toHaveBeenCalledWith('new-user-token')
+      expect(onLogin).toHaveBeenCalledWith('new-user-token', 'new-refresh-token')
     })
 
     // Verify both signup and login were called
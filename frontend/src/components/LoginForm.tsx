@@ -1,7 +1,7 @@
 import { useState, FormEvent } from 'react'
 
 interface LoginFormProps {
-  onLogin: (token: string) => void
+  onLogin: (token: string, refreshToken?: string) => void
 }
 
 export function LoginForm({ onLogin }: LoginFormProps) {
@@ -45,7 +45,7 @@ export function LoginForm({ onLogin }: LoginFormProps) {
         return
       }
 
-      onLogin(loginData.token)
+      onLogin(loginData.token, loginData.refresh_token)
     } catch {
       setError('Network error. Please try again.')
     } finally {
@@ -48,6 +48,21 @@ describe('SummaryResult', () => {
     expect(screen.getByText('Author: Test Author')).toBeInTheDocument()
   })
 
+  it('renders source and publication date', () => {
+    const withSource: SummarizeResponse = {
+      ...mockResult,
+      link_info: {
+        ...mockResult.link_info,
+        site_name: 'Example Blog',
+        canonical_url: 'https://example.com/p/article',
+        date: '2024-03-01T12:00:00+09:00',
+      },
+    }
+    render(<SummaryResult result={withSource} />)
+    expect(screen.getByText('Example Blog')).toHaveAttribute('href', 'https://example.com/p/article')
+    expect(screen.getByText('Published: 2024-03-01')).toBeInTheDocument()
+  })
+
   it('renders error state', () => {
     const errorResult: SummarizeResponse = {
       ...mockResult,
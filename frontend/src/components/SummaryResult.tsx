@@ -100,9 +100,31 @@ export function SummaryResult({ result }: SummaryResultProps) {
         {result.summary}
       </div>
 
-      {result.link_info.author && (
-        <div style={{ marginTop: '1rem', fontSize: '0.85rem', color: '#718096' }}>
-          Author: {result.link_info.author}
+      {(result.link_info.author || result.link_info.site_name || result.link_info.date) && (
+        <div
+          style={{
+            display: 'flex',
+            gap: '0.75rem',
+            flexWrap: 'wrap',
+            marginTop: '1rem',
+            fontSize: '0.85rem',
+            color: '#718096',
+          }}
+        >
+          {result.link_info.author && <span>Author: {result.link_info.author}</span>}
+          {result.link_info.site_name && (
+            <a
+              href={result.link_info.canonical_url || result.link_info.url}
+              target="_blank"
+              rel="noopener noreferrer"
+              style={{ color: 'inherit' }}
+            >
+              {result.link_info.site_name}
+            </a>
+          )}
+          {result.link_info.date && (
+            <span>Published: {result.link_info.date.slice(0, 10)}</span>
+          )}
         </div>
       )}
     </div>
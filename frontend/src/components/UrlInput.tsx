@@ -68,6 +68,8 @@ export function UrlInput({ onSubmit, isLoading }: UrlInputProps) {
         return 'OpenAI'
       case 'gemini':
         return 'Gemini'
+      case 'ollama':
+        return 'Ollama'
       default:
         return name
     }
@@ -13,7 +13,15 @@ export interface LinkInfo {
   link_type: LinkType
   title?: string
   author?: string
+  // Publication and last-update dates: RFC 3339, or YYYY-MM-DD
   date?: string
+  modified?: string
+  site_name?: string
+  canonical_url?: string
+  // BCP 47 language tag, e.g. "ko-KR"
+  language?: string
+  image?: string
+  description?: string
 }
 
 export interface ClassificationResult {
@@ -21,11 +29,13 @@ export interface ClassificationResult {
   confidence: number
   secondary?: ContentCategory
   secondary_confidence?: number
+  // Set when only the beginning of a long text was classified
+  truncated?: boolean
 }
 
 export interface SummarizeRequest {
   url: string
-  provider?: 'claude' | 'openai' | 'gemini'
+  provider?: ProviderName
 }
 
 export interface SummarizeResponse {
@@ -37,7 +47,9 @@ export interface SummarizeResponse {
 
 export type SummarizeStep = 'detecting' | 'extracting' | 'classifying' | 'summarizing' | 'done' | 'error'
 
-export type ProviderName = 'claude' | 'openai' | 'gemini'
+// Names of OpenAI-compatible providers declared in the backend configuration
+// are also accepted, hence the open string.
+export type ProviderName = 'claude' | 'openai' | 'gemini' | 'ollama' | (string & {})
 
 export interface ProviderInfo {
   name: string